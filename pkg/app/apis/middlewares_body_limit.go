@@ -39,6 +39,16 @@ func BodyLimit(limitBytes int64) *hook.Handler[*core.RequestEvent] {
 	}
 }
 
+// ApplyBodyLimit applies the request body size limit to a single request.
+//
+// It is useful for handlers that decide the limit dynamically per request
+// (e.g. a gateway applying the limit of the matched route).
+// Returns ErrRequestEntityTooLarge if the submitted content length already
+// exceeds limitBytes, otherwise the body reads fail once the limit is reached.
+func ApplyBodyLimit(e *core.RequestEvent, limitBytes int64) error {
+	return applyBodyLimit(e, limitBytes)
+}
+
 func applyBodyLimit(e *core.RequestEvent, limitBytes int64) error {
 	// no limit
 	if limitBytes <= 0 {
@@ -67,16 +77,15 @@ type limitedReader struct {
 
 func (r *limitedReader) Read(b []byte) (int, error) {
 	n, err := r.ReadCloser.Read(b)
-	if err != nil {
-		return n, err
-	}
 
+	// count the bytes even if the read also returned an error
+	// (e.g. chunked bodies may return the last chunk together with io.EOF)
 	r.totalRead += int64(n)
 	if r.totalRead > r.limit {
 		return n, ErrRequestEntityTooLarge
 	}
 
-	return n, nil
+	return n, err
 }
 
 func (r *limitedReader) Reread() {
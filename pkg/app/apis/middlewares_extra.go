@@ -1,6 +1,7 @@
 package apis
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -184,6 +185,10 @@ type JWTConfig struct {
 }
 
 // JWTAuth returns a JWT authentication middleware.
+//
+// If the Validator also implements [core.IdentityVerifier], the signed identity
+// headers forwarded by the gateway are trusted first and the bearer token is
+// only parsed when those headers are absent.
 func JWTAuth(config JWTConfig) *hook.Handler[*core.RequestEvent] {
 	return &hook.Handler[*core.RequestEvent]{
 		Id:       DefaultJWTAuthMiddlewareId,
@@ -197,6 +202,22 @@ func JWTAuth(config JWTConfig) *hook.Handler[*core.RequestEvent] {
 				}
 			}
 
+			// 优先信任网关签名的身份头
+			if verifier, ok := config.Validator.(core.IdentityVerifier); ok {
+				claims, err := verifier.VerifyIdentity(e.Request.Header)
+				if err == nil {
+					SetAuthClaims(e, claims)
+					return e.Next()
+				}
+				if !errors.Is(err, core.ErrIdentityAbsent) {
+					authErr := router.NewUnauthorizedError("无效的网关身份签名", nil)
+					if config.ErrorHandler != nil {
+						return config.ErrorHandler(e, authErr)
+					}
+					return authErr
+				}
+			}
+
 			// 获取token
 			token := e.Request.Header.Get("Authorization")
 			if token == "" {
@@ -224,27 +245,31 @@ func JWTAuth(config JWTConfig) *hook.Handler[*core.RequestEvent] {
 				return authErr
 			}
 
-			// 将用户信息存入上下文
-			e.Set("userId", claims.UserID)
-			e.Set("username", claims.Username)
-			e.Set("roleId", claims.RoleID)
-			e.Set("roleCode", claims.RoleCode)
-			e.Set("claims", claims)
-
-			// 设置Auth字段
-			authMap := map[string]any{
-				"userId":   claims.UserID,
-				"username": claims.Username,
-				"roleId":   claims.RoleID,
-				"roleCode": claims.RoleCode,
-			}
-			e.Auth = &authMap
+			SetAuthClaims(e, claims)
 
 			return e.Next()
 		},
 	}
 }
 
+// SetAuthClaims 将已验证的用户信息存入请求上下文
+func SetAuthClaims(e *core.RequestEvent, claims *core.JWTClaims) {
+	e.Set("userId", claims.UserID)
+	e.Set("username", claims.Username)
+	e.Set("roleId", claims.RoleID)
+	e.Set("roleCode", claims.RoleCode)
+	e.Set("claims", claims)
+
+	// 设置Auth字段
+	authMap := map[string]any{
+		"userId":   claims.UserID,
+		"username": claims.Username,
+		"roleId":   claims.RoleID,
+		"roleCode": claims.RoleCode,
+	}
+	e.Auth = &authMap
+}
+
 // --- Auth Helper Functions ---
 
 // GetUserID 从上下文获取用户ID
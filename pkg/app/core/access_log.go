@@ -0,0 +1,23 @@
+package core
+
+import "time"
+
+// TopicAccessLog 网关访问日志的 PubSub 主题，消息内容为 []AccessLogRecord（批量投递）
+const TopicAccessLog = "log:access"
+
+// AccessLogRecord 网关代理请求的访问与审计记录
+type AccessLogRecord struct {
+	Time      time.Time `json:"time"`                // 请求开始时间
+	UserID    int64     `json:"userId"`              // JWT 中的用户ID，未认证为 0
+	Username  string    `json:"username"`            // JWT 中的用户名
+	Module    string    `json:"module"`              // 服务 basePath，静态路由为上游或服务名称
+	Service   string    `json:"service"`             // 转发的目标服务
+	Method    string    `json:"method"`              // HTTP 方法
+	Path      string    `json:"path"`                // 网关请求路径
+	Query     string    `json:"query,omitempty"`     // 原始查询字符串
+	Status    int       `json:"status"`              // 响应状态码
+	Duration  int64     `json:"duration"`            // 处理时长（毫秒）
+	IP        string    `json:"ip"`                  // 客户端IP
+	UserAgent string    `json:"userAgent"`           // 客户端 User-Agent
+	RequestID string    `json:"requestId,omitempty"` // 请求ID（X-Request-ID）
+}
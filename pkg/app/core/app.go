@@ -14,6 +14,7 @@ import (
 	"github.com/goback/pkg/app/tools/hook"
 	"github.com/goback/pkg/app/tools/store"
 	"github.com/goback/pkg/app/tools/subscriptions"
+	"github.com/goback/pkg/cache"
 )
 
 // App defines the main PocketBase app interface.
@@ -193,6 +194,9 @@ type App interface {
 	// UpdateRBACCache updates the RBAC cache with the given data.
 	UpdateRBACCache(data RBACData)
 
+	// ModuleCache returns a cache service client namespaced to the given module.
+	ModuleCache(module string) *cache.Cache
+
 	// ClearModuleCache clears the cache for the given module.
 	ClearModuleCache(module string)
 
@@ -240,15 +244,26 @@ type App interface {
 	// SubscribeLifecycleTopic subscribes to the lifecycle topic.
 	SubscribeLifecycleTopic() error
 
-	// SubscribeTopic subscribes to a custom topic.
-	SubscribeTopic(topic string, handler func(payload []byte)) error
+	// SubscribeTopic subscribes to a custom topic. A handler error leaves the
+	// message unacknowledged so that it is redelivered.
+	SubscribeTopic(topic string, handler func(payload []byte) error) error
 
 	// SubscribeTopicWithMessage subscribes to a custom topic with full message (PubSub only).
-	SubscribeTopicWithMessage(topic string, handler func(msg *PubSubMessage)) error
+	SubscribeTopicWithMessage(topic string, handler PubSubHandler) error
+
+	// SubscribeQueueTopic subscribes to a custom topic as a queue: each message
+	// is delivered to only one node of this service.
+	SubscribeQueueTopic(topic string, handler func(payload []byte) error) error
 
 	// PublishTopic publishes a message to a custom topic.
 	PublishTopic(topic string, payload []byte) error
 
 	// PublishTopicJSON publishes a JSON message to a custom topic.
 	PublishTopicJSON(topic string, data any) error
+
+	// PublishTopicContext publishes a message within ctx, propagating its trace to subscribers.
+	PublishTopicContext(ctx context.Context, topic string, payload []byte) error
+
+	// PublishTopicJSONContext publishes a JSON message within ctx, propagating its trace to subscribers.
+	PublishTopicJSONContext(ctx context.Context, topic string, data any) error
 }
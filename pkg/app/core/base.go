@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -22,6 +24,10 @@ import (
 	"github.com/goback/pkg/app/tools/router"
 	"github.com/goback/pkg/app/tools/store"
 	"github.com/goback/pkg/app/tools/subscriptions"
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/metrics"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 )
 
 const (
@@ -403,6 +409,20 @@ type BaseAppConfig struct {
 	// BasePath 服务基础路径（用于网关路由，如 "users"、"logs"）
 	BasePath string
 
+	// PublicPaths 网关无需认证即可访问的服务内路径（相对 BasePath，前缀匹配，如 "/health"、"/auth/login"）
+	// 其余路径默认由网关校验 JWT 后以签名请求头转发用户身份
+	PublicPaths []string
+
+	// Routes 额外的网关路由配置（可选，如为某些路径指定负载均衡策略）
+	Routes []pkgRegistry.RouteConfig
+
+	// Weight 节点权重（可选，网关加权随机策略使用，默认 1）
+	Weight int
+
+	// NodeID 节点ID（可选，为空时自动生成 "<ServiceName>-<随机串>"，
+	// 保证同一服务的多个副本在注册中心互不覆盖）
+	NodeID string
+
 	// Registry 自定义注册中心（可选，为空时不自动创建）
 	Registry registry.Registry
 
@@ -412,6 +432,9 @@ type BaseAppConfig struct {
 	// RedisAddr Redis 服务地址（用于 PubSub，如 "localhost:28090"）
 	// 如果为空，则从注册中心发现
 	RedisAddr string
+
+	// LeaderTTL 选主租约时长（可选，默认 DefaultLeaderTTL；leader 失联后最多经过该时长由其他副本接替）
+	LeaderTTL time.Duration
 }
 
 // -------------------------------------------------------------------
@@ -431,6 +454,16 @@ type JWTValidator interface {
 	ParseToken(token string) (*JWTClaims, error)
 }
 
+// ErrIdentityAbsent 请求中不包含网关转发的身份信息
+var ErrIdentityAbsent = errors.New("gateway identity headers absent")
+
+// IdentityVerifier 网关身份验证器接口
+// 网关校验JWT后通过签名请求头转发用户身份，后端服务据此信任请求，无需重复解析Token。
+// 请求不含身份头时返回 ErrIdentityAbsent。
+type IdentityVerifier interface {
+	VerifyIdentity(header http.Header) (*JWTClaims, error)
+}
+
 // ServiceInfo 服务注册信息
 type ServiceInfo struct {
 	Name     string            `json:"name"`
@@ -493,8 +526,9 @@ type BaseApp struct {
 	// 服务相关 - 直接使用 go-micro registry
 	registry    registry.Registry
 	regService  *registry.Service
-	serviceInfo *ServiceInfo // 简化的服务信息（用于内部）
-	pubsub      *PubSub      // 基于 Redis 的 PubSub 客户端
+	serviceInfo *ServiceInfo   // 简化的服务信息（用于内部）
+	pubsub      *PubSub        // 基于 Redis 的 PubSub 客户端
+	leader      *LeaderElector // 选主器，首次调用 Leader() 时创建
 
 	// 缓存相关
 	cacheSpaces map[string]*CacheSpace // module -> CacheSpace
@@ -546,14 +580,19 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 	if app.config.ServiceVersion == "" {
 		app.config.ServiceVersion = "1.0.0"
 	}
+	if app.config.NodeID == "" {
+		app.config.NodeID = pkgRegistry.NewNodeID(app.config.ServiceName)
+	}
 
 	app.initHooks()
+	app.cronInstance.SetRunHook(recordCronRun(app))
 
 	// 自动创建 PubSub 客户端（基于 Redis 服务的中心化广播）
 	// 如果 DisablePubSub 为 true，则不创建（用于 Redis 服务本身）
 	if config.ServiceAddress != "" && !config.DisablePubSub {
 		opts := []PubSubOption{
 			WithPubSubRegistry(config.Registry),
+			WithPubSubNodeID(app.config.NodeID),
 		}
 		// 如果配置了 Redis 地址，优先使用静态地址
 		if config.RedisAddr != "" {
@@ -577,12 +616,24 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		if config.BasePath != "" {
 			metadata["base_path"] = config.BasePath
 		}
+		routes := config.Routes
+		if config.BasePath != "" && len(config.PublicPaths) > 0 {
+			routes = append(pkgRegistry.NewPublicRoutes(config.BasePath, config.PublicPaths...), routes...)
+		}
+		if len(routes) > 0 {
+			if routesJSON, err := json.Marshal(routes); err == nil {
+				metadata["routes"] = string(routesJSON)
+			}
+		}
+		if config.Weight > 0 {
+			metadata[pkgRegistry.MetadataWeight] = strconv.Itoa(config.Weight)
+		}
 		app.regService = &registry.Service{
 			Name:    app.config.ServiceName,
 			Version: app.config.ServiceVersion,
 			Nodes: []*registry.Node{
 				{
-					Id:       app.config.ServiceName + "-1",
+					Id:       app.config.NodeID,
 					Address:  config.ServiceAddress,
 					Metadata: metadata,
 				},
@@ -840,14 +891,25 @@ func (app *BaseApp) UpdateRBACCache(data RBACData) {
 	)
 }
 
-// ClearModuleCache 清空指定模块的缓存
+// ModuleCache 返回模块在缓存服务中的命名空间客户端，键自动带 "<module>:" 前缀
+func (app *BaseApp) ModuleCache(module string) *cache.Cache {
+	return cache.Namespace(module)
+}
+
+// ClearModuleCache 清空指定模块的缓存（本地缓存空间与缓存服务中的模块命名空间）
 func (app *BaseApp) ClearModuleCache(module string) {
 	app.cacheMu.Lock()
-	defer app.cacheMu.Unlock()
-
 	if cs, ok := app.cacheSpaces[module]; ok {
 		cs.Clear()
 	}
+	app.cacheMu.Unlock()
+
+	n, err := app.ModuleCache(module).DeleteByPrefix("")
+	if err != nil {
+		app.Logger().Warn("clear module cache failed", "module", module, "error", err)
+		return
+	}
+	app.Logger().Debug("module cache cleared", "module", module, "keys", n)
 }
 
 // ClearAllCache 清空所有缓存
@@ -902,11 +964,12 @@ func (app *BaseApp) publishLifecycleEvent(event string, metadata any) error {
 }
 
 // handlePubSubLifecycleMessage 处理来自 PubSub 的生命周期消息
-func (app *BaseApp) handlePubSubLifecycleMessage(msg *PubSubMessage) {
+// 生命周期消息仅用于通知，处理失败只记录日志而不重试投递
+func (app *BaseApp) handlePubSubLifecycleMessage(msg *PubSubMessage) error {
 	var lifecycleMsg LifecycleMessage
 	if err := json.Unmarshal(msg.Payload, &lifecycleMsg); err != nil {
 		app.Logger().Error("unmarshal lifecycle message failed", "error", err)
-		return
+		return nil
 	}
 
 	// 过滤自身服务的事件，避免自触发
@@ -915,7 +978,7 @@ func (app *BaseApp) handlePubSubLifecycleMessage(msg *PubSubMessage) {
 			"service", lifecycleMsg.Service,
 			"event", lifecycleMsg.Event,
 		)
-		return
+		return nil
 	}
 
 	app.Logger().Debug("received lifecycle message via pubsub",
@@ -942,7 +1005,7 @@ func (app *BaseApp) handlePubSubLifecycleMessage(msg *PubSubMessage) {
 		h = app.onServiceStopped
 	default:
 		app.Logger().Warn("unknown lifecycle event", "event", lifecycleMsg.Event)
-		return
+		return nil
 	}
 
 	if err := h.Trigger(event, func(e *LifecycleEvent) error {
@@ -950,6 +1013,7 @@ func (app *BaseApp) handlePubSubLifecycleMessage(msg *PubSubMessage) {
 	}); err != nil {
 		app.Logger().Error("lifecycle event hook failed", "error", err, "event", lifecycleMsg.Event)
 	}
+	return nil
 }
 
 // getNodeID 获取节点ID
@@ -970,11 +1034,11 @@ func (app *BaseApp) SubscribeLifecycleTopic() error {
 	return fmt.Errorf("pubsub not configured")
 }
 
-// SubscribeTopic 订阅自定义主题
-func (app *BaseApp) SubscribeTopic(topic string, handler func(payload []byte)) error {
+// SubscribeTopic 订阅自定义主题，handler 返回错误时消息重试投递
+func (app *BaseApp) SubscribeTopic(topic string, handler func(payload []byte) error) error {
 	if app.pubsub != nil {
-		app.pubsub.Subscribe(topic, func(msg *PubSubMessage) {
-			handler(msg.Payload)
+		app.pubsub.Subscribe(topic, func(msg *PubSubMessage) error {
+			return handler(msg.Payload)
 		})
 		return nil
 	}
@@ -982,7 +1046,7 @@ func (app *BaseApp) SubscribeTopic(topic string, handler func(payload []byte)) e
 }
 
 // SubscribeTopicWithMessage 订阅自定义主题（完整消息，仅 PubSub 支持）
-func (app *BaseApp) SubscribeTopicWithMessage(topic string, handler func(msg *PubSubMessage)) error {
+func (app *BaseApp) SubscribeTopicWithMessage(topic string, handler PubSubHandler) error {
 	if app.pubsub == nil {
 		return fmt.Errorf("pubsub not configured")
 	}
@@ -990,24 +1054,46 @@ func (app *BaseApp) SubscribeTopicWithMessage(topic string, handler func(msg *Pu
 	return nil
 }
 
+// SubscribeQueueTopic 以队列方式订阅自定义主题，每条消息只由本服务的一个节点处理
+// handler 返回错误时消息重试投递，重试耗尽后进入死信主题
+func (app *BaseApp) SubscribeQueueTopic(topic string, handler func(payload []byte) error) error {
+	if app.pubsub == nil {
+		return fmt.Errorf("pubsub not configured")
+	}
+	app.pubsub.SubscribeQueue(topic, func(msg *PubSubMessage) error {
+		return handler(msg.Payload)
+	})
+	return nil
+}
+
 // PublishTopic 发布消息到自定义主题
 func (app *BaseApp) PublishTopic(topic string, payload []byte) error {
+	return app.PublishTopicContext(context.Background(), topic, payload)
+}
+
+// PublishTopicJSON 发布 JSON 消息到自定义主题
+func (app *BaseApp) PublishTopicJSON(topic string, data any) error {
+	return app.PublishTopicJSONContext(context.Background(), topic, data)
+}
+
+// PublishTopicContext 在 ctx 中发布消息到自定义主题（ctx 处于链路中时订阅方的处理 Span 作为其子 Span）
+func (app *BaseApp) PublishTopicContext(ctx context.Context, topic string, payload []byte) error {
 	if app.pubsub != nil {
-		return app.pubsub.Publish(topic, payload)
+		return app.pubsub.PublishContext(ctx, topic, payload)
 	}
 	return fmt.Errorf("pubsub not configured")
 }
 
-// PublishTopicJSON 发布 JSON 消息到自定义主题
-func (app *BaseApp) PublishTopicJSON(topic string, data any) error {
+// PublishTopicJSONContext 在 ctx 中发布 JSON 消息到自定义主题
+func (app *BaseApp) PublishTopicJSONContext(ctx context.Context, topic string, data any) error {
 	if app.pubsub != nil {
-		return app.pubsub.PublishJSON(topic, data)
+		return app.pubsub.PublishJSONContext(ctx, topic, data)
 	}
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
-	return app.PublishTopic(topic, payload)
+	return app.PublishTopicContext(ctx, topic, payload)
 }
 
 // SetServiceInfo sets the simplified service info (deprecated, use SetService).
@@ -1110,12 +1196,22 @@ func (app *BaseApp) Serve(config ServeConfig) error {
 		return event, nil
 	})
 
+	// 请求链路追踪（解析上游 traceparent 并记录服务端 Span）
+	pbRouter.Bind(TracingMiddleware())
+
+	// 请求指标与 Prometheus 抓取路由
+	pbRouter.Bind(MetricsMiddleware())
+	pbRouter.GET(MetricsPath, func(e *RequestEvent) error {
+		metrics.Default.Handler().ServeHTTP(e.Response, e.Request)
+		return nil
+	}).Hide()
+
 	// 注册 PubSub 接收路由（基于 Redis 服务的中心化广播）
 	if app.pubsub != nil {
 		pbRouter.POST("/_pubsub", func(e *RequestEvent) error {
 			app.pubsub.Handler()(e.Response, e.Request)
 			return nil
-		})
+		}).Hide()
 	}
 
 	// 基础请求上下文
@@ -1168,6 +1264,9 @@ func (app *BaseApp) Serve(config ServeConfig) error {
 	}
 
 	serveHookErr := app.OnServe().Trigger(serveEvent, func(e *ServeEvent) error {
+		// 所有路由注册完成后生成服务的 OpenAPI 文档
+		app.registerOpenAPI(e.Router)
+
 		handler, err := e.Router.BuildMux()
 		if err != nil {
 			return err
@@ -1243,6 +1342,9 @@ func (app *BaseApp) Serve(config ServeConfig) error {
 		app.Logger().Error("terminate hook failed", "error", err)
 	}
 
+	// 释放 leader 锁，由其他副本立即接替
+	app.stopLeader()
+
 	// 注销服务
 	if app.registry != nil && app.regService != nil {
 		if err := app.registry.Deregister(app.regService); err != nil {
@@ -1257,5 +1359,12 @@ func (app *BaseApp) Serve(config ServeConfig) error {
 		app.pubsub.Stop()
 	}
 
+	// 导出剩余的 Span
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		app.Logger().Error("flush traces failed", "error", err)
+	}
+
 	return nil
 }
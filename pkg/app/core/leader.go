@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/cache"
+)
+
+// DefaultLeaderTTL 选主租约的默认时长
+const DefaultLeaderTTL = 15 * time.Second
+
+// LeaderElector 基于缓存服务租约锁的选主
+// 同名服务的多个副本竞争同一把锁，持有者为 leader；leader 停止时释放锁，
+// 失联时租约到期后由其他副本接替
+type LeaderElector struct {
+	cache  *cache.Cache
+	name   string
+	owner  string
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu   sync.RWMutex
+	lock *cache.Lock
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewLeaderElector 创建选主器，name 为锁名，owner 为当前节点标识，ttl <= 0 时使用 DefaultLeaderTTL
+func NewLeaderElector(c *cache.Cache, name, owner string, ttl time.Duration, logger *slog.Logger) *LeaderElector {
+	if ttl <= 0 {
+		ttl = DefaultLeaderTTL
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LeaderElector{
+		cache:  c,
+		name:   name,
+		owner:  owner,
+		ttl:    ttl,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start 在后台开始竞选（重复调用无效）
+func (e *LeaderElector) Start() {
+	e.startOnce.Do(func() {
+		go e.run()
+	})
+}
+
+// Stop 停止竞选，当前为 leader 时释放锁以便其他副本立即接替
+func (e *LeaderElector) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	e.startOnce.Do(func() {
+		close(e.done)
+	})
+	<-e.done
+}
+
+// IsLeader 当前节点是否为 leader
+func (e *LeaderElector) IsLeader() bool {
+	_, ok := e.Token()
+	return ok
+}
+
+// Token 返回当选时获得的防护令牌，不是 leader 时返回 false
+// 令牌随每次换届递增，leader 写入共享资源时附带令牌，可拒绝已卸任 leader 的迟到写入
+func (e *LeaderElector) Token() (int64, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.lock == nil || e.lock.Context().Err() != nil {
+		return 0, false
+	}
+	return e.lock.Token(), true
+}
+
+// run 竞选循环：未当选时每 ttl/3 尝试获取锁，当选后等待锁丢失或停止
+func (e *LeaderElector) run() {
+	defer close(e.done)
+
+	retry := e.ttl / 3
+	for {
+		lock, ok, err := e.cache.TryLock(e.name, e.ttl, cache.WithLockOwner(e.owner))
+		if err != nil {
+			e.logger.Warn("leader election failed", "lock", e.name, "error", err)
+		}
+		if ok {
+			e.setLock(lock)
+			e.logger.Info("elected as leader", "lock", e.name, "owner", e.owner, "token", lock.Token())
+
+			select {
+			case <-lock.Context().Done():
+				e.setLock(nil)
+				e.logger.Warn("leadership lost", "lock", e.name, "cause", context.Cause(lock.Context()))
+			case <-e.stop:
+				e.setLock(nil)
+				if err := lock.Release(); err != nil {
+					e.logger.Warn("release leadership failed", "lock", e.name, "error", err)
+				}
+				return
+			}
+		}
+
+		t := time.NewTimer(retry)
+		select {
+		case <-t.C:
+		case <-e.stop:
+			t.Stop()
+			return
+		}
+	}
+}
+
+func (e *LeaderElector) setLock(lock *cache.Lock) {
+	e.mu.Lock()
+	e.lock = lock
+	e.mu.Unlock()
+}
+
+// -------------------------------------------------------------------
+// BaseApp Leader Methods
+// -------------------------------------------------------------------
+
+// Leader 返回服务的选主器，首次调用时开始竞选
+// 锁名为 "leader:<ServiceName>"，持有者为节点ID，服务退出时释放
+func (app *BaseApp) Leader() *LeaderElector {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.leader == nil {
+		app.leader = NewLeaderElector(cache.New(), "leader:"+app.ServiceName(), app.config.NodeID, app.config.LeaderTTL, app.Logger())
+		app.leader.Start()
+	}
+	return app.leader
+}
+
+// IsLeader 当前节点是否为服务的 leader（首次调用时开始竞选，当选前返回 false）
+func (app *BaseApp) IsLeader() bool {
+	return app.Leader().IsLeader()
+}
+
+// LeaderOnly 包装 fn，使其仅在当前节点为 leader 时执行，用于多副本部署中只需执行一次的定时任务：
+//
+//	app.Cron().MustAdd("cleanup", "0 3 * * *", app.LeaderOnly(cleanup))
+func (app *BaseApp) LeaderOnly(fn func()) func() {
+	leader := app.Leader()
+	return func() {
+		if leader.IsLeader() {
+			fn()
+		}
+	}
+}
+
+// stopLeader 停止竞选并释放 leader 锁（未开始竞选时无操作）
+func (app *BaseApp) stopLeader() {
+	app.mu.RLock()
+	leader := app.leader
+	app.mu.RUnlock()
+
+	if leader != nil {
+		leader.Stop()
+	}
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/goback/pkg/app/tools/cron"
+	"github.com/goback/pkg/app/tools/hook"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/metrics"
+)
+
+// DefaultMetricsMiddlewareId 请求指标中间件ID
+const DefaultMetricsMiddlewareId = "pbMetrics"
+
+// MetricsPath 指标抓取路径（BaseApp.Serve 自动注册）
+const MetricsPath = "/metrics"
+
+var (
+	httpRequestsTotal = metrics.NewCounterVec("http_requests_total",
+		"Total number of HTTP requests by route and status.", "route", "status")
+	httpRequestDuration = metrics.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by route and status.", nil, "route", "status")
+	httpRequestsInFlight = metrics.NewGaugeVec("http_requests_in_flight",
+		"Number of HTTP requests currently being served.")
+
+	cronRunsTotal = metrics.NewCounterVec("cron_job_runs_total",
+		"Total number of cron job runs.", "job")
+	cronFailuresTotal = metrics.NewCounterVec("cron_job_failures_total",
+		"Total number of failed cron job runs.", "job")
+	cronRunDuration = metrics.NewHistogramVec("cron_job_duration_seconds",
+		"Cron job run duration.", nil, "job")
+
+	pubsubPublishedTotal = metrics.NewCounterVec("pubsub_published_total",
+		"Total number of published pubsub messages by topic and result.", "topic", "result")
+	pubsubDeliveredTotal = metrics.NewCounterVec("pubsub_delivered_total",
+		"Total number of pubsub messages delivered to local handlers.", "topic")
+	pubsubDuplicatesTotal = metrics.NewCounterVec("pubsub_duplicates_total",
+		"Total number of redelivered pubsub messages skipped by message id.", "topic")
+)
+
+func init() {
+	metrics.MustRegister(
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		cronRunsTotal,
+		cronFailuresTotal,
+		cronRunDuration,
+		pubsubPublishedTotal,
+		pubsubDeliveredTotal,
+		pubsubDuplicatesTotal,
+	)
+}
+
+// MetricsMiddleware 请求指标中间件（BaseApp.Serve 自动注册）
+// 按路由模式（如 "GET /users/{id}"）与状态码统计请求数与耗时，以及正在处理的请求数
+func MetricsMiddleware() *hook.Handler[*RequestEvent] {
+	return &hook.Handler[*RequestEvent]{
+		Id:       DefaultMetricsMiddlewareId,
+		Priority: -99998, // 紧随链路追踪中间件
+		Func: func(e *RequestEvent) error {
+			start := time.Now()
+			inFlight := httpRequestsInFlight.With()
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			err := e.Next()
+
+			// 错误由路由统一写出响应，此时状态码尚未写入
+			status := e.Status()
+			if status == 0 && err != nil {
+				status = router.ToApiError(err).Status
+			}
+			route := e.Request.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+
+			labels := []string{route, strconv.Itoa(status)}
+			httpRequestsTotal.With(labels...).Inc()
+			httpRequestDuration.With(labels...).Observe(time.Since(start).Seconds())
+			return err
+		},
+	}
+}
+
+// recordCronRun 记录定时任务的执行结果
+func recordCronRun(app App) func(result *cron.RunResult) {
+	return func(result *cron.RunResult) {
+		cronRunsTotal.With(result.JobId).Inc()
+		cronRunDuration.With(result.JobId).Observe(result.Duration.Seconds())
+		if result.Err != nil {
+			cronFailuresTotal.With(result.JobId).Inc()
+			app.Logger().Error("cron job failed", "job", result.JobId, "error", result.Err)
+		}
+	}
+}
+
+// recordPublish 记录消息发布结果
+func recordPublish(topic string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	pubsubPublishedTotal.With(topic, result).Inc()
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/openapi"
+)
+
+// OpenAPIPath 服务 OpenAPI 文档的路径，网关据此拉取并合并各服务的文档
+const OpenAPIPath = "/openapi.json"
+
+// OpenAPI 根据路由注册时携带的文档生成服务的 OpenAPI 文档
+// 未声明方法的路由与内部路由不出现在文档中；PublicPaths 下的路由标记为无需认证
+func (app *BaseApp) OpenAPI(r *router.Router[*RequestEvent]) *openapi.Document {
+	doc := openapi.NewDocument(app.config.ServiceName, app.config.ServiceVersion)
+
+	r.Walk(func(prefix string, route *router.Route[*RequestEvent]) {
+		path := prefix + route.Path
+		if route.Method == "" || path == OpenAPIPath || (route.Doc != nil && route.Doc.Hidden) {
+			return
+		}
+
+		item := openapi.Route{
+			Method: route.Method,
+			Path:   path,
+			Tags:   []string{defaultTag(prefix, app.config.ServiceName)},
+			Public: app.isPublicPath(path),
+		}
+		if d := route.Doc; d != nil {
+			item.Summary = d.Summary
+			item.Request = d.Request
+			item.Response = d.Response
+			item.Paged = d.Paged
+			if len(d.Tags) > 0 {
+				item.Tags = d.Tags
+			}
+		}
+		doc.AddRoute(item)
+	})
+
+	return doc
+}
+
+// registerOpenAPI 注册服务 OpenAPI 文档路由（路由注册完成后调用）
+func (app *BaseApp) registerOpenAPI(r *router.Router[*RequestEvent]) {
+	if r.HasRoute(http.MethodGet, OpenAPIPath) {
+		return
+	}
+	doc := app.OpenAPI(r)
+	r.GET(OpenAPIPath, func(e *RequestEvent) error {
+		return e.JSON(http.StatusOK, doc)
+	})
+}
+
+// isPublicPath 服务内路径是否属于网关无需认证的 PublicPaths（前缀匹配）
+func (app *BaseApp) isPublicPath(path string) bool {
+	for _, p := range app.config.PublicPaths {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultTag 未指定分组的路由按所在分组前缀的最后一段归类，如 /dicts/dict-types -> dict-types
+func defaultTag(prefix, fallback string) string {
+	prefix = strings.Trim(prefix, "/")
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	if prefix == "" || strings.HasPrefix(prefix, "{") {
+		return fallback
+	}
+	return prefix
+}
@@ -2,29 +2,55 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/goback/pkg/tracing"
 	"go-micro.dev/v5/registry"
 )
 
+// PubSubDedupWindow 消息去重窗口，窗口内重复投递的同一消息ID只处理一次
+const PubSubDedupWindow = 10 * time.Minute
+
+// pubsubDedupSize 去重窗口内最多记录的消息ID数
+const pubsubDedupSize = 10000
+
 // PubSubMessage 发布/订阅消息
 type PubSubMessage struct {
+	ID        string    `json:"id,omitempty"` // 消息ID，Redis 服务重试投递时不变
 	Topic     string    `json:"topic"`
 	Sender    string    `json:"sender"`    // 发送者服务名
 	Payload   []byte    `json:"payload"`   // 消息内容
 	Timestamp time.Time `json:"timestamp"` // 发送时间
+
+	// TraceParent 发布时所在链路（W3C traceparent），订阅方的处理 Span 作为其子 Span
+	TraceParent string `json:"traceparent,omitempty"`
+
+	ctx context.Context
+}
+
+// Context 消息处理的上下文，包含订阅方处理消息的 Span
+func (m *PubSubMessage) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
 }
 
 // SubscribeRequest 订阅请求
 type SubscribeRequest struct {
-	Service      string   `json:"service"`       // 订阅服务名
-	CallbackAddr string   `json:"callback_addr"` // 回调地址（HTTP）
-	Topics       []string `json:"topics"`        // 订阅的主题列表
+	NodeID       string   `json:"node_id,omitempty"` // 订阅节点ID（同一服务的每个副本各自收到全部消息）
+	Service      string   `json:"service"`           // 订阅服务名
+	CallbackAddr string   `json:"callback_addr"`     // 回调地址（HTTP）
+	Topics       []string `json:"topics"`            // 订阅的主题列表
+	// QueueTopics Topics 中以队列方式订阅的主题：每条消息只投递给本服务的一个节点
+	QueueTopics []string `json:"queue_topics,omitempty"`
 }
 
 // PublishRequest 发布请求
@@ -34,8 +60,8 @@ type PublishRequest struct {
 	Payload []byte `json:"payload"`
 }
 
-// PubSubHandler 消息处理函数
-type PubSubHandler func(msg *PubSubMessage)
+// PubSubHandler 消息处理函数，返回错误时消息不被确认，由 Redis 服务重试投递
+type PubSubHandler func(msg *PubSubMessage) error
 
 // PubSubOption 配置选项
 type PubSubOption func(*PubSub)
@@ -43,15 +69,18 @@ type PubSubOption func(*PubSub)
 // PubSub 基于 Redis 服务的发布/订阅客户端
 type PubSub struct {
 	service      string            // 本服务名
+	nodeID       string            // 本节点ID（为空时 Redis 服务以回调地址区分节点）
 	callbackAddr string            // 本服务回调地址
 	redisAddr    string            // Redis 服务地址
 	registry     registry.Registry // 服务注册中心（用于动态发现 Redis 服务）
 	handlers     map[string][]PubSubHandler
+	queueTopics  map[string]bool // 以队列方式订阅的主题
 	mu           sync.RWMutex
 	client       *http.Client
 	logger       *slog.Logger
 	started      bool
 	stopCh       chan struct{}
+	seen         *seenMessages
 }
 
 // NewPubSub 创建 PubSub 客户端
@@ -60,11 +89,13 @@ func NewPubSub(service, callbackAddr string, opts ...PubSubOption) *PubSub {
 		service:      service,
 		callbackAddr: callbackAddr,
 		handlers:     make(map[string][]PubSubHandler),
+		queueTopics:  make(map[string]bool),
 		client: &http.Client{
 			Timeout: 5 * time.Second,
 		},
 		logger: slog.Default(),
 		stopCh: make(chan struct{}),
+		seen:   &seenMessages{ids: make(map[string]time.Time)},
 	}
 
 	for _, opt := range opts {
@@ -81,6 +112,13 @@ func WithRedisAddr(addr string) PubSubOption {
 	}
 }
 
+// WithPubSubNodeID 设置本节点ID
+func WithPubSubNodeID(nodeID string) PubSubOption {
+	return func(ps *PubSub) {
+		ps.nodeID = nodeID
+	}
+}
+
 // WithPubSubRegistry 设置服务注册中心（动态发现 Redis 服务）
 func WithPubSubRegistry(reg registry.Registry) PubSubOption {
 	return func(ps *PubSub) {
@@ -141,10 +179,23 @@ func (ps *PubSub) Stop() error {
 	return nil
 }
 
-// Subscribe 订阅主题
+// Subscribe 订阅主题，本服务的每个节点都收到全部消息
 func (ps *PubSub) Subscribe(topic string, handler PubSubHandler) error {
+	return ps.subscribe(topic, handler, false)
+}
+
+// SubscribeQueue 以队列方式订阅主题，每条消息只投递给本服务的一个节点（适用于写库等只需处理一次的消息）
+// 同一主题在本服务中只要有一次队列订阅，该主题即按队列方式投递
+func (ps *PubSub) SubscribeQueue(topic string, handler PubSubHandler) error {
+	return ps.subscribe(topic, handler, true)
+}
+
+func (ps *PubSub) subscribe(topic string, handler PubSubHandler, queue bool) error {
 	ps.mu.Lock()
 	ps.handlers[topic] = append(ps.handlers[topic], handler)
+	if queue {
+		ps.queueTopics[topic] = true
+	}
 	started := ps.started
 	ps.mu.Unlock()
 
@@ -160,6 +211,18 @@ func (ps *PubSub) Subscribe(topic string, handler PubSubHandler) error {
 
 // Publish 发布消息
 func (ps *PubSub) Publish(topic string, payload []byte) error {
+	return ps.PublishContext(context.Background(), topic, payload)
+}
+
+// PublishContext 在 ctx 中发布消息，ctx 处于链路中时消息携带 traceparent
+func (ps *PubSub) PublishContext(ctx context.Context, topic string, payload []byte) (err error) {
+	ctx, span := tracing.StartChild(ctx, "pubsub publish "+topic, tracing.SpanKindProducer)
+	defer func() {
+		recordPublish(topic, err)
+		span.RecordError(err)
+		span.End()
+	}()
+
 	redisAddr := ps.getRedisAddr()
 	if redisAddr == "" {
 		return fmt.Errorf("redis service not available")
@@ -177,7 +240,14 @@ func (ps *PubSub) Publish(topic string, payload []byte) error {
 	}
 
 	url := fmt.Sprintf("http://%s/pubsub/publish", redisAddr)
-	resp, err := ps.client.Post(url, "application/json", bytes.NewReader(data))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create publish request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, httpReq.Header)
+
+	resp, err := ps.client.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("publish to redis service: %w", err)
 	}
@@ -192,14 +262,21 @@ func (ps *PubSub) Publish(topic string, payload []byte) error {
 
 // PublishJSON 发布 JSON 消息
 func (ps *PubSub) PublishJSON(topic string, data any) error {
+	return ps.PublishJSONContext(context.Background(), topic, data)
+}
+
+// PublishJSONContext 在 ctx 中发布 JSON 消息
+func (ps *PubSub) PublishJSONContext(ctx context.Context, topic string, data any) error {
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal data: %w", err)
 	}
-	return ps.Publish(topic, payload)
+	return ps.PublishContext(ctx, topic, payload)
 }
 
 // Handler 返回 HTTP 处理器（用于接收 Redis 服务推送的消息）
+// 处理函数全部成功后才响应 200 作为确认，任一处理函数失败时响应 500，由 Redis 服务重试投递；
+// 已成功处理过的消息（相同 ID）重复投递时直接确认而不再处理
 func (ps *PubSub) Handler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -218,26 +295,65 @@ func (ps *PubSub) Handler() http.HandlerFunc {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
+		if msg.ID != "" && ps.seen.has(msg.ID, time.Now()) {
+			pubsubDuplicatesTotal.With(msg.Topic).Inc()
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 
-		ps.handleMessage(&msg)
+		if err := ps.handleMessage(&msg); err != nil {
+			ps.logger.Warn("handle pubsub message failed", "topic", msg.Topic, "id", msg.ID, "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// 处理成功后才记录，失败的消息重试投递时仍会处理
+		if msg.ID != "" {
+			ps.seen.add(msg.ID, time.Now())
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// handleMessage 处理接收到的消息
-func (ps *PubSub) handleMessage(msg *PubSubMessage) {
+// handleMessage 处理接收到的消息并等待处理完成，每个处理函数在各自的 Span 中并发执行
+// 返回所有处理函数的错误（panic 视为错误）
+func (ps *PubSub) handleMessage(msg *PubSubMessage) error {
 	ps.mu.RLock()
 	handlers := ps.handlers[msg.Topic]
 	ps.mu.RUnlock()
 
-	for _, handler := range handlers {
-		go handler(msg)
+	if len(handlers) > 0 {
+		pubsubDeliveredTotal.With(msg.Topic).Inc()
 	}
+
+	parent := tracing.ContextWithTraceParent(context.Background(), msg.TraceParent)
+	errs := make([]error, len(handlers))
+	var wg sync.WaitGroup
+	for i, handler := range handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tracing.Start(parent, "pubsub receive "+msg.Topic, tracing.SpanKindConsumer)
+			defer span.End()
+			span.SetAttribute("messaging.source", msg.Sender)
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("pubsub handler panic: %v", r)
+				}
+				span.RecordError(errs[i])
+			}()
+
+			m := *msg
+			m.ctx = ctx
+			errs[i] = handler(&m)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
-// HandleMessage 公开的消息处理方法（供外部调用）
-func (ps *PubSub) HandleMessage(msg *PubSubMessage) {
-	ps.handleMessage(msg)
+// HandleMessage 公开的消息处理方法（供外部调用），处理函数全部返回后返回其错误
+func (ps *PubSub) HandleMessage(msg *PubSubMessage) error {
+	return ps.handleMessage(msg)
 }
 
 // registerSubscription 向 Redis 服务注册订阅
@@ -248,10 +364,18 @@ func (ps *PubSub) registerSubscription(topics []string) error {
 	}
 
 	req := SubscribeRequest{
+		NodeID:       ps.nodeID,
 		Service:      ps.service,
 		CallbackAddr: ps.callbackAddr,
 		Topics:       topics,
 	}
+	ps.mu.RLock()
+	for _, topic := range topics {
+		if ps.queueTopics[topic] {
+			req.QueueTopics = append(req.QueueTopics, topic)
+		}
+	}
+	ps.mu.RUnlock()
 
 	data, err := json.Marshal(req)
 	if err != nil {
@@ -316,6 +440,45 @@ func (ps *PubSub) heartbeatLoop() {
 	}
 }
 
+// seenMessages 去重窗口内处理过的消息ID
+type seenMessages struct {
+	mu    sync.Mutex
+	ids   map[string]time.Time
+	order []string // 按接收顺序
+}
+
+// has 消息ID是否在窗口内出现过
+func (s *seenMessages) has(id string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, ok := s.ids[id]
+	return ok && now.Sub(seen) < PubSubDedupWindow
+}
+
+// add 记录消息ID，窗口内已出现过时返回 false
+func (s *seenMessages) add(id string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 移除超出窗口或容量的最早记录
+	for len(s.order) > 0 {
+		oldest := s.order[0]
+		if now.Sub(s.ids[oldest]) < PubSubDedupWindow && len(s.order) < pubsubDedupSize {
+			break
+		}
+		delete(s.ids, oldest)
+		s.order = s.order[1:]
+	}
+
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = now
+	s.order = append(s.order, id)
+	return true
+}
+
 // Service 返回服务名
 func (ps *PubSub) Service() string {
 	return ps.service
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/goback/pkg/app/tools/hook"
+	"github.com/goback/pkg/tracing"
+)
+
+// DefaultTracingMiddlewareId 链路追踪中间件ID
+const DefaultTracingMiddlewareId = "pbTracing"
+
+// TracingMiddleware 请求链路追踪中间件（BaseApp.Serve 自动注册）
+// 从 traceparent 请求头延续上游链路（没有时开始新的链路），请求上下文中携带服务端 Span，
+// 之后通过 e.Request.Context() 发起的缓存、数据库与消息发布调用均作为其子 Span
+func TracingMiddleware() *hook.Handler[*RequestEvent] {
+	return &hook.Handler[*RequestEvent]{
+		Id:       DefaultTracingMiddlewareId,
+		Priority: -99999, // 最先执行，覆盖其他中间件的耗时
+		Func: func(e *RequestEvent) error {
+			// 路由模式（如 "GET /users/{id}"）作为 Span 名称，避免路径参数导致名称过多
+			name := e.Request.Pattern
+			if name == "" {
+				name = e.Request.Method
+			}
+
+			ctx := tracing.Extract(e.Request.Context(), e.Request.Header)
+			ctx, span := tracing.Start(ctx, name, tracing.SpanKindServer)
+			defer span.End()
+
+			span.SetAttribute("http.method", e.Request.Method)
+			span.SetAttribute("http.target", e.Request.URL.Path)
+			span.SetAttribute("http.client_ip", e.RealIP())
+			e.Request = e.Request.WithContext(ctx)
+
+			err := e.Next()
+
+			status := e.Status()
+			if status != 0 {
+				span.SetAttribute("http.status_code", status)
+			}
+			if err != nil {
+				span.RecordError(err)
+			} else if status >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(status))
+			}
+			return err
+		},
+	}
+}
@@ -24,9 +24,19 @@ type Cron struct {
 	tickerDone chan bool
 	jobs       []*Job
 	interval   time.Duration
+	runHook    func(result *RunResult)
 	mux        sync.RWMutex
 }
 
+// RunResult describes a single finished scheduled job run.
+type RunResult struct {
+	JobId    string
+	Duration time.Duration
+
+	// Err is set when the job function panicked.
+	Err error
+}
+
 // New create a new Cron struct with default tick interval of 1 minute
 // and timezone in UTC.
 //
@@ -205,6 +215,18 @@ func (c *Cron) Start() {
 	c.mux.Unlock()
 }
 
+// SetRunHook registers a function that is called after each scheduled
+// job run (e.g. for collecting metrics).
+//
+// When a hook is set, job panics are recovered and reported
+// through [RunResult.Err] instead of crashing the process.
+func (c *Cron) SetRunHook(fn func(result *RunResult)) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.runHook = fn
+}
+
 // HasStarted checks whether the current Cron ticker has been started.
 func (c *Cron) HasStarted() bool {
 	c.mux.RLock()
@@ -222,7 +244,28 @@ func (c *Cron) runDue(t time.Time) {
 
 	for _, j := range c.jobs {
 		if j.schedule.IsDue(moment) {
-			go j.Run()
+			go c.run(j, c.runHook)
 		}
 	}
 }
+
+// run executes a single job and reports the result to the run hook (if any).
+func (c *Cron) run(j *Job, hook func(result *RunResult)) {
+	if hook == nil {
+		j.Run()
+		return
+	}
+
+	start := time.Now()
+	result := &RunResult{JobId: j.Id()}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Err = fmt.Errorf("cron job %q panicked: %v", j.Id(), r)
+		}
+		result.Duration = time.Since(start)
+		hook(result)
+	}()
+
+	j.Run()
+}
@@ -303,3 +303,38 @@ func TestCronStartStop(t *testing.T) {
 		t.Fatalf("Expected %d test2, got %d", expectedCalls, test2)
 	}
 }
+
+func TestCronRunHook(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	c.MustAdd("ok", "* * * * *", func() {})
+	c.MustAdd("fail", "* * * * *", func() {
+		panic("boom")
+	})
+
+	results := make(chan *RunResult, 2)
+	c.SetRunHook(func(result *RunResult) {
+		results <- result
+	})
+
+	c.runDue(time.Now())
+
+	got := map[string]*RunResult{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-results:
+			got[r.JobId] = r
+		case <-time.After(time.Second):
+			t.Fatal("Expected run hook to be called for each due job")
+		}
+	}
+
+	if got["ok"] == nil || got["ok"].Err != nil {
+		t.Fatalf("Expected successful ok run, got %+v", got["ok"])
+	}
+	if got["fail"] == nil || got["fail"].Err == nil {
+		t.Fatalf("Expected recovered panic for fail run, got %+v", got["fail"])
+	}
+}
@@ -229,3 +229,21 @@ var wildcardPlaceholderRegex = regexp.MustCompile(`/{.+\.\.\.}$`)
 func stripWildcard(pattern string) string {
 	return wildcardPlaceholderRegex.ReplaceAllString(pattern, "/")
 }
+
+// Walk 按注册顺序遍历当前分组（含子分组）下的全部路由，
+// prefix 为拼接后的分组前缀，路由完整路径为 prefix + route.Path
+func (group *RouterGroup[T]) Walk(fn func(prefix string, route *Route[T])) {
+	group.walk("", fn)
+}
+
+func (group *RouterGroup[T]) walk(prefix string, fn func(prefix string, route *Route[T])) {
+	prefix += group.Prefix
+	for _, child := range group.children {
+		switch v := child.(type) {
+		case *RouterGroup[T]:
+			v.walk(prefix, fn)
+		case *Route[T]:
+			fn(prefix, v)
+		}
+	}
+}
@@ -428,3 +428,23 @@ func TestRouterGroupHasRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestRouterGroupWalk(t *testing.T) {
+	t.Parallel()
+
+	g0 := RouterGroup[*Event]{Prefix: "/api"}
+	g0.GET("/health", nil)
+	users := g0.Group("/users")
+	users.POST("", nil)
+	users.Group("/{id}").GET("/roles", nil)
+
+	var paths []string
+	g0.Walk(func(prefix string, route *Route[*Event]) {
+		paths = append(paths, route.Method+" "+prefix+route.Path)
+	})
+
+	expected := []string{"GET /api/health", "POST /api/users", "GET /api/users/{id}/roles"}
+	if !slices.Equal(paths, expected) {
+		t.Fatalf("Expected routes %v, got %v", expected, paths)
+	}
+}
@@ -9,6 +9,9 @@ type Route[T hook.Resolver] struct {
 	Method      string
 	Path        string
 	Middlewares []*hook.Handler[T]
+
+	// Doc 路由文档（可选，用于生成 OpenAPI 文档）
+	Doc *RouteDoc
 }
 
 // BindFunc registers one or multiple middleware functions to the current route.
@@ -0,0 +1,64 @@
+package router
+
+// RouteDoc 路由文档描述
+type RouteDoc struct {
+	Summary string   // 接口摘要
+	Tags    []string // 接口分组，为空时按路由分组前缀归类
+
+	// Request 请求结构体实例，GET、HEAD 与 DELETE 请求按 query 标签生成查询参数，
+	// 其余方法按 json 标签生成请求体，binding:"required" 的字段为必填
+	Request any
+
+	// Response 成功响应中 data 字段的结构体实例
+	Response any
+
+	// Paged 是否为分页响应（data 为列表，附带 total、page、size）
+	Paged bool
+
+	// Hidden 是否不出现在文档中（如内部接口）
+	Hidden bool
+}
+
+// doc 获取路由文档，不存在时创建
+func (route *Route[T]) doc() *RouteDoc {
+	if route.Doc == nil {
+		route.Doc = &RouteDoc{}
+	}
+	return route.Doc
+}
+
+// Describe 设置路由的接口摘要与分组
+func (route *Route[T]) Describe(summary string, tags ...string) *Route[T] {
+	doc := route.doc()
+	doc.Summary = summary
+	if len(tags) > 0 {
+		doc.Tags = tags
+	}
+	return route
+}
+
+// Request 设置路由的请求结构，如 Request(CreateRequest{})
+func (route *Route[T]) Request(v any) *Route[T] {
+	route.doc().Request = v
+	return route
+}
+
+// Response 设置路由成功响应 data 字段的结构，如 Response(model.User{})
+func (route *Route[T]) Response(v any) *Route[T] {
+	route.doc().Response = v
+	return route
+}
+
+// PagedResponse 设置路由为分页响应，items 为列表元素的结构体实例，如 PagedResponse(model.User{})
+func (route *Route[T]) PagedResponse(item any) *Route[T] {
+	doc := route.doc()
+	doc.Response = item
+	doc.Paged = true
+	return route
+}
+
+// Hide 不在文档中展示该路由
+func (route *Route[T]) Hide() *Route[T] {
+	route.doc().Hidden = true
+	return route
+}
@@ -166,3 +166,24 @@ func TestRouteUnbind(t *testing.T) {
 		}
 	}
 }
+
+func TestRouteDoc(t *testing.T) {
+	t.Parallel()
+
+	type createRequest struct{ Name string }
+
+	r := Route[*Event]{}
+	r.Describe("create", "users").Request(createRequest{}).PagedResponse("")
+
+	if r.Doc == nil || r.Doc.Summary != "create" || len(r.Doc.Tags) != 1 || r.Doc.Tags[0] != "users" {
+		t.Fatalf("Unexpected doc %+v", r.Doc)
+	}
+	if _, ok := r.Doc.Request.(createRequest); !ok || !r.Doc.Paged || r.Doc.Hidden {
+		t.Fatalf("Unexpected doc %+v", r.Doc)
+	}
+
+	r.Hide()
+	if !r.Doc.Hidden {
+		t.Fatal("Expected route to be hidden")
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+)
+
+// 网关转发的身份请求头
+const (
+	HeaderUserID    = "X-Auth-User-Id"
+	HeaderUsername  = "X-Auth-Username"
+	HeaderRoleID    = "X-Auth-Role-Id"
+	HeaderRoleCode  = "X-Auth-Role-Code"
+	HeaderTimestamp = "X-Auth-Timestamp"
+	HeaderSignature = "X-Auth-Signature"
+)
+
+// IdentityHeaders 所有身份请求头（网关转发前需清除客户端传入的同名头）
+var IdentityHeaders = []string{
+	HeaderUserID,
+	HeaderUsername,
+	HeaderRoleID,
+	HeaderRoleCode,
+	HeaderTimestamp,
+	HeaderSignature,
+}
+
+// IdentityMaxSkew 身份签名的有效时间窗口
+const IdentityMaxSkew = 5 * time.Minute
+
+// 身份头相关错误
+var (
+	ErrIdentityInvalid = errors.New("gateway identity signature is invalid")
+	ErrIdentityExpired = errors.New("gateway identity signature has expired")
+)
+
+// 确保实现 core.IdentityVerifier 接口
+var _ core.IdentityVerifier = (*JWTManager)(nil)
+
+// SignIdentity 将已验证的用户身份写入签名请求头
+func (m *JWTManager) SignIdentity(header http.Header, claims *core.JWTClaims) {
+	StripIdentity(header)
+
+	userID := strconv.FormatInt(claims.UserID, 10)
+	username := url.QueryEscape(claims.Username)
+	roleID := strconv.FormatInt(claims.RoleID, 10)
+	roleCode := url.QueryEscape(claims.RoleCode)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	header.Set(HeaderUserID, userID)
+	header.Set(HeaderUsername, username)
+	header.Set(HeaderRoleID, roleID)
+	header.Set(HeaderRoleCode, roleCode)
+	header.Set(HeaderTimestamp, timestamp)
+	header.Set(HeaderSignature, m.identitySignature(userID, username, roleID, roleCode, timestamp))
+}
+
+// VerifyIdentity 校验网关签名的身份请求头
+// 实现 core.IdentityVerifier 接口
+func (m *JWTManager) VerifyIdentity(header http.Header) (*core.JWTClaims, error) {
+	signature := header.Get(HeaderSignature)
+	if signature == "" {
+		return nil, core.ErrIdentityAbsent
+	}
+
+	userID := header.Get(HeaderUserID)
+	username := header.Get(HeaderUsername)
+	roleID := header.Get(HeaderRoleID)
+	roleCode := header.Get(HeaderRoleCode)
+	timestamp := header.Get(HeaderTimestamp)
+
+	expected := m.identitySignature(userID, username, roleID, roleCode, timestamp)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrIdentityInvalid
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrIdentityInvalid
+	}
+	if skew := time.Since(time.Unix(ts, 0)); skew > IdentityMaxSkew || skew < -IdentityMaxSkew {
+		return nil, ErrIdentityExpired
+	}
+
+	claims := &core.JWTClaims{}
+	if claims.UserID, err = strconv.ParseInt(userID, 10, 64); err != nil {
+		return nil, ErrIdentityInvalid
+	}
+	if claims.RoleID, err = strconv.ParseInt(roleID, 10, 64); err != nil {
+		return nil, ErrIdentityInvalid
+	}
+	if claims.Username, err = url.QueryUnescape(username); err != nil {
+		return nil, ErrIdentityInvalid
+	}
+	if claims.RoleCode, err = url.QueryUnescape(roleCode); err != nil {
+		return nil, ErrIdentityInvalid
+	}
+
+	return claims, nil
+}
+
+// identitySignature 计算身份签名
+// 使用由JWT密钥派生的独立密钥，避免与Token签名共用同一密钥
+func (m *JWTManager) identitySignature(fields ...string) string {
+	keyMac := hmac.New(sha256.New, m.secret)
+	keyMac.Write([]byte("gateway-identity"))
+
+	mac := hmac.New(sha256.New, keyMac.Sum(nil))
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StripIdentity 清除请求中的身份头（防止客户端伪造）
+func StripIdentity(header http.Header) {
+	for _, h := range IdentityHeaders {
+		header.Del(h)
+	}
+}
@@ -0,0 +1,110 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/config"
+)
+
+func newTestManager(secret string) *auth.JWTManager {
+	return auth.NewJWTManager(&config.JWTConfig{Secret: secret, Issuer: "test", Expire: 3600})
+}
+
+func TestIdentityRoundTrip(t *testing.T) {
+	m := newTestManager("secret")
+
+	header := http.Header{}
+	m.SignIdentity(header, &core.JWTClaims{UserID: 42, Username: "张三 a&b", RoleID: 5, RoleCode: "ops"})
+
+	claims, err := m.VerifyIdentity(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 42 || claims.Username != "张三 a&b" || claims.RoleID != 5 || claims.RoleCode != "ops" {
+		t.Fatalf("Unexpected claims %+v", claims)
+	}
+}
+
+func TestIdentityAbsent(t *testing.T) {
+	m := newTestManager("secret")
+
+	_, err := m.VerifyIdentity(http.Header{})
+	if !errors.Is(err, core.ErrIdentityAbsent) {
+		t.Fatalf("Expected ErrIdentityAbsent, got %v", err)
+	}
+}
+
+func TestIdentityTampered(t *testing.T) {
+	m := newTestManager("secret")
+
+	scenarios := []struct {
+		name   string
+		modify func(h http.Header)
+		err    error
+	}{
+		{"changed user id", func(h http.Header) { h.Set(auth.HeaderUserID, "1") }, auth.ErrIdentityInvalid},
+		{"changed role code", func(h http.Header) { h.Set(auth.HeaderRoleCode, "superadmin") }, auth.ErrIdentityInvalid},
+		{"forged signature", func(h http.Header) { h.Set(auth.HeaderSignature, "deadbeef") }, auth.ErrIdentityInvalid},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			header := http.Header{}
+			m.SignIdentity(header, &core.JWTClaims{UserID: 42, Username: "bob", RoleID: 5, RoleCode: "ops"})
+			s.modify(header)
+
+			if _, err := m.VerifyIdentity(header); !errors.Is(err, s.err) {
+				t.Fatalf("Expected %v, got %v", s.err, err)
+			}
+		})
+	}
+}
+
+func TestIdentityDifferentSecret(t *testing.T) {
+	header := http.Header{}
+	newTestManager("secret-a").SignIdentity(header, &core.JWTClaims{UserID: 1, Username: "a"})
+
+	if _, err := newTestManager("secret-b").VerifyIdentity(header); !errors.Is(err, auth.ErrIdentityInvalid) {
+		t.Fatalf("Expected ErrIdentityInvalid, got %v", err)
+	}
+}
+
+func TestIdentityReplayedTimestamp(t *testing.T) {
+	m := newTestManager("secret")
+
+	header := http.Header{}
+	m.SignIdentity(header, &core.JWTClaims{UserID: 1, Username: "a"})
+
+	// 时间戳参与签名，回退时间戳无法延长有效期
+	old := strconv.FormatInt(time.Now().Add(-2*auth.IdentityMaxSkew).Unix(), 10)
+	header.Set(auth.HeaderTimestamp, old)
+
+	if _, err := m.VerifyIdentity(header); !errors.Is(err, auth.ErrIdentityInvalid) {
+		t.Fatalf("Expected ErrIdentityInvalid, got %v", err)
+	}
+}
+
+func TestStripIdentity(t *testing.T) {
+	header := http.Header{}
+	for _, h := range auth.IdentityHeaders {
+		header.Set(h, "x")
+	}
+	header.Set("Authorization", "Bearer token")
+
+	auth.StripIdentity(header)
+
+	for _, h := range auth.IdentityHeaders {
+		if header.Get(h) != "" {
+			t.Fatalf("Expected %s to be removed", h)
+		}
+	}
+	if header.Get("Authorization") == "" {
+		t.Fatal("Expected Authorization header to be preserved")
+	}
+}
@@ -1,13 +1,96 @@
 package cache
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/tracing"
+)
+
+// Backend 缓存后端
+// HTTPBackend 访问 services/redis 缓存服务（memory 模式），RedisBackend 以 RESP2 协议访问 Redis（standalone 模式）
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, expiration time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	IncrBy(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, time.Duration, error)
+	Keys(ctx context.Context) ([]string, error)
+	Clear(ctx context.Context) error
+	Close() error
+
+	// 批量与按键名操作
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	MSet(ctx context.Context, values map[string][]byte, expiration time.Duration) error
+	GetByPrefix(ctx context.Context, prefix string) (map[string][]byte, error)
+	DeleteByPrefix(ctx context.Context, prefix string) (int64, error)
+
+	// 条件写入
+	SetNX(ctx context.Context, key string, value []byte, expiration time.Duration) (bool, error)
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, expiration time.Duration) (bool, error)
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+
+	// 哈希
+	HSet(ctx context.Context, key string, fields map[string][]byte) (int64, error)
+	HGet(ctx context.Context, key, field string) ([]byte, bool, error)
+	HGetAll(ctx context.Context, key string) (map[string][]byte, error)
+	HDel(ctx context.Context, key string, fields ...string) (int64, error)
+	HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error)
+
+	// 列表，left 为 true 时操作头部
+	Push(ctx context.Context, key string, left bool, values ...[]byte) (int64, error)
+	Pop(ctx context.Context, key string, left bool) ([]byte, bool, error)
+	BlockingPop(ctx context.Context, keys []string, left bool, timeout time.Duration) (string, []byte, bool, error)
+	LRange(ctx context.Context, key string, start, stop int64) ([][]byte, error)
+	LLen(ctx context.Context, key string) (int64, error)
+
+	// 集合
+	SAdd(ctx context.Context, key string, members ...string) (int64, error)
+	SRem(ctx context.Context, key string, members ...string) (int64, error)
+	SIsMember(ctx context.Context, key, member string) (bool, error)
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SCard(ctx context.Context, key string) (int64, error)
+
+	// 有序集合
+	ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error)
+	ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error)
+	ZScore(ctx context.Context, key, member string) (float64, bool, error)
+	ZRem(ctx context.Context, key string, members ...string) (int64, error)
+	ZRank(ctx context.Context, key, member string, reverse bool) (int64, bool, error)
+	ZRange(ctx context.Context, key string, start, stop int64, reverse bool) ([]ZMember, error)
+	ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]ZMember, error)
+	ZCard(ctx context.Context, key string) (int64, error)
+
+	// 租约锁，同名锁每次被获取时发放递增的防护令牌
+	AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error)
+	RenewLock(ctx context.Context, name, owner string, token int64, ttl time.Duration) (bool, error)
+	ReleaseLock(ctx context.Context, name, owner string, token int64) (bool, error)
+	LockInfo(ctx context.Context, name string) (LockState, bool, error)
+}
+
+// ZMember 有序集合成员
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// LockState 锁状态
+type LockState struct {
+	Owner string        `json:"owner"`
+	Token int64         `json:"token"`
+	TTL   time.Duration `json:"-"` // 剩余租约
+}
+
+// 缓存模式（config.RedisConfig.Mode）
+const (
+	ModeMemory     = "memory"     // services/redis 缓存服务（JSON-over-HTTP）
+	ModeStandalone = "standalone" // 外部 Redis（RESP2）
 )
 
 // redisServiceURL Redis 服务地址
@@ -16,6 +99,7 @@ var (
 	httpClient      = &http.Client{Timeout: 3 * time.Second}
 	mu              sync.RWMutex
 	initialized     bool
+	globalBackend   Backend // 非空时 Global/New 使用该后端，否则访问 redisServiceURL
 )
 
 // Init 从配置初始化 Redis 服务地址
@@ -28,6 +112,20 @@ func Init(host string, port int) {
 	initialized = true
 }
 
+// InitWithConfig 按 cfg.Mode 选择后端：standalone 使用 RESP2 连接外部 Redis，其余访问缓存服务
+func InitWithConfig(cfg *config.RedisConfig) {
+	if cfg.Mode != ModeStandalone {
+		Init(cfg.Host, cfg.Port)
+		return
+	}
+	SetBackend(NewRedisBackend(RedisOptions{
+		Addr:     cfg.Addr(),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	}))
+}
+
 // SetRedisServiceURL 设置 Redis 服务地址
 func SetRedisServiceURL(url string) {
 	mu.Lock()
@@ -36,54 +134,110 @@ func SetRedisServiceURL(url string) {
 	mu.Unlock()
 }
 
-// getURL 获取当前 Redis 服务地址
-func getURL() string {
+// SetBackend 设置全局缓存后端（替换的旧后端会被关闭）
+func SetBackend(b Backend) {
+	mu.Lock()
+	prev := globalBackend
+	globalBackend = b
+	initialized = true
+	mu.Unlock()
+
+	if prev != nil && prev != b {
+		_ = prev.Close()
+	}
+}
+
+// getBackend 获取全局缓存后端
+func getBackend() Backend {
 	mu.RLock()
 	defer mu.RUnlock()
-	return redisServiceURL
+	if globalBackend != nil {
+		return globalBackend
+	}
+	return NewHTTPBackend(redisServiceURL)
 }
 
 // Cache 缓存客户端
 type Cache struct {
-	baseURL string
+	backend Backend
+	ctx     context.Context // WithContext 设置的请求上下文
+	prefix  string          // Namespace 设置的键前缀
 }
 
 // Global 获取全局缓存客户端
 func Global() *Cache {
-	return &Cache{baseURL: getURL()}
+	return &Cache{backend: getBackend()}
 }
 
 // New 创建新的缓存客户端
 func New() *Cache {
-	return &Cache{baseURL: getURL()}
+	return &Cache{backend: getBackend()}
 }
 
 // NewWithURL 创建指定 URL 的缓存客户端
 func NewWithURL(url string) *Cache {
-	return &Cache{baseURL: url}
+	return &Cache{backend: NewHTTPBackend(url)}
+}
+
+// NewWithBackend 创建使用指定后端的缓存客户端
+func NewWithBackend(b Backend) *Cache {
+	return &Cache{backend: b}
+}
+
+// Backend 返回缓存后端
+func (c *Cache) Backend() Backend {
+	return c.backend
+}
+
+// WithContext 返回在 ctx 中发出请求的副本：请求随 ctx 取消，
+// ctx 处于链路中时记录缓存调用的 Span 并传递给 Redis 服务
+func (c *Cache) WithContext(ctx context.Context) *Cache {
+	return &Cache{backend: c.backend, ctx: ctx, prefix: c.prefix}
 }
 
-// setRequest 设置请求体
-type setRequest struct {
-	Key   string `json:"key"`
-	Value []byte `json:"value"`
-	TTL   int64  `json:"ttl"`
+// Namespace 返回键名自动添加 "<name>:" 前缀的副本（可嵌套，如 rbac:roles:）
+// Keys、Clear 等只作用于该命名空间内的键，返回的键名不含前缀
+func (c *Cache) Namespace(name string) *Cache {
+	return &Cache{backend: c.backend, ctx: c.ctx, prefix: c.prefix + name + ":"}
 }
 
-// getRequest 获取请求体
-type getRequest struct {
-	Key string `json:"key"`
+// Prefix 返回命名空间前缀（未设置时为空）
+func (c *Cache) Prefix() string {
+	return c.prefix
 }
 
-// getResponse 获取响应体
-type getResponse struct {
-	Value []byte `json:"value"`
-	Found bool   `json:"found"`
+// key 添加命名空间前缀
+func (c *Cache) key(key string) string {
+	return c.prefix + key
 }
 
-// keysResponse 键列表响应
-type keysResponse struct {
-	Keys []string `json:"keys"`
+// keys 为多个键添加命名空间前缀
+func (c *Cache) keys(keys []string) []string {
+	if c.prefix == "" {
+		return keys
+	}
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = c.prefix + k
+	}
+	return prefixed
+}
+
+// do 在缓存调用的 Span 中执行 fn，并记录失败次数
+func (c *Cache) do(op string, fn func(ctx context.Context) error) error {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.StartChild(ctx, "cache "+op, tracing.SpanKindClient)
+	defer span.End()
+
+	err := fn(ctx)
+	if err != nil {
+		cacheErrorsTotal.With(op).Inc()
+		span.RecordError(err)
+	}
+	return err
 }
 
 // Set 设置缓存（永不过期）
@@ -102,23 +256,9 @@ func (c *Cache) SetWithExpiration(key string, value any, expiration time.Duratio
 
 // SetRaw 设置原始字节数据
 func (c *Cache) SetRaw(key string, value []byte, expiration time.Duration) error {
-	req := setRequest{
-		Key:   key,
-		Value: value,
-		TTL:   int64(expiration.Seconds()),
-	}
-	body, _ := json.Marshal(req)
-
-	resp, err := httpClient.Post(c.baseURL+"/cache/set", "application/json", bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("redis service unavailable (%s): %w", c.baseURL, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("redis service error (%s): status %d", c.baseURL, resp.StatusCode)
-	}
-	return nil
+	return c.do("set", func(ctx context.Context) error {
+		return c.backend.Set(ctx, c.key(key), value, expiration)
+	})
 }
 
 // Get 获取缓存
@@ -132,87 +272,82 @@ func (c *Cache) Get(key string, dest any) error {
 
 // GetRaw 获取原始字节数据
 func (c *Cache) GetRaw(key string) ([]byte, bool) {
-	req := getRequest{Key: key}
-	body, _ := json.Marshal(req)
-
-	resp, err := httpClient.Post(c.baseURL+"/cache/get", "application/json", bytes.NewReader(body))
+	var value []byte
+	var found bool
+	err := c.do("get", func(ctx context.Context) (err error) {
+		value, found, err = c.backend.Get(ctx, c.key(key))
+		return err
+	})
 	if err != nil {
 		return nil, false
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return nil, false
-	}
-
-	respBody, _ := io.ReadAll(resp.Body)
-	var result getResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, false
-	}
-
-	return result.Value, result.Found
+	recordLookup(found)
+	return value, found
 }
 
 // Delete 删除缓存
 func (c *Cache) Delete(key string) {
-	req := getRequest{Key: key}
-	body, _ := json.Marshal(req)
-
-	resp, err := httpClient.Post(c.baseURL+"/cache/delete", "application/json", bytes.NewReader(body))
-	if err != nil {
-		return
-	}
-	resp.Body.Close()
+	_ = c.do("delete", func(ctx context.Context) error {
+		return c.backend.Delete(ctx, c.key(key))
+	})
 }
 
 // Exists 检查键是否存在
 func (c *Cache) Exists(key string) bool {
-	req := getRequest{Key: key}
-	body, _ := json.Marshal(req)
-
-	resp, err := httpClient.Post(c.baseURL+"/cache/exists", "application/json", bytes.NewReader(body))
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	respBody, _ := io.ReadAll(resp.Body)
-	var result map[string]bool
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return false
-	}
+	var exists bool
+	_ = c.do("exists", func(ctx context.Context) (err error) {
+		exists, err = c.backend.Exists(ctx, c.key(key))
+		return err
+	})
+	return exists
+}
 
-	return result["exists"]
+// IncrBy 原子地将键的整数值增加 delta
+// 键不存在或已过期时从 0 开始，并设置 expiration 过期时间（按秒向上取整，0 表示永不过期）；
+// 返回增加后的值与剩余过期时间（0 表示永不过期）
+func (c *Cache) IncrBy(key string, delta int64, expiration time.Duration) (int64, time.Duration, error) {
+	var value int64
+	var remaining time.Duration
+	err := c.do("incr", func(ctx context.Context) (err error) {
+		value, remaining, err = c.backend.IncrBy(ctx, c.key(key), delta, expiration)
+		return err
+	})
+	return value, remaining, err
 }
 
 // Keys 获取所有键
 func (c *Cache) Keys() []string {
-	resp, err := httpClient.Get(c.baseURL + "/cache/keys")
-	if err != nil {
-		return nil
-	}
-	defer resp.Body.Close()
+	keys, _ := c.ListKeys()
+	return keys
+}
 
-	respBody, _ := io.ReadAll(resp.Body)
-	var result keysResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil
+// ListKeys 获取所有键，服务不可用时返回错误（用于区分"无数据"与"请求失败"）
+// 命名空间客户端以 SCAN 遍历该命名空间内的键
+func (c *Cache) ListKeys() ([]string, error) {
+	if c.prefix != "" {
+		return c.ScanAll("*")
 	}
-
-	return result.Keys
+	var keys []string
+	err := c.do("keys", func(ctx context.Context) (err error) {
+		keys, err = c.backend.Keys(ctx)
+		return err
+	})
+	return keys, err
 }
 
-// Clear 清空所有缓存
+// Clear 清空所有缓存，命名空间客户端只删除该命名空间内的键
 func (c *Cache) Clear() {
-	resp, err := httpClient.Post(c.baseURL+"/cache/clear", "application/json", nil)
-	if err != nil {
+	if c.prefix != "" {
+		_, _ = c.DeleteByPrefix("")
 		return
 	}
-	resp.Body.Close()
+	_ = c.do("clear", func(ctx context.Context) error {
+		return c.backend.Clear(ctx)
+	})
 }
 
-// Close 关闭（兼容接口，无实际操作）
+// Close 关闭（兼容接口，后端由 SetBackend 的调用方或全局 Close 释放）
 func (c *Cache) Close() {}
 
 // Count 获取缓存项数量
@@ -241,6 +376,19 @@ func Clear() {
 	Global().Clear()
 }
 
+// Namespace 返回全局缓存客户端的命名空间副本
+func Namespace(name string) *Cache {
+	return Global().Namespace(name)
+}
+
+// Close 关闭全局后端（RESP 连接池）
 func Close() {
-	// no-op
+	mu.Lock()
+	b := globalBackend
+	globalBackend = nil
+	mu.Unlock()
+
+	if b != nil {
+		_ = b.Close()
+	}
 }
@@ -0,0 +1,575 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/goback/pkg/tracing"
+)
+
+// HTTPBackend 通过 JSON-over-HTTP 访问 services/redis 缓存服务（memory 模式）
+type HTTPBackend struct {
+	baseURL string
+}
+
+// NewHTTPBackend 创建访问 baseURL（如 http://localhost:28090）的后端
+func NewHTTPBackend(baseURL string) *HTTPBackend {
+	return &HTTPBackend{baseURL: baseURL}
+}
+
+// setRequest 设置请求体
+type setRequest struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+	TTL   int64  `json:"ttl"`
+}
+
+// getRequest 获取请求体
+type getRequest struct {
+	Key string `json:"key"`
+}
+
+// getResponse 获取响应体
+type getResponse struct {
+	Value []byte `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// keysResponse 键列表响应
+type keysResponse struct {
+	Keys []string `json:"keys"`
+}
+
+// incrRequest 自增请求体
+type incrRequest struct {
+	Key   string `json:"key"`
+	Delta int64  `json:"delta"`
+	TTL   int64  `json:"ttl"`
+}
+
+// incrResponse 自增响应体
+type incrResponse struct {
+	Value int64 `json:"value"`
+	TTL   int64 `json:"ttl"`
+}
+
+// send 发送请求到 Redis 服务并读取响应体，ctx 处于链路中时通过 traceparent 传递
+func (b *HTTPBackend) send(ctx context.Context, method, path string, body any) ([]byte, error) {
+	return b.sendWith(ctx, httpClient, method, path, body)
+}
+
+// sendWith 使用指定的 HTTP 客户端发送请求
+func (b *HTTPBackend) sendWith(ctx context.Context, client *http.Client, method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("redis service unavailable (%s): %w", b.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("redis service error (%s): status %d: %s", b.baseURL, resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// Get 获取原始字节数据
+func (b *HTTPBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	body, err := b.send(ctx, http.MethodPost, "/cache/get", getRequest{Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	var result getResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, false, fmt.Errorf("unmarshal get: %w", err)
+	}
+	return result.Value, result.Found, nil
+}
+
+// Set 设置原始字节数据，expiration 按秒截断
+func (b *HTTPBackend) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	_, err := b.send(ctx, http.MethodPost, "/cache/set", setRequest{
+		Key:   key,
+		Value: value,
+		TTL:   int64(expiration.Seconds()),
+	})
+	return err
+}
+
+// Delete 删除键
+func (b *HTTPBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.send(ctx, http.MethodPost, "/cache/delete", getRequest{Key: key})
+	return err
+}
+
+// Exists 检查键是否存在
+func (b *HTTPBackend) Exists(ctx context.Context, key string) (bool, error) {
+	body, err := b.send(ctx, http.MethodPost, "/cache/exists", getRequest{Key: key})
+	if err != nil {
+		return false, err
+	}
+	var result map[string]bool
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("unmarshal exists: %w", err)
+	}
+	return result["exists"], nil
+}
+
+// IncrBy 原子自增，expiration 按秒向上取整
+func (b *HTTPBackend) IncrBy(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, time.Duration, error) {
+	var ttl int64
+	if expiration > 0 {
+		ttl = int64((expiration + time.Second - 1) / time.Second)
+	}
+	body, err := b.send(ctx, http.MethodPost, "/cache/incr", incrRequest{Key: key, Delta: delta, TTL: ttl})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var result incrResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, 0, fmt.Errorf("unmarshal incr: %w", err)
+	}
+
+	var remaining time.Duration
+	if result.TTL > 0 {
+		remaining = time.Duration(result.TTL) * time.Second
+	}
+	return result.Value, remaining, nil
+}
+
+// Keys 获取所有键
+func (b *HTTPBackend) Keys(ctx context.Context) ([]string, error) {
+	body, err := b.send(ctx, http.MethodGet, "/cache/keys", nil)
+	if err != nil {
+		return nil, err
+	}
+	var result keysResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal keys: %w", err)
+	}
+	return result.Keys, nil
+}
+
+// Clear 清空所有键
+func (b *HTTPBackend) Clear(ctx context.Context) error {
+	_, err := b.send(ctx, http.MethodPost, "/cache/clear", nil)
+	return err
+}
+
+// Close 无需释放资源
+func (b *HTTPBackend) Close() error {
+	return nil
+}
+
+// Scan 增量遍历匹配 glob 模式的键
+func (b *HTTPBackend) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	var result struct {
+		Keys   []string `json:"keys"`
+		Cursor uint64   `json:"cursor"`
+	}
+	err := b.post(ctx, "/cache/scan", map[string]any{"cursor": cursor, "match": match, "count": count}, &result)
+	return result.Keys, result.Cursor, err
+}
+
+// MGet 批量获取，不存在的键为 nil
+func (b *HTTPBackend) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	var result struct {
+		Values [][]byte `json:"values"`
+	}
+	err := b.post(ctx, "/cache/mget", map[string]any{"keys": keys}, &result)
+	return result.Values, err
+}
+
+// MSet 批量设置，expiration 按秒截断
+func (b *HTTPBackend) MSet(ctx context.Context, values map[string][]byte, expiration time.Duration) error {
+	var result struct{}
+	return b.post(ctx, "/cache/mset", map[string]any{"values": values, "ttl": int64(expiration.Seconds())}, &result)
+}
+
+// GetByPrefix 一次请求获取前缀下的全部字符串键
+func (b *HTTPBackend) GetByPrefix(ctx context.Context, prefix string) (map[string][]byte, error) {
+	var result struct {
+		Values map[string][]byte `json:"values"`
+	}
+	err := b.post(ctx, "/cache/prefix/get", map[string]any{"prefix": prefix}, &result)
+	return result.Values, err
+}
+
+// DeleteByPrefix 一次请求删除前缀下的全部键
+func (b *HTTPBackend) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	var result struct {
+		Deleted int64 `json:"deleted"`
+	}
+	err := b.post(ctx, "/cache/prefix/delete", map[string]any{"prefix": prefix}, &result)
+	return result.Deleted, err
+}
+
+// post 发送 POST 请求并将响应解析到 result
+func (b *HTTPBackend) post(ctx context.Context, path string, body, result any) error {
+	data, err := b.send(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	return nil
+}
+
+// SetNX 仅键不存在时写入，expiration 按秒截断
+func (b *HTTPBackend) SetNX(ctx context.Context, key string, value []byte, expiration time.Duration) (bool, error) {
+	var result struct {
+		Set bool `json:"set"`
+	}
+	err := b.post(ctx, "/cache/setnx", setRequest{Key: key, Value: value, TTL: int64(expiration.Seconds())}, &result)
+	return result.Set, err
+}
+
+// CompareAndSwap 当前值等于 old 时替换，expiration 按秒截断
+func (b *HTTPBackend) CompareAndSwap(ctx context.Context, key string, old, value []byte, expiration time.Duration) (bool, error) {
+	var result struct {
+		Swapped bool `json:"swapped"`
+	}
+	err := b.post(ctx, "/cache/cas", map[string]any{
+		"key":   key,
+		"old":   old,
+		"value": value,
+		"ttl":   int64(expiration.Seconds()),
+	}, &result)
+	return result.Swapped, err
+}
+
+// CompareAndDelete 当前值等于 old 时删除
+func (b *HTTPBackend) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	var result struct {
+		Deleted bool `json:"deleted"`
+	}
+	err := b.post(ctx, "/cache/cad", map[string]any{"key": key, "old": old}, &result)
+	return result.Deleted, err
+}
+
+// HSet 写入哈希字段
+func (b *HTTPBackend) HSet(ctx context.Context, key string, fields map[string][]byte) (int64, error) {
+	var result struct {
+		Added int64 `json:"added"`
+	}
+	err := b.post(ctx, "/cache/hset", map[string]any{"key": key, "fields": fields}, &result)
+	return result.Added, err
+}
+
+// HGet 获取哈希字段
+func (b *HTTPBackend) HGet(ctx context.Context, key, field string) ([]byte, bool, error) {
+	var result getResponse
+	err := b.post(ctx, "/cache/hget", map[string]any{"key": key, "field": field}, &result)
+	return result.Value, result.Found, err
+}
+
+// HGetAll 获取哈希的全部字段
+func (b *HTTPBackend) HGetAll(ctx context.Context, key string) (map[string][]byte, error) {
+	var result struct {
+		Fields map[string][]byte `json:"fields"`
+	}
+	err := b.post(ctx, "/cache/hgetall", getRequest{Key: key}, &result)
+	return result.Fields, err
+}
+
+// HDel 删除哈希字段
+func (b *HTTPBackend) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	var result struct {
+		Removed int64 `json:"removed"`
+	}
+	err := b.post(ctx, "/cache/hdel", map[string]any{"key": key, "fields": fields}, &result)
+	return result.Removed, err
+}
+
+// HIncrBy 哈希字段自增
+func (b *HTTPBackend) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	var result struct {
+		Value int64 `json:"value"`
+	}
+	err := b.post(ctx, "/cache/hincrby", map[string]any{"key": key, "field": field, "delta": delta}, &result)
+	return result.Value, err
+}
+
+// Push 写入列表
+func (b *HTTPBackend) Push(ctx context.Context, key string, left bool, values ...[]byte) (int64, error) {
+	var result struct {
+		Length int64 `json:"length"`
+	}
+	err := b.post(ctx, "/cache/push", map[string]any{"key": key, "values": values, "left": left}, &result)
+	return result.Length, err
+}
+
+// popResponse 列表弹出响应
+type popResponse struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// Pop 从列表弹出
+func (b *HTTPBackend) Pop(ctx context.Context, key string, left bool) ([]byte, bool, error) {
+	var result popResponse
+	err := b.post(ctx, "/cache/pop", map[string]any{"keys": []string{key}, "left": left}, &result)
+	return result.Value, result.Found, err
+}
+
+// maxBlockTimeout 缓存服务单次阻塞弹出的最长等待时间
+const maxBlockTimeout = time.Minute
+
+// blockingClient 阻塞弹出使用的 HTTP 客户端，超时由请求的 ctx 控制
+var blockingClient = &http.Client{}
+
+// BlockingPop 阻塞弹出，超过缓存服务单次等待上限时分多次请求
+func (b *HTTPBackend) BlockingPop(ctx context.Context, keys []string, left bool, timeout time.Duration) (string, []byte, bool, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		wait := maxBlockTimeout
+		if !deadline.IsZero() {
+			wait = min(wait, time.Until(deadline))
+			if wait < time.Millisecond {
+				return "", nil, false, nil
+			}
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, wait+httpClient.Timeout)
+		data, err := b.sendWith(reqCtx, blockingClient, http.MethodPost, "/cache/pop", map[string]any{
+			"keys":    keys,
+			"left":    left,
+			"timeout": (wait + time.Millisecond - 1).Milliseconds(), // 向上取整，不早于截止时间返回
+		})
+		cancel()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", nil, false, ctxErr
+			}
+			return "", nil, false, err
+		}
+
+		var result popResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", nil, false, fmt.Errorf("unmarshal pop: %w", err)
+		}
+		if result.Found {
+			return result.Key, result.Value, true, nil
+		}
+	}
+}
+
+// LRange 获取列表区间
+func (b *HTTPBackend) LRange(ctx context.Context, key string, start, stop int64) ([][]byte, error) {
+	var result struct {
+		Values [][]byte `json:"values"`
+	}
+	err := b.post(ctx, "/cache/lrange", map[string]any{"key": key, "start": start, "stop": stop}, &result)
+	return result.Values, err
+}
+
+// LLen 获取列表长度
+func (b *HTTPBackend) LLen(ctx context.Context, key string) (int64, error) {
+	var result struct {
+		Length int64 `json:"length"`
+	}
+	err := b.post(ctx, "/cache/llen", getRequest{Key: key}, &result)
+	return result.Length, err
+}
+
+// SAdd 添加集合成员
+func (b *HTTPBackend) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	var result struct {
+		Added int64 `json:"added"`
+	}
+	err := b.post(ctx, "/cache/sadd", map[string]any{"key": key, "members": members}, &result)
+	return result.Added, err
+}
+
+// SRem 删除集合成员
+func (b *HTTPBackend) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	var result struct {
+		Removed int64 `json:"removed"`
+	}
+	err := b.post(ctx, "/cache/srem", map[string]any{"key": key, "members": members}, &result)
+	return result.Removed, err
+}
+
+// SIsMember 检查集合成员
+func (b *HTTPBackend) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	var result struct {
+		Member bool `json:"member"`
+	}
+	err := b.post(ctx, "/cache/sismember", map[string]any{"key": key, "member": member}, &result)
+	return result.Member, err
+}
+
+// SMembers 获取集合全部成员
+func (b *HTTPBackend) SMembers(ctx context.Context, key string) ([]string, error) {
+	var result struct {
+		Members []string `json:"members"`
+	}
+	err := b.post(ctx, "/cache/smembers", getRequest{Key: key}, &result)
+	return result.Members, err
+}
+
+// SCard 获取集合成员数量
+func (b *HTTPBackend) SCard(ctx context.Context, key string) (int64, error) {
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	err := b.post(ctx, "/cache/scard", getRequest{Key: key}, &result)
+	return result.Count, err
+}
+
+// ZAdd 写入有序集合成员
+func (b *HTTPBackend) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	var result struct {
+		Added int64 `json:"added"`
+	}
+	err := b.post(ctx, "/cache/zadd", map[string]any{"key": key, "members": members}, &result)
+	return result.Added, err
+}
+
+// ZIncrBy 成员分数自增
+func (b *HTTPBackend) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	var result struct {
+		Score float64 `json:"score"`
+	}
+	err := b.post(ctx, "/cache/zincrby", map[string]any{"key": key, "member": member, "delta": delta}, &result)
+	return result.Score, err
+}
+
+// ZScore 获取成员分数
+func (b *HTTPBackend) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	var result struct {
+		Score float64 `json:"score"`
+		Found bool    `json:"found"`
+	}
+	err := b.post(ctx, "/cache/zscore", map[string]any{"key": key, "member": member}, &result)
+	return result.Score, result.Found, err
+}
+
+// ZRem 删除有序集合成员
+func (b *HTTPBackend) ZRem(ctx context.Context, key string, members ...string) (int64, error) {
+	var result struct {
+		Removed int64 `json:"removed"`
+	}
+	err := b.post(ctx, "/cache/zrem", map[string]any{"key": key, "members": members}, &result)
+	return result.Removed, err
+}
+
+// ZRank 获取成员排名
+func (b *HTTPBackend) ZRank(ctx context.Context, key, member string, reverse bool) (int64, bool, error) {
+	var result struct {
+		Rank  int64 `json:"rank"`
+		Found bool  `json:"found"`
+	}
+	err := b.post(ctx, "/cache/zrank", map[string]any{"key": key, "member": member, "reverse": reverse}, &result)
+	return result.Rank, result.Found, err
+}
+
+// ZRange 按排名区间获取成员
+func (b *HTTPBackend) ZRange(ctx context.Context, key string, start, stop int64, reverse bool) ([]ZMember, error) {
+	var result struct {
+		Members []ZMember `json:"members"`
+	}
+	err := b.post(ctx, "/cache/zrange", map[string]any{"key": key, "start": start, "stop": stop, "reverse": reverse}, &result)
+	return result.Members, err
+}
+
+// ZRangeByScore 按分数区间获取成员，无穷大的边界表示不限
+func (b *HTTPBackend) ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]ZMember, error) {
+	req := map[string]any{"key": key, "limit": limit}
+	if !math.IsInf(min, -1) {
+		req["min"] = min
+	}
+	if !math.IsInf(max, 1) {
+		req["max"] = max
+	}
+	var result struct {
+		Members []ZMember `json:"members"`
+	}
+	err := b.post(ctx, "/cache/zrangebyscore", req, &result)
+	return result.Members, err
+}
+
+// ZCard 获取有序集合成员数量
+func (b *HTTPBackend) ZCard(ctx context.Context, key string) (int64, error) {
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	err := b.post(ctx, "/cache/zcard", getRequest{Key: key}, &result)
+	return result.Count, err
+}
+
+// AcquireLock 获取租约锁，ttl 按毫秒截断
+func (b *HTTPBackend) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	var result struct {
+		Token    int64 `json:"token"`
+		Acquired bool  `json:"acquired"`
+	}
+	err := b.post(ctx, "/cache/lock/acquire", map[string]any{"name": name, "owner": owner, "ttl": ttl.Milliseconds()}, &result)
+	return result.Token, result.Acquired, err
+}
+
+// RenewLock 续约租约锁
+func (b *HTTPBackend) RenewLock(ctx context.Context, name, owner string, token int64, ttl time.Duration) (bool, error) {
+	var result struct {
+		Renewed bool `json:"renewed"`
+	}
+	err := b.post(ctx, "/cache/lock/renew", map[string]any{
+		"name":  name,
+		"owner": owner,
+		"token": token,
+		"ttl":   ttl.Milliseconds(),
+	}, &result)
+	return result.Renewed, err
+}
+
+// ReleaseLock 释放租约锁
+func (b *HTTPBackend) ReleaseLock(ctx context.Context, name, owner string, token int64) (bool, error) {
+	var result struct {
+		Released bool `json:"released"`
+	}
+	err := b.post(ctx, "/cache/lock/release", map[string]any{"name": name, "owner": owner, "token": token}, &result)
+	return result.Released, err
+}
+
+// LockInfo 获取锁状态
+func (b *HTTPBackend) LockInfo(ctx context.Context, name string) (LockState, bool, error) {
+	var result struct {
+		Owner string `json:"owner"`
+		Token int64  `json:"token"`
+		TTL   int64  `json:"ttl"`
+		Found bool   `json:"found"`
+	}
+	err := b.post(ctx, "/cache/lock/info", map[string]any{"name": name}, &result)
+	state := LockState{Owner: result.Owner, Token: result.Token, TTL: time.Duration(result.TTL) * time.Millisecond}
+	return state, result.Found, err
+}
@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 批量与按键名操作；命名空间客户端的键名、模式与前缀均相对于命名空间，返回的键名不含命名空间前缀
+
+// Scan 增量遍历匹配 glob 模式（*、?、[abc]，为空表示全部）的键，返回本批键与下一次的游标
+// 游标从 0 开始，返回 0 时遍历结束；count 为每批约返回的键数（<= 0 使用服务端默认值）
+func (c *Cache) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	if c.prefix != "" {
+		if match == "" {
+			match = "*"
+		}
+		match = escapeGlob(c.prefix) + match
+	}
+
+	var keys []string
+	var next uint64
+	err := c.do("scan", func(ctx context.Context) (err error) {
+		keys, next, err = c.backend.Scan(ctx, cursor, match, count)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.trimKeys(keys), next, nil
+}
+
+// ScanAll 以 Scan 分批遍历，返回全部匹配 match 的键
+func (c *Cache) ScanAll(match string) ([]string, error) {
+	keys := make([]string, 0)
+	var cursor uint64
+	for {
+		page, next, err := c.Scan(cursor, match, 1000)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+		if next == 0 {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+// MGet 批量获取原始字节数据，返回存在的键及其值
+func (c *Cache) MGet(keys ...string) (map[string][]byte, error) {
+	var values [][]byte
+	err := c.do("mget", func(ctx context.Context) (err error) {
+		values, err = c.backend.MGet(ctx, c.keys(keys)...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string][]byte, len(values))
+	for i, v := range values {
+		if v != nil && i < len(keys) {
+			found[keys[i]] = v
+		}
+	}
+	recordLookups(len(found), len(keys)-len(found))
+	return found, nil
+}
+
+// MSet 批量设置（值按 JSON 编码），expiration 为 0 表示永不过期
+func (c *Cache) MSet(values map[string]any, expiration time.Duration) error {
+	encoded := make(map[string][]byte, len(values))
+	for k, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal value %s: %w", k, err)
+		}
+		encoded[k] = data
+	}
+	return c.MSetRaw(encoded, expiration)
+}
+
+// MSetRaw 批量设置原始字节数据
+func (c *Cache) MSetRaw(values map[string][]byte, expiration time.Duration) error {
+	prefixed := values
+	if c.prefix != "" {
+		prefixed = make(map[string][]byte, len(values))
+		for k, v := range values {
+			prefixed[c.key(k)] = v
+		}
+	}
+	return c.do("mset", func(ctx context.Context) error {
+		return c.backend.MSet(ctx, prefixed, expiration)
+	})
+}
+
+// GetByPrefix 获取以 prefix 开头的全部字符串键及其原始值（缓存服务一次请求完成）
+func (c *Cache) GetByPrefix(prefix string) (map[string][]byte, error) {
+	var values map[string][]byte
+	err := c.do("getprefix", func(ctx context.Context) (err error) {
+		values, err = c.backend.GetByPrefix(ctx, c.key(prefix))
+		return err
+	})
+	if err != nil || c.prefix == "" {
+		return values, err
+	}
+
+	trimmed := make(map[string][]byte, len(values))
+	for k, v := range values {
+		trimmed[strings.TrimPrefix(k, c.prefix)] = v
+	}
+	return trimmed, nil
+}
+
+// DeleteByPrefix 删除以 prefix 开头的全部键，返回删除数量（缓存服务一次请求完成）
+func (c *Cache) DeleteByPrefix(prefix string) (int64, error) {
+	var n int64
+	err := c.do("deleteprefix", func(ctx context.Context) (err error) {
+		n, err = c.backend.DeleteByPrefix(ctx, c.key(prefix))
+		return err
+	})
+	return n, err
+}
+
+// trimKeys 去除命名空间前缀
+func (c *Cache) trimKeys(keys []string) []string {
+	if c.prefix == "" {
+		return keys
+	}
+	trimmed := make([]string, len(keys))
+	for i, k := range keys {
+		trimmed[i] = strings.TrimPrefix(k, c.prefix)
+	}
+	return trimmed
+}
+
+// escapeGlob 转义 glob 特殊字符，使 s 按字面匹配
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLockNotHeld 锁未被当前持有者持有（已释放、租约到期或已被他人获取）
+var ErrLockNotHeld = errors.New("cache: lock not held")
+
+// DefaultLockRetry 等待获取锁时的默认重试间隔
+const DefaultLockRetry = 100 * time.Millisecond
+
+// LockOption 锁选项
+type LockOption func(*lockOptions)
+
+type lockOptions struct {
+	owner string
+	wait  time.Duration
+	retry time.Duration
+}
+
+// WithLockOwner 设置持有者标识（默认每次获取生成 "<hostname>-<pid>-<随机串>"）
+// 同一持有者再次获取未过期的锁会成功，并得到原令牌
+func WithLockOwner(owner string) LockOption {
+	return func(o *lockOptions) {
+		o.owner = owner
+	}
+}
+
+// WithLockWait 锁被占用时在 wait 时间内重试获取（默认不等待）
+func WithLockWait(wait time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.wait = wait
+	}
+}
+
+// WithLockRetry 设置等待获取时的重试间隔（默认 DefaultLockRetry）
+func WithLockRetry(interval time.Duration) LockOption {
+	return func(o *lockOptions) {
+		if interval > 0 {
+			o.retry = interval
+		}
+	}
+}
+
+// Lock 已获取的租约锁
+// 持有期间在后台每 ttl/3 续约一次；锁被他人获取，或续约持续失败直到租约到期时视为丢失，
+// 此时 Context 以 ErrLockNotHeld 为原因取消。写入受保护的资源时应附带 Token，
+// 由资源方拒绝令牌小于已见最大值的写入，避免暂停后恢复的旧持有者覆盖新持有者的结果
+type Lock struct {
+	c      *Cache
+	name   string
+	owner  string
+	token  int64
+	ttl    time.Duration
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// TryLock 获取租约为 ttl 的锁，锁被占用时返回 false（设置 WithLockWait 时在等待时间内重试）
+// 获取成功后需调用 Release 释放；等待随 WithContext 设置的 ctx 取消；锁名带命名空间前缀
+func (c *Cache) TryLock(name string, ttl time.Duration, opts ...LockOption) (*Lock, bool, error) {
+	name = c.key(name)
+	o := lockOptions{retry: DefaultLockRetry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.owner == "" {
+		o.owner = newLockOwner()
+	}
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline := time.Now().Add(o.wait)
+
+	for {
+		start := time.Now()
+		var token int64
+		var ok bool
+		err := c.do("lock", func(ctx context.Context) (err error) {
+			token, ok, err = c.backend.AcquireLock(ctx, name, o.owner, ttl)
+			return err
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			return newLock(c.backend, name, o.owner, token, ttl, start), true, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, false, nil
+		}
+		t := time.NewTimer(min(o.retry, remaining))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// LockInfo 返回锁的持有者、令牌与剩余租约，锁未被持有时返回 false
+func (c *Cache) LockInfo(name string) (LockState, bool, error) {
+	var state LockState
+	var ok bool
+	err := c.do("lock_info", func(ctx context.Context) (err error) {
+		state, ok, err = c.backend.LockInfo(ctx, c.key(name))
+		return err
+	})
+	return state, ok, err
+}
+
+// newLock 创建已获取的锁并开始续约，acquired 为发出获取请求的时间（租约从此时起算）
+func newLock(b Backend, name, owner string, token int64, ttl time.Duration, acquired time.Time) *Lock {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	l := &Lock{
+		c:      &Cache{backend: b},
+		name:   name,
+		owner:  owner,
+		token:  token,
+		ttl:    ttl,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go l.renewLoop(acquired.Add(ttl))
+	return l
+}
+
+// Name 返回锁名（含命名空间前缀）
+func (l *Lock) Name() string {
+	return l.name
+}
+
+// Owner 返回持有者标识
+func (l *Lock) Owner() string {
+	return l.owner
+}
+
+// Token 返回防护令牌，同名锁每次被获取时递增
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Context 返回随锁丢失或释放而取消的 ctx，丢失时 context.Cause 为 ErrLockNotHeld
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Release 停止续约并释放锁，锁已丢失时返回 ErrLockNotHeld
+func (l *Lock) Release() error {
+	l.cancel(context.Canceled)
+	<-l.done
+
+	var ok bool
+	err := l.c.do("unlock", func(ctx context.Context) (err error) {
+		ok, err = l.c.backend.ReleaseLock(ctx, l.name, l.owner, l.token)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// renewLoop 定期续约，直到释放或锁丢失
+// 租约到期时由定时器立即取消 Context，不等待下一次续约才发现
+func (l *Lock) renewLoop(expires time.Time) {
+	defer close(l.done)
+
+	expiry := time.AfterFunc(time.Until(expires), func() {
+		l.cancel(fmt.Errorf("%w: lease expired", ErrLockNotHeld))
+	})
+	defer expiry.Stop()
+
+	ticker := time.NewTicker(max(l.ttl/3, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		var ok bool
+		err := l.c.do("lock_renew", func(ctx context.Context) (err error) {
+			// 单次续约不超过剩余租约
+			ctx, cancel := context.WithDeadline(ctx, expires)
+			defer cancel()
+			ok, err = l.c.backend.RenewLock(ctx, l.name, l.owner, l.token, l.ttl)
+			return err
+		})
+		switch {
+		case err == nil && ok:
+			expires = start.Add(l.ttl)
+			expiry.Reset(time.Until(expires))
+		case err == nil:
+			l.cancel(ErrLockNotHeld)
+			return
+		case !time.Now().Before(expires):
+			l.cancel(fmt.Errorf("%w: renew failed until lease expired: %v", ErrLockNotHeld, err))
+			return
+		}
+	}
+}
+
+// newLockOwner 生成默认持有者标识
+func newLockOwner() string {
+	hostname, _ := os.Hostname()
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"github.com/goback/pkg/metrics"
+)
+
+var (
+	cacheHitsTotal = metrics.NewCounterVec("cache_hits_total",
+		"Total number of cache lookups that found the key.")
+	cacheMissesTotal = metrics.NewCounterVec("cache_misses_total",
+		"Total number of cache lookups that did not find the key.")
+	cacheErrorsTotal = metrics.NewCounterVec("cache_errors_total",
+		"Total number of failed requests to the cache service by operation.", "operation")
+)
+
+func init() {
+	metrics.MustRegister(cacheHitsTotal, cacheMissesTotal, cacheErrorsTotal)
+}
+
+// recordLookup 记录一次查找的命中结果
+func recordLookup(found bool) {
+	if found {
+		cacheHitsTotal.With().Inc()
+	} else {
+		cacheMissesTotal.With().Inc()
+	}
+}
+
+// recordLookups 记录批量查找的命中与未命中次数
+func recordLookups(hits, misses int) {
+	if hits > 0 {
+		cacheHitsTotal.With().Add(float64(hits))
+	}
+	if misses > 0 {
+		cacheMissesTotal.With().Add(float64(misses))
+	}
+}
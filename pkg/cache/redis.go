@@ -0,0 +1,862 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/resp"
+)
+
+// 默认 Redis 连接参数
+const (
+	DefaultPoolSize    = 10
+	DefaultDialTimeout = 3 * time.Second
+	DefaultIOTimeout   = 3 * time.Second
+)
+
+// ErrClosed 后端已关闭
+var ErrClosed = errors.New("cache: backend closed")
+
+// RedisOptions Redis 后端参数
+type RedisOptions struct {
+	Addr        string        // host:port
+	Password    string        // 非空时连接后执行 AUTH
+	DB          int           // 非 0 时连接后执行 SELECT
+	PoolSize    int           // 最大连接数，默认 10
+	DialTimeout time.Duration // 建立连接超时，默认 3 秒
+	IOTimeout   time.Duration // ctx 没有截止时间时单条命令的读写超时，默认 3 秒
+}
+
+// RedisBackend 通过 RESP2 协议访问 Redis（standalone 模式）或 services/redis 的 RESP 监听
+type RedisBackend struct {
+	opts RedisOptions
+
+	idle   chan *redisConn // 空闲连接
+	slots  chan struct{}   // 连接名额，容量为 PoolSize
+	mu     sync.Mutex
+	closed bool
+}
+
+// redisConn 单个连接
+type redisConn struct {
+	conn net.Conn
+	r    *resp.Reader
+	w    *resp.Writer
+}
+
+// NewRedisBackend 创建 Redis 后端，连接在首次使用时建立
+func NewRedisBackend(opts RedisOptions) *RedisBackend {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultPoolSize
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.IOTimeout <= 0 {
+		opts.IOTimeout = DefaultIOTimeout
+	}
+	return &RedisBackend{
+		opts:  opts,
+		idle:  make(chan *redisConn, opts.PoolSize),
+		slots: make(chan struct{}, opts.PoolSize),
+	}
+}
+
+// Do 执行一条命令，服务端错误回复以 resp.Error 返回
+func (b *RedisBackend) Do(ctx context.Context, args ...any) (resp.Value, error) {
+	replies, err := b.Pipeline(ctx, args)
+	if err != nil {
+		return resp.Value{}, err
+	}
+	return replies[0], replies[0].Err()
+}
+
+// Pipeline 在同一连接上依次发送多条命令后读取全部回复（一次往返）
+// 单条命令的错误回复不影响其他命令，由调用方通过 Value.Err 检查
+func (b *RedisBackend) Pipeline(ctx context.Context, cmds ...[]any) ([]resp.Value, error) {
+	return b.pipeline(ctx, b.opts.IOTimeout, cmds)
+}
+
+// pipeline 以 timeout 作为 ctx 没有截止时间时的读写超时（<= 0 表示不限）执行命令
+func (b *RedisBackend) pipeline(ctx context.Context, timeout time.Duration, cmds [][]any) ([]resp.Value, error) {
+	c, err := b.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := c.roundTrip(ctx, timeout, cmds)
+	if err != nil {
+		// 连接状态未知（可能残留未读取的回复），直接丢弃
+		b.discard(c)
+		return nil, fmt.Errorf("redis %s: %w", b.opts.Addr, err)
+	}
+	b.put(c)
+	return replies, nil
+}
+
+// roundTrip 发送命令并读取回复
+func (c *redisConn) roundTrip(ctx context.Context, timeout time.Duration, cmds [][]any) ([]resp.Value, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok && timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// ctx 取消时中断阻塞的读写
+	stop := context.AfterFunc(ctx, func() {
+		c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	for _, cmd := range cmds {
+		if err := c.w.WriteCommand(cmd...); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]resp.Value, len(cmds))
+	for i := range replies {
+		v, err := c.r.ReadValue()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, err
+		}
+		replies[i] = v
+	}
+	return replies, nil
+}
+
+// get 取出空闲连接，没有时在名额内新建，名额用尽时等待
+func (b *RedisBackend) get(ctx context.Context) (*redisConn, error) {
+	for {
+		b.mu.Lock()
+		closed := b.closed
+		b.mu.Unlock()
+		if closed {
+			return nil, ErrClosed
+		}
+
+		select {
+		case c := <-b.idle:
+			return c, nil
+		default:
+		}
+
+		select {
+		case c := <-b.idle:
+			return c, nil
+		case b.slots <- struct{}{}:
+			c, err := b.dial(ctx)
+			if err != nil {
+				<-b.slots
+				return nil, err
+			}
+			return c, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// put 归还连接
+func (b *RedisBackend) put(c *redisConn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		c.conn.Close()
+		<-b.slots
+		return
+	}
+	b.idle <- c
+}
+
+// discard 关闭连接并释放名额
+func (b *RedisBackend) discard(c *redisConn) {
+	c.conn.Close()
+	<-b.slots
+}
+
+// dial 建立连接并完成认证与选库
+func (b *RedisBackend) dial(ctx context.Context) (*redisConn, error) {
+	d := net.Dialer{Timeout: b.opts.DialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", b.opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis %s: %w", b.opts.Addr, err)
+	}
+	c := &redisConn{conn: conn, r: resp.NewReader(conn), w: resp.NewWriter(conn)}
+
+	var setup [][]any
+	if b.opts.Password != "" {
+		setup = append(setup, []any{"AUTH", b.opts.Password})
+	}
+	if b.opts.DB != 0 {
+		setup = append(setup, []any{"SELECT", b.opts.DB})
+	}
+	if len(setup) > 0 {
+		replies, err := c.roundTrip(ctx, b.opts.IOTimeout, setup)
+		if err == nil {
+			for _, v := range replies {
+				if err = v.Err(); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis %s: %w", b.opts.Addr, err)
+		}
+	}
+	return c, nil
+}
+
+// Get 获取原始字节数据
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, err := b.Do(ctx, "GET", key)
+	if err != nil || v.Null {
+		return nil, false, err
+	}
+	return v.Bulk, true, nil
+}
+
+// Set 设置原始字节数据，expiration 为 0 表示永不过期
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, expiration time.Duration) error {
+	var err error
+	if ms := expiration.Milliseconds(); ms > 0 {
+		_, err = b.Do(ctx, "SET", key, value, "PX", ms)
+	} else {
+		_, err = b.Do(ctx, "SET", key, value)
+	}
+	return err
+}
+
+// Delete 删除键
+func (b *RedisBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.Do(ctx, "DEL", key)
+	return err
+}
+
+// Exists 检查键是否存在
+func (b *RedisBackend) Exists(ctx context.Context, key string) (bool, error) {
+	v, err := b.Do(ctx, "EXISTS", key)
+	return v.Int > 0, err
+}
+
+// IncrBy 原子自增：键不存在时以 SET NX 建立并设置过期时间，随后 INCRBY 保留该过期时间
+// 三条命令在一次往返中完成
+func (b *RedisBackend) IncrBy(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, time.Duration, error) {
+	var ttl int64
+	if expiration > 0 {
+		ttl = int64((expiration + time.Second - 1) / time.Second)
+	}
+
+	cmds := [][]any{{"INCRBY", key, delta}, {"PTTL", key}}
+	if ttl > 0 {
+		cmds = append([][]any{{"SET", key, "0", "EX", ttl, "NX"}}, cmds...)
+	}
+	replies, err := b.Pipeline(ctx, cmds...)
+	if err != nil {
+		return 0, 0, err
+	}
+	incr, pttl := replies[len(replies)-2], replies[len(replies)-1]
+	if err := incr.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	// SET NX 与 INCRBY 之间键恰好过期时 INCRBY 会新建不带过期时间的键，补上过期时间
+	remaining := time.Duration(pttl.Int) * time.Millisecond
+	if ttl > 0 && pttl.Int == -1 {
+		if _, err := b.Do(ctx, "EXPIRE", key, ttl); err != nil {
+			return 0, 0, err
+		}
+		remaining = time.Duration(ttl) * time.Second
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return incr.Int, remaining, nil
+}
+
+// Keys 以 SCAN 遍历所有键（不阻塞服务端）
+func (b *RedisBackend) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := b.scanAll(ctx, "", func(page []string) error {
+		keys = append(keys, page...)
+		return nil
+	})
+	return keys, err
+}
+
+// Scan 增量遍历匹配 glob 模式的键
+func (b *RedisBackend) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	args := []any{"SCAN", strconv.FormatUint(cursor, 10)}
+	if match != "" {
+		args = append(args, "MATCH", match)
+	}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	v, err := b.Do(ctx, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(v.Array) != 2 {
+		return nil, 0, fmt.Errorf("redis %s: unexpected SCAN reply", b.opts.Addr)
+	}
+	next, err := strconv.ParseUint(v.Array[0].Text(), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("redis %s: invalid SCAN cursor: %w", b.opts.Addr, err)
+	}
+	keys := make([]string, 0, len(v.Array[1].Array))
+	for _, k := range v.Array[1].Array {
+		keys = append(keys, string(k.Bulk))
+	}
+	return keys, next, nil
+}
+
+// scanAll 以 SCAN 分批遍历匹配 match 的键（为空表示全部），每批调用一次 fn
+func (b *RedisBackend) scanAll(ctx context.Context, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := b.Scan(ctx, cursor, match, 1000)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// MGet 批量获取，不存在或不是字符串的键为 nil
+func (b *RedisBackend) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return [][]byte{}, nil
+	}
+	v, err := b.Do(ctx, append([]any{"MGET"}, stringsToArgs(keys)...)...)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(v.Array))
+	for i, item := range v.Array {
+		if !item.Null {
+			values[i] = append([]byte{}, item.Bulk...)
+		}
+	}
+	return values, nil
+}
+
+// MSet 批量设置：永不过期时使用 MSET，否则在一次往返中发送多条 SET PX（不保证原子性）
+func (b *RedisBackend) MSet(ctx context.Context, values map[string][]byte, expiration time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	ms := expiration.Milliseconds()
+	if ms <= 0 {
+		args := make([]any, 0, 1+len(values)*2)
+		args = append(args, "MSET")
+		for k, v := range values {
+			args = append(args, k, v)
+		}
+		_, err := b.Do(ctx, args...)
+		return err
+	}
+
+	cmds := make([][]any, 0, len(values))
+	for k, v := range values {
+		cmds = append(cmds, []any{"SET", k, v, "PX", ms})
+	}
+	replies, err := b.Pipeline(ctx, cmds...)
+	if err != nil {
+		return err
+	}
+	for _, r := range replies {
+		if err := r.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByPrefix 以 SCAN MATCH 与 MGET 分批获取前缀下的全部字符串键
+func (b *RedisBackend) GetByPrefix(ctx context.Context, prefix string) (map[string][]byte, error) {
+	result := make(map[string][]byte)
+	err := b.scanAll(ctx, escapeGlob(prefix)+"*", func(keys []string) error {
+		values, err := b.MGet(ctx, keys...)
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			if v != nil {
+				result[keys[i]] = v
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// DeleteByPrefix 以 SCAN MATCH 与 DEL 分批删除前缀下的全部键
+func (b *RedisBackend) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	var deleted int64
+	err := b.scanAll(ctx, escapeGlob(prefix)+"*", func(keys []string) error {
+		v, err := b.Do(ctx, append([]any{"DEL"}, stringsToArgs(keys)...)...)
+		deleted += v.Int
+		return err
+	})
+	return deleted, err
+}
+
+// Clear 清空当前数据库
+func (b *RedisBackend) Clear(ctx context.Context) error {
+	_, err := b.Do(ctx, "FLUSHDB")
+	return err
+}
+
+// Ping 检查连接
+func (b *RedisBackend) Ping(ctx context.Context) error {
+	_, err := b.Do(ctx, "PING")
+	return err
+}
+
+// Close 关闭所有空闲连接，使用中的连接归还时关闭
+func (b *RedisBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for {
+		select {
+		case c := <-b.idle:
+			c.conn.Close()
+			<-b.slots
+		default:
+			return nil
+		}
+	}
+}
+
+// 比较并写入的脚本（Redis 没有对应的原生命令）
+const (
+	casScript = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+if tonumber(ARGV[3]) > 0 then redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3]) else redis.call('SET', KEYS[1], ARGV[2]) end
+return 1`
+	cadScript = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+return redis.call('DEL', KEYS[1])`
+)
+
+// 租约锁的键与脚本，与缓存服务的存储方式一致：
+// 锁状态为带过期时间的 {"owner","token"} JSON 字符串，令牌计数保存在不过期的哈希中
+const (
+	lockKeyPrefix = "lock:"
+	lockFenceKey  = "lock-fence"
+
+	acquireLockScript = `local cur = redis.call('GET', KEYS[1])
+if cur then
+  local state = cjson.decode(cur)
+  if state.owner ~= ARGV[2] then return 0 end
+  redis.call('PEXPIRE', KEYS[1], ARGV[3])
+  return state.token
+end
+local token = redis.call('HINCRBY', KEYS[2], ARGV[1], 1)
+redis.call('SET', KEYS[1], cjson.encode({owner = ARGV[2], token = token}), 'PX', ARGV[3])
+return token`
+	renewLockScript = `local cur = redis.call('GET', KEYS[1])
+if not cur then return 0 end
+local state = cjson.decode(cur)
+if state.owner ~= ARGV[1] or state.token ~= tonumber(ARGV[2]) then return 0 end
+return redis.call('PEXPIRE', KEYS[1], ARGV[3])`
+	releaseLockScript = `local cur = redis.call('GET', KEYS[1])
+if not cur then return 0 end
+local state = cjson.decode(cur)
+if state.owner ~= ARGV[1] or state.token ~= tonumber(ARGV[2]) then return 0 end
+return redis.call('DEL', KEYS[1])`
+)
+
+// SetNX 仅键不存在时写入
+func (b *RedisBackend) SetNX(ctx context.Context, key string, value []byte, expiration time.Duration) (bool, error) {
+	args := []any{"SET", key, value, "NX"}
+	if ms := expiration.Milliseconds(); ms > 0 {
+		args = append(args, "PX", ms)
+	}
+	v, err := b.Do(ctx, args...)
+	return err == nil && !v.Null, err
+}
+
+// CompareAndSwap 当前值等于 old 时替换（EVAL 脚本，缓存服务的 RESP 监听不支持）
+func (b *RedisBackend) CompareAndSwap(ctx context.Context, key string, old, value []byte, expiration time.Duration) (bool, error) {
+	v, err := b.Do(ctx, "EVAL", casScript, 1, key, old, value, max(expiration.Milliseconds(), 0))
+	return v.Int == 1, err
+}
+
+// CompareAndDelete 当前值等于 old 时删除（EVAL 脚本，缓存服务的 RESP 监听不支持）
+func (b *RedisBackend) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	v, err := b.Do(ctx, "EVAL", cadScript, 1, key, old)
+	return v.Int == 1, err
+}
+
+// HSet 写入哈希字段
+func (b *RedisBackend) HSet(ctx context.Context, key string, fields map[string][]byte) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	args := make([]any, 0, 2+len(fields)*2)
+	args = append(args, "HSET", key)
+	for f, v := range fields {
+		args = append(args, f, v)
+	}
+	v, err := b.Do(ctx, args...)
+	return v.Int, err
+}
+
+// HGet 获取哈希字段
+func (b *RedisBackend) HGet(ctx context.Context, key, field string) ([]byte, bool, error) {
+	v, err := b.Do(ctx, "HGET", key, field)
+	if err != nil || v.Null {
+		return nil, false, err
+	}
+	return v.Bulk, true, nil
+}
+
+// HGetAll 获取哈希的全部字段
+func (b *RedisBackend) HGetAll(ctx context.Context, key string) (map[string][]byte, error) {
+	v, err := b.Do(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string][]byte, len(v.Array)/2)
+	for i := 0; i+1 < len(v.Array); i += 2 {
+		fields[v.Array[i].Text()] = v.Array[i+1].Bulk
+	}
+	return fields, nil
+}
+
+// HDel 删除哈希字段
+func (b *RedisBackend) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	v, err := b.Do(ctx, append([]any{"HDEL", key}, stringsToArgs(fields)...)...)
+	return v.Int, err
+}
+
+// HIncrBy 哈希字段自增
+func (b *RedisBackend) HIncrBy(ctx context.Context, key, field string, delta int64) (int64, error) {
+	v, err := b.Do(ctx, "HINCRBY", key, field, delta)
+	return v.Int, err
+}
+
+// Push 写入列表头部（LPUSH）或尾部（RPUSH）
+func (b *RedisBackend) Push(ctx context.Context, key string, left bool, values ...[]byte) (int64, error) {
+	if len(values) == 0 {
+		return b.LLen(ctx, key)
+	}
+	cmd := "RPUSH"
+	if left {
+		cmd = "LPUSH"
+	}
+	args := make([]any, 0, 2+len(values))
+	args = append(args, cmd, key)
+	for _, v := range values {
+		args = append(args, v)
+	}
+	v, err := b.Do(ctx, args...)
+	return v.Int, err
+}
+
+// Pop 从列表头部（LPOP）或尾部（RPOP）弹出
+func (b *RedisBackend) Pop(ctx context.Context, key string, left bool) ([]byte, bool, error) {
+	cmd := "RPOP"
+	if left {
+		cmd = "LPOP"
+	}
+	v, err := b.Do(ctx, cmd, key)
+	if err != nil || v.Null {
+		return nil, false, err
+	}
+	return v.Bulk, true, nil
+}
+
+// BlockingPop BLPOP/BRPOP，timeout <= 0 时一直等待直到 ctx 结束（小数秒的超时需要 Redis 6.0+）
+func (b *RedisBackend) BlockingPop(ctx context.Context, keys []string, left bool, timeout time.Duration) (string, []byte, bool, error) {
+	cmd := "BRPOP"
+	if left {
+		cmd = "BLPOP"
+	}
+	seconds := "0"
+	ioTimeout := time.Duration(0)
+	if timeout > 0 {
+		seconds = strconv.FormatFloat(timeout.Seconds(), 'f', 3, 64)
+		ioTimeout = timeout + b.opts.IOTimeout
+	}
+
+	args := append([]any{cmd}, stringsToArgs(keys)...)
+	replies, err := b.pipeline(ctx, ioTimeout, [][]any{append(args, seconds)})
+	if err != nil {
+		return "", nil, false, err
+	}
+	v := replies[0]
+	if err := v.Err(); err != nil {
+		return "", nil, false, err
+	}
+	if v.Null || len(v.Array) != 2 {
+		return "", nil, false, nil
+	}
+	return v.Array[0].Text(), v.Array[1].Bulk, true, nil
+}
+
+// LRange 获取列表闭区间 [start, stop] 的值
+func (b *RedisBackend) LRange(ctx context.Context, key string, start, stop int64) ([][]byte, error) {
+	v, err := b.Do(ctx, "LRANGE", key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(v.Array))
+	for i, e := range v.Array {
+		values[i] = e.Bulk
+	}
+	return values, nil
+}
+
+// LLen 获取列表长度
+func (b *RedisBackend) LLen(ctx context.Context, key string) (int64, error) {
+	v, err := b.Do(ctx, "LLEN", key)
+	return v.Int, err
+}
+
+// SAdd 添加集合成员
+func (b *RedisBackend) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	v, err := b.Do(ctx, append([]any{"SADD", key}, stringsToArgs(members)...)...)
+	return v.Int, err
+}
+
+// SRem 删除集合成员
+func (b *RedisBackend) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	v, err := b.Do(ctx, append([]any{"SREM", key}, stringsToArgs(members)...)...)
+	return v.Int, err
+}
+
+// SIsMember 检查集合成员
+func (b *RedisBackend) SIsMember(ctx context.Context, key, member string) (bool, error) {
+	v, err := b.Do(ctx, "SISMEMBER", key, member)
+	return v.Int == 1, err
+}
+
+// SMembers 获取集合全部成员
+func (b *RedisBackend) SMembers(ctx context.Context, key string) ([]string, error) {
+	v, err := b.Do(ctx, "SMEMBERS", key)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, len(v.Array))
+	for i, e := range v.Array {
+		members[i] = e.Text()
+	}
+	return members, nil
+}
+
+// SCard 获取集合成员数量
+func (b *RedisBackend) SCard(ctx context.Context, key string) (int64, error) {
+	v, err := b.Do(ctx, "SCARD", key)
+	return v.Int, err
+}
+
+// ZAdd 写入有序集合成员
+func (b *RedisBackend) ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	args := make([]any, 0, 2+len(members)*2)
+	args = append(args, "ZADD", key)
+	for _, m := range members {
+		args = append(args, formatScore(m.Score), m.Member)
+	}
+	v, err := b.Do(ctx, args...)
+	return v.Int, err
+}
+
+// ZIncrBy 成员分数自增
+func (b *RedisBackend) ZIncrBy(ctx context.Context, key, member string, delta float64) (float64, error) {
+	v, err := b.Do(ctx, "ZINCRBY", key, formatScore(delta), member)
+	if err != nil {
+		return 0, err
+	}
+	return parseScore(v)
+}
+
+// ZScore 获取成员分数
+func (b *RedisBackend) ZScore(ctx context.Context, key, member string) (float64, bool, error) {
+	v, err := b.Do(ctx, "ZSCORE", key, member)
+	if err != nil || v.Null {
+		return 0, false, err
+	}
+	score, err := parseScore(v)
+	return score, err == nil, err
+}
+
+// ZRem 删除有序集合成员
+func (b *RedisBackend) ZRem(ctx context.Context, key string, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	v, err := b.Do(ctx, append([]any{"ZREM", key}, stringsToArgs(members)...)...)
+	return v.Int, err
+}
+
+// ZRank 获取成员排名，reverse 为 true 时按分数降序（ZREVRANK）
+func (b *RedisBackend) ZRank(ctx context.Context, key, member string, reverse bool) (int64, bool, error) {
+	cmd := "ZRANK"
+	if reverse {
+		cmd = "ZREVRANK"
+	}
+	v, err := b.Do(ctx, cmd, key, member)
+	if err != nil || v.Null {
+		return 0, false, err
+	}
+	return v.Int, true, nil
+}
+
+// ZRange 按排名区间获取成员，reverse 为 true 时按分数降序（ZREVRANGE）
+func (b *RedisBackend) ZRange(ctx context.Context, key string, start, stop int64, reverse bool) ([]ZMember, error) {
+	cmd := "ZRANGE"
+	if reverse {
+		cmd = "ZREVRANGE"
+	}
+	v, err := b.Do(ctx, cmd, key, start, stop, "WITHSCORES")
+	if err != nil {
+		return nil, err
+	}
+	return parseZMembers(v)
+}
+
+// ZRangeByScore 按分数闭区间获取成员（升序），无穷大的边界表示不限，limit <= 0 表示不限
+func (b *RedisBackend) ZRangeByScore(ctx context.Context, key string, min, max float64, limit int64) ([]ZMember, error) {
+	args := []any{"ZRANGEBYSCORE", key, formatScore(min), formatScore(max), "WITHSCORES"}
+	if limit > 0 {
+		args = append(args, "LIMIT", 0, limit)
+	}
+	v, err := b.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseZMembers(v)
+}
+
+// ZCard 获取有序集合成员数量
+func (b *RedisBackend) ZCard(ctx context.Context, key string) (int64, error) {
+	v, err := b.Do(ctx, "ZCARD", key)
+	return v.Int, err
+}
+
+func stringsToArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// formatScore 分数参数，无穷大使用 Redis 的 +inf/-inf
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+func parseScore(v resp.Value) (float64, error) {
+	score, err := strconv.ParseFloat(v.Text(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid score %q", v.Text())
+	}
+	return score, nil
+}
+
+// parseZMembers 解析 WITHSCORES 回复（成员与分数交替）
+func parseZMembers(v resp.Value) ([]ZMember, error) {
+	members := make([]ZMember, 0, len(v.Array)/2)
+	for i := 0; i+1 < len(v.Array); i += 2 {
+		score, err := parseScore(v.Array[i+1])
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ZMember{Member: v.Array[i].Text(), Score: score})
+	}
+	return members, nil
+}
+
+// AcquireLock 获取租约锁（EVAL 脚本，缓存服务的 RESP 监听不支持）
+func (b *RedisBackend) AcquireLock(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	if ttl.Milliseconds() <= 0 {
+		return 0, false, errors.New("cache: lock ttl must be positive")
+	}
+	v, err := b.Do(ctx, "EVAL", acquireLockScript, 2, lockKeyPrefix+name, lockFenceKey, name, owner, ttl.Milliseconds())
+	return v.Int, err == nil && v.Int > 0, err
+}
+
+// RenewLock 续约租约锁（EVAL 脚本）
+func (b *RedisBackend) RenewLock(ctx context.Context, name, owner string, token int64, ttl time.Duration) (bool, error) {
+	if ttl.Milliseconds() <= 0 {
+		return false, errors.New("cache: lock ttl must be positive")
+	}
+	v, err := b.Do(ctx, "EVAL", renewLockScript, 1, lockKeyPrefix+name, owner, token, ttl.Milliseconds())
+	return v.Int == 1, err
+}
+
+// ReleaseLock 释放租约锁（EVAL 脚本）
+func (b *RedisBackend) ReleaseLock(ctx context.Context, name, owner string, token int64) (bool, error) {
+	v, err := b.Do(ctx, "EVAL", releaseLockScript, 1, lockKeyPrefix+name, owner, token)
+	return v.Int == 1, err
+}
+
+// LockInfo 获取锁状态
+func (b *RedisBackend) LockInfo(ctx context.Context, name string) (LockState, bool, error) {
+	key := lockKeyPrefix + name
+	vs, err := b.Pipeline(ctx, []any{"GET", key}, []any{"PTTL", key})
+	if err == nil {
+		err = vs[0].Err()
+	}
+	if err != nil || vs[0].Null {
+		return LockState{}, false, err
+	}
+	var state LockState
+	if err := json.Unmarshal(vs[0].Bulk, &state); err != nil {
+		return LockState{}, false, fmt.Errorf("unmarshal lock %s: %w", name, err)
+	}
+	if vs[1].Int > 0 {
+		state.TTL = time.Duration(vs[1].Int) * time.Millisecond
+	}
+	return state, true, nil
+}
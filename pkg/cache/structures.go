@@ -0,0 +1,365 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 条件写入、哈希与列表的值按 JSON 编码（与 Set/Get 一致），集合与有序集合的成员为字符串
+
+// SetNX 仅键不存在时写入，返回是否写入（expiration 为 0 表示永不过期）
+func (c *Cache) SetNX(key string, value any, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("marshal value: %w", err)
+	}
+	var ok bool
+	err = c.do("setnx", func(ctx context.Context) (err error) {
+		ok, err = c.backend.SetNX(ctx, c.key(key), data, expiration)
+		return err
+	})
+	return ok, err
+}
+
+// CompareAndSwap 键的当前值等于 old 的 JSON 编码时替换为 value，并按 expiration 重设过期时间（0 表示永不过期）
+// 键不存在或值不相等时返回 false
+func (c *Cache) CompareAndSwap(key string, old, value any, expiration time.Duration) (bool, error) {
+	oldData, err := json.Marshal(old)
+	if err != nil {
+		return false, fmt.Errorf("marshal old value: %w", err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("marshal value: %w", err)
+	}
+	var ok bool
+	err = c.do("cas", func(ctx context.Context) (err error) {
+		ok, err = c.backend.CompareAndSwap(ctx, c.key(key), oldData, data, expiration)
+		return err
+	})
+	return ok, err
+}
+
+// CompareAndDelete 键的当前值等于 old 的 JSON 编码时删除，返回是否删除
+func (c *Cache) CompareAndDelete(key string, old any) (bool, error) {
+	oldData, err := json.Marshal(old)
+	if err != nil {
+		return false, fmt.Errorf("marshal old value: %w", err)
+	}
+	var ok bool
+	err = c.do("cad", func(ctx context.Context) (err error) {
+		ok, err = c.backend.CompareAndDelete(ctx, c.key(key), oldData)
+		return err
+	})
+	return ok, err
+}
+
+// HSet 写入哈希字段，返回新增字段数
+func (c *Cache) HSet(key string, fields map[string]any) (int64, error) {
+	encoded := make(map[string][]byte, len(fields))
+	for f, v := range fields {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0, fmt.Errorf("marshal field %s: %w", f, err)
+		}
+		encoded[f] = data
+	}
+	var n int64
+	err := c.do("hset", func(ctx context.Context) (err error) {
+		n, err = c.backend.HSet(ctx, c.key(key), encoded)
+		return err
+	})
+	return n, err
+}
+
+// HGet 获取哈希字段到 dest，字段不存在时返回 false
+func (c *Cache) HGet(key, field string, dest any) (bool, error) {
+	var data []byte
+	var found bool
+	err := c.do("hget", func(ctx context.Context) (err error) {
+		data, found, err = c.backend.HGet(ctx, c.key(key), field)
+		return err
+	})
+	if err != nil || !found {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// HGetAll 获取哈希的全部字段（值为 JSON 编码）
+func (c *Cache) HGetAll(key string) (map[string][]byte, error) {
+	var fields map[string][]byte
+	err := c.do("hgetall", func(ctx context.Context) (err error) {
+		fields, err = c.backend.HGetAll(ctx, c.key(key))
+		return err
+	})
+	return fields, err
+}
+
+// HDel 删除哈希字段，返回实际删除的数量
+func (c *Cache) HDel(key string, fields ...string) (int64, error) {
+	var n int64
+	err := c.do("hdel", func(ctx context.Context) (err error) {
+		n, err = c.backend.HDel(ctx, c.key(key), fields...)
+		return err
+	})
+	return n, err
+}
+
+// HIncrBy 原子地将哈希字段的整数值增加 delta，返回增加后的值
+func (c *Cache) HIncrBy(key, field string, delta int64) (int64, error) {
+	var n int64
+	err := c.do("hincrby", func(ctx context.Context) (err error) {
+		n, err = c.backend.HIncrBy(ctx, c.key(key), field, delta)
+		return err
+	})
+	return n, err
+}
+
+// LPush 依次写入列表头部，返回写入后的长度
+func (c *Cache) LPush(key string, values ...any) (int64, error) {
+	return c.push("lpush", key, true, values)
+}
+
+// RPush 依次写入列表尾部，返回写入后的长度
+func (c *Cache) RPush(key string, values ...any) (int64, error) {
+	return c.push("rpush", key, false, values)
+}
+
+func (c *Cache) push(op, key string, left bool, values []any) (int64, error) {
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0, fmt.Errorf("marshal value: %w", err)
+		}
+		encoded[i] = data
+	}
+	var n int64
+	err := c.do(op, func(ctx context.Context) (err error) {
+		n, err = c.backend.Push(ctx, c.key(key), left, encoded...)
+		return err
+	})
+	return n, err
+}
+
+// LPop 从列表头部弹出到 dest，列表为空时返回 false
+func (c *Cache) LPop(key string, dest any) (bool, error) {
+	return c.pop("lpop", key, true, dest)
+}
+
+// RPop 从列表尾部弹出到 dest，列表为空时返回 false
+func (c *Cache) RPop(key string, dest any) (bool, error) {
+	return c.pop("rpop", key, false, dest)
+}
+
+func (c *Cache) pop(op, key string, left bool, dest any) (bool, error) {
+	var data []byte
+	var found bool
+	err := c.do(op, func(ctx context.Context) (err error) {
+		data, found, err = c.backend.Pop(ctx, c.key(key), left)
+		return err
+	})
+	if err != nil || !found {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// BLPop 从 keys 中第一个非空列表的头部弹出到 dest，均为空时等待，直到超时或 WithContext 的 ctx 结束
+// timeout 为 0 表示一直等待；返回弹出的键，超时返回 false
+func (c *Cache) BLPop(timeout time.Duration, dest any, keys ...string) (string, bool, error) {
+	return c.blockingPop("blpop", keys, true, timeout, dest)
+}
+
+// BRPop 与 BLPop 相同，从列表尾部弹出
+func (c *Cache) BRPop(timeout time.Duration, dest any, keys ...string) (string, bool, error) {
+	return c.blockingPop("brpop", keys, false, timeout, dest)
+}
+
+func (c *Cache) blockingPop(op string, keys []string, left bool, timeout time.Duration, dest any) (string, bool, error) {
+	var key string
+	var data []byte
+	var found bool
+	err := c.do(op, func(ctx context.Context) (err error) {
+		key, data, found, err = c.backend.BlockingPop(ctx, c.keys(keys), left, timeout)
+		return err
+	})
+	if err != nil || !found {
+		return "", false, err
+	}
+	return strings.TrimPrefix(key, c.prefix), true, json.Unmarshal(data, dest)
+}
+
+// LRange 返回列表闭区间 [start, stop] 的值（JSON 编码），负数下标从末尾计数
+func (c *Cache) LRange(key string, start, stop int64) ([][]byte, error) {
+	var values [][]byte
+	err := c.do("lrange", func(ctx context.Context) (err error) {
+		values, err = c.backend.LRange(ctx, c.key(key), start, stop)
+		return err
+	})
+	return values, err
+}
+
+// LLen 返回列表长度
+func (c *Cache) LLen(key string) (int64, error) {
+	var n int64
+	err := c.do("llen", func(ctx context.Context) (err error) {
+		n, err = c.backend.LLen(ctx, c.key(key))
+		return err
+	})
+	return n, err
+}
+
+// SAdd 添加集合成员，返回新增数量
+func (c *Cache) SAdd(key string, members ...string) (int64, error) {
+	var n int64
+	err := c.do("sadd", func(ctx context.Context) (err error) {
+		n, err = c.backend.SAdd(ctx, c.key(key), members...)
+		return err
+	})
+	return n, err
+}
+
+// SRem 删除集合成员，返回实际删除的数量
+func (c *Cache) SRem(key string, members ...string) (int64, error) {
+	var n int64
+	err := c.do("srem", func(ctx context.Context) (err error) {
+		n, err = c.backend.SRem(ctx, c.key(key), members...)
+		return err
+	})
+	return n, err
+}
+
+// SIsMember 检查成员是否在集合中
+func (c *Cache) SIsMember(key, member string) (bool, error) {
+	var ok bool
+	err := c.do("sismember", func(ctx context.Context) (err error) {
+		ok, err = c.backend.SIsMember(ctx, c.key(key), member)
+		return err
+	})
+	return ok, err
+}
+
+// SMembers 返回集合的全部成员（无序）
+func (c *Cache) SMembers(key string) ([]string, error) {
+	var members []string
+	err := c.do("smembers", func(ctx context.Context) (err error) {
+		members, err = c.backend.SMembers(ctx, c.key(key))
+		return err
+	})
+	return members, err
+}
+
+// SCard 返回集合成员数量
+func (c *Cache) SCard(key string) (int64, error) {
+	var n int64
+	err := c.do("scard", func(ctx context.Context) (err error) {
+		n, err = c.backend.SCard(ctx, c.key(key))
+		return err
+	})
+	return n, err
+}
+
+// ZAdd 写入成员分数（已存在的成员更新分数），返回新增成员数量
+func (c *Cache) ZAdd(key string, members ...ZMember) (int64, error) {
+	var n int64
+	err := c.do("zadd", func(ctx context.Context) (err error) {
+		n, err = c.backend.ZAdd(ctx, c.key(key), members...)
+		return err
+	})
+	return n, err
+}
+
+// ZIncrBy 将成员分数增加 delta（成员不存在时从 0 开始），返回新分数
+func (c *Cache) ZIncrBy(key, member string, delta float64) (float64, error) {
+	var score float64
+	err := c.do("zincrby", func(ctx context.Context) (err error) {
+		score, err = c.backend.ZIncrBy(ctx, c.key(key), member, delta)
+		return err
+	})
+	return score, err
+}
+
+// ZScore 返回成员分数，成员不存在时返回 false
+func (c *Cache) ZScore(key, member string) (float64, bool, error) {
+	var score float64
+	var found bool
+	err := c.do("zscore", func(ctx context.Context) (err error) {
+		score, found, err = c.backend.ZScore(ctx, c.key(key), member)
+		return err
+	})
+	return score, found, err
+}
+
+// ZRem 删除成员，返回实际删除的数量
+func (c *Cache) ZRem(key string, members ...string) (int64, error) {
+	var n int64
+	err := c.do("zrem", func(ctx context.Context) (err error) {
+		n, err = c.backend.ZRem(ctx, c.key(key), members...)
+		return err
+	})
+	return n, err
+}
+
+// ZRank 返回成员按分数升序的排名（从 0 开始），成员不存在时返回 false
+func (c *Cache) ZRank(key, member string) (int64, bool, error) {
+	return c.zrank("zrank", key, member, false)
+}
+
+// ZRevRank 返回成员按分数降序的排名（排行榜名次，从 0 开始）
+func (c *Cache) ZRevRank(key, member string) (int64, bool, error) {
+	return c.zrank("zrevrank", key, member, true)
+}
+
+func (c *Cache) zrank(op, key, member string, reverse bool) (int64, bool, error) {
+	var rank int64
+	var found bool
+	err := c.do(op, func(ctx context.Context) (err error) {
+		rank, found, err = c.backend.ZRank(ctx, c.key(key), member, reverse)
+		return err
+	})
+	return rank, found, err
+}
+
+// ZRange 返回按分数升序排名闭区间 [start, stop] 的成员，负数下标从末尾计数
+func (c *Cache) ZRange(key string, start, stop int64) ([]ZMember, error) {
+	return c.zrange("zrange", key, start, stop, false)
+}
+
+// ZRevRange 返回按分数降序排名闭区间 [start, stop] 的成员（如排行榜前 N 名：0, N-1）
+func (c *Cache) ZRevRange(key string, start, stop int64) ([]ZMember, error) {
+	return c.zrange("zrevrange", key, start, stop, true)
+}
+
+func (c *Cache) zrange(op, key string, start, stop int64, reverse bool) ([]ZMember, error) {
+	var members []ZMember
+	err := c.do(op, func(ctx context.Context) (err error) {
+		members, err = c.backend.ZRange(ctx, c.key(key), start, stop, reverse)
+		return err
+	})
+	return members, err
+}
+
+// ZRangeByScore 返回分数在闭区间 [min, max] 内的成员（升序），边界可用 math.Inf 表示不限，limit <= 0 表示不限
+func (c *Cache) ZRangeByScore(key string, min, max float64, limit int64) ([]ZMember, error) {
+	var members []ZMember
+	err := c.do("zrangebyscore", func(ctx context.Context) (err error) {
+		members, err = c.backend.ZRangeByScore(ctx, c.key(key), min, max, limit)
+		return err
+	})
+	return members, err
+}
+
+// ZCard 返回有序集合成员数量
+func (c *Cache) ZCard(key string) (int64, error) {
+	var n int64
+	err := c.do("zcard", func(ctx context.Context) (err error) {
+		n, err = c.backend.ZCard(ctx, c.key(key))
+		return err
+	})
+	return n, err
+}
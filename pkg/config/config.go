@@ -12,6 +12,11 @@ import (
 var (
 	once   sync.Once
 	config *Config
+
+	// 初始化参数与实际读取的配置文件，用于监听配置变化后重新加载
+	initPath    string
+	initService string
+	configFile  string
 )
 
 // Config 全局配置结构
@@ -23,6 +28,8 @@ type Config struct {
 	Etcd     EtcdConfig     `mapstructure:"etcd"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
+	Gateway  GatewayConfig  `mapstructure:"gateway"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
 }
 
 // AppConfig 应用配置
@@ -94,7 +101,41 @@ type RedisConfig struct {
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
 	PoolSize int    `mapstructure:"poolSize"`
-	Mode     string `mapstructure:"mode"` // "standalone" 外部 Redis, "memory" 内存模式
+	Mode     string `mapstructure:"mode"`     // "standalone" 外部 Redis（RESP2 协议）, "memory" 内存模式（缓存服务的 HTTP 接口）
+	RESPPort int    `mapstructure:"respPort"` // 缓存服务的 RESP2 监听端口（0 不监听），standalone 模式可指向该端口
+
+	Persistence RedisPersistenceConfig `mapstructure:"persistence"` // 缓存服务的持久化（仅 memory 模式）
+	Memory      RedisMemoryConfig      `mapstructure:"memory"`      // 缓存服务的内存上限与淘汰策略（仅 memory 模式）
+	PubSub      RedisPubSubConfig      `mapstructure:"pubsub"`      // 缓存服务的消息投递重试
+}
+
+// RedisPersistenceConfig 缓存服务持久化配置
+// 数据目录中保存定期快照与追加日志，启动时先加载快照再重放日志
+type RedisPersistenceConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`          // 是否开启持久化
+	Dir              string `mapstructure:"dir"`              // 数据目录，默认 data/redis
+	Fsync            string `mapstructure:"fsync"`            // 追加日志刷盘策略：always 每次写入、everysec 每秒（默认）、no 交给操作系统
+	SnapshotInterval int    `mapstructure:"snapshotInterval"` // 快照间隔（秒），默认 300，快照后压缩追加日志
+	CompactSize      int    `mapstructure:"compactSize"`      // 追加日志超过该大小（MB）时立即快照压缩，默认 64
+}
+
+// RedisMemoryConfig 缓存服务内存上限配置
+// 超出上限时按淘汰策略删除键，没有可淘汰的键时拒绝写入；内存按键与值的大小估算
+type RedisMemoryConfig struct {
+	MaxMemory    int      `mapstructure:"maxMemory"`    // 内存上限（MB），0 不限制
+	MaxKeys      int      `mapstructure:"maxKeys"`      // 键数量上限，0 不限制
+	Policy       string   `mapstructure:"policy"`       // 淘汰策略：allkeys-lru（默认）、allkeys-lfu、volatile-ttl、noeviction
+	MaxValueSize int      `mapstructure:"maxValueSize"` // 单个键的大小上限（KB），0 不限制
+	Protected    []string `mapstructure:"protected"`    // 不参与淘汰的键前缀，默认为锁与服务注册的键
+}
+
+// RedisPubSubConfig 缓存服务的消息投递配置
+// 每个订阅者一个投递队列，按发布顺序推送，失败时指数退避重试，超过次数后转入死信主题
+type RedisPubSubConfig struct {
+	MaxAttempts  int `mapstructure:"maxAttempts"`  // 每条消息的最大投递次数，默认 8
+	RetryBackoff int `mapstructure:"retryBackoff"` // 首次重试间隔（毫秒），之后每次翻倍，默认 200
+	MaxBackoff   int `mapstructure:"maxBackoff"`   // 重试间隔上限（秒），默认 30
+	QueueSize    int `mapstructure:"queueSize"`    // 每个订阅者的队列长度，队列满时最早的消息转入死信，默认 1000
 }
 
 // Addr 获取Redis地址
@@ -115,6 +156,116 @@ type JWTConfig struct {
 	Expire int64  `mapstructure:"expire"`
 }
 
+// GatewayConfig 网关配置
+type GatewayConfig struct {
+	Timeout     int      `mapstructure:"timeout"`     // 等待上游响应头的超时时间（秒），超时返回 504
+	IdleTimeout int      `mapstructure:"idleTimeout"` // 流式响应（SSE、chunked）与 WebSocket 连接的空闲超时（秒）
+	AdminRoles  []string `mapstructure:"adminRoles"`  // 允许调用网关管理接口的角色编码，默认 admin
+	// TrustedProxies 网关前的可信代理 IP 或 CIDR，只有来自这些地址的请求才采用 X-Forwarded-For 作为客户端IP
+	TrustedProxies []string               `mapstructure:"trustedProxies"`
+	Retry          GatewayRetryConfig     `mapstructure:"retry"`
+	Health         GatewayHealthConfig    `mapstructure:"health"`
+	AccessLog      GatewayAccessLogConfig `mapstructure:"accessLog"`
+
+	// 静态路由与上游（第三方或未注册的遗留服务），与注册中心路由合并，修改配置文件后热更新
+	Routes    []GatewayRouteConfig    `mapstructure:"routes"`
+	Upstreams []GatewayUpstreamConfig `mapstructure:"upstreams"`
+}
+
+// GatewayUpstreamConfig 网关静态上游
+type GatewayUpstreamConfig struct {
+	Name      string   `mapstructure:"name"`      // 上游名称，供静态路由引用
+	Addresses []string `mapstructure:"addresses"` // 节点地址 host:port
+}
+
+// GatewayRouteConfig 网关静态路由
+type GatewayRouteConfig struct {
+	Path         string                  `mapstructure:"path"`         // 网关路径规则，如 /api/v1/legacy/*
+	Host         string                  `mapstructure:"host"`         // 匹配的主机名，为空匹配任意主机
+	Upstream     string                  `mapstructure:"upstream"`     // 转发到的静态上游名称
+	Service      string                  `mapstructure:"service"`      // 转发到的注册中心服务（未设置 upstream 时）
+	TargetPrefix string                  `mapstructure:"targetPrefix"` // 目标路径前缀
+	StripPrefix  bool                    `mapstructure:"stripPrefix"`  // 是否去除网关前缀转发
+	Methods      []string                `mapstructure:"methods"`      // 允许的HTTP方法，为空时允许所有常用方法
+	AuthRequired bool                    `mapstructure:"authRequired"` // 是否需要认证
+	LoadBalance  string                  `mapstructure:"loadBalance"`  // 负载均衡策略
+	HashKey      string                  `mapstructure:"hashKey"`      // 一致性哈希键
+	Idempotent   bool                    `mapstructure:"idempotent"`   // 是否幂等（所有方法都允许失败重试）
+	RateLimit    *GatewayRateLimitConfig `mapstructure:"rateLimit"`    // 限流配置
+	Transform    *GatewayTransformConfig `mapstructure:"transform"`    // 请求与响应转换规则
+}
+
+// GatewayTransformConfig 网关静态路由的转换规则
+// 头名与参数名以列表配置（配置文件中的 map 键会被转为小写）
+type GatewayTransformConfig struct {
+	RequestHeaders  *GatewayHeaderTransformConfig `mapstructure:"requestHeaders"`  // 请求头转换
+	ResponseHeaders *GatewayHeaderTransformConfig `mapstructure:"responseHeaders"` // 响应头转换
+	PathRewrite     *GatewayPathRewriteConfig     `mapstructure:"pathRewrite"`     // 正则路径重写
+	Query           []GatewayNameValue            `mapstructure:"query"`           // 注入的查询参数
+	MaxBodySize     int64                         `mapstructure:"maxBodySize"`     // 请求体大小上限（字节）
+}
+
+// GatewayHeaderTransformConfig 请求头或响应头转换规则
+type GatewayHeaderTransformConfig struct {
+	Add    []GatewayNameValue    `mapstructure:"add"`    // 设置的头
+	Remove []string              `mapstructure:"remove"` // 删除的头
+	Rename []GatewayRenameConfig `mapstructure:"rename"` // 重命名的头
+}
+
+// GatewayPathRewriteConfig 正则路径重写
+type GatewayPathRewriteConfig struct {
+	Pattern     string `mapstructure:"pattern"`     // 正则表达式
+	Replacement string `mapstructure:"replacement"` // 替换模板，可用 $1 引用捕获组
+}
+
+// GatewayNameValue 名称与值
+type GatewayNameValue struct {
+	Name  string `mapstructure:"name"`
+	Value string `mapstructure:"value"`
+}
+
+// GatewayRenameConfig 重命名规则
+type GatewayRenameConfig struct {
+	From string `mapstructure:"from"`
+	To   string `mapstructure:"to"`
+}
+
+// GatewayRateLimitConfig 网关静态路由的限流配置
+type GatewayRateLimitConfig struct {
+	Limit        int    `mapstructure:"limit"`        // 每个窗口允许的请求数
+	Window       int    `mapstructure:"window"`       // 窗口长度（秒）
+	KeyBy        string `mapstructure:"keyBy"`        // 计数键: ip、user 或 apikey
+	APIKeyHeader string `mapstructure:"apiKeyHeader"` // API Key请求头
+	IPMultiplier int    `mapstructure:"ipMultiplier"` // 按API Key计数时同一IP的总限额为 limit 的倍数
+}
+
+// GatewayHealthConfig 网关主动健康检查配置
+type GatewayHealthConfig struct {
+	Interval           int    `mapstructure:"interval"`           // 主动健康检查间隔（秒）
+	Timeout            int    `mapstructure:"timeout"`            // 单次探测超时（毫秒）
+	Path               string `mapstructure:"path"`               // 节点健康检查路径
+	UnhealthyThreshold int    `mapstructure:"unhealthyThreshold"` // 连续失败多少次后标记为不健康
+	HealthyThreshold   int    `mapstructure:"healthyThreshold"`   // 不健康节点连续成功多少次后恢复
+}
+
+// GatewayAccessLogConfig 网关访问日志配置（记录批量异步投递到日志服务）
+type GatewayAccessLogConfig struct {
+	Enabled       bool `mapstructure:"enabled"`       // 是否记录访问日志
+	BufferSize    int  `mapstructure:"bufferSize"`    // 待投递记录的缓冲区大小，写满后丢弃新记录，不阻塞请求
+	BatchSize     int  `mapstructure:"batchSize"`     // 每批最多投递的记录数
+	FlushInterval int  `mapstructure:"flushInterval"` // 未满一批时的投递间隔（毫秒）
+}
+
+// GatewayRetryConfig 网关重试配置（仅对幂等请求生效）
+type GatewayRetryConfig struct {
+	MaxAttempts int     `mapstructure:"maxAttempts"` // 单个请求最多尝试次数（含首次），1 表示不重试
+	Backoff     int     `mapstructure:"backoff"`     // 首次重试前的退避时间（毫秒），之后指数增长
+	MaxBackoff  int     `mapstructure:"maxBackoff"`  // 最大退避时间（毫秒）
+	BudgetRatio float64 `mapstructure:"budgetRatio"` // 重试预算：重试数占请求数的最大比例
+	MinRetries  int     `mapstructure:"minRetries"`  // 每秒保底重试次数（低流量时不受比例限制）
+	MaxBodySize int64   `mapstructure:"maxBodySize"` // 可重试请求缓冲的最大请求体（字节），超过则不重试
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
@@ -127,6 +278,17 @@ type LogConfig struct {
 	Compress   bool   `mapstructure:"compress"`
 }
 
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enabled       bool              `mapstructure:"enabled"`       // 是否导出 Span（关闭时仍传递 traceparent）
+	Endpoint      string            `mapstructure:"endpoint"`      // OTLP/HTTP 接收地址，如 http://localhost:4318/v1/traces
+	Headers       map[string]string `mapstructure:"headers"`       // 导出请求附加的请求头
+	SampleRatio   float64           `mapstructure:"sampleRatio"`   // 新链路的采样比例（0~1），默认 1
+	BufferSize    int               `mapstructure:"bufferSize"`    // 待导出 Span 的缓冲区大小，写满后丢弃
+	BatchSize     int               `mapstructure:"batchSize"`     // 每批最多导出的 Span 数
+	FlushInterval int               `mapstructure:"flushInterval"` // 未满一批时的导出间隔（毫秒）
+}
+
 // Init 初始化配置
 func Init(configPath string) error {
 	return InitWithService(configPath, "")
@@ -137,19 +299,14 @@ func InitWithService(configPath string, serviceName string) error {
 	var err error
 	once.Do(func() {
 		config = &Config{}
-		err = loadConfig(configPath)
-		if err == nil && serviceName != "" {
-			// 如果是 SQLite 且服务名不为空，自动设置数据库文件名
-			if config.Database.Driver == "sqlite" && config.Database.Database != ":memory:" {
-				config.Database.Database = fmt.Sprintf("data/%s.db", serviceName)
-			}
-		}
+		configFile, err = loadConfig(config, configPath, serviceName)
+		initPath, initService = configPath, serviceName
 	})
 	return err
 }
 
-// loadConfig 加载配置文件
-func loadConfig(configPath string) error {
+// loadConfig 加载配置文件到 cfg，返回实际读取的配置文件路径
+func loadConfig(cfg *Config, configPath, serviceName string) (string, error) {
 	v := viper.New()
 
 	// 设置配置文件路径
@@ -169,8 +326,9 @@ func loadConfig(configPath string) error {
 
 	// 读取配置文件
 	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return "", fmt.Errorf("failed to read config file: %w", err)
 	}
+	used := v.ConfigFileUsed()
 
 	// 加载环境特定配置
 	env := os.Getenv("APP_ENV")
@@ -183,20 +341,25 @@ func loadConfig(configPath string) error {
 		if err := v.MergeInConfig(); err != nil {
 			// 环境配置文件不存在不报错
 			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-				return fmt.Errorf("failed to merge env config: %w", err)
+				return used, fmt.Errorf("failed to merge env config: %w", err)
 			}
 		}
 	}
 
 	// 解析配置到结构体
-	if err := v.Unmarshal(config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := v.Unmarshal(cfg); err != nil {
+		return used, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
 	// 处理环境变量占位符
-	resolveEnvVars(config)
+	resolveEnvVars(cfg)
+
+	// 如果是 SQLite 且服务名不为空，自动设置数据库文件名
+	if serviceName != "" && cfg.Database.Driver == "sqlite" && cfg.Database.Database != ":memory:" {
+		cfg.Database.Database = fmt.Sprintf("data/%s.db", serviceName)
+	}
 
-	return nil
+	return used, nil
 }
 
 // resolveEnvVars 解析环境变量占位符
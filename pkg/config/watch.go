@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce 配置文件连续变化的合并时间（编辑器保存时通常触发多个事件）
+const watchDebounce = 200 * time.Millisecond
+
+// Watch 监听配置文件（包括环境配置文件）变化，变化后重新加载完整配置并回调
+// 全局配置实例不会被替换，需要热更新的组件在回调中自行应用新配置；
+// 重新加载失败时 cfg 为空、err 为失败原因。返回的 stop 用于停止监听
+func Watch(onChange func(cfg *Config, err error)) (stop func() error, err error) {
+	if configFile == "" {
+		return nil, errors.New("config not initialized, call Init first")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// 监听目录而不是文件：编辑器与 ConfigMap 通过重命名替换文件，文件级监听会丢失
+	dir := filepath.Dir(configFile)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go func() {
+		var timer *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					if timer != nil {
+						timer.Stop()
+					}
+					return
+				}
+				if !isConfigFile(event.Name) || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(watchDebounce, func() {
+					onChange(Reload())
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				onChange(nil, err)
+			}
+		}
+	}()
+
+	return watcher.Close, nil
+}
+
+// Reload 按初始化参数重新读取配置，返回新的配置实例（不替换全局配置）
+func Reload() (*Config, error) {
+	cfg := &Config{}
+	if _, err := loadConfig(cfg, initPath, initService); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// isConfigFile 是否为主配置文件或环境配置文件（config.yaml、config.<env>.yaml）
+func isConfigFile(name string) bool {
+	base := filepath.Base(name)
+	if base == filepath.Base(configFile) {
+		return true
+	}
+	ext := filepath.Ext(base)
+	return strings.HasPrefix(base, "config.") && (ext == ".yaml" || ext == ".yml")
+}
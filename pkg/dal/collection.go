@@ -1,6 +1,7 @@
 package dal
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
@@ -55,10 +56,22 @@ type Collection[T any] struct {
 	FieldAlias  map[string]string
 	DefaultSort string
 	MaxPerPage  int
+
+	ctx context.Context // WithContext 设置的上下文
+}
+
+// WithContext 返回使用 ctx 执行查询的副本（用于传递请求的取消与链路追踪）
+func (c *Collection[T]) WithContext(ctx context.Context) *Collection[T] {
+	cp := *c
+	cp.ctx = ctx
+	return &cp
 }
 
 // DB 获取数据库实例
 func (c *Collection[T]) DB() *gorm.DB {
+	if c.ctx != nil {
+		return GetDB().WithContext(c.ctx)
+	}
 	return GetDB()
 }
 
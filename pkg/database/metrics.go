@@ -0,0 +1,39 @@
+package database
+
+import (
+	"github.com/goback/pkg/metrics"
+)
+
+func init() {
+	metrics.MustRegister(metrics.CollectorFunc(collectPoolStats))
+}
+
+// collectPoolStats 抓取时读取连接池状态（未初始化时不输出）
+func collectPoolStats(e *metrics.Encoder) {
+	if db == nil {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	stats := sqlDB.Stats()
+
+	e.Header("db_max_open_connections", metrics.TypeGauge, "Maximum number of open connections to the database.")
+	e.Sample("db_max_open_connections", float64(stats.MaxOpenConnections))
+
+	e.Header("db_connections", metrics.TypeGauge, "Number of database connections by state.")
+	e.Sample("db_connections", float64(stats.InUse), "state", "in_use")
+	e.Sample("db_connections", float64(stats.Idle), "state", "idle")
+
+	e.Header("db_wait_count_total", metrics.TypeCounter, "Total number of connections waited for.")
+	e.Sample("db_wait_count_total", float64(stats.WaitCount))
+
+	e.Header("db_wait_duration_seconds_total", metrics.TypeCounter, "Total time blocked waiting for a new connection.")
+	e.Sample("db_wait_duration_seconds_total", stats.WaitDuration.Seconds())
+
+	e.Header("db_connections_closed_total", metrics.TypeCounter, "Total number of connections closed by reason.")
+	e.Sample("db_connections_closed_total", float64(stats.MaxIdleClosed), "reason", "max_idle")
+	e.Sample("db_connections_closed_total", float64(stats.MaxIdleTimeClosed), "reason", "max_idle_time")
+	e.Sample("db_connections_closed_total", float64(stats.MaxLifetimeClosed), "reason", "max_lifetime")
+}
@@ -2,9 +2,13 @@ package logger
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/goback/pkg/tracing"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
@@ -68,15 +72,30 @@ func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{})
 	}
 }
 
-// Trace 追踪日志
+// Trace 追踪日志，ctx 处于链路中时（如 db.WithContext(e.Request.Context())）同时记录数据库 Span
 func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
-	if l.LogLevel <= logger.Silent {
+	_, span := tracing.StartChild(ctx, "db", tracing.SpanKindClient, tracing.WithStartTime(begin))
+	if l.LogLevel <= logger.Silent && span == nil {
 		return
 	}
 
 	elapsed := time.Since(begin)
 	sql, rows := fc()
 
+	if span != nil {
+		span.SetName("db " + sqlOperation(sql))
+		span.SetAttribute("db.statement", sql)
+		span.SetAttribute("db.rows_affected", rows)
+		// 未找到记录属于正常结果
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+
 	switch {
 	case err != nil && l.LogLevel >= logger.Error:
 		l.ZapLogger.Error("gorm error",
@@ -100,3 +119,9 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql
 		)
 	}
 }
+
+// sqlOperation SQL 语句的操作类型，如 SELECT、INSERT
+func sqlOperation(sql string) string {
+	op, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	return strings.ToUpper(op)
+}
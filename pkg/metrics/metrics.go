@@ -0,0 +1,147 @@
+// Package metrics Prometheus 文本格式的指标
+//
+// 指标在包级变量中定义并注册到 Default，BaseApp.Serve 通过 /metrics 暴露：
+//
+//	var requests = metrics.NewCounterVec("app_requests_total", "Total requests.", "route")
+//
+//	func init() { metrics.MustRegister(requests) }
+//
+//	requests.With("/users").Inc()
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType Prometheus 文本格式的内容类型
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// 指标类型
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// Collector 指标收集器，抓取时将当前值写入 Encoder
+type Collector interface {
+	Collect(e *Encoder)
+}
+
+// CollectorFunc 函数形式的收集器（用于抓取时才读取的值，如连接池状态）
+type CollectorFunc func(e *Encoder)
+
+// Collect 调用函数
+func (f CollectorFunc) Collect(e *Encoder) { f(e) }
+
+// Registry 收集器注册表
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+// NewRegistry 创建注册表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default 默认注册表
+var Default = NewRegistry()
+
+// MustRegister 注册收集器到默认注册表
+func MustRegister(collectors ...Collector) {
+	Default.MustRegister(collectors...)
+}
+
+// MustRegister 注册收集器，按注册顺序输出
+func (r *Registry) MustRegister(collectors ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range collectors {
+		if c == nil {
+			panic("metrics: nil collector")
+		}
+		r.collectors = append(r.collectors, c)
+	}
+}
+
+// Write 以 Prometheus 文本格式输出所有指标
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mu.RUnlock()
+
+	e := &Encoder{w: bufio.NewWriter(w)}
+	for _, c := range collectors {
+		c.Collect(e)
+	}
+	return e.w.Flush()
+}
+
+// Handler 返回输出指标的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.Write(w)
+	})
+}
+
+// Encoder Prometheus 文本格式编码器
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// Header 输出指标的 HELP 与 TYPE 行
+func (e *Encoder) Header(name, typ, help string) {
+	fmt.Fprintf(e.w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// Sample 输出一个样本，labels 为交替的标签名与标签值
+func (e *Encoder) Sample(name string, value float64, labels ...string) {
+	e.w.WriteString(name)
+	if len(labels) > 0 {
+		e.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			e.w.WriteString(labels[i])
+			e.w.WriteString(`="`)
+			e.w.WriteString(escapeLabel(labels[i+1]))
+			e.w.WriteByte('"')
+		}
+		e.w.WriteByte('}')
+	}
+	e.w.WriteByte(' ')
+	e.w.WriteString(formatFloat(value))
+	e.w.WriteByte('\n')
+}
+
+// formatFloat 格式化样本值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	requests := NewCounterVec("app_requests_total", "Total requests.", "route", "status")
+	inFlight := NewGaugeVec("app_in_flight", "In-flight requests.")
+	latency := NewHistogramVec("app_latency_seconds", "Request latency.", []float64{0.5, 0.1}, "route")
+
+	r := NewRegistry()
+	r.MustRegister(requests, inFlight, latency, CollectorFunc(func(e *Encoder) {
+		e.Header("app_info", TypeGauge, "Line one\nline two.")
+		e.Sample("app_info", 1, "version", `1.0 "beta"`)
+	}))
+
+	requests.With("/users", "200").Inc()
+	requests.With("/users", "200").Add(2)
+	requests.With("/users", "200").Add(-1) // 计数不减
+	requests.With("/groups", "500").Inc()
+	inFlight.With().Inc()
+	inFlight.With().Inc()
+	inFlight.With().Dec()
+	latency.With("/users").Observe(0.05)
+	latency.With("/users").Observe(0.3)
+	latency.With("/users").Observe(2)
+
+	var sb strings.Builder
+	if err := r.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP app_requests_total Total requests.
+# TYPE app_requests_total counter
+app_requests_total{route="/groups",status="500"} 1
+app_requests_total{route="/users",status="200"} 3
+# HELP app_in_flight In-flight requests.
+# TYPE app_in_flight gauge
+app_in_flight 1
+# HELP app_latency_seconds Request latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{route="/users",le="0.1"} 1
+app_latency_seconds_bucket{route="/users",le="0.5"} 2
+app_latency_seconds_bucket{route="/users",le="+Inf"} 3
+app_latency_seconds_sum{route="/users"} 2.35
+app_latency_seconds_count{route="/users"} 3
+# HELP app_info Line one\nline two.
+# TYPE app_info gauge
+app_info{version="1.0 \"beta\""} 1
+`
+	if sb.String() != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, sb.String())
+	}
+}
+
+func TestVecLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic for wrong number of label values")
+		}
+	}()
+
+	NewCounterVec("app_total", "Total.", "route").With()
+}
+
+func TestGaugeVecReset(t *testing.T) {
+	g := NewGaugeVec("app_state", "State.", "node")
+	g.With("a").Set(1)
+	g.Reset()
+	g.With("b").Set(2)
+
+	r := NewRegistry()
+	r.MustRegister(g)
+	var sb strings.Builder
+	if err := r.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), `node="a"`) || !strings.Contains(sb.String(), `app_state{node="b"} 2`) {
+		t.Fatalf("Unexpected output after reset:\n%s", sb.String())
+	}
+}
+
+func TestVecDeleteMatching(t *testing.T) {
+	requests := NewCounterVec("app_requests_total", "Total requests.", "node", "status")
+	requests.With("a", "200").Inc()
+	requests.With("a", "500").Inc()
+	requests.With("b", "200").Inc()
+
+	if n := requests.DeleteMatching("node", "a"); n != 2 {
+		t.Fatalf("Expected 2 deleted series, got %d", n)
+	}
+	if n := requests.DeleteMatching("missing", "a"); n != 0 {
+		t.Fatalf("Expected unknown label to delete nothing, got %d", n)
+	}
+
+	r := NewRegistry()
+	r.MustRegister(requests)
+	var sb strings.Builder
+	if err := r.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), `node="a"`) || !strings.Contains(sb.String(), `app_requests_total{node="b",status="200"} 1`) {
+		t.Fatalf("Unexpected output after delete:\n%s", sb.String())
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	c := NewCounterVec("app_total", "Total.", "route")
+	h := NewHistogramVec("app_seconds", "Seconds.", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.With("/").Inc()
+				h.With().Observe(0.01)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v := c.With("/").Value(); v != 8000 {
+		t.Fatalf("Expected 8000, got %v", v)
+	}
+	if n := h.With().Count(); n != 8000 {
+		t.Fatalf("Expected 8000 observations, got %d", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	c := NewCounterVec("app_total", "Total.")
+	r.MustRegister(c)
+	c.With().Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("Unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "app_total 1\n") {
+		t.Fatalf("Unexpected body:\n%s", rec.Body.String())
+	}
+}
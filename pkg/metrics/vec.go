@@ -0,0 +1,285 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets 默认的直方图桶（秒），适用于请求耗时
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// labelSep 组合标签值时的分隔符
+const labelSep = "\xff"
+
+// vec 按标签值分组的子指标
+type vec[T any] struct {
+	name     string
+	help     string
+	labels   []string
+	mu       sync.RWMutex
+	children map[string]*T
+	values   map[string][]string
+	newChild func() *T
+}
+
+// with 获取标签值对应的子指标，不存在时创建
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic("metrics: " + v.name + ": expected " + strings.Join(v.labels, ",") + " label values")
+	}
+	key := strings.Join(values, labelSep)
+
+	v.mu.RLock()
+	child, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return child
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if child, ok = v.children[key]; !ok {
+		child = v.newChild()
+		v.children[key] = child
+		v.values[key] = slices.Clone(values)
+	}
+	return child
+}
+
+// each 按标签值顺序遍历子指标，labels 为交替的标签名与标签值
+func (v *vec[T]) each(fn func(labels []string, child *T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	v.mu.RUnlock()
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		v.mu.RLock()
+		child, values := v.children[k], v.values[k]
+		v.mu.RUnlock()
+
+		labels := make([]string, 0, 2*len(values))
+		for i, name := range v.labels {
+			labels = append(labels, name, values[i])
+		}
+		fn(labels, child)
+	}
+}
+
+// reset 删除所有子指标
+func (v *vec[T]) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.children = make(map[string]*T)
+	v.values = make(map[string][]string)
+}
+
+// deleteMatching 删除标签 label 取值为 value 的子指标，返回删除的数量
+func (v *vec[T]) deleteMatching(label, value string) int {
+	i := slices.Index(v.labels, label)
+	if i < 0 {
+		return 0
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	deleted := 0
+	for key, values := range v.values {
+		if values[i] == value {
+			delete(v.children, key)
+			delete(v.values, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func newVec[T any](name, help string, labels []string, newChild func() *T) vec[T] {
+	return vec[T]{
+		name:     name,
+		help:     help,
+		labels:   labels,
+		children: make(map[string]*T),
+		values:   make(map[string][]string),
+		newChild: newChild,
+	}
+}
+
+// atomicFloat 原子浮点数
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) { f.bits.Store(math.Float64bits(v)) }
+
+func (f *atomicFloat) load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+// Counter 只增不减的计数
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc 加 1
+func (c *Counter) Inc() { c.value.add(1) }
+
+// Add 增加 delta，负数忽略
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.value.add(delta)
+	}
+}
+
+// Value 当前值
+func (c *Counter) Value() float64 { return c.value.load() }
+
+// CounterVec 按标签分组的计数
+type CounterVec struct {
+	vec[Counter]
+}
+
+// NewCounterVec 创建计数，名称按惯例以 _total 结尾
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{newVec(name, help, labels, func() *Counter { return &Counter{} })}
+}
+
+// With 获取标签值对应的计数
+func (v *CounterVec) With(values ...string) *Counter { return v.with(values) }
+
+// DeleteMatching 删除标签 label 取值为 value 的计数（如已下线节点的指标）
+func (v *CounterVec) DeleteMatching(label, value string) int { return v.deleteMatching(label, value) }
+
+// Collect 输出所有计数
+func (v *CounterVec) Collect(e *Encoder) {
+	e.Header(v.name, TypeCounter, v.help)
+	v.each(func(labels []string, c *Counter) {
+		e.Sample(v.name, c.Value(), labels...)
+	})
+}
+
+// Gauge 可增可减的值
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set 设置值
+func (g *Gauge) Set(v float64) { g.value.set(v) }
+
+// Inc 加 1
+func (g *Gauge) Inc() { g.value.add(1) }
+
+// Dec 减 1
+func (g *Gauge) Dec() { g.value.add(-1) }
+
+// Add 增加 delta（可为负数）
+func (g *Gauge) Add(delta float64) { g.value.add(delta) }
+
+// Value 当前值
+func (g *Gauge) Value() float64 { return g.value.load() }
+
+// GaugeVec 按标签分组的值
+type GaugeVec struct {
+	vec[Gauge]
+}
+
+// NewGaugeVec 创建值指标
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{newVec(name, help, labels, func() *Gauge { return &Gauge{} })}
+}
+
+// With 获取标签值对应的值指标
+func (v *GaugeVec) With(values ...string) *Gauge { return v.with(values) }
+
+// Reset 删除所有标签值（用于按当前状态整体重建的指标）
+func (v *GaugeVec) Reset() { v.reset() }
+
+// DeleteMatching 删除标签 label 取值为 value 的值指标
+func (v *GaugeVec) DeleteMatching(label, value string) int { return v.deleteMatching(label, value) }
+
+// Collect 输出所有值
+func (v *GaugeVec) Collect(e *Encoder) {
+	e.Header(v.name, TypeGauge, v.help)
+	v.each(func(labels []string, g *Gauge) {
+		e.Sample(v.name, g.Value(), labels...)
+	})
+}
+
+// Histogram 直方图，统计观测值的分布
+type Histogram struct {
+	buckets []float64
+	counts  []atomic.Uint64 // 各桶（不含 +Inf）的非累计计数
+	count   atomic.Uint64
+	sum     atomicFloat
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) {
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		h.counts[i].Add(1)
+	}
+	h.count.Add(1)
+	h.sum.add(v)
+}
+
+// Count 观测次数
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+// Sum 观测值之和
+func (h *Histogram) Sum() float64 { return h.sum.load() }
+
+// HistogramVec 按标签分组的直方图
+type HistogramVec struct {
+	vec[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec 创建直方图，buckets 为空时使用 DefBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	h := &HistogramVec{buckets: buckets}
+	h.vec = newVec(name, help, labels, func() *Histogram {
+		return &Histogram{buckets: buckets, counts: make([]atomic.Uint64, len(buckets))}
+	})
+	return h
+}
+
+// With 获取标签值对应的直方图
+func (v *HistogramVec) With(values ...string) *Histogram { return v.with(values) }
+
+// DeleteMatching 删除标签 label 取值为 value 的直方图
+func (v *HistogramVec) DeleteMatching(label, value string) int { return v.deleteMatching(label, value) }
+
+// Collect 输出所有直方图的累计桶、总和与次数
+func (v *HistogramVec) Collect(e *Encoder) {
+	e.Header(v.name, TypeHistogram, v.help)
+	v.each(func(labels []string, h *Histogram) {
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += h.counts[i].Load()
+			e.Sample(v.name+"_bucket", float64(cumulative), append(slices.Clone(labels), "le", formatFloat(upper))...)
+		}
+		count := h.Count()
+		e.Sample(v.name+"_bucket", float64(count), append(slices.Clone(labels), "le", "+Inf")...)
+		e.Sample(v.name+"_sum", h.Sum(), labels...)
+		e.Sample(v.name+"_count", float64(count), labels...)
+	})
+}
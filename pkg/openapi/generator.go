@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Route 需要生成文档的路由
+type Route struct {
+	Method   string   // HTTP 方法
+	Path     string   // Go ServeMux 路由路径，如 /users/{id}
+	Summary  string   // 接口摘要
+	Tags     []string // 接口分组
+	Request  any      // 请求结构体实例（GET、HEAD、DELETE 为查询参数，其余为请求体）
+	Response any      // 成功响应 data 字段的结构体实例，分页响应时为列表元素
+	Paged    bool     // 是否为分页响应
+	Public   bool     // 是否无需认证
+}
+
+// AddRoute 添加路由对应的接口，方法为空或不受支持的路由忽略
+func (d *Document) AddRoute(r Route) {
+	method := strings.ToUpper(r.Method)
+	path, pathParams := convertPath(r.Path)
+
+	item := d.Paths[path]
+	if item == nil {
+		item = &PathItem{}
+	}
+	slot := item.operation(method)
+	if slot == nil {
+		return
+	}
+	d.Paths[path] = item
+
+	op := &Operation{
+		Tags:        r.Tags,
+		Summary:     r.Summary,
+		OperationID: operationID(method, path),
+		Responses: map[string]*Response{
+			"200":     {Description: "成功", Content: jsonContent(d.envelope(r.Response, r.Paged))},
+			"default": {Description: "错误", Content: jsonContent(d.envelope(nil, false))},
+		},
+	}
+	for _, tag := range r.Tags {
+		d.AddTag(tag, "")
+	}
+	if !r.Public {
+		op.Security = []map[string][]string{{SecurityBearer: {}}}
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	if r.Request != nil {
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			op.Parameters = append(op.Parameters, d.queryParameters(reflect.TypeOf(r.Request))...)
+		default:
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(d.schemaFor(reflect.TypeOf(r.Request))),
+			}
+		}
+	}
+
+	*slot = op
+}
+
+// envelope 统一响应结构 {code, message, data}，data 为空时省略；
+// 分页响应的 data 为列表并附带 total、page、size
+func (d *Document) envelope(data any, paged bool) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Format: "int32"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"code", "message"},
+	}
+	if data != nil {
+		schema.Properties["data"] = d.schemaFor(reflect.TypeOf(data))
+	}
+	if paged {
+		items := schema.Properties["data"]
+		if items == nil {
+			items = &Schema{}
+		}
+		schema.Properties["data"] = &Schema{Type: "array", Items: items}
+		schema.Properties["total"] = &Schema{Type: "integer", Format: "int64"}
+		schema.Properties["page"] = &Schema{Type: "integer", Format: "int32"}
+		schema.Properties["size"] = &Schema{Type: "integer", Format: "int32"}
+	}
+	return schema
+}
+
+// jsonContent JSON 内容类型
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// operationID 由方法与路径生成接口ID，如 GET /users/{id} -> get_users_id
+func operationID(method, path string) string {
+	id := strings.ToLower(method) + invalidNameRegex.ReplaceAllString(path, "_")
+	return strings.TrimRight(id, "_")
+}
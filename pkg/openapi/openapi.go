@@ -0,0 +1,251 @@
+// Package openapi 根据路由与请求结构体生成 OpenAPI 3.0 文档，并支持合并多个服务的文档
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Version 生成的文档遵循的 OpenAPI 版本
+const Version = "3.0.3"
+
+// SecurityBearer 文档中 JWT 认证方案的名称
+const SecurityBearer = "bearerAuth"
+
+// Document OpenAPI 文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag 接口分组
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem 同一路径下各方法的接口
+type PathItem struct {
+	Get     *Operation `json:"get,omitempty"`
+	Put     *Operation `json:"put,omitempty"`
+	Post    *Operation `json:"post,omitempty"`
+	Delete  *Operation `json:"delete,omitempty"`
+	Options *Operation `json:"options,omitempty"`
+	Head    *Operation `json:"head,omitempty"`
+	Patch   *Operation `json:"patch,omitempty"`
+}
+
+// Operation 接口描述
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter 路径或查询参数
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType 内容类型对应的结构
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用的结构与认证方案
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 认证方案
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Schema 数据结构
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Operations 按方法返回路径下的全部接口
+func (p *PathItem) Operations() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{
+		http.MethodGet:     p.Get,
+		http.MethodPut:     p.Put,
+		http.MethodPost:    p.Post,
+		http.MethodDelete:  p.Delete,
+		http.MethodOptions: p.Options,
+		http.MethodHead:    p.Head,
+		http.MethodPatch:   p.Patch,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// operation 获取方法对应接口的存放位置，不支持的方法返回 nil
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case http.MethodGet:
+		return &p.Get
+	case http.MethodPut:
+		return &p.Put
+	case http.MethodPost:
+		return &p.Post
+	case http.MethodDelete:
+		return &p.Delete
+	case http.MethodOptions:
+		return &p.Options
+	case http.MethodHead:
+		return &p.Head
+	case http.MethodPatch:
+		return &p.Patch
+	}
+	return nil
+}
+
+// NewDocument 创建空文档
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: &Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+}
+
+// AddTag 添加接口分组，已存在时忽略
+func (d *Document) AddTag(name, description string) {
+	if name == "" || slices.ContainsFunc(d.Tags, func(t Tag) bool { return t.Name == name }) {
+		return
+	}
+	d.Tags = append(d.Tags, Tag{Name: name, Description: description})
+}
+
+// Merge 将其他服务的文档合并到当前文档：
+// 路径加上 pathPrefix，结构名称加上 namePrefix 以免不同服务的同名结构冲突
+func (d *Document) Merge(src *Document, pathPrefix, namePrefix string) {
+	rename := func(s *Schema) { renameRefs(s, namePrefix) }
+
+	if src.Components != nil {
+		for name, schema := range src.Components.Schemas {
+			rename(schema)
+			d.Components.Schemas[namePrefix+name] = schema
+		}
+	}
+
+	for path, item := range src.Paths {
+		full := strings.TrimSuffix(pathPrefix, "/") + path
+		if path == "/" && pathPrefix != "" {
+			full = strings.TrimSuffix(pathPrefix, "/")
+		}
+		target := d.Paths[full]
+		if target == nil {
+			target = &PathItem{}
+			d.Paths[full] = target
+		}
+		for method, op := range item.Operations() {
+			for _, p := range op.Parameters {
+				rename(p.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					rename(mt.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, mt := range resp.Content {
+					rename(mt.Schema)
+				}
+			}
+			op.OperationID = operationID(method, full)
+			*target.operation(method) = op
+		}
+	}
+
+	for _, tag := range src.Tags {
+		d.AddTag(tag.Name, tag.Description)
+	}
+}
+
+// renameRefs 为结构中引用的组件名称加上前缀
+func renameRefs(s *Schema, prefix string) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		s.Ref = schemaRefPrefix + prefix + strings.TrimPrefix(s.Ref, schemaRefPrefix)
+	}
+	renameRefs(s.Items, prefix)
+	renameRefs(s.AdditionalProperties, prefix)
+	for _, p := range s.Properties {
+		renameRefs(p, prefix)
+	}
+}
+
+// pathParamRegex 匹配 Go ServeMux 路由中的路径参数，如 {id} 与 {path...}
+var pathParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// convertPath 将 Go ServeMux 路由转换为 OpenAPI 路径并返回路径参数名
+// 如 /files/{path...} -> /files/{path}，/items/{$} -> /items/
+func convertPath(pattern string) (string, []string) {
+	var params []string
+	path := pathParamRegex.ReplaceAllStringFunc(pattern, func(m string) string {
+		name := strings.TrimSuffix(m[1:len(m)-1], "...")
+		if name == "$" {
+			return ""
+		}
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	return path, params
+}
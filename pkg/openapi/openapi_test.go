@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+type baseModel struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type user struct {
+	baseModel
+	Name     string   `json:"name"`
+	Password string   `json:"-"`
+	Tags     []string `json:"tags,omitempty"`
+	Manager  *user    `json:"manager,omitempty"`
+}
+
+type createUserRequest struct {
+	Name  string `json:"name" binding:"required,min=3"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Age   *int   `json:"age"`
+}
+
+type listRequest struct {
+	Page   int    `query:"page"`
+	Filter string `query:"filter"`
+}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func TestAddRoute(t *testing.T) {
+	doc := NewDocument("user-service", "v1.0.0")
+	doc.AddRoute(Route{Method: "POST", Path: "/users", Summary: "create", Tags: []string{"users"},
+		Request: createUserRequest{}, Response: user{}})
+	doc.AddRoute(Route{Method: "GET", Path: "/users", Request: listRequest{}, Response: user{}, Paged: true})
+	doc.AddRoute(Route{Method: "GET", Path: "/files/{path...}", Public: true})
+	doc.AddRoute(Route{Method: "", Path: "/"})
+
+	if len(doc.Paths) != 2 {
+		t.Fatalf("Expected 2 paths, got %v", doc.Paths)
+	}
+
+	create := doc.Paths["/users"].Post
+	if create.Summary != "create" || create.OperationID != "post_users" || len(create.Security) != 1 {
+		t.Fatalf("Unexpected operation %+v", create)
+	}
+	body := create.RequestBody.Content["application/json"].Schema
+	if body.Ref != "#/components/schemas/openapi.createUserRequest" {
+		t.Fatalf("Expected request body ref, got %+v", body)
+	}
+	req := doc.Components.Schemas["openapi.createUserRequest"]
+	if !slices.Equal(req.Required, []string{"name"}) || req.Properties["age"].Type != "integer" {
+		t.Fatalf("Unexpected request schema %+v", req)
+	}
+
+	// 匿名嵌入字段展开，json:"-" 字段忽略，自引用通过组件引用
+	u := doc.Components.Schemas["openapi.user"]
+	if u.Properties["id"] == nil || u.Properties["createdAt"].Format != "date-time" || u.Properties["Password"] != nil {
+		t.Fatalf("Unexpected user schema %+v", u.Properties)
+	}
+	if u.Properties["manager"].Ref != "#/components/schemas/openapi.user" || u.Properties["tags"].Items.Type != "string" {
+		t.Fatalf("Unexpected user schema %+v", u.Properties)
+	}
+
+	list := doc.Paths["/users"].Get
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "page" || list.Parameters[0].In != "query" {
+		t.Fatalf("Unexpected query parameters %+v", list.Parameters)
+	}
+	resp := list.Responses["200"].Content["application/json"].Schema
+	if resp.Properties["data"].Type != "array" || resp.Properties["total"] == nil {
+		t.Fatalf("Expected paged response, got %+v", resp.Properties)
+	}
+
+	files := doc.Paths["/files/{path}"].Get
+	if files.Security != nil || len(files.Parameters) != 1 || files.Parameters[0].In != "path" {
+		t.Fatalf("Unexpected public operation %+v", files)
+	}
+}
+
+func TestSchemaName(t *testing.T) {
+	doc := NewDocument("test", "v1")
+	doc.AddRoute(Route{Method: "GET", Path: "/users", Response: page[user]{}})
+
+	if _, ok := doc.Components.Schemas["openapi.page_openapi.user"]; !ok {
+		t.Fatalf("Expected generic schema name, got %v", doc.Components.Schemas)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	src := NewDocument("user-service", "v1.0.0")
+	src.AddRoute(Route{Method: "POST", Path: "/users", Tags: []string{"users"}, Request: createUserRequest{}, Response: user{}})
+	src.AddRoute(Route{Method: "GET", Path: "/", Public: true})
+
+	// 经过 JSON 往返，与网关拉取的文档一致
+	raw, err := json.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetched Document
+	if err := json.Unmarshal(raw, &fetched); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewDocument("API", "")
+	doc.Merge(&fetched, "/api/v1/users", "user-service.")
+
+	op := doc.Paths["/api/v1/users/users"].Post
+	if op == nil || op.OperationID != "post_api_v1_users_users" {
+		t.Fatalf("Expected prefixed path, got %v", doc.Paths)
+	}
+	if doc.Paths["/api/v1/users"].Get == nil {
+		t.Fatalf("Expected root path to map to the prefix, got %v", doc.Paths)
+	}
+	if ref := op.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/user-service.openapi.createUserRequest" {
+		t.Fatalf("Expected renamed ref, got %q", ref)
+	}
+	u := doc.Components.Schemas["user-service.openapi.user"]
+	if u == nil || u.Properties["manager"].Ref != "#/components/schemas/user-service.openapi.user" {
+		t.Fatalf("Expected renamed component refs, got %v", doc.Components.Schemas)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0].Name != "users" {
+		t.Fatalf("Expected merged tags, got %v", doc.Tags)
+	}
+}
@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// schemaRefPrefix 组件结构的引用前缀
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaFor 生成类型的结构，具名结构体注册为组件并返回引用
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// 自定义序列化的类型（如 gorm.DeletedAt）无法推断结构
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// 先占位，支持自引用的结构
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+	return &Schema{}
+}
+
+// structSchema 按 json 标签生成结构体的结构，匿名嵌入的结构体字段展开到当前层级
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range structFields(t, "json") {
+		schema.Properties[f.name] = d.schemaFor(f.field.Type)
+		if f.required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+	return schema
+}
+
+// queryParameters 按 query 标签生成查询参数，未设置 query 标签时依次使用 form 与 json 标签
+func (d *Document) queryParameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []*Parameter
+	for _, f := range structFields(t, "query", "form", "json") {
+		params = append(params, &Parameter{
+			Name:     f.name,
+			In:       "query",
+			Required: f.required,
+			Schema:   d.schemaFor(f.field.Type),
+		})
+	}
+	return params
+}
+
+// structField 结构体中参与序列化的字段
+type structField struct {
+	name     string
+	field    reflect.StructField
+	required bool
+}
+
+// structFields 按标签解析结构体字段，tags 依次作为字段名来源，均未设置时使用字段名
+func structFields(t reflect.Type, tags ...string) []structField {
+	var fields []structField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, omitempty, tagged := fieldName(f, tags)
+		if name == "-" {
+			continue
+		}
+
+		// 与 encoding/json 一致：匿名嵌入的结构体（包括未导出的）字段提升到当前层级
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !tagged && ft.Kind() == reflect.Struct {
+			fields = append(fields, structFields(ft, tags...)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		fields = append(fields, structField{
+			name:     name,
+			field:    f,
+			required: !omitempty && hasRule(f.Tag.Get("binding"), "required"),
+		})
+	}
+	return fields
+}
+
+// fieldName 从标签中解析字段名
+func fieldName(f reflect.StructField, tags []string) (name string, omitempty, tagged bool) {
+	for _, tag := range tags {
+		value, ok := f.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		name, opts, _ := strings.Cut(value, ",")
+		if name == "" {
+			name = f.Name
+		}
+		return name, hasRule(opts, "omitempty"), true
+	}
+	return f.Name, false, false
+}
+
+// hasRule 逗号分隔的规则列表中是否包含指定规则
+func hasRule(rules, rule string) bool {
+	for r := range strings.SplitSeq(rules, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// typePathRegex 匹配泛型类型参数中的包路径，如 github.com/goback/services/user/internal/
+	typePathRegex = regexp.MustCompile(`[\w.\-]+(/[\w.\-]+)*/`)
+	// invalidNameRegex 匹配组件名称中不允许的字符
+	invalidNameRegex = regexp.MustCompile(`[^\w.\-]+`)
+)
+
+// schemaName 组件名称，使用“包名.类型名”避免不同包的同名类型冲突，
+// 泛型类型如 dal.ListResult[model.User] 转换为 dal.ListResult_model.User
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name := typePathRegex.ReplaceAllString(t.Name(), "")
+	name = strings.Trim(invalidNameRegex.ReplaceAllString(name, "_"), "_")
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
@@ -3,7 +3,8 @@ package registry
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -17,21 +18,49 @@ const (
 	// Redis key 前缀
 	servicePrefix = "registry:service:"
 	ttlDuration   = 30 * time.Second
+
+	// defaultWatchInterval 监听器快照比对间隔
+	defaultWatchInterval = 2 * time.Second
 )
 
 // RedisRegistry 基于 Redis 的服务注册中心
 type RedisRegistry struct {
-	cache     *cache.Cache
-	mu        sync.RWMutex
-	heartbeat map[string]*time.Ticker
+	cache         *cache.Cache
+	mu            sync.RWMutex
+	heartbeat     map[string]chan struct{} // nodeKey -> 心跳停止信号
+	watchInterval time.Duration
+}
+
+// RedisOption RedisRegistry 配置选项
+type RedisOption func(*RedisRegistry)
+
+// WithCache 设置缓存客户端（默认使用 cache.Global()）
+func WithCache(c *cache.Cache) RedisOption {
+	return func(r *RedisRegistry) {
+		r.cache = c
+	}
+}
+
+// WithWatchInterval 设置监听器轮询间隔
+func WithWatchInterval(d time.Duration) RedisOption {
+	return func(r *RedisRegistry) {
+		if d > 0 {
+			r.watchInterval = d
+		}
+	}
 }
 
 // NewRedisRegistry 创建基于 Redis 的注册中心
-func NewRedisRegistry() registry.Registry {
-	return &RedisRegistry{
-		cache:     cache.Global(),
-		heartbeat: make(map[string]*time.Ticker),
+func NewRedisRegistry(opts ...RedisOption) registry.Registry {
+	r := &RedisRegistry{
+		cache:         cache.Global(),
+		heartbeat:     make(map[string]chan struct{}),
+		watchInterval: defaultWatchInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // Init 初始化
@@ -45,105 +74,210 @@ func (r *RedisRegistry) Options() registry.Options {
 }
 
 // Register 注册服务
+// 每个节点单独存储在 registry:service:<name>:<nodeID> 下，拥有独立的 TTL 与心跳
 func (r *RedisRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
 	if s == nil || len(s.Nodes) == 0 {
 		return fmt.Errorf("service or nodes cannot be empty")
 	}
 
-	key := servicePrefix + s.Name
-
-	// 序列化服务信息
-	data, err := json.Marshal(s)
-	if err != nil {
-		return fmt.Errorf("marshal service: %w", err)
+	var options registry.RegisterOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	ttl := ttlDuration
+	if options.TTL > 0 {
+		ttl = options.TTL
 	}
 
-	// 存储到 Redis（带过期时间）
-	if err := r.cache.SetRaw(key, data, ttlDuration); err != nil {
-		return fmt.Errorf("set cache: %w", err)
+	for _, node := range s.Nodes {
+		if node == nil || node.Id == "" {
+			return fmt.Errorf("node id cannot be empty")
+		}
 	}
 
-	logger.Debug("服务已注册",
-		zap.String("key", key),
-		zap.String("service", s.Name),
-		zap.Int("nodes", len(s.Nodes)),
-	)
+	for _, node := range s.Nodes {
+		entry := nodeService(s, node)
+		key := nodeKey(s.Name, node.Id)
+
+		if err := r.setNode(key, entry, ttl); err != nil {
+			return err
+		}
+
+		logger.Debug("服务节点已注册",
+			zap.String("key", key),
+			zap.String("service", s.Name),
+			zap.String("node", node.Id),
+			zap.String("address", node.Address),
+		)
 
-	// 启动心跳保活
-	r.startHeartbeat(s)
+		// 启动心跳保活
+		r.startHeartbeat(key, entry, ttl)
+	}
 
 	return nil
 }
 
 // Deregister 注销服务
+// 只移除 s.Nodes 中列出的节点，同名服务的其他副本不受影响
 func (r *RedisRegistry) Deregister(s *registry.Service, opts ...registry.DeregisterOption) error {
 	if s == nil {
 		return fmt.Errorf("service cannot be nil")
 	}
 
-	key := servicePrefix + s.Name
-	r.cache.Delete(key)
+	for _, node := range s.Nodes {
+		if node == nil || node.Id == "" {
+			continue
+		}
+		key := nodeKey(s.Name, node.Id)
 
-	// 停止心跳
-	r.stopHeartbeat(s.Name)
+		// 先停止心跳，避免注销后被重新写入
+		r.stopHeartbeat(key)
+		r.cache.Delete(key)
+	}
 
 	return nil
 }
 
 // GetService 获取服务
+// 合并该服务所有存活节点，按版本分组返回
 func (r *RedisRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
-	key := servicePrefix + name
-	data, ok := r.cache.GetRaw(key)
-	if !ok {
-		return nil, registry.ErrNotFound
+	entries, err := r.listNodes(servicePrefix + name + ":")
+	if err != nil {
+		return nil, err
 	}
 
-	var svc registry.Service
-	if err := json.Unmarshal(data, &svc); err != nil {
-		return nil, fmt.Errorf("unmarshal service: %w", err)
+	services := mergeServices(slices.DeleteFunc(entries, func(svc *registry.Service) bool {
+		return svc.Name != name
+	}))
+	if len(services) == 0 {
+		return nil, registry.ErrNotFound
 	}
-
-	return []*registry.Service{&svc}, nil
+	return services, nil
 }
 
 // ListServices 列出所有服务
 func (r *RedisRegistry) ListServices(opts ...registry.ListOption) ([]*registry.Service, error) {
-	keys := r.cache.Keys()
-	services := make([]*registry.Service, 0)
+	services, err := r.listServices()
+	if err != nil {
+		logger.Debug("ListServices - 获取key失败", zap.Error(err))
+		return make([]*registry.Service, 0), nil
+	}
+	return services, nil
+}
 
-	logger.Debug("ListServices - 获取所有key", zap.Int("total_keys", len(keys)), zap.Strings("keys", keys))
+// listServices 列出所有服务，缓存服务不可用时返回错误
+func (r *RedisRegistry) listServices() ([]*registry.Service, error) {
+	entries, err := r.listNodes(servicePrefix)
+	if err != nil {
+		return nil, err
+	}
+	services := mergeServices(entries)
 
-	for _, key := range keys {
-		// 只处理服务注册的 key
-		if !strings.HasPrefix(key, servicePrefix) {
-			continue
-		}
+	logger.Debug("ListServices - 返回服务列表", zap.Int("nodes", len(entries)), zap.Int("count", len(services)))
+	return services, nil
+}
 
-		data, ok := r.cache.GetRaw(key)
-		if !ok {
-			logger.Debug("ListServices - key不存在", zap.String("key", key))
-			continue
-		}
+// listNodes 一次请求读取 prefix 下的全部节点
+func (r *RedisRegistry) listNodes(prefix string) ([]*registry.Service, error) {
+	values, err := r.cache.GetByPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
 
-		var svc registry.Service
-		if err := json.Unmarshal(data, &svc); err != nil {
-			logger.Warn("ListServices - 反序列化失败", zap.String("key", key), zap.Error(err))
-			continue
+	entries := make([]*registry.Service, 0, len(values))
+	for key, data := range values {
+		if svc, ok := decodeNode(key, data); ok {
+			entries = append(entries, svc)
 		}
+	}
+	return entries, nil
+}
 
-		services = append(services, &svc)
+// setNode 写入单个节点
+func (r *RedisRegistry) setNode(key string, entry *registry.Service, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal service: %w", err)
 	}
 
-	logger.Debug("ListServices - 返回服务列表", zap.Int("count", len(services)))
-	return services, nil
+	// 存储到 Redis（带过期时间）
+	if err := r.cache.SetRaw(key, data, ttl); err != nil {
+		return fmt.Errorf("set cache: %w", err)
+	}
+	return nil
+}
+
+// decodeNode 解析单个节点
+func decodeNode(key string, data []byte) (*registry.Service, bool) {
+	var svc registry.Service
+	if err := json.Unmarshal(data, &svc); err != nil {
+		logger.Warn("ListServices - 反序列化失败", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	if len(svc.Nodes) == 0 {
+		return nil, false
+	}
+	return &svc, true
+}
+
+// nodeKey 节点存储键
+func nodeKey(name, nodeID string) string {
+	return servicePrefix + name + ":" + nodeID
 }
 
-// Watch 监听服务变化（简化实现）
+// nodeService 构造只包含单个节点的服务信息
+func nodeService(s *registry.Service, node *registry.Node) *registry.Service {
+	return &registry.Service{
+		Name:      s.Name,
+		Version:   s.Version,
+		Metadata:  s.Metadata,
+		Endpoints: s.Endpoints,
+		Nodes:     []*registry.Node{node},
+	}
+}
+
+// mergeServices 将单节点条目按 名称+版本 合并，节点按ID排序保证结果稳定
+func mergeServices(entries []*registry.Service) []*registry.Service {
+	merged := make(map[string]*registry.Service)
+	for _, entry := range entries {
+		id := entry.Name + "@" + entry.Version
+		svc, ok := merged[id]
+		if !ok {
+			svc = &registry.Service{
+				Name:      entry.Name,
+				Version:   entry.Version,
+				Metadata:  entry.Metadata,
+				Endpoints: entry.Endpoints,
+			}
+			merged[id] = svc
+		}
+		svc.Nodes = append(svc.Nodes, entry.Nodes...)
+	}
+
+	services := make([]*registry.Service, 0, len(merged))
+	for _, svc := range merged {
+		sort.Slice(svc.Nodes, func(i, j int) bool {
+			return svc.Nodes[i].Id < svc.Nodes[j].Id
+		})
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Name != services[j].Name {
+			return services[i].Name < services[j].Name
+		}
+		return services[i].Version < services[j].Version
+	})
+	return services
+}
+
+// Watch 监听服务变化
+// 通过定期比对注册表快照产生 create/update/delete 事件
 func (r *RedisRegistry) Watch(opts ...registry.WatchOption) (registry.Watcher, error) {
-	return &redisWatcher{
-		registry: r,
-		exit:     make(chan bool),
-	}, nil
+	var wo registry.WatchOptions
+	for _, o := range opts {
+		o(&wo)
+	}
+	return newRedisWatcher(r, wo), nil
 }
 
 // String 返回注册中心名称
@@ -151,52 +285,181 @@ func (r *RedisRegistry) String() string {
 	return "redis"
 }
 
-// startHeartbeat 启动心跳保活
-func (r *RedisRegistry) startHeartbeat(s *registry.Service) {
+// startHeartbeat 启动节点心跳保活
+func (r *RedisRegistry) startHeartbeat(key string, entry *registry.Service, ttl time.Duration) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// 如果已存在心跳，先停止
-	if ticker, ok := r.heartbeat[s.Name]; ok {
-		ticker.Stop()
+	if stop, ok := r.heartbeat[key]; ok {
+		close(stop)
 	}
 
-	// 创建新的心跳定时器
-	ticker := time.NewTicker(ttlDuration / 3)
-	r.heartbeat[s.Name] = ticker
+	stop := make(chan struct{})
+	r.heartbeat[key] = stop
 
 	go func() {
-		for range ticker.C {
-			key := servicePrefix + s.Name
-			data, err := json.Marshal(s)
-			if err != nil {
-				continue
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.setNode(key, entry, ttl); err != nil {
+					logger.Debug("服务心跳失败", zap.String("key", key), zap.Error(err))
+				}
+			case <-stop:
+				return
 			}
-			_ = r.cache.SetRaw(key, data, ttlDuration)
 		}
 	}()
 }
 
-// stopHeartbeat 停止心跳
-func (r *RedisRegistry) stopHeartbeat(name string) {
+// stopHeartbeat 停止节点心跳
+func (r *RedisRegistry) stopHeartbeat(key string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if ticker, ok := r.heartbeat[name]; ok {
-		ticker.Stop()
-		delete(r.heartbeat, name)
+	if stop, ok := r.heartbeat[key]; ok {
+		close(stop)
+		delete(r.heartbeat, key)
 	}
 }
 
-// redisWatcher Redis 监听器（简化实现）
+// redisWatcher Redis 监听器（基于快照比对）
 type redisWatcher struct {
 	registry *RedisRegistry
+	wo       registry.WatchOptions
+	results  chan *registry.Result
 	exit     chan bool
 }
 
+// watchEntry 快照条目
+type watchEntry struct {
+	service     *registry.Service
+	fingerprint string
+}
+
+func newRedisWatcher(r *RedisRegistry, wo registry.WatchOptions) *redisWatcher {
+	w := &redisWatcher{
+		registry: r,
+		wo:       wo,
+		results:  make(chan *registry.Result),
+		exit:     make(chan bool),
+	}
+
+	// 以当前注册表作为基线，已存在的服务不会产生 create 事件
+	prev, err := w.snapshot()
+	if err != nil {
+		logger.Warn("监听器初始快照失败", zap.Error(err))
+		prev = nil
+	}
+
+	go w.run(prev)
+	return w
+}
+
+// run 定期获取快照并与上一次比对
+func (w *redisWatcher) run(prev map[string]watchEntry) {
+	ticker := time.NewTicker(w.registry.watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			curr, err := w.snapshot()
+			if err != nil {
+				// 缓存服务暂不可用时保留旧快照，避免误报全部服务下线
+				logger.Debug("监听器获取快照失败", zap.Error(err))
+				continue
+			}
+			if prev == nil {
+				prev = curr
+				continue
+			}
+			for _, res := range diffSnapshots(prev, curr) {
+				select {
+				case w.results <- res:
+				case <-w.exit:
+					return
+				}
+			}
+			prev = curr
+		case <-w.exit:
+			return
+		}
+	}
+}
+
+// snapshot 获取当前注册表快照
+func (w *redisWatcher) snapshot() (map[string]watchEntry, error) {
+	services, err := w.registry.listServices()
+	if err != nil {
+		return nil, err
+	}
+
+	// 按服务名分组（同名服务可能存在多个版本）
+	groups := make(map[string][]*registry.Service)
+	for _, svc := range services {
+		if w.wo.Service != "" && svc.Name != w.wo.Service {
+			continue
+		}
+		groups[svc.Name] = append(groups[svc.Name], svc)
+	}
+
+	entries := make(map[string]watchEntry, len(groups))
+	for name, group := range groups {
+		data, err := json.Marshal(group)
+		if err != nil {
+			continue
+		}
+
+		// 事件中的服务包含所有版本的节点，仅当最后一个节点下线时才产生 delete
+		merged := *group[0]
+		merged.Nodes = nil
+		for _, svc := range group {
+			merged.Nodes = append(merged.Nodes, svc.Nodes...)
+		}
+		entries[name] = watchEntry{service: &merged, fingerprint: string(data)}
+	}
+	return entries, nil
+}
+
+// diffSnapshots 比对两次快照，返回变化事件（按服务名排序）
+func diffSnapshots(prev, curr map[string]watchEntry) []*registry.Result {
+	names := make([]string, 0, len(prev)+len(curr))
+	for name := range curr {
+		names = append(names, name)
+	}
+	for name := range prev {
+		if _, ok := curr[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]*registry.Result, 0)
+	for _, name := range names {
+		old, existed := prev[name]
+		cur, exists := curr[name]
+		switch {
+		case !existed && exists:
+			results = append(results, &registry.Result{Action: "create", Service: cur.service})
+		case existed && !exists:
+			results = append(results, &registry.Result{Action: "delete", Service: old.service})
+		case old.fingerprint != cur.fingerprint:
+			results = append(results, &registry.Result{Action: "update", Service: cur.service})
+		}
+	}
+	return results
+}
+
 func (w *redisWatcher) Next() (*registry.Result, error) {
-	<-w.exit
-	return nil, registry.ErrWatcherStopped
+	select {
+	case res := <-w.results:
+		return res, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
 }
 
 func (w *redisWatcher) Stop() {
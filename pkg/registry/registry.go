@@ -2,17 +2,121 @@ package registry
 
 import (
 	"encoding/json"
+	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/goback/pkg/app/tools/security"
 	"go-micro.dev/v5/registry"
 )
 
+// APIPrefix 网关 API 前缀，服务的 BasePath 挂载在其下: /api/v1/{BasePath}
+const APIPrefix = "/api/v1"
+
+// GatewayPath 返回服务内路径在网关上的完整路径
+// 例如: GatewayPath("users", "/auth/login") -> /api/v1/users/auth/login
+func GatewayPath(basePath, path string) string {
+	p := APIPrefix + "/" + strings.Trim(basePath, "/")
+	if path != "" && path != "/" {
+		p += "/" + strings.TrimPrefix(path, "/")
+	}
+	return p
+}
+
+// 网关负载均衡策略
+const (
+	LoadBalanceRoundRobin     = "round_robin"     // 轮询（默认）
+	LoadBalanceWeightedRandom = "weighted_random" // 按节点元数据 weight 加权随机
+	LoadBalanceLeastInFlight  = "least_inflight"  // 最少进行中请求
+	LoadBalanceConsistentHash = "consistent_hash" // 一致性哈希（会话粘滞）
+)
+
+// 一致性哈希的哈希键
+const (
+	HashKeyUser         = "user"    // 按JWT用户ID，未登录时退化为客户端IP
+	HashKeyIP           = "ip"      // 按客户端IP
+	HashKeyHeaderPrefix = "header:" // 按请求头，如 header:X-Session-Id
+)
+
+// 网关限流的计数键
+const (
+	RateLimitKeyIP     = "ip"     // 按客户端IP（默认）
+	RateLimitKeyUser   = "user"   // 按JWT用户ID，未登录时退化为客户端IP
+	RateLimitKeyAPIKey = "apikey" // 按API Key请求头，缺失时退化为客户端IP
+)
+
+// DefaultAPIKeyHeader 默认的API Key请求头
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// DefaultAPIKeyIPMultiplier 按API Key计数时，同一IP的总限额默认为单个 Key 限额的倍数
+const DefaultAPIKeyIPMultiplier = 10
+
+// RateLimitConfig 网关限流配置（固定窗口，计数存储在共享缓存服务中）
+type RateLimitConfig struct {
+	Limit        int    `json:"limit"`                    // 每个窗口允许的请求数
+	Window       int    `json:"window"`                   // 窗口长度（秒）
+	KeyBy        string `json:"key_by,omitempty"`         // 计数键: ip、user 或 apikey，为空时按IP
+	APIKeyHeader string `json:"api_key_header,omitempty"` // API Key请求头，为空时使用 X-API-Key
+	IPMultiplier int    `json:"ip_multiplier,omitempty"`  // 按API Key计数时同一IP的总限额为 Limit 的倍数，为空时使用 DefaultAPIKeyIPMultiplier
+}
+
+// 灰度请求标识：携带 X-Canary: true 请求头或 canary=true Cookie 的请求路由到灰度版本
+const (
+	HeaderCanary = "X-Canary"
+	CookieCanary = "canary"
+)
+
+// 指定版本：携带 X-Service-Version 请求头或 service_version Cookie 的请求固定路由到该版本
+const (
+	HeaderServiceVersion = "X-Service-Version"
+	CookieServiceVersion = "service_version"
+)
+
+// TrafficSplit 按服务版本分流配置
+type TrafficSplit struct {
+	Weights map[string]int `json:"weights,omitempty"` // 版本 -> 权重，为空时所有版本均分流量
+	Canary  string         `json:"canary,omitempty"`  // 灰度版本，灰度请求固定路由到该版本
+}
+
+// HeaderTransform 请求头或响应头转换规则，按 删除 → 重命名 → 添加 的顺序执行
+type HeaderTransform struct {
+	Add    map[string]string `json:"add,omitempty"`    // 设置的头（覆盖同名头），值中可用 {参数} 引用路径参数
+	Remove []string          `json:"remove,omitempty"` // 删除的头
+	Rename map[string]string `json:"rename,omitempty"` // 原名 -> 新名
+}
+
+// PathRewrite 正则路径重写，作用于去除前缀后转发给服务的路径，不匹配时不改写
+type PathRewrite struct {
+	Pattern     string `json:"pattern"`     // 正则表达式，如 ^/users/(\d+)$
+	Replacement string `json:"replacement"` // 替换模板，可用 $1、${name} 引用捕获组
+}
+
+// TransformConfig 网关路由的请求与响应转换规则
+type TransformConfig struct {
+	RequestHeaders  *HeaderTransform  `json:"request_headers,omitempty"`  // 请求头转换
+	ResponseHeaders *HeaderTransform  `json:"response_headers,omitempty"` // 响应头转换
+	PathRewrite     *PathRewrite      `json:"path_rewrite,omitempty"`     // 路径重写
+	Query           map[string]string `json:"query,omitempty"`            // 注入的查询参数（覆盖同名参数），值中可用 {参数} 引用路径参数
+	MaxBodySize     int64             `json:"max_body_size,omitempty"`    // 请求体大小上限（字节），超过返回 413
+}
+
+// MetadataWeight 节点权重的元数据键（加权随机策略使用）
+const MetadataWeight = "weight"
+
 // RouteConfig 路由配置（存储在服务元数据中）
 type RouteConfig struct {
-	PathPrefix   string   `json:"path_prefix"`   // 网关路径前缀，如 /api/v1/logs
-	StripPrefix  bool     `json:"strip_prefix"`  // 是否去除前缀
-	Methods      []string `json:"methods"`       // 允许的HTTP方法
-	AuthRequired bool     `json:"auth_required"` // 是否需要认证
+	Host         string   `json:"host,omitempty"`         // 匹配的主机名，为空匹配任意主机，支持 *.example.com
+	PathPrefix   string   `json:"path_prefix"`            // 网关路径规则，如 /api/v1/logs、/api/v1/{tenant}/orders/*
+	StripPrefix  bool     `json:"strip_prefix"`           // 是否去除前缀
+	Methods      []string `json:"methods"`                // 允许的HTTP方法
+	AuthRequired bool     `json:"auth_required"`          // 是否需要认证
+	LoadBalance  string   `json:"load_balance,omitempty"` // 负载均衡策略，为空时轮询
+	HashKey      string   `json:"hash_key,omitempty"`     // 一致性哈希键: user、ip 或 header:<名称>
+	Idempotent   bool     `json:"idempotent,omitempty"`   // 是否幂等，幂等路由的所有方法在网关失败时都会重试
+
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"` // 网关限流，为空时不限流
+	Traffic   *TrafficSplit    `json:"traffic,omitempty"`    // 按版本分流，为空时不区分版本
+	Transform *TransformConfig `json:"transform,omitempty"`  // 请求与响应转换，为空时原样转发
 }
 
 // ServiceConfig 服务配置
@@ -22,25 +126,41 @@ type ServiceConfig struct {
 	NodeID    string        // 节点ID
 	Address   string        // 服务地址
 	BasePath  string        // 服务基础路径（如 logs），网关会代理 /api/v1/{BasePath}/* 到服务的 /*
+	Weight    int           // 节点权重（可选，加权随机策略使用）
 	Routes    []RouteConfig // 路由配置（可选，用于细粒度控制）
 }
 
+// NewPublicRoutes 根据服务内的公开路径生成网关路由配置
+// 例如: NewPublicRoutes("users", "/auth/login") 使网关对 /api/v1/users/auth/login 放行
+func NewPublicRoutes(basePath string, paths ...string) []RouteConfig {
+	routes := make([]RouteConfig, 0, len(paths))
+	for _, p := range paths {
+		routes = append(routes, NewPublicRoute(GatewayPath(basePath, p)))
+	}
+	return routes
+}
+
 // BuildService 构建服务注册信息
 func BuildService(cfg *ServiceConfig) *registry.Service {
 	// 将路由配置序列化为JSON存储在Metadata中
 	routesJSON, _ := json.Marshal(cfg.Routes)
 
+	metadata := map[string]string{
+		"routes":    string(routesJSON),
+		"base_path": cfg.BasePath,
+	}
+	if cfg.Weight > 0 {
+		metadata[MetadataWeight] = strconv.Itoa(cfg.Weight)
+	}
+
 	return &registry.Service{
 		Name:    cfg.Name,
 		Version: cfg.Version,
 		Nodes: []*registry.Node{
 			{
-				Id:      cfg.NodeID,
-				Address: cfg.Address,
-				Metadata: map[string]string{
-					"routes":    string(routesJSON),
-					"base_path": cfg.BasePath,
-				},
+				Id:       cfg.NodeID,
+				Address:  cfg.Address,
+				Metadata: metadata,
 			},
 		},
 	}
@@ -62,6 +182,14 @@ func ParseServiceMeta(svc *registry.Service) (basePath string, routes []RouteCon
 	return
 }
 
+// NodeWeight 从节点元数据中解析权重，未设置或无效时返回 1
+func NodeWeight(node *registry.Node) int {
+	if w, err := strconv.Atoi(node.Metadata[MetadataWeight]); err == nil && w >= 0 {
+		return w
+	}
+	return 1
+}
+
 // ParseRoutes 从服务元数据中解析路由配置（兼容旧方法）
 func ParseRoutes(svc *registry.Service) []RouteConfig {
 	_, routes := ParseServiceMeta(svc)
@@ -129,6 +257,12 @@ func (b *ServiceBuilder) WithBasePath(basePath string) *ServiceBuilder {
 	return b
 }
 
+// WithWeight 设置节点权重（加权随机策略使用）
+func (b *ServiceBuilder) WithWeight(weight int) *ServiceBuilder {
+	b.config.Weight = weight
+	return b
+}
+
 // AddRoute 添加路由
 func (b *ServiceBuilder) AddRoute(route RouteConfig) *ServiceBuilder {
 	b.config.Routes = append(b.config.Routes, route)
@@ -147,22 +281,36 @@ func (b *ServiceBuilder) AddProtectedRoute(pathPrefix string, methods ...string)
 
 // Build 构建服务
 func (b *ServiceBuilder) Build() *registry.Service {
-	// 如果没有设置NodeID，使用服务名+"-1"
+	// 如果没有设置NodeID，自动生成唯一节点ID（同一服务的多个副本互不覆盖）
 	if b.config.NodeID == "" {
-		b.config.NodeID = b.config.Name + "-1"
+		b.config.NodeID = NewNodeID(b.config.Name)
 	}
 	return BuildService(b.config)
 }
 
-// MatchPath 检查路径是否匹配
+// nodeIDAlphabet 节点ID随机部分字符集
+const nodeIDAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// NewNodeID 生成唯一节点ID，格式: <服务名>-<8位随机串>
+func NewNodeID(serviceName string) string {
+	return serviceName + "-" + security.RandomStringWithAlphabet(8, nodeIDAlphabet)
+}
+
+// MatchPath 检查路径是否匹配（按段边界前缀匹配，/api/v1/user 不匹配 /api/v1/users）
 func (r *RouteConfig) MatchPath(path string) bool {
-	return strings.HasPrefix(path, r.PathPrefix)
+	prefix := strings.TrimSuffix(r.PathPrefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/") || prefix == ""
 }
 
 // MatchMethod 检查方法是否允许
 func (r *RouteConfig) MatchMethod(method string) bool {
-	for _, m := range r.Methods {
-		if strings.EqualFold(m, method) {
+	return MethodAllowed(r.Methods, method)
+}
+
+// MethodAllowed 检查方法是否在允许列表中，允许 GET 时同时允许 HEAD
+func MethodAllowed(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) || (strings.EqualFold(method, http.MethodHead) && strings.EqualFold(m, http.MethodGet)) {
 			return true
 		}
 	}
@@ -0,0 +1,342 @@
+// Package resp RESP2（Redis 序列化协议）编解码
+//
+// 客户端（pkg/cache 的 Redis 后端）与服务端（services/redis 的 RESP 监听）共用
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// 值类型（首字节）
+const (
+	TypeSimpleString = '+'
+	TypeError        = '-'
+	TypeInteger      = ':'
+	TypeBulkString   = '$'
+	TypeArray        = '*'
+)
+
+// 协议限制
+const (
+	MaxBulkLength  = 512 << 20 // 单个字符串最大 512MB（与 Redis 一致）
+	MaxArrayLength = 1 << 20   // 单个数组最多 1M 个元素
+	maxInlineSize  = 64 << 10  // 内联命令最大 64KB
+	bulkChunk      = 64 << 10  // 读取字符串时按块增长缓冲，不按声明的长度一次分配
+
+	// 未认证连接的限制（与 Redis 一致），AUTH 等命令用不到更大的请求
+	UnauthArrayLength = 10
+	UnauthBulkLength  = 16 << 10
+)
+
+// ErrProtocol 协议错误（连接上的数据无法继续解析，应关闭连接）
+var ErrProtocol = errors.New("resp: protocol error")
+
+// Error 服务端返回的错误回复（如 "ERR unknown command"），不影响连接继续使用
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Value RESP2 值
+type Value struct {
+	Type  byte
+	Str   string  // 简单字符串与错误
+	Int   int64   // 整数
+	Bulk  []byte  // 字符串
+	Array []Value // 数组
+	Null  bool    // 空字符串（$-1）或空数组（*-1）
+}
+
+// Err 错误回复转换为 Error，其他类型返回 nil
+func (v Value) Err() error {
+	if v.Type == TypeError {
+		return Error(v.Str)
+	}
+	return nil
+}
+
+// Text 以字符串形式返回简单字符串、字符串或整数
+func (v Value) Text() string {
+	switch v.Type {
+	case TypeSimpleString, TypeError:
+		return v.Str
+	case TypeBulkString:
+		return string(v.Bulk)
+	case TypeInteger:
+		return strconv.FormatInt(v.Int, 10)
+	}
+	return ""
+}
+
+// Reader RESP2 读取器
+type Reader struct {
+	r        *bufio.Reader
+	maxArray int // 数组最大元素数
+	maxBulk  int // 字符串最大字节数
+}
+
+// NewReader 创建读取器
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), maxArray: MaxArrayLength, maxBulk: MaxBulkLength}
+}
+
+// SetLimits 设置数组与字符串的长度上限（如连接未认证时收紧），超出时返回协议错误
+func (r *Reader) SetLimits(maxArray, maxBulk int) {
+	r.maxArray = maxArray
+	r.maxBulk = maxBulk
+}
+
+// ReadValue 读取一个值
+func (r *Reader) ReadValue() (Value, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+	if len(line) == 0 {
+		return Value{}, fmt.Errorf("%w: empty line", ErrProtocol)
+	}
+
+	v := Value{Type: line[0]}
+	switch line[0] {
+	case TypeSimpleString, TypeError:
+		v.Str = string(line[1:])
+	case TypeInteger:
+		if v.Int, err = parseInt(line[1:]); err != nil {
+			return Value{}, err
+		}
+	case TypeBulkString:
+		n, err := parseLength(line[1:], r.maxBulk)
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			v.Null = true
+			return v, nil
+		}
+		if v.Bulk, err = r.readBulk(n); err != nil {
+			return Value{}, err
+		}
+	case TypeArray:
+		n, err := parseLength(line[1:], r.maxArray)
+		if err != nil {
+			return Value{}, err
+		}
+		if n < 0 {
+			v.Null = true
+			return v, nil
+		}
+		// 元素到达后再追加，避免按客户端声明的长度预先分配
+		v.Array = make([]Value, 0, min(n, 1024))
+		for range n {
+			elem, err := r.ReadValue()
+			if err != nil {
+				return Value{}, err
+			}
+			v.Array = append(v.Array, elem)
+		}
+	default:
+		return Value{}, fmt.Errorf("%w: unexpected type %q", ErrProtocol, line[0])
+	}
+	return v, nil
+}
+
+// ReadCommand 读取一条命令：字符串数组，或以空格分隔的内联命令（便于 telnet 调试）
+// 空行返回空命令
+func (r *Reader) ReadCommand() ([][]byte, error) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if b[0] != TypeArray {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Fields(line), nil
+	}
+
+	v, err := r.ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	args := make([][]byte, len(v.Array))
+	for i, arg := range v.Array {
+		if arg.Type != TypeBulkString || arg.Null {
+			return nil, fmt.Errorf("%w: expected bulk string argument", ErrProtocol)
+		}
+		args[i] = arg.Bulk
+	}
+	return args, nil
+}
+
+// Peek 等待连接上有可读数据，连接关闭或读取出错时返回错误（不消费数据）
+// 用于阻塞命令执行期间检测客户端断开
+func (r *Reader) Peek() error {
+	_, err := r.r.Peek(1)
+	return err
+}
+
+// readLine 读取以 CRLF 结尾的一行（不含 CRLF）
+func (r *Reader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+		if len(line) > maxInlineSize {
+			return nil, fmt.Errorf("%w: line too long", ErrProtocol)
+		}
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		// 兼容只以 LF 结尾的内联命令
+		return bytes.TrimSuffix(line, []byte("\n")), nil
+	}
+	return line[:len(line)-2], nil
+}
+
+// readBulk 读取 n 字节与结尾的 CRLF
+// 缓冲随数据到达按块增长，声明了很大长度却不发送数据的连接不会占用大量内存
+func (r *Reader) readBulk(n int) ([]byte, error) {
+	buf := make([]byte, 0, min(n+2, bulkChunk))
+	for len(buf) < n+2 {
+		chunk := min(n+2-len(buf), max(len(buf), bulkChunk))
+		buf = slices.Grow(buf, chunk)
+		m, err := io.ReadFull(r.r, buf[len(buf):len(buf)+chunk])
+		buf = buf[:len(buf)+m]
+		if err != nil {
+			return nil, err
+		}
+	}
+	if buf[n] != '\r' || buf[n+1] != '\n' {
+		return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", ErrProtocol)
+	}
+	return buf[:n], nil
+}
+
+func parseInt(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid integer %q", ErrProtocol, b)
+	}
+	return n, nil
+}
+
+func parseLength(b []byte, max int) (int, error) {
+	n, err := parseInt(b)
+	if err != nil {
+		return 0, err
+	}
+	if n < -1 || n > int64(max) {
+		return 0, fmt.Errorf("%w: invalid length %d", ErrProtocol, n)
+	}
+	return int(n), nil
+}
+
+// Writer RESP2 写入器，写入缓冲后需调用 Flush
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter 创建写入器
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteSimple 写入简单字符串（如 OK）
+func (w *Writer) WriteSimple(s string) error {
+	w.w.WriteByte(TypeSimpleString)
+	w.w.WriteString(s)
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteError 写入错误回复，msg 应以错误类型开头（如 "ERR syntax error"）
+func (w *Writer) WriteError(msg string) error {
+	w.w.WriteByte(TypeError)
+	w.w.WriteString(msg)
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteInt 写入整数
+func (w *Writer) WriteInt(n int64) error {
+	w.w.WriteByte(TypeInteger)
+	w.w.WriteString(strconv.FormatInt(n, 10))
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteBulk 写入字符串
+func (w *Writer) WriteBulk(b []byte) error {
+	w.w.WriteByte(TypeBulkString)
+	w.w.WriteString(strconv.Itoa(len(b)))
+	w.w.WriteString("\r\n")
+	w.w.Write(b)
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteBulkString 写入字符串
+func (w *Writer) WriteBulkString(s string) error {
+	return w.WriteBulk([]byte(s))
+}
+
+// WriteNull 写入空字符串（$-1，表示键不存在）
+func (w *Writer) WriteNull() error {
+	_, err := w.w.WriteString("$-1\r\n")
+	return err
+}
+
+// WriteNullArray 写入空数组（*-1，如阻塞弹出超时）
+func (w *Writer) WriteNullArray() error {
+	_, err := w.w.WriteString("*-1\r\n")
+	return err
+}
+
+// WriteArray 写入数组头，之后依次写入 n 个元素
+func (w *Writer) WriteArray(n int) error {
+	w.w.WriteByte(TypeArray)
+	w.w.WriteString(strconv.Itoa(n))
+	_, err := w.w.WriteString("\r\n")
+	return err
+}
+
+// WriteCommand 写入命令，参数支持 string、[]byte、int、int64
+func (w *Writer) WriteCommand(args ...any) error {
+	w.WriteArray(len(args))
+	for _, arg := range args {
+		var err error
+		switch v := arg.(type) {
+		case string:
+			err = w.WriteBulkString(v)
+		case []byte:
+			err = w.WriteBulk(v)
+		case int:
+			err = w.WriteBulkString(strconv.Itoa(v))
+		case int64:
+			err = w.WriteBulkString(strconv.FormatInt(v, 10))
+		default:
+			return fmt.Errorf("resp: unsupported argument type %T", arg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush 将缓冲写入底层连接
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
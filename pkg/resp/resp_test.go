@@ -0,0 +1,148 @@
+package resp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteAndReadValues(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteSimple("OK")
+	w.WriteError("ERR boom")
+	w.WriteInt(-42)
+	w.WriteBulk([]byte("a\r\nb"))
+	w.WriteNull()
+	w.WriteArray(2)
+	w.WriteBulkString("x")
+	w.WriteInt(1)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "+OK\r\n-ERR boom\r\n:-42\r\n$4\r\na\r\nb\r\n$-1\r\n*2\r\n$1\r\nx\r\n:1\r\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, buf.String())
+	}
+
+	r := NewReader(&buf)
+	values := make([]Value, 6)
+	for i := range values {
+		v, err := r.ReadValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values[i] = v
+	}
+
+	if values[0].Text() != "OK" || values[0].Err() != nil {
+		t.Fatalf("Unexpected simple string %+v", values[0])
+	}
+	var respErr Error
+	if !errors.As(values[1].Err(), &respErr) || string(respErr) != "ERR boom" {
+		t.Fatalf("Unexpected error %+v", values[1])
+	}
+	if values[2].Int != -42 || values[2].Text() != "-42" {
+		t.Fatalf("Unexpected integer %+v", values[2])
+	}
+	if string(values[3].Bulk) != "a\r\nb" {
+		t.Fatalf("Unexpected bulk string %+v", values[3])
+	}
+	if !values[4].Null {
+		t.Fatalf("Expected null bulk string, got %+v", values[4])
+	}
+	if len(values[5].Array) != 2 || values[5].Array[0].Text() != "x" || values[5].Array[1].Int != 1 {
+		t.Fatalf("Unexpected array %+v", values[5])
+	}
+}
+
+func TestReadCommand(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteCommand("SET", []byte("key"), 10, int64(20))
+	w.Flush()
+	buf.WriteString("PING  hello\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString("GET key\n")
+
+	r := NewReader(&buf)
+	scenarios := [][]string{
+		{"SET", "key", "10", "20"},
+		{"PING", "hello"},
+		{},
+		{"GET", "key"},
+	}
+	for _, expected := range scenarios {
+		args, err := r.ReadCommand()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(args))
+		for i, a := range args {
+			got[i] = string(a)
+		}
+		if strings.Join(got, " ") != strings.Join(expected, " ") || len(got) != len(expected) {
+			t.Fatalf("Expected %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestReadProtocolErrors(t *testing.T) {
+	scenarios := []string{
+		"?what\r\n",
+		":abc\r\n",
+		"$-2\r\n",
+		"$3\r\nabcd\r\n",
+		"*99999999999\r\n",
+	}
+	for _, s := range scenarios {
+		_, err := NewReader(strings.NewReader(s)).ReadValue()
+		if !errors.Is(err, ErrProtocol) {
+			t.Fatalf("Expected protocol error for %q, got %v", s, err)
+		}
+	}
+
+	// 命令参数必须是字符串
+	if _, err := NewReader(strings.NewReader("*1\r\n:1\r\n")).ReadCommand(); !errors.Is(err, ErrProtocol) {
+		t.Fatalf("Expected protocol error for integer argument, got %v", err)
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	// 长度超过限制时在读取数据前返回协议错误
+	r := NewReader(strings.NewReader("*11\r\n"))
+	r.SetLimits(UnauthArrayLength, UnauthBulkLength)
+	if _, err := r.ReadCommand(); !errors.Is(err, ErrProtocol) {
+		t.Fatalf("Expected protocol error for a long array, got %v", err)
+	}
+	r = NewReader(strings.NewReader("*1\r\n$16385\r\n"))
+	r.SetLimits(UnauthArrayLength, UnauthBulkLength)
+	if _, err := r.ReadCommand(); !errors.Is(err, ErrProtocol) {
+		t.Fatalf("Expected protocol error for a long bulk string, got %v", err)
+	}
+
+	// 声明的长度很大但数据不足时返回读取错误
+	if _, err := NewReader(strings.NewReader("$536870912\r\nabc")).ReadValue(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected unexpected EOF, got %v", err)
+	}
+	if _, err := NewReader(strings.NewReader("*1048576\r\n$1\r\na\r\n")).ReadValue(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Expected EOF, got %v", err)
+	}
+
+	// 跨越多个缓冲块的字符串完整读取
+	large := strings.Repeat("x", 3*bulkChunk+7)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteBulkString(large)
+	w.Flush()
+	v, err := NewReader(&buf).ReadValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v.Bulk) != large {
+		t.Fatalf("Expected %d bytes, got %d", len(large), len(v.Bulk))
+	}
+}
@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 批量导出默认配置
+const (
+	DefaultBufferSize    = 4096
+	DefaultBatchSize     = 256
+	DefaultFlushInterval = 5 * time.Second
+)
+
+// Exporter Span 导出器
+type Exporter interface {
+	// ExportSpans 导出一批已结束的 Span
+	ExportSpans(ctx context.Context, spans []*Span) error
+	// Shutdown 关闭导出器
+	Shutdown(ctx context.Context) error
+}
+
+// InMemoryExporter 将 Span 保存在内存中（用于测试）
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryExporter 创建内存导出器
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpans 保存 Span
+func (e *InMemoryExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// Shutdown 无操作
+func (e *InMemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans 已导出的 Span（按结束顺序）
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]*Span, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+// Reset 清空已导出的 Span
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// batcher 批量导出：Span 结束时只放入缓冲区，由后台协程按批量大小或导出间隔导出
+type batcher struct {
+	exporter  Exporter
+	spans     chan *Span
+	batchSize int
+	interval  time.Duration
+	dropped   atomic.Int64 // 缓冲区已满而丢弃的 Span 数，导出时汇报后清零
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// newBatcher 创建并启动批量导出，未设置的参数使用默认值
+func newBatcher(exporter Exporter, bufferSize, batchSize int, interval time.Duration) *batcher {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	b := &batcher{
+		exporter:  exporter,
+		spans:     make(chan *Span, bufferSize),
+		batchSize: batchSize,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add 放入缓冲区，已满或已关闭时丢弃
+func (b *batcher) add(span *Span) {
+	select {
+	case <-b.stop:
+		b.dropped.Add(1)
+		return
+	default:
+	}
+
+	select {
+	case b.spans <- span:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+// close 停止接收并导出缓冲区中剩余的 Span，ctx 结束时不再等待
+func (b *batcher) close(ctx context.Context) error {
+	b.closeOnce.Do(func() { close(b.stop) })
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 后台按批量大小或导出间隔导出
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, b.batchSize)
+	flush := func() {
+		if dropped := b.dropped.Swap(0); dropped > 0 {
+			slog.Warn("trace buffer full, spans dropped", "dropped", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.exporter.ExportSpans(context.Background(), batch); err != nil {
+			logExportError(err, len(batch))
+		}
+		batch = make([]*Span, 0, b.batchSize)
+	}
+
+	for {
+		select {
+		case span := <-b.spans:
+			batch = append(batch, span)
+			if len(batch) >= b.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-b.stop:
+			// 导出缓冲区中剩余的 Span
+			for {
+				select {
+				case span := <-b.spans:
+					batch = append(batch, span)
+					if len(batch) >= b.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// logExportError 记录导出失败（pkg/logger 依赖本包记录数据库 Span，这里使用 slog）
+func logExportError(err error, spans int) {
+	slog.Warn("export spans failed", "spans", spans, "error", err)
+}
@@ -0,0 +1,197 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// instrumentationScope 导出时使用的 instrumentation scope 名称
+const instrumentationScope = "github.com/goback/pkg/tracing"
+
+// OTLPExporter 通过 OTLP/HTTP（JSON 编码）导出 Span，如发送到 OpenTelemetry Collector 的 :4318/v1/traces
+type OTLPExporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewOTLPExporter 创建 OTLP/HTTP 导出器，headers 为附加的请求头（如认证信息）
+func NewOTLPExporter(endpoint string, headers map[string]string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ExportSpans 按服务分组后发送一次导出请求
+func (e *OTLPExporter) ExportSpans(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(newOTLPRequest(spans))
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp endpoint unavailable (%s): %w", e.endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp endpoint error (%s): status %d: %s", e.endpoint, resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// Shutdown 关闭空闲连接
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// OTLP JSON 编码的请求结构（opentelemetry-proto 的 JSON 映射）
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		TraceState        string         `json:"traceState,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"` // int64 在 JSON 映射中编码为字符串
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// newOTLPRequest 将 Span 按服务分组转换为导出请求
+func newOTLPRequest(spans []*Span) *otlpRequest {
+	var services []string
+	grouped := make(map[string][]otlpSpan)
+	for _, s := range spans {
+		if _, ok := grouped[s.Service]; !ok {
+			services = append(services, s.Service)
+		}
+		grouped[s.Service] = append(grouped[s.Service], toOTLPSpan(s))
+	}
+
+	req := &otlpRequest{ResourceSpans: make([]otlpResourceSpans, 0, len(services))}
+	for _, service := range services {
+		req.ResourceSpans = append(req.ResourceSpans, otlpResourceSpans{
+			Resource: otlpResource{Attributes: []otlpKeyValue{{Key: "service.name", Value: anyValue(service)}}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationScope},
+				Spans: grouped[service],
+			}},
+		})
+	}
+	return req
+}
+
+// toOTLPSpan 转换单个 Span，属性按键排序保证输出稳定
+func toOTLPSpan(s *Span) otlpSpan {
+	span := otlpSpan{
+		TraceID:           s.Context.TraceID.String(),
+		SpanID:            s.Context.SpanID.String(),
+		TraceState:        s.Context.TraceState,
+		Name:              s.Name,
+		Kind:              s.Kind,
+		StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+		Status:            otlpStatus{Code: s.Status, Message: s.StatusMessage},
+	}
+	if s.ParentID.IsValid() {
+		span.ParentSpanID = s.ParentID.String()
+	}
+
+	keys := make([]string, 0, len(s.Attributes))
+	for k := range s.Attributes {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: k, Value: anyValue(s.Attributes[k])})
+	}
+	return span
+}
+
+// anyValue 转换属性值
+func anyValue(v any) otlpAnyValue {
+	switch v := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func intValue(v int64) otlpAnyValue {
+	s := strconv.FormatInt(v, 10)
+	return otlpAnyValue{IntValue: &s}
+}
@@ -0,0 +1,252 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID 链路ID（16 字节）
+type TraceID [16]byte
+
+// SpanID Span ID（8 字节）
+type SpanID [8]byte
+
+// IsValid 是否为有效（非全零）的链路ID
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// String 十六进制表示
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid 是否为有效（非全零）的 Span ID
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// String 十六进制表示
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// newTraceID 生成随机链路ID
+func newTraceID() TraceID {
+	var t TraceID
+	for !t.IsValid() {
+		binary.BigEndian.PutUint64(t[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(t[8:], rand.Uint64())
+	}
+	return t
+}
+
+// newSpanID 生成随机 Span ID
+func newSpanID() SpanID {
+	var s SpanID
+	for !s.IsValid() {
+		binary.BigEndian.PutUint64(s[:], rand.Uint64())
+	}
+	return s
+}
+
+// SpanContext 跨进程传递的链路上下文（W3C Trace Context）
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool   // 是否采样（导出）
+	TraceState string // 原样传递的 tracestate
+	Remote     bool   // 是否从上游请求中解析得到
+}
+
+// IsValid 链路ID与 Span ID 均有效
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParent 格式化为 traceparent 头的值，如 00-<trace-id>-<span-id>-01
+func (sc SpanContext) TraceParent() string {
+	if !sc.IsValid() {
+		return ""
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ErrInvalidTraceParent traceparent 格式无效
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// ParseTraceParent 解析 traceparent 头
+// 兼容更高版本：版本号不为 00 时只解析前四个字段
+func ParseTraceParent(s string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+
+	var sc SpanContext
+	if err := decodeHex(parts[1], sc.TraceID[:]); err != nil {
+		return SpanContext{}, err
+	}
+	if err := decodeHex(parts[2], sc.SpanID[:]); err != nil {
+		return SpanContext{}, err
+	}
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return SpanContext{}, err
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	sc.Sampled = flags[0]&0x01 == 0x01
+	sc.Remote = true
+	return sc, nil
+}
+
+// decodeHex 解码固定长度的小写十六进制字段
+func decodeHex(s string, dst []byte) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTraceParent, err)
+	}
+	return nil
+}
+
+// SpanKind Span 类型，取值与 OTLP 一致
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1 // 进程内操作
+	SpanKindServer   SpanKind = 2 // 处理收到的请求
+	SpanKindClient   SpanKind = 3 // 发出的请求（HTTP、缓存、数据库）
+	SpanKindProducer SpanKind = 4 // 发布消息
+	SpanKindConsumer SpanKind = 5 // 处理收到的消息
+)
+
+// StatusCode Span 状态，取值与 OTLP 一致
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Span 一次操作的追踪记录
+// 所有方法对 nil 安全，未处于链路中时 StartChild 返回 nil
+type Span struct {
+	Service       string
+	Name          string
+	Kind          SpanKind
+	Context       SpanContext
+	ParentID      SpanID // 父 Span ID，为空表示根 Span
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]any
+	Status        StatusCode
+	StatusMessage string
+
+	tracer *Tracer
+	mu     sync.Mutex
+	ended  bool
+}
+
+// SpanOption 创建 Span 的选项
+type SpanOption func(*Span)
+
+// WithStartTime 指定开始时间（如在操作完成后补记 Span）
+func WithStartTime(t time.Time) SpanOption {
+	return func(s *Span) {
+		s.StartTime = t
+	}
+}
+
+// WithAttributes 设置初始属性
+func WithAttributes(attrs map[string]any) SpanOption {
+	return func(s *Span) {
+		for k, v := range attrs {
+			s.Attributes[k] = v
+		}
+	}
+}
+
+// SpanContext 返回 Span 的链路上下文
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.Context
+}
+
+// SetName 修改名称
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Name = name
+	}
+}
+
+// SetAttribute 设置属性，值支持字符串、整数、浮点数与布尔值，其他类型按 fmt 格式化
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Attributes[key] = value
+	}
+}
+
+// SetStatus 设置状态
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.Status = code
+		s.StatusMessage = message
+	}
+}
+
+// RecordError 记录错误并将状态置为失败，err 为空时不做处理
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetAttribute("error.message", err.Error())
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End 结束 Span 并交给导出器，重复调用无效
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+
+	if s.Context.Sampled {
+		s.tracer.export(s)
+	}
+}
+
+// Duration 持续时间
+func (s *Span) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
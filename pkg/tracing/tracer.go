@@ -0,0 +1,248 @@
+// Package tracing 分布式链路追踪
+//
+// 按 W3C Trace Context 规范通过 traceparent 在网关、服务、PubSub 与缓存调用之间传递链路，
+// 结束的 Span 交给可插拔的导出器（测试使用 InMemoryExporter，生产使用 OTLP/HTTP）。
+package tracing
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/goback/pkg/config"
+)
+
+// W3C Trace Context 请求头
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+// Tracer 创建 Span 并交给导出器
+type Tracer struct {
+	service     string
+	exporter    Exporter
+	batcher     *batcher // 为空时 Span 结束即同步导出
+	sampleRatio float64  // 根 Span 的采样比例，子 Span 沿用上游的采样决定
+}
+
+// Option Tracer 配置选项
+type Option func(*Tracer)
+
+// WithSampleRatio 设置根 Span 的采样比例（0~1，默认 1 全部采样）
+func WithSampleRatio(ratio float64) Option {
+	return func(t *Tracer) {
+		t.sampleRatio = ratio
+	}
+}
+
+// WithBatch 批量导出：Span 先放入大小为 bufferSize 的缓冲区，按 batchSize 或 interval 批量导出，
+// 缓冲区写满时丢弃新的 Span
+func WithBatch(bufferSize, batchSize int, interval time.Duration) Option {
+	return func(t *Tracer) {
+		t.batcher = newBatcher(t.exporter, bufferSize, batchSize, interval)
+	}
+}
+
+// NewTracer 创建 Tracer，exporter 为空时只传递链路不导出
+func NewTracer(service string, exporter Exporter, opts ...Option) *Tracer {
+	t := &Tracer{
+		service:     service,
+		exporter:    exporter,
+		sampleRatio: 1,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.exporter == nil && t.batcher != nil {
+		t.batcher.close(context.Background())
+		t.batcher = nil
+	}
+	return t
+}
+
+// Start 创建 Span：ctx 中有上游链路时作为其子 Span，否则开始新的链路
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (context.Context, *Span) {
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		Service:    t.service,
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: make(map[string]any),
+		tracer:     t,
+	}
+	if parent.IsValid() {
+		span.Context = SpanContext{
+			TraceID:    parent.TraceID,
+			SpanID:     newSpanID(),
+			Sampled:    parent.Sampled,
+			TraceState: parent.TraceState,
+		}
+		span.ParentID = parent.SpanID
+	} else {
+		span.Context = SpanContext{
+			TraceID: newTraceID(),
+			SpanID:  newSpanID(),
+			Sampled: t.sampleRatio >= 1 || rand.Float64() < t.sampleRatio,
+		}
+	}
+	for _, opt := range opts {
+		opt(span)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// StartChild 仅在 ctx 处于链路中时创建子 Span，否则返回 nil（Span 的方法对 nil 安全）
+// 用于缓存、数据库、消息发布等客户端调用，避免没有上游请求的后台操作产生零散的链路
+func (t *Tracer) StartChild(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (context.Context, *Span) {
+	if !SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+	return t.Start(ctx, name, kind, opts...)
+}
+
+// export 导出结束的 Span
+func (t *Tracer) export(span *Span) {
+	switch {
+	case t.batcher != nil:
+		t.batcher.add(span)
+	case t.exporter != nil:
+		if err := t.exporter.ExportSpans(context.Background(), []*Span{span}); err != nil {
+			logExportError(err, 1)
+		}
+	}
+}
+
+// Shutdown 导出剩余的 Span 并关闭导出器
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t.batcher != nil {
+		if err := t.batcher.close(ctx); err != nil {
+			return err
+		}
+	}
+	if t.exporter != nil {
+		return t.exporter.Shutdown(ctx)
+	}
+	return nil
+}
+
+// global 全局 Tracer，默认只传递链路不导出
+var global atomic.Pointer[Tracer]
+
+func init() {
+	global.Store(NewTracer("", nil))
+}
+
+// Default 获取全局 Tracer
+func Default() *Tracer {
+	return global.Load()
+}
+
+// SetTracer 设置全局 Tracer，返回原 Tracer
+func SetTracer(t *Tracer) *Tracer {
+	return global.Swap(t)
+}
+
+// Init 按配置初始化全局 Tracer：开启时通过 OTLP/HTTP 批量导出，否则只传递链路
+func Init(service string, cfg *config.TracingConfig) {
+	if cfg == nil || !cfg.Enabled || cfg.Endpoint == "" {
+		SetTracer(NewTracer(service, nil))
+		return
+	}
+
+	opts := []Option{
+		WithBatch(cfg.BufferSize, cfg.BatchSize, time.Duration(cfg.FlushInterval)*time.Millisecond),
+	}
+	if cfg.SampleRatio > 0 {
+		opts = append(opts, WithSampleRatio(cfg.SampleRatio))
+	}
+	SetTracer(NewTracer(service, NewOTLPExporter(cfg.Endpoint, cfg.Headers), opts...))
+}
+
+// Shutdown 导出全局 Tracer 剩余的 Span
+func Shutdown(ctx context.Context) error {
+	return Default().Shutdown(ctx)
+}
+
+// Start 使用全局 Tracer 创建 Span
+func Start(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (context.Context, *Span) {
+	return Default().Start(ctx, name, kind, opts...)
+}
+
+// StartChild 使用全局 Tracer 在已有链路中创建子 Span
+func StartChild(ctx context.Context, name string, kind SpanKind, opts ...SpanOption) (context.Context, *Span) {
+	return Default().StartChild(ctx, name, kind, opts...)
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// ContextWithSpan 将 Span 放入上下文
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext 获取上下文中的 Span，没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext 将上游传入的链路上下文放入上下文，之后创建的 Span 作为其子 Span
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext 获取当前链路上下文：优先使用本进程的 Span，其次使用上游传入的链路
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.Context
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// TraceParent 当前链路的 traceparent 值，不在链路中时返回空字符串
+func TraceParent(ctx context.Context) string {
+	return SpanContextFromContext(ctx).TraceParent()
+}
+
+// ContextWithTraceParent 解析 traceparent 值并放入上下文，值无效时原样返回 ctx
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	sc, err := ParseTraceParent(traceParent)
+	if err != nil {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Inject 将当前链路写入请求头
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(HeaderTraceParent, sc.TraceParent())
+	if sc.TraceState != "" {
+		header.Set(HeaderTraceState, sc.TraceState)
+	}
+}
+
+// Extract 从请求头解析上游链路并放入上下文，请求头无效时原样返回 ctx
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceParent(header.Get(HeaderTraceParent))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = header.Get(HeaderTraceState)
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		value   string
+		valid   bool
+		sampled bool
+	}{
+		{"sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"not sampled", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true, true},
+		{"version 00 with extra fields", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false, false},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"short trace id", "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01", false, false},
+		{"empty", "", false, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			sc, err := ParseTraceParent(s.value)
+			if !s.valid {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", sc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sc.Sampled != s.sampled || !sc.Remote {
+				t.Fatalf("Unexpected span context %+v", sc)
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Fatalf("Unexpected ids %s %s", sc.TraceID, sc.SpanID)
+			}
+		})
+	}
+}
+
+func TestStartAndPropagate(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test-service", exporter)
+
+	header := http.Header{}
+	header.Set(HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	header.Set(HeaderTraceState, "vendor=value")
+	ctx := Extract(context.Background(), header)
+
+	ctx, server := tracer.Start(ctx, "GET /users", SpanKindServer)
+	_, client := tracer.StartChild(ctx, "cache get", SpanKindClient)
+	client.RecordError(errors.New("boom"))
+	client.End()
+	server.End()
+	server.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(spans))
+	}
+	if server.Context.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("Expected server span to continue the remote trace, got %+v", server.Context)
+	}
+	if client.Context.TraceID != server.Context.TraceID || client.ParentID != server.Context.SpanID {
+		t.Fatal("Expected client span to be a child of the server span")
+	}
+	if client.Status != StatusError || client.Attributes["error.message"] != "boom" || client.Service != "test-service" {
+		t.Fatalf("Unexpected client span %+v", client)
+	}
+
+	out := http.Header{}
+	Inject(ContextWithSpan(context.Background(), client), out)
+	if out.Get(HeaderTraceParent) != client.Context.TraceParent() || out.Get(HeaderTraceState) != "vendor=value" {
+		t.Fatalf("Unexpected injected headers %v", out)
+	}
+
+	// 不在链路中时不创建子 Span
+	if _, span := tracer.StartChild(context.Background(), "db", SpanKindClient); span != nil {
+		t.Fatal("Expected no span outside a trace")
+	}
+}
+
+func TestSampling(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer("test-service", exporter, WithSampleRatio(0))
+
+	ctx, root := tracer.Start(context.Background(), "root", SpanKindServer)
+	_, child := tracer.Start(ctx, "child", SpanKindInternal)
+	child.End()
+	root.End()
+
+	if len(exporter.Spans()) != 0 {
+		t.Fatal("Expected unsampled spans not to be exported")
+	}
+	if TraceParent(ctx)[53:] != "00" || child.Context.Sampled {
+		t.Fatalf("Expected unsampled flag to propagate, got %s", TraceParent(ctx))
+	}
+
+	// 上游已采样的链路沿用上游的决定
+	remote := ContextWithTraceParent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(remote, "server", SpanKindServer)
+	span.End()
+	if len(exporter.Spans()) != 1 {
+		t.Fatal("Expected sampled parent to be honored")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var requests []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(raw, &body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, body)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	exporter := NewOTLPExporter(ts.URL, map[string]string{"Authorization": "Bearer token"})
+	tracer := NewTracer("user-service", exporter, WithBatch(10, 2, time.Hour))
+
+	ctx, root := tracer.Start(context.Background(), "GET /users", SpanKindServer)
+	root.SetAttribute("http.status_code", 200)
+	_, child := tracer.Start(ctx, "db SELECT", SpanKindClient)
+	child.SetAttribute("db.statement", "SELECT 1")
+	child.End()
+	root.End()
+	_, last := tracer.Start(context.Background(), "pubsub receive", SpanKindConsumer)
+	last.End()
+
+	// 第三个 Span 未满一批，关闭时导出
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 export requests, got %d", len(requests))
+	}
+
+	resource := requests[0]["resourceSpans"].([]any)[0].(map[string]any)
+	attr := resource["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	if attr["key"] != "service.name" || attr["value"].(map[string]any)["stringValue"] != "user-service" {
+		t.Fatalf("Unexpected resource %v", resource["resource"])
+	}
+	spans := resource["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans in the first batch, got %d", len(spans))
+	}
+	db := spans[0].(map[string]any)
+	if db["name"] != "db SELECT" || db["kind"] != float64(SpanKindClient) || db["parentSpanId"] != root.Context.SpanID.String() {
+		t.Fatalf("Unexpected span %v", db)
+	}
+	if db["traceId"] != root.Context.TraceID.String() || db["startTimeUnixNano"] == "" {
+		t.Fatalf("Unexpected span %v", db)
+	}
+	server := spans[1].(map[string]any)
+	status := server["attributes"].([]any)[0].(map[string]any)
+	if status["key"] != "http.status_code" || status["value"].(map[string]any)["intValue"] != "200" {
+		t.Fatalf("Unexpected attributes %v", server["attributes"])
+	}
+}
+
+func TestGlobalTracer(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	prev := SetTracer(NewTracer("test-service", exporter))
+	defer SetTracer(prev)
+
+	_, span := Start(context.Background(), "job", SpanKindInternal)
+	span.End()
+	if spans := exporter.Spans(); len(spans) != 1 || spans[0].Name != "job" {
+		t.Fatalf("Expected span exported through the global tracer, got %v", spans)
+	}
+
+	exporter.Reset()
+	if len(exporter.Spans()) != 0 {
+		t.Fatal("Expected Reset to clear spans")
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/config/internal/model"
 	"github.com/goback/services/config/internal/sysconfig"
 	"go.uber.org/zap"
@@ -35,6 +37,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -49,6 +58,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health", "/config/get-by-key"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		IsDev:          cfg.App.Env == "dev",
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -94,12 +104,13 @@ func main() {
 		// 系统参数配置路由组
 		configGroup := e.Router.Group("/config")
 		configGroup.Bind(jwtMiddleware)
-		configGroup.GET("/info", sysconfig.Info)
-		configGroup.GET("/get-by-key", sysconfig.GetByKey).Unbind(apis.DefaultJWTAuthMiddlewareId)
-		configGroup.GET("/page", sysconfig.Page)
-		configGroup.POST("/add", sysconfig.Add)
-		configGroup.PUT("/update", sysconfig.Update)
-		configGroup.DELETE("/remove", sysconfig.Remove)
+		configGroup.GET("/info", sysconfig.Info).Describe("获取系统参数").Response(model.SysConfig{})
+		configGroup.GET("/get-by-key", sysconfig.GetByKey).Unbind(apis.DefaultJWTAuthMiddlewareId).
+			Describe("按键名获取系统参数").Response(model.SysConfig{})
+		configGroup.GET("/page", sysconfig.Page).Describe("系统参数分页列表").Request(sysconfig.PageRequest{}).PagedResponse(model.SysConfig{})
+		configGroup.POST("/add", sysconfig.Add).Describe("创建系统参数").Request(sysconfig.CreateRequest{}).Response(model.SysConfig{})
+		configGroup.PUT("/update", sysconfig.Update).Describe("更新系统参数").Request(sysconfig.UpdateRequest{}).Response(model.SysConfig{})
+		configGroup.DELETE("/remove", sysconfig.Remove).Describe("批量删除系统参数").Request(sysconfig.RemoveRequest{})
 
 		return e.Next()
 	})
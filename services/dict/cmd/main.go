@@ -8,10 +8,12 @@ import (
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/dict/internal/dictdata"
 	"github.com/goback/services/dict/internal/dicttype"
 	"github.com/goback/services/dict/internal/model"
@@ -36,6 +38,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -50,6 +59,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health", "/dicts/dict-data/dicts/"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		IsDev:          cfg.App.Env == "dev",
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -95,23 +105,24 @@ func main() {
 		// 字典类型路由组（需要认证）
 		dictTypeGroup := e.Router.Group("/dicts/dict-types")
 		dictTypeGroup.Bind(jwtMiddleware)
-		dictTypeGroup.POST("", dicttype.Create)
-		dictTypeGroup.GET("", dicttype.List)
-		dictTypeGroup.GET("/{id}", dicttype.Get)
-		dictTypeGroup.PUT("/{id}", dicttype.Update)
-		dictTypeGroup.DELETE("/{id}", dicttype.Delete)
+		dictTypeGroup.POST("", dicttype.Create).Describe("创建字典类型").Request(dicttype.CreateRequest{}).Response(model.DictType{})
+		dictTypeGroup.GET("", dicttype.List).Describe("字典类型列表").PagedResponse(model.DictType{})
+		dictTypeGroup.GET("/{id}", dicttype.Get).Describe("获取字典类型").Response(model.DictType{})
+		dictTypeGroup.PUT("/{id}", dicttype.Update).Describe("更新字典类型").Request(dicttype.UpdateRequest{}).Response(model.DictType{})
+		dictTypeGroup.DELETE("/{id}", dicttype.Delete).Describe("删除字典类型")
 
 		// 字典数据路由组（需要认证）
 		dictDataGroup := e.Router.Group("/dicts/dict-data")
 		dictDataGroup.Bind(jwtMiddleware)
-		dictDataGroup.POST("", dictdata.Create)
-		dictDataGroup.GET("/{id}", dictdata.Get)
-		dictDataGroup.GET("/type/{typeId}", dictdata.ListByType)
-		dictDataGroup.PUT("/{id}", dictdata.Update)
-		dictDataGroup.DELETE("/{id}", dictdata.Delete)
+		dictDataGroup.POST("", dictdata.Create).Describe("创建字典数据").Request(dictdata.CreateRequest{}).Response(model.DictData{})
+		dictDataGroup.GET("/{id}", dictdata.Get).Describe("获取字典数据").Response(model.DictData{})
+		dictDataGroup.GET("/type/{typeId}", dictdata.ListByType).Describe("按类型获取字典数据").Response([]model.DictData{})
+		dictDataGroup.PUT("/{id}", dictdata.Update).Describe("更新字典数据").Request(dictdata.UpdateRequest{}).Response(model.DictData{})
+		dictDataGroup.DELETE("/{id}", dictdata.Delete).Describe("删除字典数据")
 
 		// 公开路由（无需认证）
-		e.Router.GET("/dicts/dict-data/dicts/{code}", dictdata.GetByCode)
+		e.Router.GET("/dicts/dict-data/dicts/{code}", dictdata.GetByCode).
+			Describe("按字典编码获取字典数据", "dict-data").Response([]model.DictData{})
 
 		return e.Next()
 	})
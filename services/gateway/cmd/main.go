@@ -9,17 +9,20 @@ import (
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/gateway/internal/gateway"
+	"github.com/goback/services/gateway/internal/model"
 	"go.uber.org/zap"
 )
 
 const serviceName = "gateway-service"
 
 func main() {
-	// 加载配置
-	if err := config.Init(""); err != nil {
+	// 加载配置（自动设置 SQLite 数据库文件名为 data/<serviceName>.db）
+	if err := config.InitWithService("", serviceName); err != nil {
 		fmt.Printf("加载配置失败: %v\n", err)
 		os.Exit(1)
 	}
@@ -29,9 +32,19 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
-	// 初始化 Redis 缓存客户端
-	cache.Init(cfg.Redis.Host, cfg.Redis.Port)
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化数据库（持久化路由覆盖配置）
+	if err := database.Init(&cfg.Database); err != nil {
+		logger.Fatal("初始化数据库失败", zap.Error(err))
+	}
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
 	logger.Info("缓存客户端已初始化",
+		zap.String("mode", cfg.Redis.Mode),
 		zap.String("host", cfg.Redis.Host),
 		zap.Int("port", cfg.Redis.Port),
 	)
@@ -42,9 +55,6 @@ func main() {
 	// 创建 Redis 注册中心
 	reg := pkgRegistry.NewRedisRegistry()
 
-	// 创建网关
-	gw := gateway.NewGateway(reg, cfg)
-
 	// 创建应用（自动创建 Registry、PubSub、Service）
 	app := core.NewBaseApp(core.BaseAppConfig{
 		ServiceName:    serviceName,
@@ -54,35 +64,70 @@ func main() {
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
 	})
 
-	// 启动时同步路由并监听服务
+	// 创建网关，开启访问日志时通过 PubSub 批量投递到日志服务
+	var opts []gateway.Option
+	if cfg.Gateway.AccessLog.Enabled {
+		opts = append(opts, gateway.WithAccessLog(func(records []core.AccessLogRecord) error {
+			return app.PublishTopicJSON(core.TopicAccessLog, records)
+		}))
+	}
+	gw := gateway.NewGateway(reg, cfg, opts...)
+
+	// 配置文件变化时热更新静态路由与上游
+	stopConfigWatch, err := config.Watch(func(newCfg *config.Config, err error) {
+		if err != nil {
+			logger.Error("重新加载配置失败", zap.Error(err))
+			return
+		}
+		if err := gw.ApplyStaticConfig(newCfg.Gateway); err != nil {
+			logger.Error("静态路由配置无效，保留原有配置", zap.Error(err))
+		}
+	})
+	if err != nil {
+		logger.Warn("监听配置文件失败，静态路由不会热更新", zap.Error(err))
+	}
+
+	// 启动时迁移数据库、同步路由并监听服务
 	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := database.Get().AutoMigrate(&model.GatewayRoute{}); err != nil {
+			return fmt.Errorf("数据库迁移失败: %w", err)
+		}
+		if err := gw.SyncOverrides(); err != nil {
+			logger.Warn("加载路由覆盖配置失败", zap.Error(err))
+		}
 		if err := gw.SyncRoutes(); err != nil {
 			logger.Warn("同步服务路由失败", zap.Error(err))
 		}
 		if err := gw.WatchServices(); err != nil {
 			return fmt.Errorf("启动服务监听失败: %w", err)
 		}
+		gw.StartHealthCheck()
 		return e.Next()
 	})
 
 	// 路由注册
 	app.OnServe().BindFunc(func(e *core.ServeEvent) error {
-		// 服务状态
+		// 健康检查与服务状态
+		e.Router.GET("/health", gw.HealthCheck)
 		e.Router.GET("/services", gw.GetServicesStatus)
 
-		// API 代理 - 使用通配符路由
-		e.Router.GET("/api/{path...}", gw.GetHandler())
-		e.Router.POST("/api/{path...}", gw.GetHandler())
-		e.Router.PUT("/api/{path...}", gw.GetHandler())
-		e.Router.DELETE("/api/{path...}", gw.GetHandler())
-		e.Router.PATCH("/api/{path...}", gw.GetHandler())
+		// 网关管理接口
+		gw.RegisterAdminRoutes(e.Router)
+
+		// 聚合各服务的 OpenAPI 文档与文档页面
+		gw.RegisterDocRoutes(e.Router)
+
+		// API 代理 - 使用通配符路由匹配所有方法，由路由配置决定允许的方法
+		// （包括 WebSocket 升级与 SSE 等流式响应）
+		e.Router.Any("/api/{path...}", gw.GetHandler())
 
 		return e.Next()
 	})
 
 	// 订阅 RBAC 数据更新（通过 PubSub）
-	app.SubscribeTopic(core.KeyRBACData, func(payload []byte) {
+	app.SubscribeTopic(core.KeyRBACData, func(payload []byte) error {
 		logger.Debug("收到 RBAC 数据更新", zap.Int("size", len(payload)))
+		return nil
 	})
 
 	// 服务就绪事件
@@ -95,6 +140,9 @@ func main() {
 	app.OnServiceStopped().BindFunc(func(e *core.LifecycleEvent) error {
 		ctx2, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		if stopConfigWatch != nil {
+			stopConfigWatch()
+		}
 		if err := gw.Shutdown(ctx2); err != nil {
 			logger.Error("网关关闭异常", zap.Error(err))
 		}
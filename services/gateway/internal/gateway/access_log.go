@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 访问日志默认配置
+const (
+	DefaultAccessLogBufferSize    = 10000
+	DefaultAccessLogBatchSize     = 200
+	DefaultAccessLogFlushInterval = time.Second
+)
+
+// StatusClientClosedRequest 客户端在响应前断开连接时记录的状态码（沿用 nginx 的 499）
+const StatusClientClosedRequest = 499
+
+// AccessLogSink 投递一批访问日志（如发布到 PubSub 主题），在后台协程中调用
+type AccessLogSink func(records []core.AccessLogRecord) error
+
+// AccessLogger 访问日志收集器
+// 请求处理中只把记录放入缓冲区，由后台协程按批量大小或投递间隔批量投递，
+// 缓冲区写满时丢弃新记录，不阻塞请求
+type AccessLogger struct {
+	sink          AccessLogSink
+	records       chan core.AccessLogRecord
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64 // 缓冲区已满而丢弃的记录数，投递时汇报后清零
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewAccessLogger 从配置创建访问日志收集器并启动后台投递，未配置的字段使用默认值
+func NewAccessLogger(cfg config.GatewayAccessLogConfig, sink AccessLogSink) *AccessLogger {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultAccessLogBufferSize
+	}
+	l := &AccessLogger{
+		sink:          sink,
+		records:       make(chan core.AccessLogRecord, bufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if l.batchSize <= 0 {
+		l.batchSize = DefaultAccessLogBatchSize
+	}
+	if l.flushInterval <= 0 {
+		l.flushInterval = DefaultAccessLogFlushInterval
+	}
+
+	go l.run()
+	return l
+}
+
+// Log 记录一条访问日志，缓冲区已满或已关闭时丢弃
+func (l *AccessLogger) Log(record core.AccessLogRecord) {
+	select {
+	case <-l.stop:
+		l.dropped.Add(1)
+		return
+	default:
+	}
+
+	select {
+	case l.records <- record:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// Close 停止收集并投递缓冲区中剩余的记录，ctx 结束时不再等待
+func (l *AccessLogger) Close(ctx context.Context) error {
+	l.closeOnce.Do(func() { close(l.stop) })
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 后台按批量大小或投递间隔投递记录
+func (l *AccessLogger) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]core.AccessLogRecord, 0, l.batchSize)
+	flush := func() {
+		if dropped := l.dropped.Swap(0); dropped > 0 {
+			logger.Warn("访问日志缓冲区已满，丢弃记录", zap.Int64("dropped", dropped))
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := l.sink(batch); err != nil {
+			logger.Warn("投递访问日志失败", zap.Int("records", len(batch)), zap.Error(err))
+		}
+		// sink 可能异步持有切片，每批使用新的切片
+		batch = make([]core.AccessLogRecord, 0, l.batchSize)
+	}
+
+	for {
+		select {
+		case record := <-l.records:
+			batch = append(batch, record)
+			if len(batch) >= l.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-l.stop:
+			// 投递缓冲区中剩余的记录
+			for {
+				select {
+				case record := <-l.records:
+					batch = append(batch, record)
+					if len(batch) >= l.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// module 访问日志中的模块：服务的 basePath，没有时使用服务或上游名称
+func (r *ServiceRoute) module() string {
+	if r.basePath != "" {
+		return r.basePath
+	}
+	return r.ServiceName
+}
+
+// accessRecord 由代理请求生成访问记录
+func accessRecord(e *core.RequestEvent, route *ServiceRoute, claims *core.JWTClaims, clientIP string, start time.Time) core.AccessLogRecord {
+	status := e.Status()
+	if status == 0 && e.Request.Context().Err() != nil {
+		// 客户端在响应前断开
+		status = StatusClientClosedRequest
+	}
+
+	record := core.AccessLogRecord{
+		Time:      start,
+		Module:    route.module(),
+		Service:   route.ServiceName,
+		Method:    e.Request.Method,
+		Path:      e.Request.URL.Path,
+		Query:     e.Request.URL.RawQuery,
+		Status:    status,
+		Duration:  time.Since(start).Milliseconds(),
+		IP:        clientIP,
+		UserAgent: e.Request.UserAgent(),
+		RequestID: e.Request.Header.Get(HeaderRequestID),
+	}
+	if claims != nil {
+		record.UserID = claims.UserID
+		record.Username = claims.Username
+	}
+	return record
+}
@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+// recordingSink 记录每批投递的访问日志
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]core.AccessLogRecord
+	block   chan struct{} // 不为空时投递阻塞到关闭
+}
+
+func (s *recordingSink) sink(records []core.AccessLogRecord) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, records)
+	return nil
+}
+
+func (s *recordingSink) records() []core.AccessLogRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []core.AccessLogRecord
+	for _, b := range s.batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+// waitRecords 等待收到至少 n 条记录
+func (s *recordingSink) waitRecords(t *testing.T, n int) []core.AccessLogRecord {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if records := s.records(); len(records) >= n {
+			return records
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d access log records, got %d", n, len(s.records()))
+	return nil
+}
+
+func TestAccessLoggerBatch(t *testing.T) {
+	s := &recordingSink{}
+	l := NewAccessLogger(config.GatewayAccessLogConfig{BatchSize: 2, FlushInterval: 60000}, s.sink)
+	defer l.Close(context.Background())
+
+	for i := range 5 {
+		l.Log(core.AccessLogRecord{Path: "/" + string(rune('a'+i))})
+	}
+	// 未达到批量大小的最后一条等待投递间隔
+	s.waitRecords(t, 4)
+	s.mu.Lock()
+	for _, b := range s.batches {
+		if len(b) != 2 {
+			t.Fatalf("Expected batches of 2, got %d", len(b))
+		}
+	}
+	s.mu.Unlock()
+
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if records := s.records(); len(records) != 5 || records[4].Path != "/e" {
+		t.Fatalf("Expected Close to flush remaining records, got %v", records)
+	}
+
+	// 关闭后的记录丢弃
+	l.Log(core.AccessLogRecord{})
+	if len(s.records()) != 5 {
+		t.Fatal("Expected records after Close to be dropped")
+	}
+}
+
+func TestAccessLoggerFlushInterval(t *testing.T) {
+	s := &recordingSink{}
+	l := NewAccessLogger(config.GatewayAccessLogConfig{BatchSize: 100, FlushInterval: 20}, s.sink)
+	defer l.Close(context.Background())
+
+	l.Log(core.AccessLogRecord{Path: "/a"})
+	if records := s.waitRecords(t, 1); records[0].Path != "/a" {
+		t.Fatalf("Unexpected records %v", records)
+	}
+}
+
+func TestAccessLoggerDropWhenFull(t *testing.T) {
+	s := &recordingSink{block: make(chan struct{})}
+	l := NewAccessLogger(config.GatewayAccessLogConfig{BufferSize: 2, BatchSize: 1, FlushInterval: 60000}, s.sink)
+
+	// 第一条被后台协程取出后阻塞在投递中，缓冲区只能再容纳 2 条
+	l.Log(core.AccessLogRecord{})
+	time.Sleep(20 * time.Millisecond)
+	for range 10 {
+		l.Log(core.AccessLogRecord{})
+	}
+	if dropped := l.dropped.Load(); dropped != 8 {
+		t.Fatalf("Expected 8 dropped records, got %d", dropped)
+	}
+
+	close(s.block)
+	if err := l.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.records()); n != 3 {
+		t.Fatalf("Expected 3 delivered records, got %d", n)
+	}
+}
+
+func TestGatewayAccessLog(t *testing.T) {
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.TrustedProxies = []string{"127.0.0.1", "::1"}
+	})
+	s := &recordingSink{}
+	gw.accessLog = NewAccessLogger(config.GatewayAccessLogConfig{BatchSize: 1}, s.sink)
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	registerBackend(t, reg, "user-service", "users", backend)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := gw.jwt.GenerateToken(7, "alice", 3, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doRequest(t, http.MethodPost, ts.URL+"/api/v1/users/users?x=1", map[string]string{
+		"Authorization": "Bearer " + token,
+		"User-Agent":    "test-agent",
+		"X-Request-ID":  "req-1",
+		// 可信代理追加的客户端地址
+		"X-Forwarded-For": "203.0.113.7",
+	})
+	// 认证失败的请求同样记录
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/users", nil)
+
+	records := s.waitRecords(t, 2)
+	ok := records[0]
+	if ok.UserID != 7 || ok.Username != "alice" || ok.Module != "users" || ok.Service != "user-service" {
+		t.Fatalf("Unexpected record identity %+v", ok)
+	}
+	if ok.Method != http.MethodPost || ok.Path != "/api/v1/users/users" || ok.Query != "x=1" || ok.Status != http.StatusCreated {
+		t.Fatalf("Unexpected record request %+v", ok)
+	}
+	if ok.UserAgent != "test-agent" || ok.RequestID != "req-1" || ok.IP != "203.0.113.7" || ok.Time.IsZero() {
+		t.Fatalf("Unexpected record client info %+v", ok)
+	}
+
+	denied := records[1]
+	if denied.Status != http.StatusUnauthorized || denied.UserID != 0 || denied.Module != "users" {
+		t.Fatalf("Unexpected denied record %+v", denied)
+	}
+
+	if err := gw.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
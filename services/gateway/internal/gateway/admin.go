@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/logger"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go.uber.org/zap"
+)
+
+// DefaultAdminRole 默认允许调用网关管理接口的角色编码
+const DefaultAdminRole = "admin"
+
+// RegisterAdminRoutes 注册网关管理接口（需要管理员角色的JWT）
+//
+//	GET    /admin/traffic            查看运行时分流配置
+//	PUT    /admin/traffic/{service}  设置服务的分流权重与灰度版本
+//	DELETE /admin/traffic/{service}  删除运行时分流配置，恢复路由上的配置
+//	GET    /admin/routes                  查看生效的路由（注册中心路由合并覆盖配置）
+//	GET    /admin/routes/overrides        查看路由覆盖配置
+//	PUT    /admin/routes/overrides        按主机与路径规则新增或更新覆盖配置（可新增、覆盖或禁用路由）
+//	DELETE /admin/routes/overrides/{id}   删除覆盖配置，恢复注册中心下发的路由
+func (g *Gateway) RegisterAdminRoutes(r *router.Router[*core.RequestEvent]) {
+	admin := r.Group("/admin")
+	admin.BindFunc(g.requireAdmin)
+
+	admin.GET("/traffic", g.handleListTraffic)
+	admin.PUT("/traffic/{service}", g.handleSetTraffic)
+	admin.DELETE("/traffic/{service}", g.handleDeleteTraffic)
+
+	admin.GET("/routes", g.handleListRoutes)
+	admin.GET("/routes/overrides", g.handleListOverrides)
+	admin.PUT("/routes/overrides", g.handleSaveOverride)
+	admin.DELETE("/routes/overrides/{id}", g.handleDeleteOverride)
+}
+
+// requireAdmin 校验管理接口的JWT与角色
+func (g *Gateway) requireAdmin(e *core.RequestEvent) error {
+	token := strings.TrimPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return apis.Error(e, 401, "未提供认证令牌")
+	}
+	claims, err := g.jwt.ParseToken(token)
+	if err != nil {
+		return apis.Error(e, 401, "无效的认证令牌")
+	}
+
+	roles := g.config.Gateway.AdminRoles
+	if len(roles) == 0 {
+		roles = []string{DefaultAdminRole}
+	}
+	if !slices.Contains(roles, claims.RoleCode) {
+		return apis.Error(e, 403, "无权访问网关管理接口")
+	}
+
+	apis.SetAuthClaims(e, claims)
+	return e.Next()
+}
+
+func (g *Gateway) handleListTraffic(e *core.RequestEvent) error {
+	return apis.Success(e, g.traffic.All())
+}
+
+func (g *Gateway) handleSetTraffic(e *core.RequestEvent) error {
+	service := e.Request.PathValue("service")
+
+	var split pkgRegistry.TrafficSplit
+	if err := e.BindBody(&split); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+	if err := validateTrafficSplit(&split); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+	if err := g.traffic.Set(service, &split); err != nil {
+		logger.Error("保存分流配置失败", zap.String("service", service), zap.Error(err))
+		return apis.Error(e, 500, "保存分流配置失败")
+	}
+
+	logger.Info("更新分流配置",
+		zap.String("service", service),
+		zap.Any("weights", split.Weights),
+		zap.String("canary", split.Canary),
+	)
+	return apis.Success(e, split)
+}
+
+func (g *Gateway) handleDeleteTraffic(e *core.RequestEvent) error {
+	service := e.Request.PathValue("service")
+
+	if err := g.traffic.Set(service, nil); err != nil {
+		logger.Error("删除分流配置失败", zap.String("service", service), zap.Error(err))
+		return apis.Error(e, 500, "删除分流配置失败")
+	}
+
+	logger.Info("删除分流配置", zap.String("service", service))
+	return apis.Success(e, nil)
+}
+
+func (g *Gateway) handleListRoutes(e *core.RequestEvent) error {
+	return apis.Success(e, g.ListRoutes())
+}
+
+func (g *Gateway) handleListOverrides(e *core.RequestEvent) error {
+	routes, err := g.ListOverrides()
+	if err != nil {
+		logger.Error("获取路由覆盖配置失败", zap.Error(err))
+		return apis.Error(e, 500, "获取路由覆盖配置失败")
+	}
+	return apis.Success(e, routes)
+}
+
+func (g *Gateway) handleSaveOverride(e *core.RequestEvent) error {
+	var req RouteOverrideRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	route := req.toModel()
+	route.CreateBy = apis.GetUserID(e)
+	if err := normalizeOverride(route); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	saved, err := g.SaveOverride(route)
+	if err != nil {
+		logger.Error("保存路由覆盖配置失败", zap.String("gateway_path", req.PathPrefix), zap.Error(err))
+		return apis.Error(e, 500, "保存路由覆盖配置失败")
+	}
+	return apis.Success(e, saved)
+}
+
+func (g *Gateway) handleDeleteOverride(e *core.RequestEvent) error {
+	id, err := strconv.ParseInt(e.Request.PathValue("id"), 10, 64)
+	if err != nil {
+		return apis.Error(e, 400, "无效的路由覆盖配置ID")
+	}
+
+	ok, err := g.DeleteOverride(id)
+	if err != nil {
+		logger.Error("删除路由覆盖配置失败", zap.Int64("id", id), zap.Error(err))
+		return apis.Error(e, 500, "删除路由覆盖配置失败")
+	}
+	if !ok {
+		return apis.Error(e, 404, "路由覆盖配置不存在")
+	}
+	return apis.Success(e, nil)
+}
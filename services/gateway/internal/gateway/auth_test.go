@@ -0,0 +1,110 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goback/pkg/auth"
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+func TestGatewayAuthRequired(t *testing.T) {
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	var forwarded http.Header
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	})
+	registerBackend(t, reg, "user-service", "users", backend,
+		pkgRegistry.NewPublicRoutes("users", "/auth/login")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := gw.jwt.GenerateToken(7, "alice", 3, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedUserID string
+	}{
+		{"protected without token", "/api/v1/users/users", nil, 401, ""},
+		{"protected with invalid token", "/api/v1/users/users", map[string]string{"Authorization": "Bearer invalid"}, 401, ""},
+		{"protected with valid token", "/api/v1/users/users", map[string]string{"Authorization": "Bearer " + token}, 200, "7"},
+		{"public without token", "/api/v1/users/auth/login", nil, 200, ""},
+		{"public with valid token", "/api/v1/users/auth/login", map[string]string{"Authorization": "Bearer " + token}, 200, "7"},
+		{"public with invalid token", "/api/v1/users/auth/login", map[string]string{"Authorization": "Bearer invalid"}, 200, ""},
+		{
+			"spoofed identity headers are stripped",
+			"/api/v1/users/auth/login",
+			map[string]string{auth.HeaderUserID: "1", auth.HeaderSignature: "forged"},
+			200,
+			"",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			forwarded = nil
+
+			resp, body := doRequest(t, http.MethodGet, ts.URL+s.path, s.headers)
+			if resp.StatusCode != s.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (%s)", s.expectedStatus, resp.StatusCode, body)
+			}
+			if s.expectedStatus != 200 {
+				if forwarded != nil {
+					t.Fatal("Expected the request to not reach the backend")
+				}
+				return
+			}
+
+			if userID := forwarded.Get(auth.HeaderUserID); userID != s.expectedUserID {
+				t.Fatalf("Expected forwarded user id %q, got %q", s.expectedUserID, userID)
+			}
+
+			claims, err := gw.jwt.VerifyIdentity(forwarded)
+			if s.expectedUserID == "" {
+				if err == nil {
+					t.Fatalf("Expected no verifiable identity, got %+v", claims)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected verifiable identity, got error %v", err)
+			}
+			if claims.Username != "alice" || claims.RoleID != 3 || claims.RoleCode != "admin" {
+				t.Fatalf("Unexpected forwarded claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestGatewayPublicRouteRewrite(t *testing.T) {
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	var path string
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	})
+	registerBackend(t, reg, "user-service", "users", backend,
+		pkgRegistry.NewPublicRoutes("users", "/auth/login")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, _ := doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/auth/login", nil)
+	if resp.StatusCode != 200 {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if path != "/auth/login" {
+		t.Fatalf("Expected backend path /auth/login, got %s", path)
+	}
+}
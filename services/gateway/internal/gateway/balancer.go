@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/goback/pkg/app/core"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// PickContext 节点选择上下文
+type PickContext struct {
+	Request  *http.Request
+	Claims   *core.JWTClaims // 已认证的用户身份，匿名请求为 nil
+	ClientIP string          // 客户端IP（仅采用可信代理转发的 X-Forwarded-For）
+}
+
+// Balancer 负载均衡器
+type Balancer interface {
+	// Name 策略名称
+	Name() string
+
+	// Pick 从候选节点中选择一个节点（nodes 保证非空）
+	Pick(nodes []*registry.Node, ctx *PickContext) *registry.Node
+}
+
+// NewBalancer 根据策略名称创建负载均衡器，未知策略退化为轮询
+func NewBalancer(strategy, hashKey string, tracker *InFlightTracker) Balancer {
+	switch strategy {
+	case pkgRegistry.LoadBalanceWeightedRandom:
+		return &weightedRandomBalancer{}
+	case pkgRegistry.LoadBalanceLeastInFlight:
+		return &leastInFlightBalancer{tracker: tracker}
+	case pkgRegistry.LoadBalanceConsistentHash:
+		return &consistentHashBalancer{hashKey: hashKey}
+	default:
+		return &roundRobinBalancer{}
+	}
+}
+
+// -------------------------------------------------------------------
+// 轮询
+// -------------------------------------------------------------------
+
+type roundRobinBalancer struct {
+	counter atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Name() string {
+	return pkgRegistry.LoadBalanceRoundRobin
+}
+
+func (b *roundRobinBalancer) Pick(nodes []*registry.Node, _ *PickContext) *registry.Node {
+	n := b.counter.Add(1) - 1
+	return nodes[n%uint64(len(nodes))]
+}
+
+// -------------------------------------------------------------------
+// 加权随机
+// -------------------------------------------------------------------
+
+type weightedRandomBalancer struct{}
+
+func (b *weightedRandomBalancer) Name() string {
+	return pkgRegistry.LoadBalanceWeightedRandom
+}
+
+// Pick 按节点元数据中的权重随机选择，权重为 0 的节点不接收流量（全部为 0 时均匀随机）
+func (b *weightedRandomBalancer) Pick(nodes []*registry.Node, _ *PickContext) *registry.Node {
+	total := 0
+	for _, node := range nodes {
+		total += pkgRegistry.NodeWeight(node)
+	}
+	if total <= 0 {
+		return nodes[rand.IntN(len(nodes))]
+	}
+
+	r := rand.IntN(total)
+	for _, node := range nodes {
+		r -= pkgRegistry.NodeWeight(node)
+		if r < 0 {
+			return node
+		}
+	}
+	return nodes[len(nodes)-1]
+}
+
+// -------------------------------------------------------------------
+// 最少进行中请求
+// -------------------------------------------------------------------
+
+type leastInFlightBalancer struct {
+	tracker *InFlightTracker
+}
+
+func (b *leastInFlightBalancer) Name() string {
+	return pkgRegistry.LoadBalanceLeastInFlight
+}
+
+// Pick 选择进行中请求数最少的节点，从随机位置开始扫描以打散并列节点
+func (b *leastInFlightBalancer) Pick(nodes []*registry.Node, _ *PickContext) *registry.Node {
+	start := rand.IntN(len(nodes))
+	best := nodes[start]
+	bestCount := b.tracker.Count(best.Id)
+
+	for i := 1; i < len(nodes); i++ {
+		node := nodes[(start+i)%len(nodes)]
+		if count := b.tracker.Count(node.Id); count < bestCount {
+			best, bestCount = node, count
+		}
+	}
+	return best
+}
+
+// -------------------------------------------------------------------
+// 一致性哈希
+// -------------------------------------------------------------------
+
+type consistentHashBalancer struct {
+	hashKey string
+}
+
+func (b *consistentHashBalancer) Name() string {
+	return pkgRegistry.LoadBalanceConsistentHash
+}
+
+// Pick 使用 Rendezvous（HRW）哈希选择节点
+// 同一哈希键总是落到同一节点，节点增减时只有该节点上的键会迁移
+func (b *consistentHashBalancer) Pick(nodes []*registry.Node, ctx *PickContext) *registry.Node {
+	key := b.key(ctx)
+
+	var best *registry.Node
+	var bestScore uint64
+	for _, node := range nodes {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(node.Id))
+		if score := h.Sum64(); best == nil || score > bestScore {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// key 计算请求的哈希键，取不到时退化为客户端IP
+func (b *consistentHashBalancer) key(ctx *PickContext) string {
+	switch {
+	case b.hashKey == pkgRegistry.HashKeyIP:
+		// 直接使用客户端IP
+	case strings.HasPrefix(b.hashKey, pkgRegistry.HashKeyHeaderPrefix):
+		name := strings.TrimPrefix(b.hashKey, pkgRegistry.HashKeyHeaderPrefix)
+		if v := ctx.Request.Header.Get(name); v != "" {
+			return v
+		}
+	default: // HashKeyUser
+		if ctx.Claims != nil {
+			return strconv.FormatInt(ctx.Claims.UserID, 10)
+		}
+	}
+	return ctx.ClientIP
+}
+
+// -------------------------------------------------------------------
+// 进行中请求计数
+// -------------------------------------------------------------------
+
+// InFlightTracker 按节点统计进行中的请求数（并发安全）
+type InFlightTracker struct {
+	counts sync.Map // nodeID -> *atomic.Int64
+}
+
+// NewInFlightTracker 创建进行中请求计数器
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{}
+}
+
+// Acquire 节点进行中请求数加一，返回的函数在请求结束时调用
+func (t *InFlightTracker) Acquire(nodeID string) (release func()) {
+	c := t.counter(nodeID)
+	c.Add(1)
+	return func() { c.Add(-1) }
+}
+
+// Count 获取节点进行中的请求数
+func (t *InFlightTracker) Count(nodeID string) int64 {
+	if c, ok := t.counts.Load(nodeID); ok {
+		return c.(*atomic.Int64).Load()
+	}
+	return 0
+}
+
+// Remove 删除节点的计数（节点下线时调用）
+func (t *InFlightTracker) Remove(nodeID string) {
+	t.counts.Delete(nodeID)
+}
+
+func (t *InFlightTracker) counter(nodeID string) *atomic.Int64 {
+	if c, ok := t.counts.Load(nodeID); ok {
+		return c.(*atomic.Int64)
+	}
+	c, _ := t.counts.LoadOrStore(nodeID, new(atomic.Int64))
+	return c.(*atomic.Int64)
+}
+
+// collectNodes 汇总所有版本的服务节点（按节点ID去重并排序，保证轮询顺序稳定）
+func collectNodes(services []*registry.Service) []*registry.Node {
+	var nodes []*registry.Node
+	seen := make(map[string]bool)
+	for _, svc := range services {
+		for _, node := range svc.Nodes {
+			if seen[node.Id] {
+				continue
+			}
+			seen[node.Id] = true
+			nodes = append(nodes, node)
+		}
+	}
+	slices.SortFunc(nodes, func(a, b *registry.Node) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+	return nodes
+}
@@ -0,0 +1,193 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goback/pkg/app/core"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+func newTestNodes(weights ...int) []*registry.Node {
+	nodes := make([]*registry.Node, len(weights))
+	for i, w := range weights {
+		nodes[i] = &registry.Node{
+			Id:       fmt.Sprintf("node-%d", i),
+			Address:  fmt.Sprintf("127.0.0.1:%d", 9000+i),
+			Metadata: map[string]string{pkgRegistry.MetadataWeight: fmt.Sprint(w)},
+		}
+	}
+	return nodes
+}
+
+func newPickContext(remoteAddr string, claims *core.JWTClaims) *PickContext {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	return &PickContext{Request: req, Claims: claims, ClientIP: NewTrustedProxies(nil).ClientIP(req)}
+}
+
+func TestNewBalancer(t *testing.T) {
+	scenarios := []struct {
+		strategy string
+		expected string
+	}{
+		{"", pkgRegistry.LoadBalanceRoundRobin},
+		{"unknown", pkgRegistry.LoadBalanceRoundRobin},
+		{pkgRegistry.LoadBalanceRoundRobin, pkgRegistry.LoadBalanceRoundRobin},
+		{pkgRegistry.LoadBalanceWeightedRandom, pkgRegistry.LoadBalanceWeightedRandom},
+		{pkgRegistry.LoadBalanceLeastInFlight, pkgRegistry.LoadBalanceLeastInFlight},
+		{pkgRegistry.LoadBalanceConsistentHash, pkgRegistry.LoadBalanceConsistentHash},
+	}
+
+	for _, s := range scenarios {
+		if name := NewBalancer(s.strategy, "", NewInFlightTracker()).Name(); name != s.expected {
+			t.Errorf("[%q] Expected %s, got %s", s.strategy, s.expected, name)
+		}
+	}
+}
+
+func TestRoundRobinBalancer(t *testing.T) {
+	b := NewBalancer(pkgRegistry.LoadBalanceRoundRobin, "", nil)
+	nodes := newTestNodes(1, 1, 1)
+	ctx := newPickContext("10.0.0.1:1234", nil)
+
+	for i := range 6 {
+		if node := b.Pick(nodes, ctx); node != nodes[i%3] {
+			t.Fatalf("[%d] Expected %s, got %s", i, nodes[i%3].Id, node.Id)
+		}
+	}
+}
+
+func TestWeightedRandomBalancer(t *testing.T) {
+	b := NewBalancer(pkgRegistry.LoadBalanceWeightedRandom, "", nil)
+	nodes := newTestNodes(0, 1, 3)
+	ctx := newPickContext("10.0.0.1:1234", nil)
+
+	counts := make(map[string]int)
+	for range 4000 {
+		counts[b.Pick(nodes, ctx).Id]++
+	}
+
+	if counts["node-0"] != 0 {
+		t.Fatalf("Expected zero-weight node to receive no traffic, got %d", counts["node-0"])
+	}
+	// 期望约 1:3，留足随机误差
+	if counts["node-2"] < 2*counts["node-1"] {
+		t.Fatalf("Expected node-2 to receive about 3x node-1 traffic, got %v", counts)
+	}
+}
+
+func TestLeastInFlightBalancer(t *testing.T) {
+	tracker := NewInFlightTracker()
+	b := NewBalancer(pkgRegistry.LoadBalanceLeastInFlight, "", tracker)
+	nodes := newTestNodes(1, 1, 1)
+	ctx := newPickContext("10.0.0.1:1234", nil)
+
+	release0 := tracker.Acquire("node-0")
+	tracker.Acquire("node-0")
+	tracker.Acquire("node-2")
+
+	for range 10 {
+		if node := b.Pick(nodes, ctx); node.Id != "node-1" {
+			t.Fatalf("Expected node-1, got %s", node.Id)
+		}
+	}
+
+	release0()
+	if count := tracker.Count("node-0"); count != 1 {
+		t.Fatalf("Expected node-0 in-flight count 1, got %d", count)
+	}
+}
+
+func TestConsistentHashBalancer(t *testing.T) {
+	nodes := newTestNodes(1, 1, 1, 1)
+
+	t.Run("sticky by user", func(t *testing.T) {
+		b := NewBalancer(pkgRegistry.LoadBalanceConsistentHash, pkgRegistry.HashKeyUser, nil)
+
+		for userID := int64(1); userID <= 20; userID++ {
+			claims := &core.JWTClaims{UserID: userID}
+			first := b.Pick(nodes, newPickContext("10.0.0.1:1", claims))
+			// 不同客户端IP不影响用户粘滞
+			if node := b.Pick(nodes, newPickContext("10.0.0.2:2", claims)); node != first {
+				t.Fatalf("Expected user %d to stick to %s, got %s", userID, first.Id, node.Id)
+			}
+		}
+	})
+
+	t.Run("sticky by header", func(t *testing.T) {
+		b := NewBalancer(pkgRegistry.LoadBalanceConsistentHash, pkgRegistry.HashKeyHeaderPrefix+"X-Session-Id", nil)
+
+		ctx1 := newPickContext("10.0.0.1:1", nil)
+		ctx1.Request.Header.Set("X-Session-Id", "abc")
+		ctx2 := newPickContext("10.0.0.2:2", nil)
+		ctx2.Request.Header.Set("X-Session-Id", "abc")
+
+		if b.Pick(nodes, ctx1) != b.Pick(nodes, ctx2) {
+			t.Fatal("Expected same session id to pick the same node")
+		}
+	})
+
+	t.Run("ignores spoofed forwarded-for", func(t *testing.T) {
+		b := NewBalancer(pkgRegistry.LoadBalanceConsistentHash, pkgRegistry.HashKeyIP, nil)
+
+		first := b.Pick(nodes, newPickContext("10.0.0.1:1", nil))
+		for i := range 20 {
+			ctx := newPickContext("10.0.0.1:1", nil)
+			ctx.Request.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+			if node := b.Pick(nodes, ctx); node != first {
+				t.Fatalf("Expected the direct client to stay on %s, got %s", first.Id, node.Id)
+			}
+		}
+	})
+
+	t.Run("minimal remapping", func(t *testing.T) {
+		b := NewBalancer(pkgRegistry.LoadBalanceConsistentHash, pkgRegistry.HashKeyIP, nil)
+
+		before := make(map[string]string)
+		for i := range 200 {
+			ip := fmt.Sprintf("10.0.%d.%d:80", i/250, i%250)
+			before[ip] = b.Pick(nodes, newPickContext(ip, nil)).Id
+		}
+
+		// 移除 node-3 后只有原本落在 node-3 上的键会迁移
+		for ip, id := range before {
+			after := b.Pick(nodes[:3], newPickContext(ip, nil)).Id
+			if id != "node-3" && after != id {
+				t.Fatalf("Expected %s to stay on %s, got %s", ip, id, after)
+			}
+		}
+	})
+}
+
+func TestGatewayLoadBalancesAcrossReplicas(t *testing.T) {
+	// go-micro 内存注册中心支持同一服务的多个节点
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	hits := make(map[string]int)
+	for _, name := range []string{"a", "b"} {
+		backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+			hits[name]++
+		})
+		registerBackend(t, reg, "log-service", "logs", backend,
+			pkgRegistry.NewPublicRoutes("logs", "/health")...)
+	}
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 4 {
+		resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", nil)
+		if resp.StatusCode != 200 {
+			t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+		}
+	}
+
+	if hits["a"] != 2 || hits["b"] != 2 {
+		t.Fatalf("Expected round-robin across both replicas, got %v", hits)
+	}
+}
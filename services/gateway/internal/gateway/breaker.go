@@ -0,0 +1,202 @@
+package gateway
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 熔断器默认配置
+const (
+	DefaultBreakerThreshold = 5                // 连续失败多少次后熔断
+	DefaultBreakerTimeout   = 30 * time.Second // 熔断后多久进入半开状态
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	StateClosed   CircuitState = iota // 关闭：正常放行
+	StateOpen                         // 打开：拒绝请求
+	StateHalfOpen                     // 半开：放行单个探测请求
+)
+
+// String 状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// MarshalText 实现 encoding.TextMarshaler，JSON 中输出状态名称
+func (s CircuitState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (s *CircuitState) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "closed":
+		*s = StateClosed
+	case "open":
+		*s = StateOpen
+	case "half-open":
+		*s = StateHalfOpen
+	default:
+		return fmt.Errorf("unknown circuit state %q", text)
+	}
+	return nil
+}
+
+// CircuitBreaker 熔断器（并发安全）
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	failures    int
+	threshold   int
+	timeout     time.Duration
+	lastFailure time.Time
+	state       CircuitState
+	probing     bool // 半开状态下是否已有探测请求在进行
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(threshold int, timeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		timeout:   timeout,
+		state:     StateClosed,
+	}
+}
+
+// Ready 是否可以放行请求（不占用半开探测名额，用于筛选候选节点）
+func (cb *CircuitBreaker) Ready() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		return !cb.probing
+	}
+	return false
+}
+
+// Allow 是否允许请求
+// 半开状态下只放行一个探测请求，调用方必须随后调用 Success、Failure 或 Release 之一
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.state = StateHalfOpen
+		cb.probing = true
+		return true
+	}
+	return false
+}
+
+// Success 记录成功
+func (cb *CircuitBreaker) Success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.state = StateClosed
+	cb.probing = false
+}
+
+// Failure 记录失败
+func (cb *CircuitBreaker) Failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.lastFailure = time.Now()
+	// 半开探测失败立即重新熔断
+	if cb.state == StateHalfOpen || cb.failures >= cb.threshold {
+		cb.state = StateOpen
+	}
+	cb.probing = false
+}
+
+// Release 释放半开探测名额而不记录结果（如客户端主动取消请求）
+func (cb *CircuitBreaker) Release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.probing = false
+}
+
+// State 获取状态
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.currentState()
+}
+
+// Failures 获取连续失败次数
+func (cb *CircuitBreaker) Failures() int {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.failures
+}
+
+// currentState 计算当前状态（打开超过 timeout 后视为半开），调用方需持有锁
+func (cb *CircuitBreaker) currentState() CircuitState {
+	if cb.state == StateOpen && time.Since(cb.lastFailure) > cb.timeout {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// BreakerGroup 按节点管理熔断器
+type BreakerGroup struct {
+	breakers  sync.Map // nodeID -> *CircuitBreaker
+	threshold int
+	timeout   time.Duration
+}
+
+// NewBreakerGroup 创建节点熔断器组
+func NewBreakerGroup(threshold int, timeout time.Duration) *BreakerGroup {
+	return &BreakerGroup{
+		threshold: threshold,
+		timeout:   timeout,
+	}
+}
+
+// Get 获取节点的熔断器，不存在时创建
+func (g *BreakerGroup) Get(nodeID string) *CircuitBreaker {
+	if cb, ok := g.breakers.Load(nodeID); ok {
+		return cb.(*CircuitBreaker)
+	}
+	cb, _ := g.breakers.LoadOrStore(nodeID, NewCircuitBreaker(g.threshold, g.timeout))
+	return cb.(*CircuitBreaker)
+}
+
+// State 获取节点的熔断状态，未记录过的节点视为关闭
+func (g *BreakerGroup) State(nodeID string) CircuitState {
+	if cb, ok := g.breakers.Load(nodeID); ok {
+		return cb.(*CircuitBreaker).State()
+	}
+	return StateClosed
+}
+
+// Remove 移除节点的熔断器（节点下线时调用）
+func (g *BreakerGroup) Remove(nodeID string) {
+	g.breakers.Delete(nodeID)
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	cb := NewCircuitBreaker(2, 50*time.Millisecond)
+
+	if cb.State() != StateClosed || !cb.Allow() {
+		t.Fatal("Expected new breaker to be closed and allow requests")
+	}
+
+	cb.Failure()
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed below threshold, got %s", cb.State())
+	}
+
+	cb.Failure()
+	if cb.State() != StateOpen || cb.Allow() || cb.Ready() {
+		t.Fatalf("Expected open breaker to reject requests, got %s", cb.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if cb.State() != StateHalfOpen || !cb.Ready() {
+		t.Fatalf("Expected half-open after timeout, got %s", cb.State())
+	}
+
+	// 半开状态只放行一个探测请求
+	if !cb.Allow() {
+		t.Fatal("Expected the first half-open probe to be allowed")
+	}
+	if cb.Allow() || cb.Ready() {
+		t.Fatal("Expected concurrent half-open probes to be rejected")
+	}
+
+	// 探测失败重新熔断
+	cb.Failure()
+	if cb.State() != StateOpen {
+		t.Fatalf("Expected failed probe to reopen the breaker, got %s", cb.State())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !cb.Allow() {
+		t.Fatal("Expected a new half-open probe")
+	}
+	cb.Success()
+	if cb.State() != StateClosed || cb.Failures() != 0 {
+		t.Fatalf("Expected successful probe to close the breaker, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	cb := NewCircuitBreaker(1, 10*time.Millisecond)
+	cb.Failure()
+	time.Sleep(20 * time.Millisecond)
+
+	if !cb.Allow() {
+		t.Fatal("Expected half-open probe to be allowed")
+	}
+	cb.Release()
+	if !cb.Allow() {
+		t.Fatal("Expected released probe slot to be available again")
+	}
+}
+
+func TestCircuitBreakerConcurrent(t *testing.T) {
+	cb := NewCircuitBreaker(100, time.Second)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cb.Allow() {
+				if i%2 == 0 {
+					cb.Failure()
+				} else {
+					cb.Success()
+				}
+			}
+			_ = cb.State()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCircuitStateJSON(t *testing.T) {
+	raw, err := json.Marshal(NodeStatus{ID: "n1", Breaker: StateHalfOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"id":"n1","address":"","health":"unknown","latencyMs":0,"breaker":"half-open","inFlight":0}`; string(raw) != expected {
+		t.Fatalf("Expected %s, got %s", expected, raw)
+	}
+}
+
+func TestGatewaySkipsOpenNodes(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	backends := map[string]int{"bad": http.StatusServiceUnavailable, "good": http.StatusOK}
+	for name, status := range backends {
+		backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+			w.WriteHeader(status)
+		})
+		registerBackend(t, reg, "log-service", "logs", backend,
+			pkgRegistry.NewPublicRoutes("logs", "/health")...)
+	}
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 * DefaultBreakerThreshold {
+		doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", nil)
+	}
+	if hits["bad"] != DefaultBreakerThreshold {
+		t.Fatalf("Expected the failing node to receive %d requests before opening, got %d", DefaultBreakerThreshold, hits["bad"])
+	}
+
+	// 熔断后所有请求都转发到健康节点
+	for range 5 {
+		resp, _ := doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+	}
+	if hits["bad"] != DefaultBreakerThreshold {
+		t.Fatalf("Expected no more requests to the open node, got %d", hits["bad"])
+	}
+
+	// 状态接口展示熔断状态
+	_, body := doRequest(t, http.MethodGet, ts.URL+"/services", nil)
+
+	var result struct {
+		Data []ServiceStatus `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 1 || result.Data[0].Status != "degraded" {
+		t.Fatalf("Expected one degraded service, got %s", body)
+	}
+
+	states := make(map[CircuitState]int)
+	for _, d := range result.Data[0].Details {
+		states[d.Breaker]++
+	}
+	if states[StateOpen] != 1 || states[StateClosed] != 1 {
+		t.Fatalf("Expected one open and one closed node, got %s", body)
+	}
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/goback/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TrustedProxies 网关前的可信代理（负载均衡器等）
+// 只有直接来自可信代理的请求才采用 X-Forwarded-For，客户端自行伪造的该头会被忽略
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// NewTrustedProxies 由 IP 或 CIDR 列表创建可信代理，无效的项记录日志后忽略
+func NewTrustedProxies(proxies []string) *TrustedProxies {
+	t := &TrustedProxies{}
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				logger.Warn("可信代理地址无效", zap.String("proxy", p), zap.Error(err))
+				continue
+			}
+			t.prefixes = append(t.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			logger.Warn("可信代理地址无效", zap.String("proxy", p), zap.Error(err))
+			continue
+		}
+		t.prefixes = append(t.prefixes, prefix.Masked())
+	}
+	return t
+}
+
+// trusted 地址是否属于可信代理
+func (t *TrustedProxies) trusted(ip string) bool {
+	if t == nil || len(t.prefixes) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range t.prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP 获取客户端IP
+// 直连地址不是可信代理时直接使用直连地址；否则从右向左查找 X-Forwarded-For 中第一个不可信的地址，
+// 全部可信时使用最左侧的地址
+func (t *TrustedProxies) ClientIP(r *http.Request) string {
+	return t.chain(r)[0]
+}
+
+// ForwardedFor 转发给上游的 X-Forwarded-For：从客户端IP到直连地址之前的可信链路
+// 客户端IP之前（客户端自行填写）的内容不会转发；直连地址由 httputil.ReverseProxy 追加
+func (t *TrustedProxies) ForwardedFor(r *http.Request) string {
+	chain := t.chain(r)
+	return strings.Join(chain[:len(chain)-1], ", ")
+}
+
+// chain 从客户端IP到直连地址依次经过的地址
+func (t *TrustedProxies) chain(r *http.Request) []string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	chain := []string{ip}
+	if !t.trusted(ip) {
+		return chain
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// 可信代理追加的地址不会无效，之前的内容不可信
+			break
+		}
+		chain = append(chain, hop)
+		if !t.trusted(hop) {
+			break
+		}
+	}
+	slices.Reverse(chain)
+	return chain
+}
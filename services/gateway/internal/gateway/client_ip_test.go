@@ -0,0 +1,44 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies := NewTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "invalid"})
+
+	scenarios := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+		chain      string // 转发给上游的 X-Forwarded-For（不含直连地址）
+	}{
+		{"direct client", "203.0.113.7:1234", nil, "203.0.113.7", ""},
+		{"spoofed header from untrusted client", "203.0.113.7:1234", []string{"1.1.1.1"}, "203.0.113.7", ""},
+		{"trusted proxy", "10.0.0.2:80", []string{"203.0.113.7"}, "203.0.113.7", "203.0.113.7"},
+		{"right-most untrusted hop", "10.0.0.2:80", []string{"1.1.1.1, 203.0.113.7, 192.168.1.1"}, "203.0.113.7", "203.0.113.7, 192.168.1.1"},
+		{"multiple headers", "10.0.0.2:80", []string{"1.1.1.1", "203.0.113.7"}, "203.0.113.7", "203.0.113.7"},
+		{"all hops trusted", "10.0.0.2:80", []string{"10.1.1.1, 10.2.2.2"}, "10.1.1.1", "10.1.1.1, 10.2.2.2"},
+		{"invalid hop", "10.0.0.2:80", []string{"203.0.113.7, garbage"}, "10.0.0.2", ""},
+		{"trusted proxy without header", "10.0.0.2:80", nil, "10.0.0.2", ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = s.remoteAddr
+			for _, v := range s.forwarded {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if ip := proxies.ClientIP(req); ip != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, ip)
+			}
+			if chain := proxies.ForwardedFor(req); chain != s.chain {
+				t.Fatalf("Expected forwarded chain %q, got %q", s.chain, chain)
+			}
+		})
+	}
+}
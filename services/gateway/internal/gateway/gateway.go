@@ -2,84 +2,269 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/security"
+	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"go-micro.dev/v5/registry"
 	"go.uber.org/zap"
 )
 
 const (
 	// APIVersion API版本前缀
-	APIVersion = "/api/v1"
+	APIVersion = pkgRegistry.APIPrefix
+
+	// HeaderRequestID 请求ID头
+	HeaderRequestID = "X-Request-ID"
+
+	// runtimeSyncInterval 同步运行时配置（分流配置、路由覆盖）的间隔
+	runtimeSyncInterval = 5 * time.Second
 )
 
 // Gateway API网关
 type Gateway struct {
-	registry registry.Registry
-	config   *config.Config
-	routes   map[string]*ServiceRoute // key: 网关路径前缀
-	mu       sync.RWMutex             // 保护routes的并发访问
-	watcher  registry.Watcher
-	stopChan chan struct{}
+	registry    registry.Registry
+	config      *config.Config
+	jwt         *auth.JWTManager             // 校验受保护路由的JWT并签名转发的身份头
+	routes      map[string]*ServiceRoute     // 注册中心下发的路由，key: 主机 + 网关路径规则
+	static      map[string]*ServiceRoute     // 配置文件中的静态路由，同键时覆盖 routes
+	overrides   map[string]*ServiceRoute     // 管理接口覆盖的路由（持久化在数据库中），优先级最高
+	upstreams   map[string]*registry.Service // 配置文件中的静态上游，key: 上游名称
+	sorted      []*ServiceRoute              // 合并后按具体程度排序的生效路由，用于匹配
+	mu          sync.RWMutex                 // 保护routes的并发访问
+	nodes       map[string]map[string]bool   // 注册中心各服务的已知节点，key: 服务名称，用于清理下线节点的状态
+	nodesMu     sync.Mutex                   // 保护nodes的并发访问
+	inflight    *InFlightTracker             // 各节点进行中的请求数
+	breakers    *BreakerGroup                // 各节点的熔断器
+	transport   *http.Transport              // 转发上游请求的连接池
+	idleTimeout time.Duration                // 流式响应与 WebSocket 连接的空闲超时
+	retryPolicy RetryPolicy                  // 幂等请求的重试策略
+	retryBudget *RetryBudget                 // 全局重试预算
+	limiter     *RateLimiter                 // 路由限流（计数存储在共享缓存服务中）
+	proxies     *TrustedProxies              // 可信代理，决定是否采用 X-Forwarded-For
+	health      *HealthChecker               // 节点主动健康检查
+	traffic     *TrafficManager              // 运行时按版本分流配置（管理接口修改）
+	routeStore  RouteStore                   // 路由覆盖配置存储
+	accessLog   *AccessLogger                // 访问日志收集器，为空时不记录
+	watcher     registry.Watcher
+	stopChan    chan struct{}
 }
 
 // ServiceRoute 服务路由配置
 type ServiceRoute struct {
-	ServiceName  string   // 微服务名称
-	PathPrefix   string   // 网关路径前缀，如 /api/v1/logs
-	TargetPrefix string   // 目标服务路径前缀，如 / 或 /api
-	StripPrefix  bool     // 是否去除前缀转发
-	Methods      []string // 允许的HTTP方法
-	AuthRequired bool     // 是否需要认证
+	ServiceName  string                       // 微服务名称
+	Host         string                       // 匹配的主机名，为空匹配任意主机，支持 *.example.com
+	PathPrefix   string                       // 网关路径规则，如 /api/v1/logs、/api/v1/{tenant}/orders/*
+	TargetPrefix string                       // 目标服务路径前缀，如 / 或 /api
+	StripPrefix  bool                         // 是否去除前缀转发
+	Methods      []string                     // 允许的HTTP方法
+	AuthRequired bool                         // 是否需要认证
+	LoadBalance  string                       // 负载均衡策略，为空时轮询
+	HashKey      string                       // 一致性哈希键: user、ip 或 header:<名称>
+	Idempotent   bool                         // 是否幂等（所有方法都允许失败重试）
+	RateLimit    *pkgRegistry.RateLimitConfig // 限流配置，为空时不限流
+	Traffic      *pkgRegistry.TrafficSplit    // 按版本分流配置，为空时不区分版本
+	Upstream     string                       // 静态上游名称，不为空时转发到配置文件中的固定地址
+	Transform    *pkgRegistry.TransformConfig // 请求与响应转换规则，为空时原样转发
+
+	balancer   Balancer
+	pattern    *routePattern
+	rewrite    *regexp.Regexp // 编译后的路径重写规则
+	source     string         // 路由来源，为空表示来自注册中心
+	overrideID int64          // 覆盖配置ID
+	disabled   bool           // 覆盖配置禁用了该路由
+	basePath   string         // 服务的 basePath，作为访问日志中的模块
+}
+
+// Option Gateway 配置选项
+type Option func(*Gateway)
+
+// WithRateLimitCounter 设置限流计数器（默认使用 cache.New()）
+func WithRateLimitCounter(c Counter) Option {
+	return func(g *Gateway) {
+		g.limiter = NewRateLimiter(c)
+	}
+}
+
+// WithTrafficStore 设置运行时分流配置的存储（默认使用缓存服务）
+func WithTrafficStore(s TrafficStore) Option {
+	return func(g *Gateway) {
+		g.traffic = NewTrafficManager(s)
+	}
+}
+
+// WithRouteStore 设置路由覆盖配置的存储（默认使用数据库）
+func WithRouteStore(s RouteStore) Option {
+	return func(g *Gateway) {
+		g.routeStore = s
+	}
+}
+
+// WithAccessLog 开启访问日志，按 gateway.accessLog 配置批量交给 sink 投递
+func WithAccessLog(sink AccessLogSink) Option {
+	return func(g *Gateway) {
+		g.accessLog = NewAccessLogger(g.config.Gateway.AccessLog, sink)
+	}
 }
 
 // NewGateway 创建网关
-func NewGateway(reg registry.Registry, cfg *config.Config) *Gateway {
-	return &Gateway{
-		registry: reg,
-		config:   cfg,
-		routes:   make(map[string]*ServiceRoute),
-		stopChan: make(chan struct{}),
+func NewGateway(reg registry.Registry, cfg *config.Config, opts ...Option) *Gateway {
+	timeout := time.Duration(cfg.Gateway.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultUpstreamTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	idleTimeout := time.Duration(cfg.Gateway.IdleTimeout) * time.Second
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultStreamIdleTimeout
+	}
+
+	g := &Gateway{
+		registry:    reg,
+		config:      cfg,
+		jwt:         auth.NewJWTManager(&cfg.JWT),
+		routes:      make(map[string]*ServiceRoute),
+		static:      make(map[string]*ServiceRoute),
+		overrides:   make(map[string]*ServiceRoute),
+		upstreams:   make(map[string]*registry.Service),
+		nodes:       make(map[string]map[string]bool),
+		inflight:    NewInFlightTracker(),
+		breakers:    NewBreakerGroup(DefaultBreakerThreshold, DefaultBreakerTimeout),
+		transport:   transport,
+		idleTimeout: idleTimeout,
+		retryPolicy: newRetryPolicy(cfg.Gateway.Retry),
+		retryBudget: newRetryBudgetFromConfig(cfg.Gateway.Retry),
+		limiter:     NewRateLimiter(cache.New()),
+		proxies:     NewTrustedProxies(cfg.Gateway.TrustedProxies),
+		health:      NewHealthChecker(cfg.Gateway.Health),
+		traffic:     NewTrafficManager(NewCacheTrafficStore(cache.New())),
+		routeStore:  NewDBRouteStore(),
+		stopChan:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	if err := g.ApplyStaticConfig(cfg.Gateway); err != nil {
+		logger.Error("静态路由配置无效", zap.Error(err))
+	}
+	return g
 }
 
 // RegisterRoute 注册服务路由
 func (g *Gateway) RegisterRoute(route *ServiceRoute) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	g.routes[route.PathPrefix] = route
+
+	if err := g.prepareRouteLocked(route, g.routes); err != nil {
+		logger.Error("路由规则无效",
+			zap.String("service", route.ServiceName),
+			zap.String("gateway_path", route.PathPrefix),
+			zap.Error(err),
+		)
+		return
+	}
+
+	g.routes[routeKey(route.Host, route.PathPrefix)] = route
+	g.rebuildLocked()
 	logger.Info("注册路由",
 		zap.String("service", route.ServiceName),
+		zap.String("host", route.Host),
 		zap.String("gateway_path", route.PathPrefix),
 		zap.String("target_prefix", route.TargetPrefix),
 		zap.Bool("strip_prefix", route.StripPrefix),
 		zap.Strings("methods", route.Methods),
 		zap.Bool("auth", route.AuthRequired),
+		zap.String("load_balance", route.balancer.Name()),
 	)
 }
 
-// UnregisterRoute 注销服务路由
+// UnregisterRoute 注销服务路由（包括所有主机下的同名路径规则）
 func (g *Gateway) UnregisterRoute(pathPrefix string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	if route, exists := g.routes[pathPrefix]; exists {
-		delete(g.routes, pathPrefix)
-		logger.Info("注销路由",
-			zap.String("service", route.ServiceName),
-			zap.String("path", pathPrefix),
-		)
+	for key, route := range g.routes {
+		if route.PathPrefix == pathPrefix {
+			delete(g.routes, key)
+			logger.Info("注销路由",
+				zap.String("service", route.ServiceName),
+				zap.String("host", route.Host),
+				zap.String("path", pathPrefix),
+			)
+		}
+	}
+	g.rebuildLocked()
+}
+
+// prepareRouteLocked 编译路由规则并分配负载均衡器，调用方需持有写锁
+// 路由更新时沿用 prev 中同键、同策略路由的负载均衡器，避免轮询等状态被重置
+func (g *Gateway) prepareRouteLocked(route *ServiceRoute, prev map[string]*ServiceRoute) error {
+	pattern, err := compilePattern(route.Host, route.PathPrefix)
+	if err != nil {
+		return err
+	}
+	route.pattern = pattern
+
+	rewrite, err := compileTransform(route.Transform)
+	if err != nil {
+		return err
+	}
+	route.rewrite = rewrite
+
+	if existing, ok := prev[routeKey(route.Host, route.PathPrefix)]; ok && existing.balancer != nil &&
+		existing.LoadBalance == route.LoadBalance && existing.HashKey == route.HashKey {
+		route.balancer = existing.balancer
+	} else {
+		route.balancer = NewBalancer(route.LoadBalance, route.HashKey, g.inflight)
+	}
+	return nil
+}
+
+// rebuildLocked 依次合并注册中心路由、静态路由与覆盖配置，重建排序后的路由表，调用方需持有写锁
+func (g *Gateway) rebuildLocked() {
+	effective := make(map[string]*ServiceRoute, len(g.routes)+len(g.static)+len(g.overrides))
+	for key, route := range g.routes {
+		effective[key] = route
+	}
+	for key, route := range g.static {
+		effective[key] = route
+	}
+	for key, route := range g.overrides {
+		if route.disabled {
+			delete(effective, key)
+			continue
+		}
+		effective[key] = route
+	}
+
+	sorted := make([]*ServiceRoute, 0, len(effective))
+	for _, route := range effective {
+		sorted = append(sorted, route)
 	}
+	sortRoutes(sorted)
+	g.sorted = sorted
+}
+
+// routeKey 路由表的键
+func routeKey(host, pathPrefix string) string {
+	return strings.ToLower(host) + pathPrefix
 }
 
 // SyncRoutes 从注册中心同步所有服务路由
@@ -100,9 +285,12 @@ func (g *Gateway) SyncRoutes() error {
 			continue
 		}
 
+		var nodes []*registry.Node
 		for _, s := range svcDetails {
 			g.registerServiceRoutes(s)
+			nodes = append(nodes, s.Nodes...)
 		}
+		g.retainServiceNodes(svc.Name, nodes)
 	}
 
 	return nil
@@ -119,6 +307,7 @@ func (g *Gateway) registerServiceRoutes(svc *registry.Service) {
 		g.RegisterRoute(&ServiceRoute{
 			ServiceName:  svc.Name,
 			PathPrefix:   gatewayPrefix,
+			basePath:     basePath,
 			TargetPrefix: "/",
 			StripPrefix:  true,
 			Methods:      pkgRegistry.DefaultMethods,
@@ -128,13 +317,31 @@ func (g *Gateway) registerServiceRoutes(svc *registry.Service) {
 
 	// 如果有细粒度的路由配置，也注册它们（覆盖默认配置）
 	for _, route := range routes {
+		// basePath 下的子路由只去除 basePath 前缀，保留服务内路径
+		// 例如: /api/v1/users/auth/login -> /auth/login
+		targetPrefix := "/"
+		if basePath != "" && route.StripPrefix {
+			gatewayPrefix := fmt.Sprintf("%s/%s", APIVersion, basePath)
+			if strings.HasPrefix(route.PathPrefix, gatewayPrefix+"/") {
+				targetPrefix = trimTrailingWildcard(strings.TrimPrefix(route.PathPrefix, gatewayPrefix))
+			}
+		}
+
 		g.RegisterRoute(&ServiceRoute{
 			ServiceName:  svc.Name,
+			Host:         route.Host,
 			PathPrefix:   route.PathPrefix,
-			TargetPrefix: "/",
+			TargetPrefix: targetPrefix,
 			StripPrefix:  route.StripPrefix,
 			Methods:      route.Methods,
 			AuthRequired: route.AuthRequired,
+			LoadBalance:  route.LoadBalance,
+			HashKey:      route.HashKey,
+			Idempotent:   route.Idempotent,
+			RateLimit:    route.RateLimit,
+			Traffic:      route.Traffic,
+			Transform:    route.Transform,
+			basePath:     basePath,
 		})
 	}
 }
@@ -144,15 +351,17 @@ func (g *Gateway) unregisterServiceRoutes(serviceName string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	for prefix, route := range g.routes {
+	for key, route := range g.routes {
 		if route.ServiceName == serviceName {
-			delete(g.routes, prefix)
+			delete(g.routes, key)
 			logger.Info("注销路由",
 				zap.String("service", serviceName),
-				zap.String("path", prefix),
+				zap.String("host", route.Host),
+				zap.String("path", route.PathPrefix),
 			)
 		}
 	}
+	g.rebuildLocked()
 }
 
 // WatchServices 监听服务变化，自动更新路由
@@ -162,6 +371,7 @@ func (g *Gateway) WatchServices() error {
 		return err
 	}
 	g.watcher = watcher
+	go g.watchRuntimeConfig()
 
 	go func() {
 		for {
@@ -170,6 +380,9 @@ func (g *Gateway) WatchServices() error {
 				return
 			default:
 				result, err := watcher.Next()
+				if err == registry.ErrWatcherStopped {
+					return
+				}
 				if err != nil {
 					logger.Error("监听服务变化失败", zap.Error(err))
 					time.Sleep(time.Second)
@@ -185,6 +398,28 @@ func (g *Gateway) WatchServices() error {
 	return nil
 }
 
+// watchRuntimeConfig 定期同步管理接口修改的运行时配置（分流配置与路由覆盖），网关关闭时停止
+// 多个网关副本共享同一份配置，任一副本的修改都会在一个同步周期内生效
+func (g *Gateway) watchRuntimeConfig() {
+	ticker := time.NewTicker(runtimeSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.traffic.Sync(); err != nil {
+			logger.Warn("同步分流配置失败", zap.Error(err))
+		}
+		if err := g.SyncOverrides(); err != nil {
+			logger.Warn("同步路由覆盖配置失败", zap.Error(err))
+		}
+
+		select {
+		case <-g.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // handleServiceEvent 处理服务事件
 func (g *Gateway) handleServiceEvent(result *registry.Result) {
 	if result.Service == nil {
@@ -198,14 +433,54 @@ func (g *Gateway) handleServiceEvent(result *registry.Result) {
 			zap.String("action", result.Action),
 		)
 		g.registerServiceRoutes(result.Service)
+		// 事件中的服务包含所有版本的节点，不在其中的节点已下线
+		g.retainServiceNodes(result.Service.Name, result.Service.Nodes)
 	case "delete":
 		logger.Info("服务注销",
 			zap.String("service", result.Service.Name),
 		)
 		g.unregisterServiceRoutes(result.Service.Name)
+		for _, node := range result.Service.Nodes {
+			g.removeNode(node.Id)
+		}
+		g.retainServiceNodes(result.Service.Name, nil)
+	}
+}
+
+// retainServiceNodes 记录服务的当前节点，清理已不在其中的节点状态
+func (g *Gateway) retainServiceNodes(service string, nodes []*registry.Node) {
+	alive := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		alive[node.Id] = true
+	}
+
+	g.nodesMu.Lock()
+	prev := g.nodes[service]
+	if len(alive) == 0 {
+		delete(g.nodes, service)
+	} else {
+		g.nodes[service] = alive
+	}
+	g.nodesMu.Unlock()
+
+	for id := range prev {
+		if !alive[id] {
+			g.removeNode(id)
+		}
 	}
 }
 
+// removeNode 清理已下线节点的熔断器、健康状态、进行中请求计数与指标
+func (g *Gateway) removeNode(nodeID string) {
+	g.breakers.Remove(nodeID)
+	g.health.Remove(nodeID)
+	g.inflight.Remove(nodeID)
+	upstreamRequestsTotal.DeleteMatching("node", nodeID)
+	upstreamRequestDuration.DeleteMatching("node", nodeID)
+	upstreamInFlight.DeleteMatching("node", nodeID)
+	upstreamBreakerState.DeleteMatching("node", nodeID)
+}
+
 // StopWatch 停止监听
 func (g *Gateway) StopWatch() {
 	close(g.stopChan)
@@ -217,37 +492,73 @@ func (g *Gateway) StopWatch() {
 // GetHandler 获取HTTP处理器（core.RequestEvent版本）
 func (g *Gateway) GetHandler() func(e *core.RequestEvent) error {
 	return func(e *core.RequestEvent) error {
-		path := e.Request.URL.Path
+		// 请求ID：沿用客户端传入的 X-Request-ID，没有时生成，随请求转发给上游并返回给客户端
+		requestID := e.Request.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = security.RandomString(32)
+			e.Request.Header.Set(HeaderRequestID, requestID)
+		}
+		e.Response.Header().Set(HeaderRequestID, requestID)
+
+		// 客户端IP（仅采用可信代理追加的 X-Forwarded-For），覆盖链路追踪中间件记录的直连地址
+		clientIP := g.proxies.ClientIP(e.Request)
+		tracing.SpanFromContext(e.Request.Context()).SetAttribute("http.client_ip", clientIP)
 
-		// 查找匹配的路由
+		// 查找匹配的路由（按主机、段数、字面量段数确定最具体的规则）
 		g.mu.RLock()
-		var matchedRoute *ServiceRoute
-		for prefix, route := range g.routes {
-			if strings.HasPrefix(path, prefix) {
-				matchedRoute = route
-				break
-			}
-		}
+		match := matchRoute(g.sorted, e.Request.Host, e.Request.URL.Path)
 		g.mu.RUnlock()
 
-		if matchedRoute == nil {
+		if match == nil {
 			return apis.Error(e, 404, "服务未找到")
 		}
+		matchedRoute := match.Route
+
+		// 请求结束后记录访问日志（包括被拒绝的请求）
+		start := time.Now()
+		var claims *core.JWTClaims
+		if g.accessLog != nil {
+			defer func() {
+				g.accessLog.Log(accessRecord(e, matchedRoute, claims, clientIP, start))
+			}()
+		}
 
 		// 检查方法是否允许
-		methodAllowed := false
-		for _, m := range matchedRoute.Methods {
-			if m == e.Request.Method {
-				methodAllowed = true
-				break
-			}
-		}
-		if !methodAllowed {
+		if !pkgRegistry.MethodAllowed(matchedRoute.Methods, e.Request.Method) {
 			return apis.Error(e, 405, "方法不允许")
 		}
 
-		// 服务发现
-		services, err := g.registry.GetService(matchedRoute.ServiceName)
+		// 请求体大小限制（已知长度时直接拒绝，分块传输时在转发读取中途中止）
+		if err := apis.ApplyBodyLimit(e, matchedRoute.maxBodySize()); err != nil {
+			return apis.Error(e, 413, "请求体过大")
+		}
+
+		// 认证检查
+		var err error
+		claims, err = g.authenticate(e, matchedRoute)
+		if err != nil {
+			return apis.Error(e, 401, err.Error())
+		}
+
+		// 限流检查
+		if rateLimitEnabled(matchedRoute.RateLimit) {
+			result, err := g.limiter.AllowAll(rateLimitBuckets(matchedRoute, e.Request, claims, clientIP), matchedRoute.RateLimit)
+			if err != nil {
+				// 缓存服务不可用时放行，避免限流组件故障导致整体不可用
+				logger.Warn("限流检查失败，放行请求",
+					zap.String("service", matchedRoute.ServiceName),
+					zap.Error(err),
+				)
+			} else {
+				result.WriteHeaders(e.Response.Header())
+				if !result.Allowed {
+					return apis.Error(e, 429, "请求过于频繁，请稍后重试")
+				}
+			}
+		}
+
+		// 服务发现（静态路由使用配置中的上游节点）
+		services, err := g.discover(matchedRoute)
 		if err != nil || len(services) == 0 {
 			logger.Error("服务发现失败",
 				zap.String("service", matchedRoute.ServiceName),
@@ -256,36 +567,215 @@ func (g *Gateway) GetHandler() func(e *core.RequestEvent) error {
 			return apis.Error(e, 503, "服务不可用")
 		}
 
-		// 简单轮询负载均衡
-		service := services[0]
-		if len(service.Nodes) == 0 {
+		// 按版本分流（静态上游不区分版本）
+		if matchedRoute.Upstream == "" {
+			services, err = g.selectVersion(e.Request, matchedRoute, services, claims)
+			if err != nil {
+				return apis.Error(e, 503, "服务版本不可用")
+			}
+		}
+
+		// 负载均衡（汇总所选版本的节点，排除健康检查失败的节点）
+		nodes := g.health.Filter(collectNodes(services))
+		if len(nodes) == 0 {
 			return apis.Error(e, 503, "服务节点不可用")
 		}
 
-		node := service.Nodes[time.Now().UnixNano()%int64(len(service.Nodes))]
+		return g.forward(e, match, nodes, claims)
+	}
+}
 
-		// 代理请求
-		return g.proxyRequest(e, node.Address, matchedRoute)
+// forward 选择节点并代理请求
+// 幂等请求在节点连接失败或超时时换一个节点重试，重试次数受重试策略和重试预算限制
+func (g *Gateway) forward(e *core.RequestEvent, match *routeMatch, nodes []*registry.Node, claims *core.JWTClaims) error {
+	g.retryBudget.Deposit()
+
+	route := match.Route
+	retryable := (route.Idempotent || isRetryableMethod(e.Request.Method)) &&
+		g.retryPolicy.MaxAttempts > 1 &&
+		bufferBody(e.Request, g.retryPolicy.MaxBodySize)
+
+	ctx := &PickContext{Request: e.Request, Claims: claims, ClientIP: g.proxies.ClientIP(e.Request)}
+	candidates := nodes
+	var lastErr error
+
+	for attempt := 1; ; attempt++ {
+		node := g.selectNode(route, candidates, ctx)
+		if node == nil {
+			if lastErr != nil {
+				return g.upstreamError(e, lastErr)
+			}
+			return apis.Error(e, 503, "服务熔断中，请稍后重试")
+		}
+
+		release := g.inflight.Acquire(node.Id)
+		err := g.proxyRequest(e, node, match, claims)
+		release()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !retryable || attempt >= g.retryPolicy.MaxAttempts || e.Request.Context().Err() != nil {
+			return g.upstreamError(e, err)
+		}
+
+		// 换一个节点重试
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(n *registry.Node) bool { return n.Id == node.Id })
+		if len(candidates) == 0 || !g.retryBudget.Withdraw() {
+			return g.upstreamError(e, err)
+		}
+		if !g.retryPolicy.wait(e.Request.Context(), attempt) {
+			return g.upstreamError(e, err)
+		}
+		rewindBody(e.Request)
+		upstreamRetriesTotal.With(route.ServiceName).Inc()
+
+		logger.Warn("重试代理请求",
+			zap.String("service", route.ServiceName),
+			zap.String("failed_node", node.Id),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+	}
+}
+
+// upstreamError 将代理失败转换为错误响应：超时返回 504，其余返回 502
+func (g *Gateway) upstreamError(e *core.RequestEvent, err error) error {
+	// 客户端已断开，无需响应
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if errors.Is(err, apis.ErrRequestEntityTooLarge) {
+		return apis.Error(e, 413, "请求体过大")
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return apis.Error(e, 504, "上游服务响应超时")
+	}
+	return apis.Error(e, 502, "上游服务不可用")
+}
+
+// selectNode 选择节点：跳过熔断中的节点，并占用所选节点的半开探测名额
+// 所有节点都不可用时返回 nil
+func (g *Gateway) selectNode(route *ServiceRoute, nodes []*registry.Node, ctx *PickContext) *registry.Node {
+	candidates := make([]*registry.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if g.breakers.Get(node.Id).Ready() {
+			candidates = append(candidates, node)
+		}
+	}
+
+	for len(candidates) > 0 {
+		node := route.balancer.Pick(candidates, ctx)
+		if g.breakers.Get(node.Id).Allow() {
+			return node
+		}
+		// 探测名额已被并发请求占用，换一个节点
+		candidates = slices.DeleteFunc(candidates, func(n *registry.Node) bool { return n == node })
 	}
+	return nil
+}
+
+// recordOutcome 将代理结果记录到节点熔断器
+func (g *Gateway) recordOutcome(node *registry.Node, proxyErr error, statusFailed bool) {
+	cb := g.breakers.Get(node.Id)
+
+	// 客户端主动取消或请求体超限不代表节点故障
+	if errors.Is(proxyErr, context.Canceled) || errors.Is(proxyErr, apis.ErrRequestEntityTooLarge) {
+		cb.Release()
+		return
+	}
+
+	defer func() {
+		upstreamBreakerState.With(node.Id).Set(float64(cb.State()))
+	}()
+
+	if proxyErr == nil && !statusFailed {
+		cb.Success()
+		return
+	}
+
+	before := cb.State()
+	cb.Failure()
+	if after := cb.State(); after == StateOpen && before != StateOpen {
+		logger.Warn("节点熔断",
+			zap.String("node", node.Id),
+			zap.String("address", node.Address),
+			zap.Int("failures", cb.Failures()),
+		)
+	}
+}
+
+// isUpstreamFailure 上游响应是否表示节点不可用（计入熔断失败）
+func isUpstreamFailure(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
+
+// authenticate 校验请求的JWT
+// 受保护路由必须携带有效Token；公开路由携带有效Token时同样转发身份，无效则按匿名处理
+func (g *Gateway) authenticate(e *core.RequestEvent, route *ServiceRoute) (*core.JWTClaims, error) {
+	token := e.Request.Header.Get("Authorization")
+	if token == "" {
+		token = e.Request.URL.Query().Get("token")
+	}
+	token = strings.TrimPrefix(token, "Bearer ")
+
+	if token == "" {
+		if route.AuthRequired {
+			return nil, fmt.Errorf("未提供认证令牌")
+		}
+		return nil, nil
+	}
+
+	claims, err := g.jwt.ParseToken(token)
+	if err != nil {
+		if route.AuthRequired {
+			return nil, fmt.Errorf("无效的认证令牌")
+		}
+		return nil, nil
+	}
+
+	apis.SetAuthClaims(e, claims)
+	return claims, nil
 }
 
 // proxyRequest 代理请求到后端服务
-func (g *Gateway) proxyRequest(e *core.RequestEvent, targetAddr string, route *ServiceRoute) error {
+// claims 不为空时以签名请求头转发已验证的用户身份
+// 连接上游失败时返回错误且不写入响应，由调用方决定重试或返回错误响应
+func (g *Gateway) proxyRequest(e *core.RequestEvent, node *registry.Node, match *routeMatch, claims *core.JWTClaims) error {
+	route := match.Route
+	targetAddr := node.Address
 	targetURL, err := url.Parse("http://" + targetAddr)
 	if err != nil {
 		logger.Error("解析目标URL失败", zap.Error(err))
-		return apis.Error(e, 500, "内部错误")
+		g.breakers.Get(node.Id).Release()
+		return err
 	}
 
+	// 每次转发（包括重试）记录一个客户端 Span，上游服务的 Span 作为其子 Span
+	ctx, span := tracing.StartChild(e.Request.Context(), "proxy "+route.ServiceName, tracing.SpanKindClient)
+	defer span.End()
+	span.SetAttribute("gateway.node", node.Id)
+	span.SetAttribute("net.peer.name", targetAddr)
+
+	inFlight := upstreamInFlight.With(route.ServiceName, node.Id)
+	inFlight.Inc()
+	defer inFlight.Dec()
+	start := time.Now()
+
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.Transport = g.transport
 
 	// 修改请求，需要在 handler 中设置
 	originalDirector := proxy.Director
 
-	clientIP := e.Request.RemoteAddr
-	if forwarded := e.Request.Header.Get("X-Forwarded-For"); forwarded != "" {
-		clientIP = forwarded
-	}
+	// 客户端地址只采用可信代理追加的部分，客户端伪造的 X-Forwarded-For 不会传给上游
+	clientIP := g.proxies.ClientIP(e.Request)
+	forwardedFor := g.proxies.ForwardedFor(e.Request)
 	reqHost := e.Request.Host
 	scheme := "http"
 	if e.Request.TLS != nil {
@@ -295,32 +785,62 @@ func (g *Gateway) proxyRequest(e *core.RequestEvent, targetAddr string, route *S
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
 
-		// 路径转换：去除网关前缀，保留服务路径
+		// 路径转换：去除匹配的网关前缀，替换为目标前缀（支持 {参数} 占位）
 		// 例如: /api/v1/logs/health -> /health
 		if route.StripPrefix && route.PathPrefix != "" {
-			newPath := strings.TrimPrefix(req.URL.Path, route.PathPrefix)
-			if newPath == "" || newPath[0] != '/' {
-				newPath = "/" + newPath
-			}
-			req.URL.Path = route.TargetPrefix + strings.TrimPrefix(newPath, "/")
-			if req.URL.Path == "" {
-				req.URL.Path = "/"
-			}
-			// 规范化路径
-			if !strings.HasPrefix(req.URL.Path, "/") {
-				req.URL.Path = "/" + req.URL.Path
-			}
+			req.URL.Path = rewritePath(match.Rest, expandParams(route.TargetPrefix, match.Params))
+			req.URL.RawPath = ""
+		}
+
+		// 路由转换规则：路径重写、查询参数注入与请求头转换
+		transformRequest(req, match)
+
+		// 每次转发只读取一遍请求体（重试时由 rewindBody 倒回）
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = &singleReadBody{ReadCloser: req.Body}
+		}
+
+		// 清除客户端伪造的身份头，仅转发网关签名的身份
+		auth.StripIdentity(req.Header)
+		if claims != nil {
+			g.jwt.SignIdentity(req.Header, claims)
 		}
 
 		// 传递原始请求信息
-		req.Header.Set("X-Forwarded-For", clientIP)
+		// X-Forwarded-For 只保留可信链路，ReverseProxy 随后追加直连地址
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		} else {
+			req.Header.Del("X-Forwarded-For")
+		}
 		req.Header.Set("X-Real-IP", clientIP)
 		req.Header.Set("X-Forwarded-Proto", scheme)
 		req.Header.Set("X-Forwarded-Host", reqHost)
+
+		// 传递链路（替换客户端传入的 traceparent）
+		tracing.Inject(ctx, req.Header)
+	}
+
+	// 记录上游状态码，用于熔断判断；流式响应与 WebSocket 改用空闲超时
+	var statusFailed bool
+	var status int
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		status = resp.StatusCode
+		statusFailed = isUpstreamFailure(resp.StatusCode)
+		span.SetAttribute("http.status_code", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, resp.Status)
+		}
+		transformResponse(resp, match)
+		wrapStream(e.Response, resp, g.idleTimeout)
+		return nil
 	}
 
 	// 错误处理
+	var proxyErr error
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		proxyErr = err
+		span.RecordError(err)
 		logger.Error("代理请求失败",
 			zap.String("target", targetAddr),
 			zap.Error(err),
@@ -329,7 +849,26 @@ func (g *Gateway) proxyRequest(e *core.RequestEvent, targetAddr string, route *S
 
 	// 使用 ResponseWriter 代理
 	proxy.ServeHTTP(e.Response, e.Request)
-	return nil
+
+	recordUpstream(route.ServiceName, node.Id, status, time.Since(start))
+	g.recordOutcome(node, proxyErr, statusFailed)
+	return proxyErr
+}
+
+// rewritePath 将去除网关前缀后的剩余路径拼接到 targetPrefix 之后
+func rewritePath(rest, targetPrefix string) string {
+	if rest != "" && rest[0] != '/' {
+		rest = "/" + rest
+	}
+	newPath := strings.TrimSuffix(targetPrefix, "/") + rest
+	if newPath == "" {
+		return "/"
+	}
+	// 规范化路径
+	if !strings.HasPrefix(newPath, "/") {
+		newPath = "/" + newPath
+	}
+	return newPath
 }
 
 // HealthCheck 健康检查
@@ -343,10 +882,25 @@ func (g *Gateway) HealthCheck(e *core.RequestEvent) error {
 
 // ServiceStatus 服务状态
 type ServiceStatus struct {
-	Name      string   `json:"name"`
-	Status    string   `json:"status"`
-	Nodes     int      `json:"nodes"`
-	Addresses []string `json:"addresses,omitempty"`
+	Name      string       `json:"name"`
+	Status    string       `json:"status"`
+	Nodes     int          `json:"nodes"`
+	Addresses []string     `json:"addresses,omitempty"`
+	Details   []NodeStatus `json:"details,omitempty"`
+	Static    bool         `json:"static,omitempty"` // 配置文件中的静态上游
+}
+
+// NodeStatus 节点状态
+type NodeStatus struct {
+	ID        string       `json:"id"`
+	Address   string       `json:"address"`
+	Version   string       `json:"version,omitempty"`
+	Health    HealthState  `json:"health"`
+	LatencyMs float64      `json:"latencyMs"`           // 最近一次健康检查耗时（毫秒）
+	LastError string       `json:"lastError,omitempty"` // 最近一次健康检查失败原因
+	LastCheck *time.Time   `json:"lastCheck,omitempty"`
+	Breaker   CircuitState `json:"breaker"`
+	InFlight  int64        `json:"inFlight"`
 }
 
 // GetServicesStatus 获取所有服务状态
@@ -371,84 +925,70 @@ func (g *Gateway) GetServicesStatus(e *core.RequestEvent) error {
 			continue
 		}
 
-		var addresses []string
-		nodeCount := 0
-		for _, s := range svcDetails {
-			for _, node := range s.Nodes {
-				addresses = append(addresses, node.Address)
-				nodeCount++
-			}
-		}
-
-		status := "unhealthy"
-		if nodeCount > 0 {
-			status = "healthy"
-		}
+		statuses = append(statuses, g.serviceStatus(svc.Name, svcDetails))
+	}
 
-		statuses = append(statuses, ServiceStatus{
-			Name:      svc.Name,
-			Status:    status,
-			Nodes:     nodeCount,
-			Addresses: addresses,
-		})
+	// 配置文件中的静态上游
+	for _, svc := range g.upstreamServices() {
+		status := g.serviceStatus(svc.Name, []*registry.Service{svc})
+		status.Static = true
+		statuses = append(statuses, status)
 	}
 
 	return apis.Success(e, statuses)
 }
 
-// CircuitBreaker 熔断器
-type CircuitBreaker struct {
-	failures    int
-	threshold   int
-	timeout     time.Duration
-	lastFailure time.Time
-	state       string // closed, open, half-open
-}
-
-// NewCircuitBreaker 创建熔断器
-func NewCircuitBreaker(threshold int, timeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		threshold: threshold,
-		timeout:   timeout,
-		state:     "closed",
+// serviceStatus 汇总服务各节点的熔断与健康状态
+func (g *Gateway) serviceStatus(name string, svcDetails []*registry.Service) ServiceStatus {
+	var addresses []string
+	var details []NodeStatus
+	available := 0
+	versions := make(map[string]string)
+	for _, s := range svcDetails {
+		for _, node := range s.Nodes {
+			versions[node.Id] = s.Version
+		}
 	}
-}
-
-// Allow 是否允许请求
-func (cb *CircuitBreaker) Allow() bool {
-	switch cb.state {
-	case "closed":
-		return true
-	case "open":
-		if time.Since(cb.lastFailure) > cb.timeout {
-			cb.state = "half-open"
-			return true
+	nodes := collectNodes(svcDetails)
+	for _, node := range nodes {
+		state := g.breakers.State(node.Id)
+		health, checked := g.health.Get(node.Id)
+		if state != StateOpen && health.State != HealthUnhealthy {
+			available++
+		}
+		addresses = append(addresses, node.Address)
+
+		detail := NodeStatus{
+			ID:       node.Id,
+			Address:  node.Address,
+			Version:  versions[node.Id],
+			Health:   health.State,
+			Breaker:  state,
+			InFlight: g.inflight.Count(node.Id),
+		}
+		if checked {
+			detail.LatencyMs = float64(health.Latency.Microseconds()) / 1000
+			detail.LastError = health.LastError
+			detail.LastCheck = &health.LastCheck
 		}
-		return false
-	case "half-open":
-		return true
+		details = append(details, detail)
 	}
-	return false
-}
-
-// Success 记录成功
-func (cb *CircuitBreaker) Success() {
-	cb.failures = 0
-	cb.state = "closed"
-}
 
-// Failure 记录失败
-func (cb *CircuitBreaker) Failure() {
-	cb.failures++
-	cb.lastFailure = time.Now()
-	if cb.failures >= cb.threshold {
-		cb.state = "open"
+	// 部分节点熔断或健康检查失败时为 degraded，全部不可用时为 unhealthy
+	status := "unhealthy"
+	if available == len(nodes) && available > 0 {
+		status = "healthy"
+	} else if available > 0 {
+		status = "degraded"
 	}
-}
 
-// State 获取状态
-func (cb *CircuitBreaker) State() string {
-	return cb.state
+	return ServiceStatus{
+		Name:      name,
+		Status:    status,
+		Nodes:     len(nodes),
+		Addresses: addresses,
+		Details:   details,
+	}
 }
 
 // Shutdown 关闭网关
@@ -456,18 +996,21 @@ func (g *Gateway) Shutdown(ctx context.Context) error {
 	logger.Info("正在关闭网关...")
 	// 停止监听服务变化
 	g.StopWatch()
-	// 清理资源
+	// 投递剩余的访问日志
+	if g.accessLog != nil {
+		return g.accessLog.Close(ctx)
+	}
 	return nil
 }
 
-// GetRoutes 获取所有已注册的路由（用于调试）
+// GetRoutes 获取所有生效的路由（用于调试）
 func (g *Gateway) GetRoutes() map[string]*ServiceRoute {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
-	routes := make(map[string]*ServiceRoute)
-	for k, v := range g.routes {
-		routes[k] = v
+	routes := make(map[string]*ServiceRoute, len(g.sorted))
+	for _, v := range g.sorted {
+		routes[routeKey(v.Host, v.PathPrefix)] = v
 	}
 	return routes
 }
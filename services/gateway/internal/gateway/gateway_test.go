@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{
+			Secret: "gateway-test-secret",
+			Issuer: "goback",
+			Expire: 3600,
+		},
+	}
+}
+
+// newTestGateway 创建网关及其 HTTP 测试服务器，opts 用于调整测试配置
+func newTestGateway(t *testing.T, reg registry.Registry, opts ...func(cfg *config.Config)) (*Gateway, *httptest.Server) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	gw := NewGateway(reg, cfg)
+
+	r := router.NewRouter(func(w http.ResponseWriter, req *http.Request) (*core.RequestEvent, router.EventCleanupFunc) {
+		event := new(core.RequestEvent)
+		event.Response = w
+		event.Request = req
+		return event, nil
+	})
+	r.Bind(core.TracingMiddleware())
+	r.GET("/services", gw.GetServicesStatus)
+	gw.RegisterAdminRoutes(r)
+	gw.RegisterDocRoutes(r)
+	for _, method := range pkgRegistry.DefaultMethods {
+		r.Route(method, "/api/{path...}", gw.GetHandler())
+	}
+
+	mux, err := r.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	return gw, ts
+}
+
+// newTestBackend 创建记录请求的后端服务
+func newTestBackend(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	if handler == nil {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"path":   r.URL.Path,
+				"userId": r.Header.Get(auth.HeaderUserID),
+			})
+		}
+	}
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// registerBackend 将后端注册到注册中心
+func registerBackend(t *testing.T, reg registry.Registry, name, basePath string, backend *httptest.Server, routes ...pkgRegistry.RouteConfig) {
+	t.Helper()
+
+	builder := pkgRegistry.NewServiceBuilder(name, "v1.0.0").
+		WithAddress(strings.TrimPrefix(backend.URL, "http://")).
+		WithBasePath(basePath)
+	for _, route := range routes {
+		builder.AddRoute(route)
+	}
+
+	if err := reg.Register(builder.Build()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func doRequest(t *testing.T, method, url string, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+	return doRequestWithBody(t, method, url, nil, headers)
+}
+
+func doRequestWithBody(t *testing.T, method, url string, body io.Reader, headers map[string]string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	return resp, string(raw)
+}
@@ -0,0 +1,309 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	"go-micro.dev/v5/registry"
+	"go.uber.org/zap"
+)
+
+// 主动健康检查默认配置
+const (
+	DefaultHealthInterval           = 10 * time.Second
+	DefaultHealthTimeout            = 2 * time.Second
+	DefaultHealthPath               = "/health"
+	DefaultHealthUnhealthyThreshold = 3
+	DefaultHealthHealthyThreshold   = 2
+)
+
+// HealthState 节点健康状态
+type HealthState int
+
+const (
+	HealthUnknown   HealthState = iota // 尚未探测
+	HealthHealthy                      // 健康
+	HealthUnhealthy                    // 不健康，从负载均衡中摘除
+)
+
+// String 返回状态名称
+func (s HealthState) String() string {
+	switch s {
+	case HealthHealthy:
+		return "healthy"
+	case HealthUnhealthy:
+		return "unhealthy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (s HealthState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (s *HealthState) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "healthy":
+		*s = HealthHealthy
+	case "unhealthy":
+		*s = HealthUnhealthy
+	case "unknown":
+		*s = HealthUnknown
+	default:
+		return fmt.Errorf("unknown health state %q", text)
+	}
+	return nil
+}
+
+// NodeHealth 节点最近一次健康检查的结果
+type NodeHealth struct {
+	State     HealthState
+	Latency   time.Duration // 最近一次探测耗时
+	LastError string        // 最近一次失败原因，恢复健康后清空
+	LastCheck time.Time
+	failures  int // 连续失败次数
+	successes int // 连续成功次数
+}
+
+// HealthChecker 节点主动健康检查（并发安全）
+// 节点连续失败达到阈值后标记为不健康并从负载均衡中摘除，连续成功达到阈值后恢复
+type HealthChecker struct {
+	client             *http.Client
+	interval           time.Duration
+	path               string
+	unhealthyThreshold int
+	healthyThreshold   int
+
+	mu    sync.RWMutex
+	nodes map[string]*NodeHealth
+}
+
+// NewHealthChecker 从配置创建健康检查器，未配置的字段使用默认值
+func NewHealthChecker(cfg config.GatewayHealthConfig) *HealthChecker {
+	h := &HealthChecker{
+		interval:           time.Duration(cfg.Interval) * time.Second,
+		path:               cfg.Path,
+		unhealthyThreshold: cfg.UnhealthyThreshold,
+		healthyThreshold:   cfg.HealthyThreshold,
+		nodes:              make(map[string]*NodeHealth),
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	if h.interval <= 0 {
+		h.interval = DefaultHealthInterval
+	}
+	if h.path == "" {
+		h.path = DefaultHealthPath
+	}
+	if h.unhealthyThreshold <= 0 {
+		h.unhealthyThreshold = DefaultHealthUnhealthyThreshold
+	}
+	if h.healthyThreshold <= 0 {
+		h.healthyThreshold = DefaultHealthHealthyThreshold
+	}
+	h.client = &http.Client{Timeout: timeout}
+	return h
+}
+
+// Filter 过滤掉不健康的节点（未探测过的节点视为可用）
+func (h *HealthChecker) Filter(nodes []*registry.Node) []*registry.Node {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	healthy := make([]*registry.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if nh, ok := h.nodes[node.Id]; ok && nh.State == HealthUnhealthy {
+			continue
+		}
+		healthy = append(healthy, node)
+	}
+	return healthy
+}
+
+// Get 获取节点健康检查结果的副本
+func (h *HealthChecker) Get(nodeID string) (NodeHealth, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	nh, ok := h.nodes[nodeID]
+	if !ok {
+		return NodeHealth{}, false
+	}
+	return *nh, true
+}
+
+// Remove 删除节点的健康状态（节点注销时调用）
+func (h *HealthChecker) Remove(nodeID string) {
+	h.mu.Lock()
+	delete(h.nodes, nodeID)
+	h.mu.Unlock()
+}
+
+// CheckNodes 并发探测一轮节点
+func (h *HealthChecker) CheckNodes(ctx context.Context, nodes []*registry.Node) {
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Go(func() {
+			latency, err := h.probe(ctx, node)
+			h.record(node, latency, err)
+		})
+	}
+	wg.Wait()
+}
+
+// Retain 仅保留 nodes 中节点的健康状态，清理已下线节点
+func (h *HealthChecker) Retain(nodes []*registry.Node) {
+	alive := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		alive[node.Id] = struct{}{}
+	}
+
+	h.mu.Lock()
+	for id := range h.nodes {
+		if _, ok := alive[id]; !ok {
+			delete(h.nodes, id)
+		}
+	}
+	h.mu.Unlock()
+}
+
+// probe 请求节点的健康检查接口，2xx 视为成功
+func (h *HealthChecker) probe(ctx context.Context, node *registry.Node) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+node.Address+h.path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return latency, fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+	return latency, nil
+}
+
+// record 记录一次探测结果并更新节点状态
+func (h *HealthChecker) record(node *registry.Node, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	nh, ok := h.nodes[node.Id]
+	if !ok {
+		nh = &NodeHealth{}
+		h.nodes[node.Id] = nh
+	}
+	nh.Latency = latency
+	nh.LastCheck = time.Now()
+
+	if err != nil {
+		nh.LastError = err.Error()
+		nh.failures++
+		nh.successes = 0
+		if nh.State != HealthUnhealthy && nh.failures >= h.unhealthyThreshold {
+			nh.State = HealthUnhealthy
+			logger.Warn("节点健康检查失败，已摘除",
+				zap.String("node", node.Id),
+				zap.String("address", node.Address),
+				zap.Int("failures", nh.failures),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	nh.failures = 0
+	nh.successes++
+	switch {
+	case nh.State == HealthUnhealthy && nh.successes >= h.healthyThreshold:
+		nh.State = HealthHealthy
+		nh.LastError = ""
+		logger.Info("节点恢复健康",
+			zap.String("node", node.Id),
+			zap.String("address", node.Address),
+		)
+	case nh.State == HealthUnknown:
+		nh.State = HealthHealthy
+		nh.LastError = ""
+	case nh.State == HealthHealthy:
+		nh.LastError = ""
+	}
+}
+
+// StartHealthCheck 启动后台主动健康检查，网关关闭时停止
+func (g *Gateway) StartHealthCheck() {
+	go func() {
+		ticker := time.NewTicker(g.health.interval)
+		defer ticker.Stop()
+
+		for {
+			g.checkHealth()
+
+			select {
+			case <-g.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	logger.Info("开始主动健康检查",
+		zap.Duration("interval", g.health.interval),
+		zap.String("path", g.health.path),
+	)
+}
+
+// checkHealth 探测注册中心中所有服务与静态上游的节点
+func (g *Gateway) checkHealth() {
+	// 静态上游不依赖注册中心，注册中心不可用时同样检查
+	all := g.upstreamServices()
+	complete := true
+
+	services, err := g.registry.ListServices()
+	if err != nil {
+		logger.Warn("健康检查获取服务列表失败", zap.Error(err))
+		complete = false
+	}
+	for _, svc := range services {
+		details, err := g.registry.GetService(svc.Name)
+		if err != nil {
+			complete = false
+			continue
+		}
+		all = append(all, details...)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-g.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	nodes := collectNodes(all)
+	g.health.CheckNodes(ctx, nodes)
+	// 服务列表不完整时保留原有状态，避免不健康节点被误放回负载均衡
+	if complete {
+		g.health.Retain(nodes)
+	}
+}
@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// newHealthBackend 创建健康状态可切换的后端，业务请求返回 name
+func newHealthBackend(t *testing.T, name string, healthy *atomic.Bool) string {
+	t.Helper()
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" && !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(name))
+	})
+	return backend.URL
+}
+
+func TestGatewayHealthCheck(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.Health = config.GatewayHealthConfig{UnhealthyThreshold: 2, HealthyThreshold: 2}
+	})
+
+	var goodHealthy, flakyHealthy atomic.Bool
+	goodHealthy.Store(true)
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("orders", "/items"))
+	for _, b := range []struct {
+		name    string
+		healthy *atomic.Bool
+	}{{"good", &goodHealthy}, {"flaky", &flakyHealthy}} {
+		url := newHealthBackend(t, b.name, b.healthy)
+		err := reg.Register(pkgRegistry.NewServiceBuilder("order-service", "v1.0.0").
+			WithNodeID(b.name).
+			WithAddress(strings.TrimPrefix(url, "http://")).
+			WithBasePath("orders").
+			AddRoute(route).
+			Build())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	served := func() map[string]int {
+		counts := make(map[string]int)
+		for range 4 {
+			resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/orders/items", nil)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+			}
+			counts[body]++
+		}
+		return counts
+	}
+
+	// 未达到失败阈值前仍参与负载均衡
+	gw.checkHealth()
+	if counts := served(); counts["flaky"] == 0 {
+		t.Fatalf("Expected flaky node to still receive traffic, got %v", counts)
+	}
+
+	// 达到阈值后摘除
+	gw.checkHealth()
+	if counts := served(); counts["flaky"] != 0 {
+		t.Fatalf("Expected unhealthy node to be removed, got %v", counts)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/services", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	var result struct {
+		Data []ServiceStatus `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 1 || result.Data[0].Status != "degraded" {
+		t.Fatalf("Expected one degraded service, got %s", body)
+	}
+	for _, node := range result.Data[0].Details {
+		if node.LastCheck == nil || node.LatencyMs <= 0 {
+			t.Fatalf("Expected latency and check time for %s, got %+v", node.ID, node)
+		}
+		switch node.ID {
+		case "good":
+			if node.Health != HealthHealthy || node.LastError != "" {
+				t.Fatalf("Expected good node to be healthy, got %+v", node)
+			}
+		case "flaky":
+			if node.Health != HealthUnhealthy || !strings.Contains(node.LastError, "503") {
+				t.Fatalf("Expected flaky node to be unhealthy with the last error, got %+v", node)
+			}
+		}
+	}
+
+	// 连续成功达到阈值后恢复
+	flakyHealthy.Store(true)
+	gw.checkHealth()
+	if counts := served(); counts["flaky"] != 0 {
+		t.Fatalf("Expected node to stay removed until the healthy threshold, got %v", counts)
+	}
+	gw.checkHealth()
+	if counts := served(); counts["flaky"] == 0 {
+		t.Fatalf("Expected recovered node to receive traffic, got %v", counts)
+	}
+	if h, _ := gw.health.Get("flaky"); h.State != HealthHealthy || h.LastError != "" {
+		t.Fatalf("Expected recovered node to be healthy, got %+v", h)
+	}
+
+	// 全部节点不健康时返回 503
+	goodHealthy.Store(false)
+	flakyHealthy.Store(false)
+	gw.checkHealth()
+	gw.checkHealth()
+	resp, body = doRequest(t, http.MethodGet, ts.URL+"/api/v1/orders/items", nil)
+	assertErrorResponse(t, resp, body, http.StatusServiceUnavailable)
+}
+
+func TestHealthCheckerRetain(t *testing.T) {
+	h := NewHealthChecker(config.GatewayHealthConfig{UnhealthyThreshold: 1})
+
+	down := &registry.Node{Id: "down", Address: "127.0.0.1:1"}
+	h.record(down, 0, http.ErrHandlerTimeout)
+	if nodes := h.Filter([]*registry.Node{down}); len(nodes) != 0 {
+		t.Fatalf("Expected node to be filtered, got %d nodes", len(nodes))
+	}
+
+	// 节点下线后清理状态，重新上线时视为未探测
+	h.Retain(nil)
+	if _, ok := h.Get("down"); ok {
+		t.Fatal("Expected node state to be removed")
+	}
+	if nodes := h.Filter([]*registry.Node{down}); len(nodes) != 1 {
+		t.Fatalf("Expected unchecked node to be available, got %d nodes", len(nodes))
+	}
+}
@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+// 路由匹配规则
+//
+// 路径规则按段（/ 分隔）做前缀匹配，/api/v1/users 匹配 /api/v1/users 与 /api/v1/users/...，
+// 但不匹配 /api/v1/usersX。段支持以下写法：
+//
+//	users      字面量
+//	{tenant}   路径参数，匹配任意单段
+//	*          匹配任意单段；位于末尾时匹配剩余所有段
+//	{rest...}  位于末尾，匹配剩余所有段并捕获为参数
+//
+// 主机规则为空时匹配任意主机，支持精确主机名与 *.example.com 形式的子域名通配。
+// 多条规则同时匹配时按以下顺序选择：主机更具体 > 段数更多 > 字面量段更多 > 规则字符串字典序。
+
+// patternSegment 路径规则中的一段
+type patternSegment struct {
+	literal string // 字面量，param 为空且非 any 时有效
+	param   string // 参数名
+	any     bool   // 匿名单段通配
+}
+
+// routePattern 编译后的路由规则
+type routePattern struct {
+	raw      string
+	host     string // 小写主机名；以 "*." 开头表示子域名通配
+	segments []patternSegment
+	rest     string // 末尾 {name...} 捕获的参数名
+	literals int
+}
+
+// routeMatch 路由匹配结果
+type routeMatch struct {
+	Route  *ServiceRoute
+	Params map[string]string // 路径参数
+	Prefix string            // 请求路径中被规则匹配的前缀部分
+	Rest   string            // 前缀之后的剩余路径（以 / 开头或为空）
+}
+
+// compilePattern 编译路由规则
+func compilePattern(host, pattern string) (*routePattern, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("route pattern %q must start with /", pattern)
+	}
+
+	p := &routePattern{
+		raw:  pattern,
+		host: strings.ToLower(host),
+	}
+
+	parts := splitPath(pattern)
+	for i, part := range parts {
+		last := i == len(parts)-1
+		switch {
+		case part.text == "*":
+			if last {
+				break
+			}
+			p.segments = append(p.segments, patternSegment{any: true})
+		case strings.HasPrefix(part.text, "{") && strings.HasSuffix(part.text, "...}"):
+			if !last {
+				return nil, fmt.Errorf("route pattern %q: %s must be the last segment", pattern, part.text)
+			}
+			p.rest = strings.TrimSuffix(strings.TrimPrefix(part.text, "{"), "...}")
+		case strings.HasPrefix(part.text, "{") && strings.HasSuffix(part.text, "}"):
+			name := strings.TrimSuffix(strings.TrimPrefix(part.text, "{"), "}")
+			if name == "" {
+				return nil, fmt.Errorf("route pattern %q: empty parameter name", pattern)
+			}
+			p.segments = append(p.segments, patternSegment{param: name})
+		case strings.ContainsAny(part.text, "{}*"):
+			return nil, fmt.Errorf("route pattern %q: invalid segment %q", pattern, part.text)
+		default:
+			p.segments = append(p.segments, patternSegment{literal: part.text})
+			p.literals++
+		}
+	}
+
+	return p, nil
+}
+
+// hostRank 主机规则的具体程度：精确 > 通配 > 任意
+func (p *routePattern) hostRank() int {
+	switch {
+	case p.host == "":
+		return 0
+	case strings.HasPrefix(p.host, "*."):
+		return 1
+	}
+	return 2
+}
+
+// matchHost 检查请求主机是否匹配
+func (p *routePattern) matchHost(host string) bool {
+	switch {
+	case p.host == "":
+		return true
+	case strings.HasPrefix(p.host, "*."):
+		suffix := p.host[1:] // .example.com
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return host == p.host
+}
+
+// match 匹配请求路径，返回参数与匹配的段数
+func (p *routePattern) match(parts []pathPart) (map[string]string, int, bool) {
+	if len(parts) < len(p.segments) {
+		return nil, 0, false
+	}
+
+	var params map[string]string
+	for i, seg := range p.segments {
+		text := parts[i].text
+		switch {
+		case seg.any:
+		case seg.param != "":
+			if text == "" {
+				return nil, 0, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[seg.param] = text
+		case seg.literal != text:
+			return nil, 0, false
+		}
+	}
+	return params, len(p.segments), true
+}
+
+// compareSpecificity 比较两条规则的具体程度，更具体的排在前面
+func compareSpecificity(a, b *routePattern) int {
+	if c := cmp.Compare(b.hostRank(), a.hostRank()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(len(b.segments), len(a.segments)); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(b.literals, a.literals); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.host, b.host); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.raw, b.raw)
+}
+
+// sortRoutes 按具体程度排序路由，匹配时取第一条命中的规则
+func sortRoutes(routes []*ServiceRoute) {
+	slices.SortFunc(routes, func(a, b *ServiceRoute) int {
+		return compareSpecificity(a.pattern, b.pattern)
+	})
+}
+
+// matchRoute 在已排序的路由中查找匹配请求的规则
+func matchRoute(routes []*ServiceRoute, host, path string) *routeMatch {
+	host = normalizeHost(host)
+	parts := splitPath(path)
+
+	for _, route := range routes {
+		if !route.pattern.matchHost(host) {
+			continue
+		}
+		params, n, ok := route.pattern.match(parts)
+		if !ok {
+			continue
+		}
+
+		m := &routeMatch{Route: route, Params: params, Prefix: "", Rest: path}
+		if n > 0 {
+			end := parts[n-1].end
+			m.Prefix, m.Rest = path[:end], path[end:]
+		}
+		if route.pattern.rest != "" {
+			if m.Params == nil {
+				m.Params = make(map[string]string)
+			}
+			m.Params[route.pattern.rest] = strings.TrimPrefix(m.Rest, "/")
+		}
+		return m
+	}
+	return nil
+}
+
+// pathPart 路径中的一段及其在原路径中的结束位置
+type pathPart struct {
+	text string
+	end  int
+}
+
+// splitPath 按 / 拆分路径（忽略开头和末尾的 /）
+func splitPath(path string) []pathPart {
+	var parts []pathPart
+	start := 0
+	for start < len(path) && path[start] == '/' {
+		start++
+	}
+	trimmed := strings.TrimRight(path, "/")
+	for start < len(trimmed) {
+		end := strings.IndexByte(trimmed[start:], '/')
+		if end < 0 {
+			end = len(trimmed)
+		} else {
+			end += start
+		}
+		parts = append(parts, pathPart{text: trimmed[start:end], end: end})
+		start = end + 1
+	}
+	return parts
+}
+
+// normalizeHost 去除端口并转为小写
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// expandParams 将 s 中的 {name} 替换为路径参数
+func expandParams(s string, params map[string]string) string {
+	if len(params) == 0 || !strings.Contains(s, "{") {
+		return s
+	}
+	for name, value := range params {
+		s = strings.ReplaceAll(s, "{"+name+"}", value)
+	}
+	return s
+}
+
+// trimTrailingWildcard 去除规则末尾的通配段（* 或 {name...}）
+func trimTrailingWildcard(pattern string) string {
+	i := strings.LastIndexByte(pattern, '/')
+	if i < 0 {
+		return pattern
+	}
+	last := pattern[i+1:]
+	if last == "*" || (strings.HasPrefix(last, "{") && strings.HasSuffix(last, "...}")) {
+		return pattern[:i]
+	}
+	return pattern
+}
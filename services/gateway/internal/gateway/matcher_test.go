@@ -0,0 +1,196 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+func newMatcherRoutes(t *testing.T, specs ...[2]string) []*ServiceRoute {
+	t.Helper()
+
+	routes := make([]*ServiceRoute, 0, len(specs))
+	for _, spec := range specs {
+		pattern, err := compilePattern(spec[0], spec[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		routes = append(routes, &ServiceRoute{
+			ServiceName: spec[0] + spec[1],
+			Host:        spec[0],
+			PathPrefix:  spec[1],
+			pattern:     pattern,
+		})
+	}
+	sortRoutes(routes)
+	return routes
+}
+
+func TestMatchRoute(t *testing.T) {
+	routes := newMatcherRoutes(t,
+		[2]string{"", "/api/v1/users"},
+		[2]string{"", "/api/v1/users/auth"},
+		[2]string{"", "/api/v1/{tenant}/orders/*"},
+		[2]string{"", "/api/v1/{tenant}/orders/{id}/items"},
+		[2]string{"", "/api/v1/files/{path...}"},
+		[2]string{"", "/api/v1/*/reports"},
+		[2]string{"", "/api/v1/acme/reports"},
+		[2]string{"admin.example.com", "/api/v1/users"},
+		[2]string{"*.example.com", "/api/v1/users"},
+	)
+
+	scenarios := []struct {
+		host           string
+		path           string
+		expectedRoute  string // Host + PathPrefix，空字符串表示不匹配
+		expectedRest   string
+		expectedParams map[string]string
+	}{
+		// 段边界
+		{"localhost", "/api/v1/users", "/api/v1/users", "", nil},
+		{"localhost", "/api/v1/users/", "/api/v1/users", "/", nil},
+		{"localhost", "/api/v1/users/1", "/api/v1/users", "/1", nil},
+		{"localhost", "/api/v1/user", "", "", nil},
+		{"localhost", "/api/v1/usersX/1", "", "", nil},
+
+		// 最长前缀
+		{"localhost", "/api/v1/users/auth", "/api/v1/users/auth", "", nil},
+		{"localhost", "/api/v1/users/auth/login", "/api/v1/users/auth", "/login", nil},
+		{"localhost", "/api/v1/users/authz", "/api/v1/users", "/authz", nil},
+
+		// 路径参数与通配符
+		{"localhost", "/api/v1/t1/orders", "/api/v1/{tenant}/orders/*", "", map[string]string{"tenant": "t1"}},
+		{"localhost", "/api/v1/t1/orders/42", "/api/v1/{tenant}/orders/*", "/42", map[string]string{"tenant": "t1"}},
+		{"localhost", "/api/v1/t1/orders/42/items/7", "/api/v1/{tenant}/orders/{id}/items", "/7", map[string]string{"tenant": "t1", "id": "42"}},
+		{"localhost", "/api/v1/files/a/b.txt", "/api/v1/files/{path...}", "/a/b.txt", map[string]string{"path": "a/b.txt"}},
+		{"localhost", "/api/v1/other/reports/1", "/api/v1/*/reports", "/1", nil},
+
+		// 字面量优先于通配
+		{"localhost", "/api/v1/acme/reports", "/api/v1/acme/reports", "", nil},
+
+		// 主机匹配
+		{"admin.example.com", "/api/v1/users/1", "admin.example.com/api/v1/users", "/1", nil},
+		{"ADMIN.example.com:8080", "/api/v1/users", "admin.example.com/api/v1/users", "", nil},
+		{"shop.example.com", "/api/v1/users", "*.example.com/api/v1/users", "", nil},
+		{"example.com", "/api/v1/users", "/api/v1/users", "", nil},
+		// 主机更具体的规则优先于更长的路径
+		{"shop.example.com", "/api/v1/users/auth", "*.example.com/api/v1/users", "/auth", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.host+s.path, func(t *testing.T) {
+			m := matchRoute(routes, s.host, s.path)
+
+			if s.expectedRoute == "" {
+				if m != nil {
+					t.Fatalf("Expected no match, got %s", m.Route.ServiceName)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatalf("Expected %s, got no match", s.expectedRoute)
+			}
+			if m.Route.ServiceName != s.expectedRoute {
+				t.Fatalf("Expected %s, got %s", s.expectedRoute, m.Route.ServiceName)
+			}
+			if m.Rest != s.expectedRest {
+				t.Fatalf("Expected rest %q, got %q", s.expectedRest, m.Rest)
+			}
+			if len(m.Params) != len(s.expectedParams) {
+				t.Fatalf("Expected params %v, got %v", s.expectedParams, m.Params)
+			}
+			for k, v := range s.expectedParams {
+				if m.Params[k] != v {
+					t.Fatalf("Expected params %v, got %v", s.expectedParams, m.Params)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchRouteDeterministic(t *testing.T) {
+	// 同等具体程度的规则按字典序选择，与注册顺序无关
+	for range 20 {
+		routes := newMatcherRoutes(t,
+			[2]string{"", "/api/v1/{b}/x"},
+			[2]string{"", "/api/v1/{a}/x"},
+		)
+		if m := matchRoute(routes, "", "/api/v1/1/x"); m.Route.PathPrefix != "/api/v1/{a}/x" {
+			t.Fatalf("Expected /api/v1/{a}/x, got %s", m.Route.PathPrefix)
+		}
+	}
+}
+
+func TestCompilePatternInvalid(t *testing.T) {
+	patterns := []string{
+		"api/v1",
+		"/api/{rest...}/x",
+		"/api/{}/x",
+		"/api/v{1}",
+		"/api/a*",
+	}
+
+	for _, p := range patterns {
+		if _, err := compilePattern("", p); err == nil {
+			t.Errorf("Expected %q to be rejected", p)
+		}
+	}
+}
+
+func TestRewritePath(t *testing.T) {
+	scenarios := []struct {
+		rest     string
+		target   string
+		expected string
+	}{
+		{"", "/", "/"},
+		{"/health", "/", "/health"},
+		{"/login", "/auth/login", "/auth/login/login"},
+		{"", "/auth/login", "/auth/login"},
+		{"/1", "", "/1"},
+		{"/", "/users", "/users/"},
+	}
+
+	for _, s := range scenarios {
+		if result := rewritePath(s.rest, s.target); result != s.expected {
+			t.Errorf("rewritePath(%q, %q): expected %q, got %q", s.rest, s.target, s.expected, result)
+		}
+	}
+}
+
+func TestGatewayPatternRoute(t *testing.T) {
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	var path string
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	})
+	registerBackend(t, reg, "order-service", "orders", backend)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	gw.RegisterRoute(&ServiceRoute{
+		ServiceName:  "order-service",
+		PathPrefix:   "/api/v1/{tenant}/orders/*",
+		TargetPrefix: "/tenants/{tenant}/orders",
+		StripPrefix:  true,
+		Methods:      pkgRegistry.DefaultMethods,
+	})
+
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/acme/orders/42", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	if path != "/tenants/acme/orders/42" {
+		t.Fatalf("Expected backend path /tenants/acme/orders/42, got %s", path)
+	}
+
+	// 相似前缀不会误匹配
+	resp, _ = doRequest(t, http.MethodGet, ts.URL+"/api/v1/order/1", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/goback/pkg/metrics"
+)
+
+var (
+	upstreamRequestsTotal = metrics.NewCounterVec("gateway_upstream_requests_total",
+		"Total number of requests proxied to upstream nodes by status (error when no response).", "service", "node", "status")
+	upstreamRequestDuration = metrics.NewHistogramVec("gateway_upstream_request_duration_seconds",
+		"Upstream response latency by node.", nil, "service", "node")
+	upstreamInFlight = metrics.NewGaugeVec("gateway_upstream_in_flight",
+		"Number of requests currently proxied to each upstream node.", "service", "node")
+	upstreamRetriesTotal = metrics.NewCounterVec("gateway_upstream_retries_total",
+		"Total number of retried upstream requests.", "service")
+	upstreamBreakerState = metrics.NewGaugeVec("gateway_upstream_breaker_state",
+		"Circuit breaker state of each upstream node (0 closed, 1 open, 2 half-open).", "node")
+)
+
+func init() {
+	metrics.MustRegister(
+		upstreamRequestsTotal,
+		upstreamRequestDuration,
+		upstreamInFlight,
+		upstreamRetriesTotal,
+		upstreamBreakerState,
+	)
+}
+
+// recordUpstream 记录一次上游转发，status 为 0 表示未收到响应
+func recordUpstream(service, node string, status int, elapsed time.Duration) {
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	upstreamRequestsTotal.With(service, node, code).Inc()
+	upstreamRequestDuration.With(service, node).Observe(elapsed.Seconds())
+}
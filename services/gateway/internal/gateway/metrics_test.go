@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/metrics"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// scrape 读取默认注册表中以 prefix 开头且包含 service 标签的样本行
+func scrape(t *testing.T, prefix, service string) []string {
+	t.Helper()
+
+	var sb strings.Builder
+	if err := metrics.Default.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if strings.HasPrefix(line, prefix) && strings.Contains(line, `service="`+service+`"`) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestGatewayUpstreamMetrics(t *testing.T) {
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	registerBackend(t, reg, "metrics-service", "metrics", backend, pkgRegistry.NewPublicRoutes("metrics", "/ok", "/missing")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/metrics/ok", nil)
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/metrics/ok", nil)
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/metrics/missing", nil)
+
+	requests := strings.Join(scrape(t, "gateway_upstream_requests_total", "metrics-service"), "\n")
+	if !strings.Contains(requests, `status="200"} 2`) || !strings.Contains(requests, `status="404"} 1`) {
+		t.Fatalf("Unexpected upstream request counts:\n%s", requests)
+	}
+
+	latency := strings.Join(scrape(t, "gateway_upstream_request_duration_seconds_count", "metrics-service"), "\n")
+	if !strings.HasSuffix(latency, " 3") {
+		t.Fatalf("Expected 3 latency observations, got:\n%s", latency)
+	}
+
+	inFlight := strings.Join(scrape(t, "gateway_upstream_in_flight", "metrics-service"), "\n")
+	if !strings.HasSuffix(inFlight, " 0") {
+		t.Fatalf("Expected no in-flight requests after completion, got:\n%s", inFlight)
+	}
+
+	// 上游不可达时记录为 error
+	backend.Close()
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/metrics/ok", nil)
+	requests = strings.Join(scrape(t, "gateway_upstream_requests_total", "metrics-service"), "\n")
+	if !strings.Contains(requests, `status="error"} 1`) {
+		t.Fatalf("Expected failed upstream request to be counted, got:\n%s", requests)
+	}
+}
+
+func TestGatewayDropsRemovedNodeState(t *testing.T) {
+	gw := NewGateway(pkgRegistry.NewMemoryRegistry(), newTestConfig())
+
+	nodes := []*registry.Node{{Id: "prune-1"}, {Id: "prune-2"}}
+	gw.handleServiceEvent(&registry.Result{Action: "create", Service: &registry.Service{Name: "prune-service", Nodes: nodes}})
+	for _, node := range nodes {
+		gw.breakers.Get(node.Id).Failure()
+		gw.inflight.Acquire(node.Id)
+		recordUpstream("prune-service", node.Id, http.StatusOK, time.Millisecond)
+		upstreamInFlight.With("prune-service", node.Id).Inc()
+		upstreamBreakerState.With(node.Id).Set(float64(StateClosed))
+	}
+
+	// 更新事件中不再包含的节点应被清理，保留的节点不受影响
+	gw.handleServiceEvent(&registry.Result{Action: "update", Service: &registry.Service{Name: "prune-service", Nodes: nodes[1:]}})
+
+	if _, ok := gw.breakers.breakers.Load("prune-1"); ok {
+		t.Fatal("Expected breaker of removed node to be dropped")
+	}
+	if _, ok := gw.inflight.counts.Load("prune-1"); ok {
+		t.Fatal("Expected in-flight counter of removed node to be dropped")
+	}
+	if gw.breakers.Get("prune-2").Failures() != 1 || gw.inflight.Count("prune-2") != 1 {
+		t.Fatal("Expected remaining node to keep its state")
+	}
+
+	var sb strings.Builder
+	if err := metrics.Default.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), `node="prune-1"`) {
+		t.Fatalf("Expected metrics of removed node to be dropped, got:\n%s", sb.String())
+	}
+	if !strings.Contains(sb.String(), `gateway_upstream_breaker_state{node="prune-2"}`) {
+		t.Fatalf("Expected metrics of remaining node to be kept, got:\n%s", sb.String())
+	}
+
+	// 服务注销后清理所有节点
+	gw.handleServiceEvent(&registry.Result{Action: "delete", Service: &registry.Service{Name: "prune-service", Nodes: nodes[1:]}})
+	if _, ok := gw.breakers.breakers.Load("prune-2"); ok {
+		t.Fatal("Expected breaker of deleted service to be dropped")
+	}
+}
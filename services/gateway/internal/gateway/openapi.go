@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/logger"
+	"github.com/goback/pkg/openapi"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+	"go.uber.org/zap"
+)
+
+// openAPIFetchTimeout 拉取单个服务文档的超时时间
+const openAPIFetchTimeout = 3 * time.Second
+
+//go:embed docs.html
+var docsPage string
+
+// serviceSpec 服务的 OpenAPI 文档
+type serviceSpec struct {
+	name     string
+	basePath string
+	doc      *openapi.Document
+}
+
+// RegisterDocRoutes 注册聚合文档接口
+//
+//	GET /openapi.json  各服务文档按 /api/v1/{basePath} 前缀合并后的 OpenAPI 文档
+//	GET /docs          基于聚合文档的接口文档页面
+func (g *Gateway) RegisterDocRoutes(r *router.Router[*core.RequestEvent]) {
+	r.GET(core.OpenAPIPath, g.handleOpenAPI).Hide()
+	r.GET("/docs", g.handleDocs).Hide()
+}
+
+// handleOpenAPI 返回聚合的 OpenAPI 文档
+func (g *Gateway) handleOpenAPI(e *core.RequestEvent) error {
+	doc, err := g.OpenAPI(e.Request.Context())
+	if err != nil {
+		return apis.Error(e, 500, "获取服务列表失败")
+	}
+	return e.JSON(http.StatusOK, doc)
+}
+
+// handleDocs 返回接口文档页面
+func (g *Gateway) handleDocs(e *core.RequestEvent) error {
+	return e.HTML(http.StatusOK, docsPage)
+}
+
+// OpenAPI 拉取注册中心中各服务的 OpenAPI 文档，按网关路径 /api/v1/{basePath} 合并为一份文档
+// 未设置 basePath 的服务不经网关统一路由访问，不纳入文档；拉取失败的服务跳过并记录日志
+func (g *Gateway) OpenAPI(ctx context.Context) (*openapi.Document, error) {
+	services, err := g.registry.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]*serviceSpec, len(services))
+	var wg sync.WaitGroup
+	for i, svc := range services {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			specs[i] = g.fetchServiceSpec(ctx, svc.Name)
+		}()
+	}
+	wg.Wait()
+
+	specs = slices.DeleteFunc(specs, func(s *serviceSpec) bool { return s == nil })
+	slices.SortFunc(specs, func(a, b *serviceSpec) int { return strings.Compare(a.name, b.name) })
+
+	title := "API"
+	if g.config.App.Name != "" {
+		title = g.config.App.Name + " API"
+	}
+	doc := openapi.NewDocument(title, g.config.App.Version)
+	for _, spec := range specs {
+		doc.Merge(spec.doc, pkgRegistry.GatewayPath(spec.basePath, ""), spec.name+".")
+	}
+	return doc, nil
+}
+
+// fetchServiceSpec 从服务的健康节点拉取文档，服务未设置 basePath 或所有节点均失败时返回 nil
+func (g *Gateway) fetchServiceSpec(ctx context.Context, name string) *serviceSpec {
+	details, err := g.registry.GetService(name)
+	if err != nil {
+		logger.Warn("获取服务详情失败", zap.String("service", name), zap.Error(err))
+		return nil
+	}
+
+	var basePath string
+	var nodes []*registry.Node
+	for _, svc := range details {
+		if bp, _ := pkgRegistry.ParseServiceMeta(svc); bp != "" {
+			basePath = bp
+		}
+		nodes = append(nodes, svc.Nodes...)
+	}
+	if basePath == "" {
+		return nil
+	}
+
+	for _, node := range g.health.Filter(nodes) {
+		doc, err := g.fetchNodeSpec(ctx, node)
+		if err == nil {
+			return &serviceSpec{name: name, basePath: basePath, doc: doc}
+		}
+		logger.Warn("拉取服务文档失败",
+			zap.String("service", name),
+			zap.String("node", node.Id),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// fetchNodeSpec 从节点拉取文档
+func (g *Gateway) fetchNodeSpec(ctx context.Context, node *registry.Node) (*openapi.Document, error) {
+	ctx, cancel := context.WithTimeout(ctx, openAPIFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+node.Address+core.OpenAPIPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	var doc openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/openapi"
+	"go-micro.dev/v5/registry"
+)
+
+// specRequest 测试文档使用的请求结构
+type specRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// newSpecBackend 返回提供 OpenAPI 文档的后端
+func newSpecBackend(t *testing.T, title string, routes ...openapi.Route) *httptest.Server {
+	t.Helper()
+
+	doc := openapi.NewDocument(title, "v1.0.0")
+	for _, r := range routes {
+		doc.AddRoute(r)
+	}
+	return newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != core.OpenAPIPath {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(doc)
+	})
+}
+
+func TestGatewayOpenAPI(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	_, ts := newTestGateway(t, reg)
+
+	users := newSpecBackend(t, "user-service",
+		openapi.Route{Method: "POST", Path: "/users", Request: specRequest{}},
+		openapi.Route{Method: "GET", Path: "/health", Public: true},
+	)
+	dicts := newSpecBackend(t, "dict-service",
+		openapi.Route{Method: "PUT", Path: "/dicts/dict-types/{id}", Request: specRequest{}},
+	)
+	registerBackend(t, reg, "user-service", "users", users)
+	registerBackend(t, reg, "dict-service", "dicts", dicts)
+	// 未设置 basePath 的服务与无法访问的服务均跳过
+	registerBackend(t, reg, "internal-service", "", newTestBackend(t, nil))
+	registerBackend(t, reg, "broken-service", "broken", newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/openapi.json", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Paths) != 3 {
+		t.Fatalf("Expected 3 merged paths, got %v", doc.Paths)
+	}
+	create := doc.Paths["/api/v1/users/users"]
+	if create == nil || create.Post == nil {
+		t.Fatalf("Expected user routes under /api/v1/users, got %v", doc.Paths)
+	}
+	if ref := create.Post.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/user-service.gateway.specRequest" {
+		t.Fatalf("Expected service-scoped schema ref, got %q", ref)
+	}
+	if doc.Paths["/api/v1/dicts/dicts/dict-types/{id}"] == nil {
+		t.Fatalf("Expected dict routes under /api/v1/dicts, got %v", doc.Paths)
+	}
+	if _, ok := doc.Components.Schemas["dict-service.gateway.specRequest"]; !ok {
+		t.Fatalf("Expected schemas of both services, got %v", doc.Components.Schemas)
+	}
+
+	resp, body = doRequest(t, http.MethodGet, ts.URL+"/docs", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "text/html") ||
+		!strings.Contains(body, "/openapi.json") {
+		t.Fatalf("Expected docs page, got %d (%s)", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
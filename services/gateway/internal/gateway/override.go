@@ -0,0 +1,312 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goback/pkg/dal"
+	"github.com/goback/pkg/logger"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/services/gateway/internal/model"
+	"go.uber.org/zap"
+)
+
+// 路由来源
+const (
+	RouteSourceRegistry = "registry" // 服务元数据
+	RouteSourceOverride = "override" // 管理接口覆盖
+)
+
+// ErrDatabaseUnavailable 数据库未初始化
+var ErrDatabaseUnavailable = errors.New("database is not initialized")
+
+// RouteStore 路由覆盖配置的持久化存储
+type RouteStore interface {
+	ListRoutes() ([]model.GatewayRoute, error)
+	// SaveRoute 按 Host + PathPrefix 新增或更新覆盖配置，返回保存后的记录
+	SaveRoute(route *model.GatewayRoute) (*model.GatewayRoute, error)
+	// DeleteRoute 删除覆盖配置，不存在时返回 false
+	DeleteRoute(id int64) (bool, error)
+}
+
+// dbRouteStore 基于数据库的路由覆盖配置存储
+type dbRouteStore struct{}
+
+// NewDBRouteStore 创建基于数据库（dal 全局实例）的路由覆盖配置存储
+func NewDBRouteStore() RouteStore {
+	return dbRouteStore{}
+}
+
+// ListRoutes 实现 RouteStore
+func (dbRouteStore) ListRoutes() ([]model.GatewayRoute, error) {
+	if dal.GetDB() == nil {
+		return nil, ErrDatabaseUnavailable
+	}
+	return model.GatewayRoutes.ListAll()
+}
+
+// SaveRoute 实现 RouteStore
+func (dbRouteStore) SaveRoute(route *model.GatewayRoute) (*model.GatewayRoute, error) {
+	if dal.GetDB() == nil {
+		return nil, ErrDatabaseUnavailable
+	}
+
+	existing, err := model.GatewayRoutes.GetByKey(route.Host, route.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		route.ID = existing.ID
+		route.CreatedAt = existing.CreatedAt
+		route.CreateBy = existing.CreateBy
+	}
+	if err := model.GatewayRoutes.Save(route); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// DeleteRoute 实现 RouteStore
+func (dbRouteStore) DeleteRoute(id int64) (bool, error) {
+	if dal.GetDB() == nil {
+		return false, ErrDatabaseUnavailable
+	}
+	n, err := model.GatewayRoutes.HardDeleteByID(id)
+	return n > 0, err
+}
+
+// RouteInfo 生效路由（管理接口展示用）
+type RouteInfo struct {
+	ServiceName  string                       `json:"serviceName"`
+	Host         string                       `json:"host,omitempty"`
+	PathPrefix   string                       `json:"pathPrefix"`
+	TargetPrefix string                       `json:"targetPrefix"`
+	StripPrefix  bool                         `json:"stripPrefix"`
+	Methods      []string                     `json:"methods"`
+	AuthRequired bool                         `json:"authRequired"`
+	LoadBalance  string                       `json:"loadBalance"`
+	HashKey      string                       `json:"hashKey,omitempty"`
+	Idempotent   bool                         `json:"idempotent"`
+	RateLimit    *pkgRegistry.RateLimitConfig `json:"rateLimit,omitempty"`
+	Traffic      *pkgRegistry.TrafficSplit    `json:"traffic,omitempty"`
+	Upstream     string                       `json:"upstream,omitempty"`
+	Transform    *pkgRegistry.TransformConfig `json:"transform,omitempty"`
+	Source       string                       `json:"source"` // registry、static 或 override
+	OverrideID   int64                        `json:"overrideId,omitempty"`
+}
+
+// RouteOverrideRequest 新增或更新路由覆盖配置请求，按 Host + PathPrefix 定位
+type RouteOverrideRequest struct {
+	Host         string                       `json:"host"`
+	PathPrefix   string                       `json:"pathPrefix" binding:"required"`
+	ServiceName  string                       `json:"serviceName"` // 禁用路由时可为空
+	TargetPrefix string                       `json:"targetPrefix"`
+	StripPrefix  bool                         `json:"stripPrefix"`
+	Methods      []string                     `json:"methods"` // 为空时允许所有常用方法
+	AuthRequired bool                         `json:"authRequired"`
+	LoadBalance  string                       `json:"loadBalance"`
+	HashKey      string                       `json:"hashKey"`
+	Idempotent   bool                         `json:"idempotent"`
+	RateLimit    *pkgRegistry.RateLimitConfig `json:"rateLimit"`
+	Traffic      *pkgRegistry.TrafficSplit    `json:"traffic"`
+	Transform    *pkgRegistry.TransformConfig `json:"transform"`
+	Disabled     bool                         `json:"disabled"`
+	Remark       string                       `json:"remark"`
+}
+
+// toModel 转换为路由覆盖配置模型
+func (r *RouteOverrideRequest) toModel() *model.GatewayRoute {
+	return &model.GatewayRoute{
+		Host:         r.Host,
+		PathPrefix:   r.PathPrefix,
+		ServiceName:  r.ServiceName,
+		TargetPrefix: r.TargetPrefix,
+		StripPrefix:  r.StripPrefix,
+		Methods:      r.Methods,
+		AuthRequired: r.AuthRequired,
+		LoadBalance:  r.LoadBalance,
+		HashKey:      r.HashKey,
+		Idempotent:   r.Idempotent,
+		RateLimit:    r.RateLimit,
+		Traffic:      r.Traffic,
+		Transform:    r.Transform,
+		Disabled:     r.Disabled,
+		Remark:       r.Remark,
+	}
+}
+
+// routeFromOverride 将覆盖配置转换为服务路由
+func routeFromOverride(o *model.GatewayRoute) *ServiceRoute {
+	return &ServiceRoute{
+		ServiceName:  o.ServiceName,
+		Host:         o.Host,
+		PathPrefix:   o.PathPrefix,
+		TargetPrefix: o.TargetPrefix,
+		StripPrefix:  o.StripPrefix,
+		Methods:      o.Methods,
+		AuthRequired: o.AuthRequired,
+		LoadBalance:  o.LoadBalance,
+		HashKey:      o.HashKey,
+		Idempotent:   o.Idempotent,
+		RateLimit:    o.RateLimit,
+		Traffic:      o.Traffic,
+		Transform:    o.Transform,
+		source:       RouteSourceOverride,
+		overrideID:   o.ID,
+		disabled:     o.Disabled,
+	}
+}
+
+// normalizeOverride 校验覆盖配置并补全默认值
+func normalizeOverride(o *model.GatewayRoute) error {
+	o.Host = strings.ToLower(strings.TrimSpace(o.Host))
+	if _, err := compilePattern(o.Host, o.PathPrefix); err != nil {
+		return err
+	}
+	if o.Disabled {
+		return nil
+	}
+
+	if o.ServiceName == "" {
+		return errors.New("serviceName is required")
+	}
+	if len(o.Methods) == 0 {
+		o.Methods = slices.Clone(pkgRegistry.DefaultMethods)
+	}
+	for i, m := range o.Methods {
+		o.Methods[i] = strings.ToUpper(m)
+	}
+	if o.StripPrefix && o.TargetPrefix == "" {
+		o.TargetPrefix = "/"
+	}
+	if o.RateLimit != nil && (o.RateLimit.Limit < 0 || o.RateLimit.Window < 0) {
+		return errors.New("rateLimit must not be negative")
+	}
+	if o.Traffic != nil {
+		if err := validateTrafficSplit(o.Traffic); err != nil {
+			return fmt.Errorf("traffic: %w", err)
+		}
+	}
+	if _, err := compileTransform(o.Transform); err != nil {
+		return fmt.Errorf("transform: %w", err)
+	}
+	return nil
+}
+
+// SaveOverride 持久化并应用路由覆盖配置
+func (g *Gateway) SaveOverride(o *model.GatewayRoute) (*model.GatewayRoute, error) {
+	if err := normalizeOverride(o); err != nil {
+		return nil, err
+	}
+	saved, err := g.routeStore.SaveRoute(o)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	route := routeFromOverride(saved)
+	if err := g.prepareRouteLocked(route, g.overrides); err != nil {
+		return nil, err
+	}
+	g.overrides[routeKey(route.Host, route.PathPrefix)] = route
+	g.rebuildLocked()
+
+	logger.Info("保存路由覆盖",
+		zap.Int64("id", saved.ID),
+		zap.String("service", saved.ServiceName),
+		zap.String("host", saved.Host),
+		zap.String("gateway_path", saved.PathPrefix),
+		zap.Bool("disabled", saved.Disabled),
+	)
+	return saved, nil
+}
+
+// DeleteOverride 删除路由覆盖配置，恢复注册中心下发的路由
+func (g *Gateway) DeleteOverride(id int64) (bool, error) {
+	ok, err := g.routeStore.DeleteRoute(id)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, route := range g.overrides {
+		if route.overrideID == id {
+			delete(g.overrides, key)
+		}
+	}
+	g.rebuildLocked()
+
+	logger.Info("删除路由覆盖", zap.Int64("id", id))
+	return true, nil
+}
+
+// ListOverrides 获取所有路由覆盖配置（包括已禁用的）
+func (g *Gateway) ListOverrides() ([]model.GatewayRoute, error) {
+	return g.routeStore.ListRoutes()
+}
+
+// SyncOverrides 从存储重新加载路由覆盖配置（同步其他网关副本的修改）
+func (g *Gateway) SyncOverrides() error {
+	rows, err := g.routeStore.ListRoutes()
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	overrides := make(map[string]*ServiceRoute, len(rows))
+	for i := range rows {
+		route := routeFromOverride(&rows[i])
+		if err := g.prepareRouteLocked(route, g.overrides); err != nil {
+			logger.Error("路由覆盖规则无效",
+				zap.Int64("id", rows[i].ID),
+				zap.String("gateway_path", rows[i].PathPrefix),
+				zap.Error(err),
+			)
+			continue
+		}
+		overrides[routeKey(route.Host, route.PathPrefix)] = route
+	}
+	g.overrides = overrides
+	g.rebuildLocked()
+	return nil
+}
+
+// ListRoutes 获取当前生效的路由（覆盖配置合并在注册中心路由之上），按匹配优先级排序
+func (g *Gateway) ListRoutes() []RouteInfo {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	routes := make([]RouteInfo, 0, len(g.sorted))
+	for _, r := range g.sorted {
+		source := r.source
+		if source == "" {
+			source = RouteSourceRegistry
+		}
+		routes = append(routes, RouteInfo{
+			ServiceName:  r.ServiceName,
+			Host:         r.Host,
+			PathPrefix:   r.PathPrefix,
+			TargetPrefix: r.TargetPrefix,
+			StripPrefix:  r.StripPrefix,
+			Methods:      slices.Clone(r.Methods),
+			AuthRequired: r.AuthRequired,
+			LoadBalance:  r.balancer.Name(),
+			HashKey:      r.HashKey,
+			Idempotent:   r.Idempotent,
+			RateLimit:    r.RateLimit,
+			Traffic:      r.Traffic,
+			Upstream:     r.Upstream,
+			Transform:    r.Transform,
+			Source:       source,
+			OverrideID:   r.overrideID,
+		})
+	}
+	return routes
+}
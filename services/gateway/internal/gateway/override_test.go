@@ -0,0 +1,271 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/goback/pkg/dal"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/services/gateway/internal/model"
+	"go-micro.dev/v5/registry"
+	"gorm.io/gorm"
+)
+
+// memoryRouteStore 进程内路由覆盖配置存储，模拟共享数据库
+type memoryRouteStore struct {
+	mu     sync.Mutex
+	nextID int64
+	routes []model.GatewayRoute
+}
+
+func (s *memoryRouteStore) ListRoutes() ([]model.GatewayRoute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.GatewayRoute(nil), s.routes...), nil
+}
+
+func (s *memoryRouteStore) SaveRoute(route *model.GatewayRoute) (*model.GatewayRoute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.routes {
+		if s.routes[i].Host == route.Host && s.routes[i].PathPrefix == route.PathPrefix {
+			route.ID = s.routes[i].ID
+			s.routes[i] = *route
+			return route, nil
+		}
+	}
+	s.nextID++
+	route.ID = s.nextID
+	s.routes = append(s.routes, *route)
+	return route, nil
+}
+
+func (s *memoryRouteStore) DeleteRoute(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.routes {
+		if s.routes[i].ID == id {
+			s.routes = append(s.routes[:i], s.routes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newOverrideGateway 创建注册了 user-service 的网关，路由覆盖配置存储在 store 中
+func newOverrideGateway(t *testing.T, store RouteStore) (*Gateway, string) {
+	t.Helper()
+
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+	WithRouteStore(store)(gw)
+
+	registerBackend(t, reg, "user-service", "users", newTestBackend(t, nil))
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+	return gw, ts.URL
+}
+
+// adminRequest 以管理员身份调用网关管理接口
+func adminRequest(t *testing.T, gw *Gateway, method, url, payload string) (*http.Response, string) {
+	t.Helper()
+
+	token, err := gw.jwt.GenerateToken(1, "root", 1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + token,
+		"Content-Type":  "application/json",
+	}
+	return doRequestWithBody(t, method, url, strings.NewReader(payload), headers)
+}
+
+// saveOverride 调用管理接口保存路由覆盖配置，返回配置ID
+func saveOverride(t *testing.T, gw *Gateway, url, payload string) int64 {
+	t.Helper()
+
+	resp, body := adminRequest(t, gw, http.MethodPut, url+"/admin/routes/overrides", payload)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	var result struct {
+		Data model.GatewayRoute `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result.Data.ID
+}
+
+func TestGatewayRouteOverrides(t *testing.T) {
+	gw, url := newOverrideGateway(t, &memoryRouteStore{})
+
+	// 注册中心路由默认需要认证
+	resp, body := doRequest(t, http.MethodGet, url+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusUnauthorized)
+
+	// 覆盖为公开路由
+	id := saveOverride(t, gw, url, `{
+		"pathPrefix": "/api/v1/users",
+		"serviceName": "user-service",
+		"targetPrefix": "/",
+		"stripPrefix": true
+	}`)
+	resp, body = doRequest(t, http.MethodGet, url+"/api/v1/users/profile", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"path":"/profile"`) {
+		t.Fatalf("Expected override to make the route public, got %d (%s)", resp.StatusCode, body)
+	}
+
+	// 新增注册中心中不存在的路由
+	saveOverride(t, gw, url, `{
+		"pathPrefix": "/api/v1/people/*",
+		"serviceName": "user-service",
+		"targetPrefix": "/users",
+		"stripPrefix": true,
+		"methods": ["get"]
+	}`)
+	resp, body = doRequest(t, http.MethodGet, url+"/api/v1/people/42", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"path":"/users/42"`) {
+		t.Fatalf("Expected added route to be served, got %d (%s)", resp.StatusCode, body)
+	}
+	resp, body = doRequest(t, http.MethodPost, url+"/api/v1/people/42", nil)
+	assertErrorResponse(t, resp, body, http.StatusMethodNotAllowed)
+
+	resp, body = adminRequest(t, gw, http.MethodGet, url+"/admin/routes", "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"source":"override"`) {
+		t.Fatalf("Expected effective routes to include overrides, got %d (%s)", resp.StatusCode, body)
+	}
+
+	// 删除覆盖后恢复注册中心路由
+	resp, body = adminRequest(t, gw, http.MethodDelete, url+"/admin/routes/overrides/"+strconv.FormatInt(id, 10), "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	resp, body = doRequest(t, http.MethodGet, url+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusUnauthorized)
+
+	resp, body = adminRequest(t, gw, http.MethodDelete, url+"/admin/routes/overrides/"+strconv.FormatInt(id, 10), "")
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+}
+
+func TestGatewayRouteOverrideDisable(t *testing.T) {
+	gw, url := newOverrideGateway(t, &memoryRouteStore{})
+
+	saveOverride(t, gw, url, `{"pathPrefix": "/api/v1/users", "disabled": true}`)
+	resp, body := doRequest(t, http.MethodGet, url+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+
+	// 注册中心重新下发路由时仍保持禁用
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+	resp, body = doRequest(t, http.MethodGet, url+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+
+	for _, r := range gw.ListRoutes() {
+		if r.PathPrefix == "/api/v1/users" {
+			t.Fatalf("Expected disabled route to be hidden, got %+v", r)
+		}
+	}
+}
+
+func TestGatewayRouteOverrideValidation(t *testing.T) {
+	gw, url := newOverrideGateway(t, &memoryRouteStore{})
+
+	for _, payload := range []string{
+		`{"pathPrefix": "api/v1/users", "serviceName": "user-service"}`,
+		`{"pathPrefix": "/api/v1/users"}`,
+		`{"pathPrefix": "/api/v1/users", "serviceName": "user-service", "traffic": {"weights": {"v1": 0}}}`,
+	} {
+		resp, body := adminRequest(t, gw, http.MethodPut, url+"/admin/routes/overrides", payload)
+		assertErrorResponse(t, resp, body, http.StatusBadRequest)
+	}
+
+	resp, body := doRequestWithBody(t, http.MethodPut, url+"/admin/routes/overrides",
+		strings.NewReader(`{"pathPrefix": "/api/v1/users", "disabled": true}`), nil)
+	assertErrorResponse(t, resp, body, http.StatusUnauthorized)
+}
+
+func TestGatewayRouteOverrideSync(t *testing.T) {
+	store := &memoryRouteStore{}
+	gw1, url1 := newOverrideGateway(t, store)
+	gw2, url2 := newOverrideGateway(t, store)
+
+	saveOverride(t, gw1, url1, `{"pathPrefix": "/api/v1/users", "disabled": true}`)
+
+	resp, _ := doRequest(t, http.MethodGet, url2+"/api/v1/users/profile", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected replica to serve the registry route before sync, got %d", resp.StatusCode)
+	}
+	if err := gw2.SyncOverrides(); err != nil {
+		t.Fatal(err)
+	}
+	resp, body := doRequest(t, http.MethodGet, url2+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+}
+
+func TestDBRouteStore(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.GatewayRoute{}); err != nil {
+		t.Fatal(err)
+	}
+	prev := dal.GetDB()
+	dal.SetDB(db)
+	t.Cleanup(func() { dal.SetDB(prev) })
+
+	store := NewDBRouteStore()
+	first, err := store.SaveRoute(&model.GatewayRoute{
+		PathPrefix:  "/api/v1/users",
+		ServiceName: "user-service",
+		Methods:     []string{"GET"},
+		RateLimit:   &pkgRegistry.RateLimitConfig{Limit: 10, Window: 60},
+		CreateBy:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一主机与路径规则更新已有记录
+	second, err := store.SaveRoute(&model.GatewayRoute{
+		PathPrefix: "/api/v1/users",
+		Disabled:   true,
+		CreateBy:   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || second.CreateBy != 1 {
+		t.Fatalf("Expected upsert of record %d, got %+v", first.ID, second)
+	}
+
+	routes, err := store.ListRoutes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || !routes[0].Disabled {
+		t.Fatalf("Expected one disabled route, got %+v", routes)
+	}
+
+	// 物理删除后可以重新创建
+	if ok, err := store.DeleteRoute(first.ID); err != nil || !ok {
+		t.Fatalf("Expected delete to succeed, got %v, %v", ok, err)
+	}
+	if ok, _ := store.DeleteRoute(first.ID); ok {
+		t.Fatal("Expected second delete to report missing record")
+	}
+	if _, err := store.SaveRoute(&model.GatewayRoute{PathPrefix: "/api/v1/users", ServiceName: "user-service"}); err != nil {
+		t.Fatal(err)
+	}
+}
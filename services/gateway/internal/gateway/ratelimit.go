@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+// rateLimitKeyPrefix 限流计数在缓存中的键前缀
+const rateLimitKeyPrefix = "gateway:ratelimit:"
+
+// 限流响应头（IETF RateLimit header fields）
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// Counter 带过期时间的原子计数器，*cache.Cache 实现了该接口
+// 多个网关副本共享同一计数器时限流全局生效
+type Counter interface {
+	// IncrBy 原子地增加计数，键新建时设置过期时间，返回增加后的值与剩余过期时间
+	IncrBy(key string, delta int64, expiration time.Duration) (int64, time.Duration, error)
+}
+
+// RateLimiter 网关限流器（固定窗口）
+// 窗口从某个键的第一个请求开始计时，窗口结束后计数随缓存过期自动清除
+type RateLimiter struct {
+	counter Counter
+}
+
+// NewRateLimiter 创建网关限流器
+func NewRateLimiter(counter Counter) *RateLimiter {
+	return &RateLimiter{counter: counter}
+}
+
+// RateLimitResult 限流检查结果
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // 距离窗口重置的时间
+	Window    time.Duration
+}
+
+// RateLimitBucket 限流计数桶
+type RateLimitBucket struct {
+	Key   string
+	Limit int // 每个窗口允许的请求数
+}
+
+// Allow 为 key 计数一次并检查是否超出限制
+func (l *RateLimiter) Allow(key string, cfg *pkgRegistry.RateLimitConfig) (RateLimitResult, error) {
+	return l.allow(RateLimitBucket{Key: key, Limit: cfg.Limit}, time.Duration(cfg.Window)*time.Second)
+}
+
+func (l *RateLimiter) allow(bucket RateLimitBucket, window time.Duration) (RateLimitResult, error) {
+	count, ttl, err := l.counter.IncrBy(rateLimitKeyPrefix+bucket.Key, 1, window)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	if ttl <= 0 || ttl > window {
+		ttl = window
+	}
+
+	return RateLimitResult{
+		Allowed:   count <= int64(bucket.Limit),
+		Limit:     bucket.Limit,
+		Remaining: int(max(int64(bucket.Limit)-count, 0)),
+		Reset:     ttl,
+		Window:    window,
+	}, nil
+}
+
+// AllowAll 依次为每个桶计数，任一桶超出限制即拒绝并停止计数（被拒绝的请求不再消耗之后的桶），
+// 全部通过时返回剩余额度最少的结果
+func (l *RateLimiter) AllowAll(buckets []RateLimitBucket, cfg *pkgRegistry.RateLimitConfig) (RateLimitResult, error) {
+	window := time.Duration(cfg.Window) * time.Second
+
+	var result RateLimitResult
+	for i, bucket := range buckets {
+		r, err := l.allow(bucket, window)
+		if err != nil {
+			return RateLimitResult{}, err
+		}
+		if !r.Allowed {
+			return r, nil
+		}
+		if i == 0 || r.Remaining < result.Remaining {
+			result = r
+		}
+	}
+	return result, nil
+}
+
+// WriteHeaders 写入 RateLimit-* 响应头，被限流时同时写入 Retry-After
+func (r RateLimitResult) WriteHeaders(h http.Header) {
+	reset := strconv.Itoa(int(math.Ceil(r.Reset.Seconds())))
+	h.Set(HeaderRateLimitLimit, strconv.Itoa(r.Limit))
+	h.Set(HeaderRateLimitRemaining, strconv.Itoa(r.Remaining))
+	h.Set(HeaderRateLimitReset, reset)
+	h.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", r.Limit, int(r.Window.Seconds())))
+	if !r.Allowed {
+		h.Set("Retry-After", reset)
+	}
+}
+
+// rateLimitEnabled 路由是否配置了有效的限流
+func rateLimitEnabled(cfg *pkgRegistry.RateLimitConfig) bool {
+	return cfg != nil && cfg.Limit > 0 && cfg.Window > 0
+}
+
+// rateLimitBuckets 计算请求的限流桶：路由 + 客户端标识，ip 为可信代理解析后的客户端IP
+// 网关无法校验 API Key，随机的 API Key 各自计数会绕过限流，因此按 API Key 计数时另设同一IP的总限额
+// （单个 Key 限额的 IPMultiplier 倍），同一出口IP后的多个 Key 仍各自享有额度；
+// IP 总限额先计数，被其拒绝的请求不消耗 Key 的额度；API Key 只保存摘要，避免明文出现在缓存中
+func rateLimitBuckets(route *ServiceRoute, r *http.Request, claims *core.JWTClaims, ip string) []RateLimitBucket {
+	cfg := route.RateLimit
+	prefix := routeKey(route.Host, route.PathPrefix) + ":"
+
+	ipBucket := RateLimitBucket{Key: prefix + "ip:" + ip, Limit: cfg.Limit}
+	switch cfg.KeyBy {
+	case pkgRegistry.RateLimitKeyUser:
+		if claims != nil {
+			return []RateLimitBucket{{Key: prefix + "user:" + strconv.FormatInt(claims.UserID, 10), Limit: cfg.Limit}}
+		}
+	case pkgRegistry.RateLimitKeyAPIKey:
+		header := cfg.APIKeyHeader
+		if header == "" {
+			header = pkgRegistry.DefaultAPIKeyHeader
+		}
+		if apiKey := r.Header.Get(header); apiKey != "" {
+			multiplier := cfg.IPMultiplier
+			if multiplier <= 0 {
+				multiplier = pkgRegistry.DefaultAPIKeyIPMultiplier
+			}
+			sum := sha256.Sum256([]byte(apiKey))
+			return []RateLimitBucket{
+				{Key: prefix + "apikey-ip:" + ip, Limit: cfg.Limit * multiplier},
+				{Key: prefix + "apikey:" + hex.EncodeToString(sum[:16]), Limit: cfg.Limit},
+			}
+		}
+	}
+	return []RateLimitBucket{ipBucket}
+}
@@ -0,0 +1,282 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// memoryCounter 进程内计数器，模拟共享缓存服务
+type memoryCounter struct {
+	mu      sync.Mutex
+	values  map[string]int64
+	expires map[string]time.Time
+}
+
+func newMemoryCounter() *memoryCounter {
+	return &memoryCounter{
+		values:  make(map[string]int64),
+		expires: make(map[string]time.Time),
+	}
+}
+
+func (c *memoryCounter) IncrBy(key string, delta int64, expiration time.Duration) (int64, time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if exp, ok := c.expires[key]; !ok || now.After(exp) {
+		c.values[key] = 0
+		c.expires[key] = now.Add(expiration)
+	}
+	c.values[key] += delta
+	return c.values[key], c.expires[key].Sub(now), nil
+}
+
+// newRateLimitedGateway 创建使用指定限流计数器的网关，返回网关及其地址
+// 测试客户端作为本机的可信代理，以 X-Forwarded-For 模拟不同客户端
+func newRateLimitedGateway(t *testing.T, reg registry.Registry, counter Counter) (*Gateway, string) {
+	t.Helper()
+
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.TrustedProxies = []string{"127.0.0.1", "::1"}
+	})
+	WithRateLimitCounter(counter)(gw)
+	return gw, ts.URL
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	counter := newMemoryCounter()
+
+	// 两个网关副本共享同一个计数器
+	gw1, url1 := newRateLimitedGateway(t, reg, counter)
+	gw2, url2 := newRateLimitedGateway(t, reg, counter)
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("logs", "/health"))
+	route.RateLimit = &pkgRegistry.RateLimitConfig{Limit: 3, Window: 60}
+	registerBackend(t, reg, "log-service", "logs", newTestBackend(t, nil), route)
+	for _, gw := range []*Gateway{gw1, gw2} {
+		if err := gw.SyncRoutes(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range 3 {
+		url := []string{url1, url2}[i%2]
+		resp, body := doRequest(t, http.MethodGet, url+"/api/v1/logs/health", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("[%d] Expected status 200, got %d (%s)", i, resp.StatusCode, body)
+		}
+		if v := resp.Header.Get(HeaderRateLimitLimit); v != "3" {
+			t.Fatalf("[%d] Expected %s 3, got %q", i, HeaderRateLimitLimit, v)
+		}
+		if v := resp.Header.Get(HeaderRateLimitRemaining); v != strconv.Itoa(2-i) {
+			t.Fatalf("[%d] Expected %s %d, got %q", i, HeaderRateLimitRemaining, 2-i, v)
+		}
+		if v := resp.Header.Get(HeaderRateLimitPolicy); v != "3;w=60" {
+			t.Fatalf("[%d] Expected %s 3;w=60, got %q", i, HeaderRateLimitPolicy, v)
+		}
+	}
+
+	resp, body := doRequest(t, http.MethodGet, url2+"/api/v1/logs/health", nil)
+	assertErrorResponse(t, resp, body, http.StatusTooManyRequests)
+	if v := resp.Header.Get(HeaderRateLimitRemaining); v != "0" {
+		t.Fatalf("Expected %s 0, got %q", HeaderRateLimitRemaining, v)
+	}
+	reset, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || reset <= 0 || reset > 60 {
+		t.Fatalf("Expected Retry-After within the window, got %q", resp.Header.Get("Retry-After"))
+	}
+
+	// 其他客户端IP不受影响
+	resp, body = doRequest(t, http.MethodGet, url1+"/api/v1/logs/health", map[string]string{
+		"X-Forwarded-For": "203.0.113.7",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for another client, got %d (%s)", resp.StatusCode, body)
+	}
+}
+
+func TestGatewayRateLimitKeyBy(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		keyBy   string
+		headers [2]map[string]string // 两个请求的请求头
+		limited bool                 // 第二个请求是否被限流
+	}{
+		{
+			name:    "api key shares a counter",
+			keyBy:   pkgRegistry.RateLimitKeyAPIKey,
+			headers: [2]map[string]string{{"X-API-Key": "k1", "X-Forwarded-For": "1.1.1.1"}, {"X-API-Key": "k1", "X-Forwarded-For": "2.2.2.2"}},
+			limited: true,
+		},
+		{
+			name:    "different api keys",
+			keyBy:   pkgRegistry.RateLimitKeyAPIKey,
+			headers: [2]map[string]string{{"X-API-Key": "k1", "X-Forwarded-For": "1.1.1.1"}, {"X-API-Key": "k2", "X-Forwarded-For": "2.2.2.2"}},
+			limited: false,
+		},
+		{
+			name:    "different api keys behind one IP",
+			keyBy:   pkgRegistry.RateLimitKeyAPIKey,
+			headers: [2]map[string]string{{"X-API-Key": "k1"}, {"X-API-Key": "k2"}},
+			limited: false,
+		},
+		{
+			name:    "same user from different IPs",
+			keyBy:   pkgRegistry.RateLimitKeyUser,
+			headers: [2]map[string]string{{"X-Forwarded-For": "1.1.1.1"}, {"X-Forwarded-For": "2.2.2.2"}},
+			limited: true,
+		},
+		{
+			name:    "ip ignores api key",
+			keyBy:   pkgRegistry.RateLimitKeyIP,
+			headers: [2]map[string]string{{"X-API-Key": "k1"}, {"X-API-Key": "k2"}},
+			limited: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			reg := registry.NewMemoryRegistry()
+			gw, url := newRateLimitedGateway(t, reg, newMemoryCounter())
+
+			route := pkgRegistry.RouteConfig{
+				PathPrefix:   pkgRegistry.GatewayPath("users", "/profile"),
+				Methods:      pkgRegistry.DefaultMethods,
+				AuthRequired: s.keyBy == pkgRegistry.RateLimitKeyUser,
+				RateLimit:    &pkgRegistry.RateLimitConfig{Limit: 1, Window: 60, KeyBy: s.keyBy},
+			}
+			registerBackend(t, reg, "user-service", "users", newTestBackend(t, nil), route)
+			if err := gw.SyncRoutes(); err != nil {
+				t.Fatal(err)
+			}
+
+			token := ""
+			if route.AuthRequired {
+				var err error
+				if token, err = gw.jwt.GenerateToken(7, "alice", 1, "admin"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var status int
+			for i, headers := range s.headers {
+				if token != "" {
+					headers["Authorization"] = "Bearer " + token
+				}
+				resp, body := doRequest(t, http.MethodGet, url+"/api/v1/users/profile", headers)
+				if i == 0 && resp.StatusCode != http.StatusOK {
+					t.Fatalf("Expected first request to succeed, got %d (%s)", resp.StatusCode, body)
+				}
+				status = resp.StatusCode
+			}
+
+			if s.limited && status != http.StatusTooManyRequests {
+				t.Fatalf("Expected second request to be limited, got %d", status)
+			}
+			if !s.limited && status != http.StatusOK {
+				t.Fatalf("Expected second request to pass, got %d", status)
+			}
+		})
+	}
+}
+
+func TestGatewayRateLimitAPIKeyIPGuard(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, url := newRateLimitedGateway(t, reg, newMemoryCounter())
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("logs", "/health"))
+	route.RateLimit = &pkgRegistry.RateLimitConfig{Limit: 1, Window: 60, KeyBy: pkgRegistry.RateLimitKeyAPIKey, IPMultiplier: 3}
+	registerBackend(t, reg, "log-service", "logs", newTestBackend(t, nil), route)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一IP的随机 API Key 最多共享 Limit * IPMultiplier 的总额度
+	for i := range 4 {
+		resp, body := doRequest(t, http.MethodGet, url+"/api/v1/logs/health", map[string]string{"X-API-Key": "k" + strconv.Itoa(i)})
+		if i < 3 && resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected key %d to pass, got %d (%s)", i, resp.StatusCode, body)
+		}
+		if i == 3 {
+			assertErrorResponse(t, resp, body, http.StatusTooManyRequests)
+		}
+	}
+}
+
+func TestRateLimiterAllowAllStopsOnReject(t *testing.T) {
+	counter := newMemoryCounter()
+	limiter := NewRateLimiter(counter)
+	cfg := &pkgRegistry.RateLimitConfig{Limit: 1, Window: 60}
+	buckets := []RateLimitBucket{{Key: "strict", Limit: 1}, {Key: "loose", Limit: 10}}
+
+	for i := range 3 {
+		result, err := limiter.AllowAll(buckets, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != (i == 0) || result.Limit != 1 {
+			t.Fatalf("Unexpected result %d: %+v", i, result)
+		}
+	}
+	// 被拒绝的请求不再计入之后的桶
+	if n := counter.values[rateLimitKeyPrefix+"loose"]; n != 1 {
+		t.Fatalf("Expected the loose bucket to be charged once, got %d", n)
+	}
+}
+
+func TestGatewayRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+	WithRateLimitCounter(newMemoryCounter())(gw)
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("logs", "/health"))
+	route.RateLimit = &pkgRegistry.RateLimitConfig{Limit: 1, Window: 60}
+	registerBackend(t, reg, "log-service", "logs", newTestBackend(t, nil), route)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未配置可信代理时，每次伪造的 X-Forwarded-For 不会得到新的计数
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", map[string]string{"X-Forwarded-For": "1.1.1.1"})
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", map[string]string{"X-Forwarded-For": "2.2.2.2"})
+	assertErrorResponse(t, resp, body, http.StatusTooManyRequests)
+}
+
+// failingCounter 模拟缓存服务不可用
+type failingCounter struct{}
+
+func (failingCounter) IncrBy(string, int64, time.Duration) (int64, time.Duration, error) {
+	return 0, 0, errors.New("redis service unavailable")
+}
+
+func TestGatewayRateLimitFailOpen(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, url := newRateLimitedGateway(t, reg, failingCounter{})
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("logs", "/health"))
+	route.RateLimit = &pkgRegistry.RateLimitConfig{Limit: 1, Window: 60}
+	registerBackend(t, reg, "log-service", "logs", newTestBackend(t, nil), route)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		resp, body := doRequest(t, http.MethodGet, url+"/api/v1/logs/health", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200 when the counter is unavailable, got %d (%s)", resp.StatusCode, body)
+		}
+		if resp.Header.Get(HeaderRateLimitLimit) != "" {
+			t.Fatal("Expected no RateLimit headers when the counter is unavailable")
+		}
+	}
+}
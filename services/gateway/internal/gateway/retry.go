@@ -0,0 +1,201 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/config"
+)
+
+// 重试默认配置
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBackoff     = 50 * time.Millisecond
+	DefaultRetryMaxBackoff  = time.Second
+	DefaultRetryBudgetRatio = 0.2
+	DefaultRetryMinRetries  = 10
+	DefaultRetryMaxBodySize = 1 << 20
+	DefaultUpstreamTimeout  = 30 * time.Second
+)
+
+// maxRetryBalance 按比例累积的重试额度上限，避免长时间空闲后突发大量重试
+const maxRetryBalance = 100
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // 单个请求最多尝试次数（含首次）
+	Backoff     time.Duration // 首次重试前的退避时间，之后指数增长
+	MaxBackoff  time.Duration // 最大退避时间
+	MaxBodySize int64         // 可重试请求缓冲的最大请求体
+}
+
+// newRetryPolicy 从配置创建重试策略，未配置的字段使用默认值
+func newRetryPolicy(cfg config.GatewayRetryConfig) RetryPolicy {
+	p := RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     time.Duration(cfg.Backoff) * time.Millisecond,
+		MaxBackoff:  time.Duration(cfg.MaxBackoff) * time.Millisecond,
+		MaxBodySize: cfg.MaxBodySize,
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = DefaultRetryBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.MaxBodySize <= 0 {
+		p.MaxBodySize = DefaultRetryMaxBodySize
+	}
+	return p
+}
+
+// backoff 计算第 retry 次重试（从 1 开始）前的退避时间，带 ±25% 抖动
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	jitter := time.Duration(rand.Int64N(int64(d)/2 + 1))
+	return d - d/4 + jitter
+}
+
+// wait 等待退避时间，请求被取消时提前返回 false
+func (p RetryPolicy) wait(ctx context.Context, retry int) bool {
+	timer := time.NewTimer(p.backoff(retry))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isRetryableMethod 请求方法是否天然幂等
+func isRetryableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodPut
+}
+
+// RetryBudget 重试预算（并发安全）
+// 每个请求按比例存入重试额度，每次重试消耗一个额度，另有每秒保底额度；
+// 上游大面积故障时限制重试放大流量
+type RetryBudget struct {
+	mu         sync.Mutex
+	ratio      float64
+	minRetries int
+	balance    float64   // 按请求比例累积的重试额度
+	reserve    int       // 当前秒剩余的保底额度
+	window     time.Time // 当前保底额度所属的秒
+}
+
+// NewRetryBudget 创建重试预算
+func NewRetryBudget(ratio float64, minRetries int) *RetryBudget {
+	return &RetryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+	}
+}
+
+// newRetryBudgetFromConfig 从配置创建重试预算，未配置的字段使用默认值
+func newRetryBudgetFromConfig(cfg config.GatewayRetryConfig) *RetryBudget {
+	ratio := cfg.BudgetRatio
+	if ratio <= 0 {
+		ratio = DefaultRetryBudgetRatio
+	}
+	minRetries := cfg.MinRetries
+	if minRetries <= 0 {
+		minRetries = DefaultRetryMinRetries
+	}
+	return NewRetryBudget(ratio, minRetries)
+}
+
+// Deposit 记录一个请求，存入按比例计算的重试额度
+func (b *RetryBudget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.balance = min(b.balance+b.ratio, maxRetryBalance)
+}
+
+// Withdraw 尝试消耗一个重试额度，额度不足时返回 false
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now().Truncate(time.Second)
+	if !now.Equal(b.window) {
+		b.window = now
+		b.reserve = b.minRetries
+	}
+
+	if b.reserve > 0 {
+		b.reserve--
+		return true
+	}
+	if b.balance >= 1 {
+		b.balance--
+		return true
+	}
+	return false
+}
+
+// bufferBody 使用 router.RereadableReadCloser 缓冲请求体以便重试时重放
+// 请求体未知长度或超过 maxSize 时不缓冲并返回 false
+func bufferBody(req *http.Request, maxSize int64) bool {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return true
+	}
+	if req.ContentLength < 0 || req.ContentLength > maxSize {
+		return false
+	}
+
+	// 路由层通常已包装为 RereadableReadCloser，直接复用
+	body, ok := req.Body.(*router.RereadableReadCloser)
+	if !ok {
+		body = &router.RereadableReadCloser{ReadCloser: req.Body}
+	}
+	// 预先读完原始请求体，读到 EOF 后自动倒回，之后的读取都来自缓冲
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// rewindBody 将缓冲的请求体倒回开头
+// 读尽剩余内容会触发 RereadableReadCloser 自动倒回（部分读取后直接 Reread 会丢失未读部分）
+func rewindBody(req *http.Request) {
+	if body, ok := req.Body.(*router.RereadableReadCloser); ok {
+		io.Copy(io.Discard, body)
+	}
+}
+
+// singleReadBody 读到 EOF 后始终返回 EOF 的请求体
+// 路由层包装的 RereadableReadCloser 读到 EOF 后会自动倒回，直接转发时
+// Transport 校验请求体长度的额外读取会读到重复内容
+type singleReadBody struct {
+	io.ReadCloser
+	eof bool
+}
+
+// Read 实现 io.Reader
+func (b *singleReadBody) Read(p []byte) (int, error) {
+	if b.eof {
+		return 0, io.EOF
+	}
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// newDownBackend 创建一个已关闭的后端（连接被拒绝）
+func newDownBackend(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	backend := newTestBackend(t, nil)
+	backend.Close()
+	return backend
+}
+
+func assertErrorResponse(t *testing.T, resp *http.Response, body string, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Fatalf("Expected status %d, got %d (%s)", status, resp.StatusCode, body)
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Expected JSON error body, got %q", body)
+	}
+	if result.Code != status || result.Message == "" {
+		t.Fatalf("Expected apis.Error body with code %d, got %s", status, body)
+	}
+}
+
+func TestGatewayUpstreamDown(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	registerBackend(t, reg, "log-service", "logs", newDownBackend(t),
+		pkgRegistry.NewPublicRoutes("logs", "/health")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", nil)
+	assertErrorResponse(t, resp, body, http.StatusBadGateway)
+}
+
+func TestGatewayUpstreamTimeout(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.Retry.MaxAttempts = 1
+	})
+	gw.transport.ResponseHeaderTimeout = 50 * time.Millisecond
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	registerBackend(t, reg, "log-service", "logs", backend,
+		pkgRegistry.NewPublicRoutes("logs", "/health")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/logs/health", nil)
+	assertErrorResponse(t, resp, body, http.StatusGatewayTimeout)
+}
+
+func TestGatewayRetry(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		method        string
+		idempotent    bool
+		expectFailure bool
+	}{
+		{"GET is retried", http.MethodGet, false, false},
+		{"PUT is retried", http.MethodPut, false, false},
+		{"POST is not retried", http.MethodPost, false, true},
+		{"POST on idempotent route is retried", http.MethodPost, true, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			reg := registry.NewMemoryRegistry()
+			gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+				cfg.Gateway.Retry.Backoff = 1
+			})
+
+			var mu sync.Mutex
+			var bodies []string
+			good := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+				raw, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				bodies = append(bodies, string(raw))
+				mu.Unlock()
+			})
+
+			route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("orders", "/items"))
+			route.Idempotent = s.idempotent
+			registerBackend(t, reg, "order-service", "orders", good, route)
+			registerBackend(t, reg, "order-service", "orders", newDownBackend(t), route)
+			if err := gw.SyncRoutes(); err != nil {
+				t.Fatal(err)
+			}
+
+			// 轮询下两次请求必有一次先落到故障节点
+			failures := 0
+			for i := range 2 {
+				payload := strings.Repeat("x", 1000) + string(rune('a'+i))
+				resp, body := doRequestWithBody(t, s.method, ts.URL+"/api/v1/orders/items", strings.NewReader(payload), nil)
+				if resp.StatusCode == http.StatusBadGateway {
+					failures++
+					continue
+				}
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+				}
+			}
+
+			if s.expectFailure && failures != 1 {
+				t.Fatalf("Expected exactly one 502, got %d", failures)
+			}
+			if !s.expectFailure && failures != 0 {
+				t.Fatalf("Expected all requests to succeed through retries, got %d failures", failures)
+			}
+
+			// 重试时请求体被完整重放
+			for _, b := range bodies {
+				if len(b) != 1001 {
+					t.Fatalf("Expected the full body to reach the backend, got %d bytes", len(b))
+				}
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := NewRetryBudget(0.5, 1)
+
+	// 每秒保底额度
+	if !b.Withdraw() {
+		t.Fatal("Expected the per-second reserve to allow one retry")
+	}
+	if b.Withdraw() {
+		t.Fatal("Expected the budget to be exhausted")
+	}
+
+	// 两个请求累积一次重试额度
+	b.Deposit()
+	if b.Withdraw() {
+		t.Fatal("Expected half a token to be insufficient")
+	}
+	b.Deposit()
+	if !b.Withdraw() {
+		t.Fatal("Expected accumulated budget to allow a retry")
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := newRetryPolicy(config.GatewayRetryConfig{Backoff: 100, MaxBackoff: 300})
+
+	scenarios := []struct {
+		retry int
+		base  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 300 * time.Millisecond},
+		{10, 300 * time.Millisecond},
+	}
+
+	for _, s := range scenarios {
+		for range 20 {
+			d := p.backoff(s.retry)
+			if d < s.base*3/4 || d > s.base*5/4 {
+				t.Fatalf("[%d] Expected backoff within ±25%% of %s, got %s", s.retry, s.base, d)
+			}
+		}
+	}
+}
+
+func TestNewRetryPolicyDefaults(t *testing.T) {
+	p := newRetryPolicy(config.GatewayRetryConfig{})
+
+	if p.MaxAttempts != DefaultRetryMaxAttempts ||
+		p.Backoff != DefaultRetryBackoff ||
+		p.MaxBackoff != DefaultRetryMaxBackoff ||
+		p.MaxBodySize != DefaultRetryMaxBodySize {
+		t.Fatalf("Expected default retry policy, got %+v", p)
+	}
+}
@@ -0,0 +1,210 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+	"go.uber.org/zap"
+)
+
+// RouteSourceStatic 路由来源：配置文件中的静态路由
+const RouteSourceStatic = "static"
+
+// StaticVersion 静态上游节点的服务版本
+const StaticVersion = "static"
+
+// upstreamNodeID 静态上游节点ID，同一上游的同一地址ID不变，熔断与健康状态得以沿用
+func upstreamNodeID(upstream, address string) string {
+	return "upstream:" + upstream + ":" + address
+}
+
+// ApplyStaticConfig 应用配置文件中的静态上游与路由，替换之前的静态配置
+// 静态路由与注册中心路由同键时覆盖后者，管理接口的覆盖配置优先级最高；
+// 配置无效时返回错误并保留原有配置
+func (g *Gateway) ApplyStaticConfig(cfg config.GatewayConfig) error {
+	upstreams, routes, err := buildStaticConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	static := make(map[string]*ServiceRoute, len(routes))
+	for _, route := range routes {
+		if err := g.prepareRouteLocked(route, g.static); err != nil {
+			return err
+		}
+		static[routeKey(route.Host, route.PathPrefix)] = route
+	}
+
+	// 清理已移除节点的熔断与健康状态
+	for name, svc := range g.upstreams {
+		for _, node := range svc.Nodes {
+			if !hasNode(upstreams[name], node.Id) {
+				g.breakers.Remove(node.Id)
+				g.health.Remove(node.Id)
+			}
+		}
+	}
+
+	g.upstreams = upstreams
+	g.static = static
+	g.rebuildLocked()
+
+	logger.Info("应用静态路由配置",
+		zap.Int("upstreams", len(upstreams)),
+		zap.Int("routes", len(static)),
+	)
+	return nil
+}
+
+// upstreamServices 获取所有静态上游（按名称排序）
+func (g *Gateway) upstreamServices() []*registry.Service {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	services := make([]*registry.Service, 0, len(g.upstreams))
+	for _, svc := range g.upstreams {
+		services = append(services, svc)
+	}
+	slices.SortFunc(services, func(a, b *registry.Service) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return services
+}
+
+// discover 获取路由的服务实例：静态路由使用配置中的上游节点，否则从注册中心发现
+func (g *Gateway) discover(route *ServiceRoute) ([]*registry.Service, error) {
+	if route.Upstream == "" {
+		return g.registry.GetService(route.ServiceName)
+	}
+
+	g.mu.RLock()
+	svc := g.upstreams[route.Upstream]
+	g.mu.RUnlock()
+	if svc == nil {
+		return nil, fmt.Errorf("upstream %q not found", route.Upstream)
+	}
+	return []*registry.Service{svc}, nil
+}
+
+// buildStaticConfig 校验并转换静态上游与路由配置
+func buildStaticConfig(cfg config.GatewayConfig) (map[string]*registry.Service, []*ServiceRoute, error) {
+	upstreams := make(map[string]*registry.Service, len(cfg.Upstreams))
+	for _, u := range cfg.Upstreams {
+		if u.Name == "" {
+			return nil, nil, errors.New("upstream name is required")
+		}
+		if _, ok := upstreams[u.Name]; ok {
+			return nil, nil, fmt.Errorf("duplicate upstream %q", u.Name)
+		}
+		if len(u.Addresses) == 0 {
+			return nil, nil, fmt.Errorf("upstream %q has no addresses", u.Name)
+		}
+
+		svc := &registry.Service{Name: u.Name, Version: StaticVersion}
+		for _, addr := range u.Addresses {
+			addr = strings.TrimPrefix(strings.TrimSpace(addr), "http://")
+			if addr == "" {
+				return nil, nil, fmt.Errorf("upstream %q has an empty address", u.Name)
+			}
+			svc.Nodes = append(svc.Nodes, &registry.Node{
+				Id:      upstreamNodeID(u.Name, addr),
+				Address: addr,
+			})
+		}
+		upstreams[u.Name] = svc
+	}
+
+	routes := make([]*ServiceRoute, 0, len(cfg.Routes))
+	seen := make(map[string]bool, len(cfg.Routes))
+	for _, rc := range cfg.Routes {
+		route, err := staticRoute(rc, upstreams)
+		if err != nil {
+			return nil, nil, fmt.Errorf("route %q: %w", rc.Path, err)
+		}
+		key := routeKey(route.Host, route.PathPrefix)
+		if seen[key] {
+			return nil, nil, fmt.Errorf("duplicate route %q", rc.Path)
+		}
+		seen[key] = true
+		routes = append(routes, route)
+	}
+	return upstreams, routes, nil
+}
+
+// staticRoute 将静态路由配置转换为服务路由
+func staticRoute(rc config.GatewayRouteConfig, upstreams map[string]*registry.Service) (*ServiceRoute, error) {
+	if _, err := compilePattern(rc.Host, rc.Path); err != nil {
+		return nil, err
+	}
+
+	route := &ServiceRoute{
+		ServiceName:  rc.Service,
+		Host:         strings.ToLower(strings.TrimSpace(rc.Host)),
+		PathPrefix:   rc.Path,
+		TargetPrefix: rc.TargetPrefix,
+		StripPrefix:  rc.StripPrefix,
+		Methods:      make([]string, 0, len(rc.Methods)),
+		AuthRequired: rc.AuthRequired,
+		LoadBalance:  rc.LoadBalance,
+		HashKey:      rc.HashKey,
+		Idempotent:   rc.Idempotent,
+		Upstream:     rc.Upstream,
+		Transform:    staticTransform(rc.Transform),
+		source:       RouteSourceStatic,
+	}
+	if _, err := compileTransform(route.Transform); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case rc.Upstream != "" && rc.Service != "":
+		return nil, errors.New("upstream and service are mutually exclusive")
+	case rc.Upstream != "":
+		if upstreams[rc.Upstream] == nil {
+			return nil, fmt.Errorf("unknown upstream %q", rc.Upstream)
+		}
+		route.ServiceName = rc.Upstream
+	case rc.Service == "":
+		return nil, errors.New("upstream or service is required")
+	}
+
+	for _, m := range rc.Methods {
+		route.Methods = append(route.Methods, strings.ToUpper(m))
+	}
+	if len(route.Methods) == 0 {
+		route.Methods = pkgRegistry.DefaultMethods
+	}
+	if route.StripPrefix && route.TargetPrefix == "" {
+		route.TargetPrefix = "/"
+	}
+	if rl := rc.RateLimit; rl != nil {
+		if rl.Limit < 0 || rl.Window < 0 || rl.IPMultiplier < 0 {
+			return nil, errors.New("rateLimit must not be negative")
+		}
+		route.RateLimit = &pkgRegistry.RateLimitConfig{
+			Limit:        rl.Limit,
+			Window:       rl.Window,
+			KeyBy:        rl.KeyBy,
+			APIKeyHeader: rl.APIKeyHeader,
+			IPMultiplier: rl.IPMultiplier,
+		}
+	}
+	return route, nil
+}
+
+// hasNode 服务中是否包含指定节点
+func hasNode(svc *registry.Service, id string) bool {
+	if svc == nil {
+		return false
+	}
+	return slices.ContainsFunc(svc.Nodes, func(n *registry.Node) bool { return n.Id == id })
+}
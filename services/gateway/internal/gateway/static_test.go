@@ -0,0 +1,173 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goback/pkg/config"
+	"go-micro.dev/v5/registry"
+)
+
+// staticUpstream 返回指向测试后端的静态上游配置
+func staticUpstream(name string, backends ...string) config.GatewayUpstreamConfig {
+	upstream := config.GatewayUpstreamConfig{Name: name}
+	for _, url := range backends {
+		upstream.Addresses = append(upstream.Addresses, strings.TrimPrefix(url, "http://"))
+	}
+	return upstream
+}
+
+func TestGatewayStaticUpstream(t *testing.T) {
+	backend := newTestBackend(t, nil)
+
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.Upstreams = []config.GatewayUpstreamConfig{staticUpstream("legacy", backend.URL)}
+		cfg.Gateway.Routes = []config.GatewayRouteConfig{{
+			Path:         "/api/v1/legacy/*",
+			Upstream:     "legacy",
+			StripPrefix:  true,
+			TargetPrefix: "/v2",
+			Methods:      []string{"get"},
+		}}
+	})
+
+	// 注册中心中没有任何服务
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/items/1", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"path":"/v2/items/1"`) {
+		t.Fatalf("Expected static upstream to be served, got %d (%s)", resp.StatusCode, body)
+	}
+	resp, body = doRequest(t, http.MethodPost, ts.URL+"/api/v1/legacy/items/1", nil)
+	assertErrorResponse(t, resp, body, http.StatusMethodNotAllowed)
+
+	// 允许 GET 的路由同时允许 HEAD
+	resp, _ = doRequest(t, http.MethodHead, ts.URL+"/api/v1/legacy/items/1", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected HEAD to be allowed with GET, got %d", resp.StatusCode)
+	}
+
+	// 版本请求头不影响静态上游
+	resp, body = doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/items/1", map[string]string{"X-Service-Version": "v9"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected version header to be ignored, got %d (%s)", resp.StatusCode, body)
+	}
+
+	resp, body = doRequest(t, http.MethodGet, ts.URL+"/services", nil)
+	if !strings.Contains(body, `"name":"legacy"`) || !strings.Contains(body, `"static":true`) {
+		t.Fatalf("Expected static upstream in service status, got %d (%s)", resp.StatusCode, body)
+	}
+
+	for _, r := range gw.ListRoutes() {
+		if r.PathPrefix == "/api/v1/legacy/*" && r.Source != RouteSourceStatic {
+			t.Fatalf("Expected static route source, got %+v", r)
+		}
+	}
+}
+
+func TestGatewayStaticRoutePrecedence(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+	WithRouteStore(&memoryRouteStore{})(gw)
+
+	registerBackend(t, reg, "user-service", "users", newTestBackend(t, nil))
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 静态路由覆盖注册中心同一路径规则的路由（改为公开）
+	err := gw.ApplyStaticConfig(config.GatewayConfig{
+		Routes: []config.GatewayRouteConfig{{
+			Path:         "/api/v1/users",
+			Service:      "user-service",
+			StripPrefix:  true,
+			AuthRequired: false,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/profile", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"path":"/profile"`) {
+		t.Fatalf("Expected static route to take precedence, got %d (%s)", resp.StatusCode, body)
+	}
+
+	// 管理接口的覆盖配置优先于静态路由
+	saveOverride(t, gw, ts.URL, `{"pathPrefix": "/api/v1/users", "disabled": true}`)
+	resp, body = doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/profile", nil)
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+}
+
+func TestGatewayStaticConfigReload(t *testing.T) {
+	first := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("first")) })
+	second := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("second")) })
+
+	staticConfig := func(backend string) config.GatewayConfig {
+		return config.GatewayConfig{
+			Upstreams: []config.GatewayUpstreamConfig{staticUpstream("legacy", backend)},
+			Routes:    []config.GatewayRouteConfig{{Path: "/api/v1/legacy/*", Upstream: "legacy", StripPrefix: true}},
+		}
+	}
+
+	gw, ts := newTestGateway(t, registry.NewMemoryRegistry())
+	if err := gw.ApplyStaticConfig(staticConfig(first.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if _, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/x", nil); body != "first" {
+		t.Fatalf("Expected first backend, got %q", body)
+	}
+
+	// 热更新上游地址
+	if err := gw.ApplyStaticConfig(staticConfig(second.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if _, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/x", nil); body != "second" {
+		t.Fatalf("Expected reloaded backend, got %q", body)
+	}
+
+	// 无效配置不生效
+	invalid := staticConfig(second.URL)
+	invalid.Routes = append(invalid.Routes, config.GatewayRouteConfig{Path: "/api/v1/other", Upstream: "missing"})
+	if err := gw.ApplyStaticConfig(invalid); err == nil {
+		t.Fatal("Expected unknown upstream to be rejected")
+	}
+	if _, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/x", nil); body != "second" {
+		t.Fatalf("Expected previous config to be kept, got %q", body)
+	}
+
+	// 移除后不再转发
+	if err := gw.ApplyStaticConfig(config.GatewayConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	resp, body := doRequest(t, http.MethodGet, ts.URL+"/api/v1/legacy/x", nil)
+	assertErrorResponse(t, resp, body, http.StatusNotFound)
+}
+
+func TestBuildStaticConfigValidation(t *testing.T) {
+	upstreams := []config.GatewayUpstreamConfig{{Name: "legacy", Addresses: []string{"127.0.0.1:8080"}}}
+
+	cases := map[string]config.GatewayConfig{
+		"empty upstream name": {Upstreams: []config.GatewayUpstreamConfig{{Addresses: []string{"127.0.0.1:1"}}}},
+		"no addresses":        {Upstreams: []config.GatewayUpstreamConfig{{Name: "legacy"}}},
+		"duplicate upstream":  {Upstreams: append(upstreams, upstreams...)},
+		"invalid path": {Upstreams: upstreams, Routes: []config.GatewayRouteConfig{
+			{Path: "legacy", Upstream: "legacy"},
+		}},
+		"no target": {Routes: []config.GatewayRouteConfig{{Path: "/legacy"}}},
+		"both targets": {Upstreams: upstreams, Routes: []config.GatewayRouteConfig{
+			{Path: "/legacy", Upstream: "legacy", Service: "user-service"},
+		}},
+		"duplicate route": {Upstreams: upstreams, Routes: []config.GatewayRouteConfig{
+			{Path: "/legacy", Upstream: "legacy"},
+			{Path: "/legacy", Service: "user-service"},
+		}},
+		"negative rate limit": {Upstreams: upstreams, Routes: []config.GatewayRouteConfig{
+			{Path: "/legacy", Upstream: "legacy", RateLimit: &config.GatewayRateLimitConfig{Limit: -1}},
+		}},
+	}
+	for name, cfg := range cases {
+		if _, _, err := buildStaticConfig(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStreamIdleTimeout 流式响应与 WebSocket 连接的默认空闲超时
+const DefaultStreamIdleTimeout = 5 * time.Minute
+
+// isStreamingResponse 上游响应是否为长连接或流式响应（协议升级、SSE、未知长度的 chunked 响应）
+func isStreamingResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return true
+	}
+	if ct, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); ct == "text/event-stream" {
+		return true
+	}
+	return resp.ContentLength == -1
+}
+
+// wrapStream 为流式响应设置空闲超时
+// 流式响应可能持续远超服务器读写超时的时间，因此改为按数据往来续期：
+// 超过 idleTimeout 没有数据时结束响应（WebSocket 则关闭连接）
+// httputil.ReverseProxy 会立即刷新 SSE 与未知长度的响应，这里无需额外设置 FlushInterval
+func wrapStream(w http.ResponseWriter, resp *http.Response, idleTimeout time.Duration) {
+	if idleTimeout <= 0 || !isStreamingResponse(resp) {
+		return
+	}
+
+	// 协议升级后连接被劫持，服务器的读写超时随之清除，由空闲超时接管
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		if conn, ok := resp.Body.(io.ReadWriteCloser); ok {
+			resp.Body = newIdleTimeoutConn(conn, idleTimeout)
+		}
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	extend := func() {
+		_ = rc.SetWriteDeadline(time.Now().Add(idleTimeout))
+	}
+	_ = rc.SetReadDeadline(time.Time{})
+	extend()
+	resp.Body = newIdleTimeoutBody(resp.Body, idleTimeout, extend)
+}
+
+// idleWatcher 空闲计时器：超过 timeout 没有活动时调用 onIdle
+type idleWatcher struct {
+	timer   *time.Timer
+	timeout time.Duration
+	idle    atomic.Bool
+}
+
+func newIdleWatcher(timeout time.Duration, onIdle func()) *idleWatcher {
+	w := &idleWatcher{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.idle.Store(true)
+		onIdle()
+	})
+	return w
+}
+
+// touch 记录一次活动，重新计时
+func (w *idleWatcher) touch() {
+	if !w.idle.Load() {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// stop 停止计时
+func (w *idleWatcher) stop() {
+	w.timer.Stop()
+}
+
+// idleTimeoutBody 空闲超时后以 EOF 正常结束的响应体
+type idleTimeoutBody struct {
+	io.ReadCloser
+	watcher    *idleWatcher
+	onActivity func()
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, onActivity func()) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, onActivity: onActivity}
+	// 关闭上游响应体以中断阻塞中的读取
+	b.watcher = newIdleWatcher(timeout, func() { body.Close() })
+	return b
+}
+
+// Read 实现 io.Reader
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.watcher.idle.Load() {
+		// 续期一次写超时，确保结束响应的最后一个 chunk 能写出
+		b.onActivity()
+		return n, io.EOF
+	}
+	if n > 0 {
+		b.watcher.touch()
+		b.onActivity()
+	}
+	return n, err
+}
+
+// Close 实现 io.Closer
+func (b *idleTimeoutBody) Close() error {
+	b.watcher.stop()
+	return b.ReadCloser.Close()
+}
+
+// idleTimeoutConn 空闲超时后关闭的升级连接（如 WebSocket），任一方向有数据都会续期
+type idleTimeoutConn struct {
+	io.ReadWriteCloser
+	watcher *idleWatcher
+}
+
+func newIdleTimeoutConn(conn io.ReadWriteCloser, timeout time.Duration) *idleTimeoutConn {
+	return &idleTimeoutConn{
+		ReadWriteCloser: conn,
+		watcher:         newIdleWatcher(timeout, func() { conn.Close() }),
+	}
+}
+
+// Read 实现 io.Reader
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	if n > 0 {
+		c.watcher.touch()
+	}
+	return n, err
+}
+
+// Write 实现 io.Writer
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	if n > 0 {
+		c.watcher.touch()
+	}
+	return n, err
+}
+
+// Close 实现 io.Closer
+func (c *idleTimeoutConn) Close() error {
+	c.watcher.stop()
+	return c.ReadWriteCloser.Close()
+}
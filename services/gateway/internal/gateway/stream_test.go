@@ -0,0 +1,197 @@
+package gateway
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// newEchoUpgradeBackend 创建接受 Upgrade: echo 协议升级并逐行回显的后端
+func newEchoUpgradeBackend(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "echo" {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		conn, brw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		brw.Flush()
+		for {
+			line, err := brw.ReadString('\n')
+			if err != nil {
+				return
+			}
+			brw.WriteString(line)
+			brw.Flush()
+		}
+	})
+}
+
+// dialUpgrade 通过网关发起协议升级，返回升级后的连接
+func dialUpgrade(t *testing.T, gatewayURL, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(gatewayURL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	req, _ := http.NewRequest(http.MethodGet, gatewayURL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "echo")
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	return conn, br
+}
+
+func TestGatewayWebSocketUpgrade(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.IdleTimeout = 1
+	})
+
+	registerBackend(t, reg, "chat-service", "chat", newEchoUpgradeBackend(t),
+		pkgRegistry.NewPublicRoutes("chat", "/ws")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, br := dialUpgrade(t, ts.URL, "/api/v1/chat/ws")
+	for i := range 3 {
+		msg := fmt.Sprintf("ping %d\n", i)
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != msg {
+			t.Fatalf("Expected echo %q, got %q", msg, line)
+		}
+	}
+
+	// 空闲超时后网关关闭连接
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := br.ReadString('\n'); err != io.EOF {
+		t.Fatalf("Expected the idle connection to be closed, got %v", err)
+	}
+}
+
+func TestGatewayServerSentEvents(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg, func(cfg *config.Config) {
+		cfg.Gateway.IdleTimeout = 1
+	})
+
+	next := make(chan struct{})
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 2 {
+			fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+			select {
+			case <-next:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		// 之后不再发送数据，由网关空闲超时结束
+		<-r.Context().Done()
+	})
+	registerBackend(t, reg, "event-service", "events", backend,
+		pkgRegistry.NewPublicRoutes("events", "/stream")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/v1/events/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	// 每个事件在后端刷新后立即到达客户端
+	br := bufio.NewReader(resp.Body)
+	for i := range 2 {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := fmt.Sprintf("data: %d\n", i); line != expected {
+			t.Fatalf("Expected %q, got %q", expected, line)
+		}
+		br.ReadString('\n')
+		next <- struct{}{}
+	}
+
+	// 空闲超时后流正常结束
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(br)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the idle stream to be closed")
+	}
+}
+
+func TestIsStreamingResponse(t *testing.T) {
+	scenarios := []struct {
+		status        int
+		contentType   string
+		contentLength int64
+		expected      bool
+	}{
+		{http.StatusSwitchingProtocols, "", 0, true},
+		{http.StatusOK, "text/event-stream; charset=utf-8", 0, true},
+		{http.StatusOK, "application/x-ndjson", -1, true},
+		{http.StatusOK, "application/json", 42, false},
+	}
+
+	for _, s := range scenarios {
+		resp := &http.Response{StatusCode: s.status, Header: http.Header{}, ContentLength: s.contentLength}
+		resp.Header.Set("Content-Type", s.contentType)
+		if result := isStreamingResponse(resp); result != s.expected {
+			t.Errorf("[%d %s %d] Expected %v, got %v", s.status, s.contentType, s.contentLength, s.expected, result)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
+)
+
+func TestGatewayTracePropagation(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	prev := tracing.SetTracer(tracing.NewTracer("gateway-test", exporter))
+	t.Cleanup(func() { tracing.SetTracer(prev) })
+
+	reg := pkgRegistry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+
+	var forwarded http.Header
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+	})
+	registerBackend(t, reg, "user-service", "users", backend, pkgRegistry.NewPublicRoutes("users", "/health")...)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	resp, _ := doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/health", map[string]string{
+		"traceparent":     "00-" + traceID + "-00f067aa0ba902b7-01",
+		"X-Forwarded-For": "203.0.113.7",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	// 生成的请求ID转发给上游并返回给客户端
+	requestID := resp.Header.Get(HeaderRequestID)
+	if requestID == "" || forwarded.Get(HeaderRequestID) != requestID {
+		t.Fatalf("Expected request ID %q to be forwarded, got %q", requestID, forwarded.Get(HeaderRequestID))
+	}
+
+	// 服务端 Span 在处理函数返回时结束，可能晚于客户端收到响应
+	var spans []*tracing.Span
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if spans = exporter.Spans(); len(spans) >= 2 {
+			break
+		}
+	}
+	if len(spans) != 2 {
+		t.Fatalf("Expected server and proxy spans, got %d", len(spans))
+	}
+	proxy, server := spans[0], spans[1]
+	if server.Kind != tracing.SpanKindServer || server.Context.TraceID.String() != traceID || server.ParentID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("Expected server span to continue the client trace, got %+v", server)
+	}
+	// 未配置可信代理时忽略客户端传入的 X-Forwarded-For
+	if ip := server.Attributes["http.client_ip"]; ip != "127.0.0.1" {
+		t.Fatalf("Expected client ip 127.0.0.1, got %v", ip)
+	}
+	if forwarded.Get("X-Forwarded-For") != "127.0.0.1" || forwarded.Get("X-Real-IP") != "127.0.0.1" {
+		t.Fatalf("Expected spoofed X-Forwarded-For not to reach the upstream, got %q / %q",
+			forwarded.Get("X-Forwarded-For"), forwarded.Get("X-Real-IP"))
+	}
+	if proxy.Name != "proxy user-service" || proxy.ParentID != server.Context.SpanID || proxy.Attributes["http.status_code"] != http.StatusOK {
+		t.Fatalf("Unexpected proxy span %+v", proxy)
+	}
+
+	// 上游收到的 traceparent 指向网关的转发 Span
+	sc, err := tracing.ParseTraceParent(forwarded.Get("traceparent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceID.String() != traceID || sc.SpanID != proxy.Context.SpanID {
+		t.Fatalf("Expected upstream traceparent to reference the proxy span, got %s", forwarded.Get("traceparent"))
+	}
+
+	// 客户端传入的请求ID原样转发
+	doRequest(t, http.MethodGet, ts.URL+"/api/v1/users/health", map[string]string{HeaderRequestID: "client-id"})
+	if forwarded.Get(HeaderRequestID) != "client-id" {
+		t.Fatalf("Expected client request ID to be kept, got %q", forwarded.Get(HeaderRequestID))
+	}
+}
@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/cache"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// trafficKeyPrefix 运行时分流配置在共享缓存中的键前缀，每个服务一个键（gateway:traffic:<服务名>）
+// 各副本只写入修改的服务，不会覆盖其他副本对其他服务的修改
+const trafficKeyPrefix = "gateway:traffic:"
+
+// ErrVersionUnavailable 请求指定的服务版本没有可用节点
+var ErrVersionUnavailable = errors.New("requested service version is unavailable")
+
+// TrafficStore 运行时分流配置的存储，多个网关副本共享同一份配置
+type TrafficStore interface {
+	// LoadTraffic 加载所有服务的分流配置
+	LoadTraffic() (map[string]*pkgRegistry.TrafficSplit, error)
+	// SaveTraffic 保存单个服务的分流配置，split 为空时删除
+	SaveTraffic(service string, split *pkgRegistry.TrafficSplit) error
+}
+
+// cacheTrafficStore 基于共享缓存服务的分流配置存储
+type cacheTrafficStore struct {
+	cache *cache.Cache
+}
+
+// NewCacheTrafficStore 创建基于缓存服务的分流配置存储
+func NewCacheTrafficStore(c *cache.Cache) TrafficStore {
+	return &cacheTrafficStore{cache: c}
+}
+
+// LoadTraffic 实现 TrafficStore
+func (s *cacheTrafficStore) LoadTraffic() (map[string]*pkgRegistry.TrafficSplit, error) {
+	keys, err := s.cache.ScanAll(trafficKeyPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	splits := make(map[string]*pkgRegistry.TrafficSplit, len(keys))
+	if len(keys) == 0 {
+		return splits, nil
+	}
+
+	values, err := s.cache.MGet(keys...)
+	if err != nil {
+		return nil, err
+	}
+	for key, data := range values {
+		var split pkgRegistry.TrafficSplit
+		if err := json.Unmarshal(data, &split); err != nil {
+			return nil, fmt.Errorf("unmarshal %s: %w", key, err)
+		}
+		splits[strings.TrimPrefix(key, trafficKeyPrefix)] = &split
+	}
+	return splits, nil
+}
+
+// SaveTraffic 实现 TrafficStore
+func (s *cacheTrafficStore) SaveTraffic(service string, split *pkgRegistry.TrafficSplit) error {
+	key := trafficKeyPrefix + service
+	if split == nil {
+		// Cache.Delete 不返回错误，直接调用后端以便删除失败时报告
+		return s.cache.Backend().Delete(context.Background(), s.cache.Prefix()+key)
+	}
+	return s.cache.Set(key, split)
+}
+
+// TrafficManager 运行时分流配置（并发安全），按服务名覆盖路由上的分流配置
+type TrafficManager struct {
+	store TrafficStore
+
+	mu     sync.RWMutex
+	splits map[string]*pkgRegistry.TrafficSplit
+}
+
+// NewTrafficManager 创建分流配置管理器
+func NewTrafficManager(store TrafficStore) *TrafficManager {
+	return &TrafficManager{
+		store:  store,
+		splits: make(map[string]*pkgRegistry.TrafficSplit),
+	}
+}
+
+// Get 获取服务的运行时分流配置
+func (m *TrafficManager) Get(service string) *pkgRegistry.TrafficSplit {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.splits[service]
+}
+
+// All 获取所有运行时分流配置的副本
+func (m *TrafficManager) All() map[string]*pkgRegistry.TrafficSplit {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	splits := make(map[string]*pkgRegistry.TrafficSplit, len(m.splits))
+	for k, v := range m.splits {
+		splits[k] = v
+	}
+	return splits
+}
+
+// Set 设置服务的运行时分流配置，split 为空时删除并恢复路由上的配置
+func (m *TrafficManager) Set(service string, split *pkgRegistry.TrafficSplit) error {
+	if split != nil {
+		if err := validateTrafficSplit(split); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.store.SaveTraffic(service, split); err != nil {
+		return fmt.Errorf("save traffic split: %w", err)
+	}
+
+	splits := make(map[string]*pkgRegistry.TrafficSplit, len(m.splits)+1)
+	for k, v := range m.splits {
+		splits[k] = v
+	}
+	if split == nil {
+		delete(splits, service)
+	} else {
+		splits[service] = split
+	}
+	m.splits = splits
+	return nil
+}
+
+// Sync 从存储同步其他网关副本的修改，存储不可用时保留本地配置
+func (m *TrafficManager) Sync() error {
+	splits, err := m.store.LoadTraffic()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.splits = splits
+	m.mu.Unlock()
+	return nil
+}
+
+// validateTrafficSplit 校验分流配置
+func validateTrafficSplit(split *pkgRegistry.TrafficSplit) error {
+	total := 0
+	for version, weight := range split.Weights {
+		if version == "" {
+			return errors.New("version must not be empty")
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight of %s must not be negative", version)
+		}
+		total += weight
+	}
+	if len(split.Weights) > 0 && total == 0 {
+		return errors.New("at least one version must have a positive weight")
+	}
+	return nil
+}
+
+// selectVersion 按分流配置筛选本次请求使用的服务版本
+// 优先级：指定版本（X-Service-Version）> 灰度标识（X-Canary）> 按权重分流；
+// 权重分流按用户ID（未登录时按客户端IP）哈希，同一用户稳定落在同一版本
+func (g *Gateway) selectVersion(r *http.Request, route *ServiceRoute, services []*registry.Service, claims *core.JWTClaims) ([]*registry.Service, error) {
+	if version := requestValue(r, pkgRegistry.HeaderServiceVersion, pkgRegistry.CookieServiceVersion); version != "" {
+		matched := filterVersion(services, version)
+		if len(matched) == 0 {
+			return nil, ErrVersionUnavailable
+		}
+		return matched, nil
+	}
+
+	split := g.traffic.Get(route.ServiceName)
+	if split == nil {
+		split = route.Traffic
+	}
+	if split == nil {
+		return services, nil
+	}
+
+	// 灰度版本未部署时按权重分流
+	if split.Canary != "" && isCanaryRequest(r) {
+		if matched := filterVersion(services, split.Canary); len(matched) > 0 {
+			return matched, nil
+		}
+	}
+
+	if version := pickVersion(split.Weights, services, trafficKey(claims, g.proxies.ClientIP(r))+"|"+route.ServiceName); version != "" {
+		return filterVersion(services, version), nil
+	}
+	// 权重中的版本均无节点时不区分版本
+	return services, nil
+}
+
+// pickVersion 在有节点的版本中按权重选择一个版本，没有可选版本时返回空字符串
+func pickVersion(weights map[string]int, services []*registry.Service, key string) string {
+	versions := make([]string, 0, len(weights))
+	total := 0
+	for version, weight := range weights {
+		if weight > 0 && len(filterVersion(services, version)) > 0 {
+			versions = append(versions, version)
+			total += weight
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	slices.Sort(versions)
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	bucket := int(h.Sum64() % uint64(total))
+	for _, version := range versions {
+		bucket -= weights[version]
+		if bucket < 0 {
+			return version
+		}
+	}
+	return versions[len(versions)-1]
+}
+
+// filterVersion 筛选指定版本的服务（只保留有节点的）
+func filterVersion(services []*registry.Service, version string) []*registry.Service {
+	var matched []*registry.Service
+	for _, svc := range services {
+		if svc.Version == version && len(svc.Nodes) > 0 {
+			matched = append(matched, svc)
+		}
+	}
+	return matched
+}
+
+// trafficKey 分流哈希键：已登录用户按用户ID，否则按客户端IP（ip 为可信代理解析后的客户端IP）
+func trafficKey(claims *core.JWTClaims, ip string) string {
+	if claims != nil {
+		return "user:" + strconv.FormatInt(claims.UserID, 10)
+	}
+	return "ip:" + ip
+}
+
+// isCanaryRequest 请求是否携带灰度标识
+func isCanaryRequest(r *http.Request) bool {
+	v, err := strconv.ParseBool(requestValue(r, pkgRegistry.HeaderCanary, pkgRegistry.CookieCanary))
+	return err == nil && v
+}
+
+// requestValue 读取请求头，缺失时读取同名含义的 Cookie
+func requestValue(r *http.Request, header, cookie string) string {
+	if v := strings.TrimSpace(r.Header.Get(header)); v != "" {
+		return v
+	}
+	if c, err := r.Cookie(cookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
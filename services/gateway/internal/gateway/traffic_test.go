@@ -0,0 +1,264 @@
+package gateway
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// memoryTrafficStore 进程内分流配置存储，模拟共享缓存服务
+type memoryTrafficStore struct {
+	mu     sync.Mutex
+	splits map[string]*pkgRegistry.TrafficSplit
+}
+
+func (s *memoryTrafficStore) LoadTraffic() (map[string]*pkgRegistry.TrafficSplit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.splits), nil
+}
+
+func (s *memoryTrafficStore) SaveTraffic(service string, split *pkgRegistry.TrafficSplit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.splits == nil {
+		s.splits = make(map[string]*pkgRegistry.TrafficSplit)
+	}
+	if split == nil {
+		delete(s.splits, service)
+	} else {
+		s.splits[service] = split
+	}
+	return nil
+}
+
+// registerVersion 注册指定版本的后端，后端返回自己的版本号
+func registerVersion(t *testing.T, reg registry.Registry, version string, route pkgRegistry.RouteConfig) {
+	t.Helper()
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(version))
+	})
+	err := reg.Register(pkgRegistry.NewServiceBuilder("order-service", version).
+		WithNodeID("order-" + version).
+		WithAddress(strings.TrimPrefix(backend.URL, "http://")).
+		WithBasePath("orders").
+		AddRoute(route).
+		Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTrafficGateway 创建注册了 v1、v2 两个版本的网关
+// 测试客户端默认作为本机的可信代理，以 X-Forwarded-For 模拟不同客户端
+func newTrafficGateway(t *testing.T, split *pkgRegistry.TrafficSplit, opts ...func(cfg *config.Config)) (*Gateway, string) {
+	t.Helper()
+
+	reg := registry.NewMemoryRegistry()
+	opts = append([]func(cfg *config.Config){func(cfg *config.Config) {
+		cfg.Gateway.TrustedProxies = []string{"127.0.0.1", "::1"}
+	}}, opts...)
+	gw, ts := newTestGateway(t, reg, opts...)
+	WithTrafficStore(&memoryTrafficStore{})(gw)
+
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("orders", "/items"))
+	route.Traffic = split
+	registerVersion(t, reg, "v1", route)
+	registerVersion(t, reg, "v2", route)
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+	return gw, ts.URL
+}
+
+// countVersions 以 n 个不同客户端IP（X-Forwarded-For）请求，统计各版本收到的请求数
+func countVersions(t *testing.T, url string, n int, headers map[string]string) map[string]int {
+	t.Helper()
+
+	counts := make(map[string]int)
+	for i := range n {
+		h := map[string]string{"X-Forwarded-For": fmt.Sprintf("10.0.%d.%d", i/256, i%256)}
+		for k, v := range headers {
+			h[k] = v
+		}
+		resp, body := doRequest(t, http.MethodGet, url+"/api/v1/orders/items", h)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+		}
+		counts[body]++
+	}
+	return counts
+}
+
+func TestGatewayTrafficSplit(t *testing.T) {
+	_, url := newTrafficGateway(t, &pkgRegistry.TrafficSplit{
+		Weights: map[string]int{"v1": 100, "v2": 0},
+		Canary:  "v2",
+	})
+
+	if counts := countVersions(t, url, 20, nil); counts["v1"] != 20 {
+		t.Fatalf("Expected all traffic on v1, got %v", counts)
+	}
+
+	// 灰度请求头与 Cookie
+	if counts := countVersions(t, url, 5, map[string]string{"X-Canary": "true"}); counts["v2"] != 5 {
+		t.Fatalf("Expected canary header to pin v2, got %v", counts)
+	}
+	if counts := countVersions(t, url, 5, map[string]string{"Cookie": "canary=true"}); counts["v2"] != 5 {
+		t.Fatalf("Expected canary cookie to pin v2, got %v", counts)
+	}
+
+	// 指定版本
+	if counts := countVersions(t, url, 5, map[string]string{"X-Service-Version": "v2"}); counts["v2"] != 5 {
+		t.Fatalf("Expected version header to pin v2, got %v", counts)
+	}
+	resp, body := doRequest(t, http.MethodGet, url+"/api/v1/orders/items", map[string]string{"X-Service-Version": "v3"})
+	assertErrorResponse(t, resp, body, http.StatusServiceUnavailable)
+}
+
+func TestGatewayTrafficSplitWeights(t *testing.T) {
+	_, url := newTrafficGateway(t, &pkgRegistry.TrafficSplit{
+		Weights: map[string]int{"v1": 50, "v2": 50},
+	})
+
+	counts := countVersions(t, url, 200, nil)
+	if counts["v1"] < 60 || counts["v2"] < 60 {
+		t.Fatalf("Expected traffic split between versions, got %v", counts)
+	}
+
+	// 同一客户端稳定落在同一版本
+	first := countVersions(t, url, 1, nil)
+	for range 10 {
+		if again := countVersions(t, url, 1, nil); fmt.Sprint(again) != fmt.Sprint(first) {
+			t.Fatalf("Expected sticky version %v, got %v", first, again)
+		}
+	}
+
+	// 不是可信代理时忽略客户端伪造的 X-Forwarded-For，无法借此选择版本
+	_, untrusted := newTrafficGateway(t, &pkgRegistry.TrafficSplit{
+		Weights: map[string]int{"v1": 50, "v2": 50},
+	}, func(cfg *config.Config) { cfg.Gateway.TrustedProxies = nil })
+	if counts := countVersions(t, untrusted, 20, nil); len(counts) != 1 {
+		t.Fatalf("Expected spoofed addresses to land on one version, got %v", counts)
+	}
+}
+
+func TestGatewayTrafficAdmin(t *testing.T) {
+	gw, url := newTrafficGateway(t, &pkgRegistry.TrafficSplit{
+		Weights: map[string]int{"v1": 100},
+	})
+
+	adminToken, err := gw.jwt.GenerateToken(1, "root", 1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, err := gw.jwt.GenerateToken(2, "alice", 2, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setTraffic := func(token, payload string) (*http.Response, string) {
+		headers := map[string]string{"Content-Type": "application/json"}
+		if token != "" {
+			headers["Authorization"] = "Bearer " + token
+		}
+		return doRequestWithBody(t, http.MethodPut, url+"/admin/traffic/order-service", strings.NewReader(payload), headers)
+	}
+
+	// 认证与授权
+	resp, body := setTraffic("", `{"weights":{"v2":100}}`)
+	assertErrorResponse(t, resp, body, http.StatusUnauthorized)
+	resp, body = setTraffic(userToken, `{"weights":{"v2":100}}`)
+	assertErrorResponse(t, resp, body, http.StatusForbidden)
+
+	// 参数校验
+	resp, body = setTraffic(adminToken, `{"weights":{"v1":-1}}`)
+	assertErrorResponse(t, resp, body, http.StatusBadRequest)
+	resp, body = setTraffic(adminToken, `{"weights":{"v1":0}}`)
+	assertErrorResponse(t, resp, body, http.StatusBadRequest)
+
+	// 运行时覆盖路由上的权重
+	resp, body = setTraffic(adminToken, `{"weights":{"v2":100}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	if counts := countVersions(t, url, 10, nil); counts["v2"] != 10 {
+		t.Fatalf("Expected runtime weights to move traffic to v2, got %v", counts)
+	}
+
+	resp, body = doRequest(t, http.MethodGet, url+"/admin/traffic", map[string]string{"Authorization": "Bearer " + adminToken})
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"order-service"`) {
+		t.Fatalf("Expected the override to be listed, got %d (%s)", resp.StatusCode, body)
+	}
+
+	// 删除后恢复路由上的配置
+	resp, body = doRequest(t, http.MethodDelete, url+"/admin/traffic/order-service", map[string]string{"Authorization": "Bearer " + adminToken})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	if counts := countVersions(t, url, 10, nil); counts["v1"] != 10 {
+		t.Fatalf("Expected route weights after delete, got %v", counts)
+	}
+}
+
+func TestTrafficManagerSync(t *testing.T) {
+	store := &memoryTrafficStore{}
+	replica1 := NewTrafficManager(store)
+	replica2 := NewTrafficManager(store)
+
+	split := &pkgRegistry.TrafficSplit{Weights: map[string]int{"v2": 10, "v1": 90}}
+	if err := replica1.Set("order-service", split); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replica2.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := replica2.Get("order-service"); got == nil || got.Weights["v2"] != 10 {
+		t.Fatalf("Expected replica to pick up the split, got %+v", got)
+	}
+
+	// 两个副本在同步前修改不同服务，互不覆盖
+	if err := replica1.Set("user-service", &pkgRegistry.TrafficSplit{Canary: "v2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := replica2.Set("order-service", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := replica2.Set("log-service", &pkgRegistry.TrafficSplit{Canary: "v3"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, replica := range []*TrafficManager{replica1, replica2} {
+		if err := replica.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		all := replica.All()
+		if len(all) != 2 || all["user-service"] == nil || all["log-service"] == nil {
+			t.Fatalf("Expected both replicas' changes to survive, got %+v", all)
+		}
+	}
+}
+
+func TestPickVersionSkipsMissingVersions(t *testing.T) {
+	services := []*registry.Service{
+		{Version: "v1", Nodes: []*registry.Node{{Id: "a"}}},
+		{Version: "v2"},
+	}
+
+	for i := range 20 {
+		if v := pickVersion(map[string]int{"v1": 1, "v2": 99}, services, fmt.Sprint(i)); v != "v1" {
+			t.Fatalf("Expected v1 when v2 has no nodes, got %q", v)
+		}
+	}
+	if v := pickVersion(map[string]int{"v3": 1}, services, "k"); v != "" {
+		t.Fatalf("Expected no version, got %q", v)
+	}
+}
@@ -0,0 +1,174 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+// compileTransform 校验转换规则并编译路径重写正则，没有路径重写时返回 nil
+func compileTransform(t *pkgRegistry.TransformConfig) (*regexp.Regexp, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.MaxBodySize < 0 {
+		return nil, errors.New("maxBodySize must not be negative")
+	}
+	for _, h := range []*pkgRegistry.HeaderTransform{t.RequestHeaders, t.ResponseHeaders} {
+		if err := validateHeaderTransform(h); err != nil {
+			return nil, err
+		}
+	}
+	for name := range t.Query {
+		if name == "" {
+			return nil, errors.New("query parameter name must not be empty")
+		}
+	}
+
+	if t.PathRewrite == nil {
+		return nil, nil
+	}
+	re, err := regexp.Compile(t.PathRewrite.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("path rewrite: %w", err)
+	}
+	return re, nil
+}
+
+// validateHeaderTransform 校验头转换规则
+func validateHeaderTransform(h *pkgRegistry.HeaderTransform) error {
+	if h == nil {
+		return nil
+	}
+	for name := range h.Add {
+		if name == "" {
+			return errors.New("header name must not be empty")
+		}
+	}
+	for _, name := range h.Remove {
+		if name == "" {
+			return errors.New("header name must not be empty")
+		}
+	}
+	for from, to := range h.Rename {
+		if from == "" || to == "" {
+			return errors.New("header name must not be empty")
+		}
+	}
+	return nil
+}
+
+// maxBodySize 路由的请求体大小上限，0 表示不限制
+func (r *ServiceRoute) maxBodySize() int64 {
+	if r.Transform == nil {
+		return 0
+	}
+	return r.Transform.MaxBodySize
+}
+
+// transformRequest 按路由规则改写转发的请求：路径重写、查询参数注入与请求头转换
+// 在去除网关前缀之后、写入网关身份头之前执行，规则无法伪造或覆盖网关签名的身份
+func transformRequest(req *http.Request, match *routeMatch) {
+	route := match.Route
+	t := route.Transform
+	if t == nil {
+		return
+	}
+
+	if route.rewrite != nil && route.rewrite.MatchString(req.URL.Path) {
+		req.URL.Path = route.rewrite.ReplaceAllString(req.URL.Path, t.PathRewrite.Replacement)
+		if !strings.HasPrefix(req.URL.Path, "/") {
+			req.URL.Path = "/" + req.URL.Path
+		}
+		req.URL.RawPath = ""
+	}
+
+	if len(t.Query) > 0 {
+		query := req.URL.Query()
+		for name, value := range t.Query {
+			query.Set(name, expandParams(value, match.Params))
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	applyHeaderTransform(req.Header, t.RequestHeaders, match.Params)
+}
+
+// transformResponse 按路由规则转换上游响应头
+func transformResponse(resp *http.Response, match *routeMatch) {
+	if t := match.Route.Transform; t != nil {
+		applyHeaderTransform(resp.Header, t.ResponseHeaders, match.Params)
+	}
+}
+
+// applyHeaderTransform 依次执行删除、重命名与添加
+func applyHeaderTransform(header http.Header, t *pkgRegistry.HeaderTransform, params map[string]string) {
+	if t == nil {
+		return
+	}
+	for _, name := range t.Remove {
+		header.Del(name)
+	}
+	for from, to := range t.Rename {
+		if values := header.Values(from); len(values) > 0 {
+			header.Del(from)
+			header[http.CanonicalHeaderKey(to)] = values
+		}
+	}
+	for name, value := range t.Add {
+		header.Set(name, expandParams(value, params))
+	}
+}
+
+// staticTransform 将静态路由的转换配置转换为路由转换规则
+func staticTransform(tc *config.GatewayTransformConfig) *pkgRegistry.TransformConfig {
+	if tc == nil {
+		return nil
+	}
+
+	t := &pkgRegistry.TransformConfig{
+		RequestHeaders:  staticHeaderTransform(tc.RequestHeaders),
+		ResponseHeaders: staticHeaderTransform(tc.ResponseHeaders),
+		MaxBodySize:     tc.MaxBodySize,
+	}
+	if tc.PathRewrite != nil {
+		t.PathRewrite = &pkgRegistry.PathRewrite{
+			Pattern:     tc.PathRewrite.Pattern,
+			Replacement: tc.PathRewrite.Replacement,
+		}
+	}
+	if len(tc.Query) > 0 {
+		t.Query = make(map[string]string, len(tc.Query))
+		for _, q := range tc.Query {
+			t.Query[q.Name] = q.Value
+		}
+	}
+	return t
+}
+
+// staticHeaderTransform 将静态路由的头转换配置转换为头转换规则
+func staticHeaderTransform(hc *config.GatewayHeaderTransformConfig) *pkgRegistry.HeaderTransform {
+	if hc == nil {
+		return nil
+	}
+
+	h := &pkgRegistry.HeaderTransform{Remove: hc.Remove}
+	if len(hc.Add) > 0 {
+		h.Add = make(map[string]string, len(hc.Add))
+		for _, a := range hc.Add {
+			h.Add[a.Name] = a.Value
+		}
+	}
+	if len(hc.Rename) > 0 {
+		h.Rename = make(map[string]string, len(hc.Rename))
+		for _, r := range hc.Rename {
+			h.Rename[r.From] = r.To
+		}
+	}
+	return h
+}
@@ -0,0 +1,189 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/config"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"go-micro.dev/v5/registry"
+)
+
+// echoRequest 后端收到的请求
+type echoRequest struct {
+	Path    string      `json:"path"`
+	Query   string      `json:"query"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+// newEchoBackend 回显请求并返回固定响应头的后端
+func newEchoBackend(t *testing.T) string {
+	t.Helper()
+
+	backend := newTestBackend(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Internal", "secret")
+		w.Header().Set("X-Backend-Version", "1.2.3")
+		json.NewEncoder(w).Encode(echoRequest{
+			Path:    r.URL.Path,
+			Query:   r.URL.RawQuery,
+			Headers: r.Header,
+			Body:    string(body),
+		})
+	})
+	return backend.URL
+}
+
+// newTransformGateway 创建转发到回显后端的网关，路由转换规则来自服务元数据
+func newTransformGateway(t *testing.T, route pkgRegistry.RouteConfig) string {
+	t.Helper()
+
+	reg := registry.NewMemoryRegistry()
+	gw, ts := newTestGateway(t, reg)
+	err := reg.Register(pkgRegistry.NewServiceBuilder("file-service", "v1.0.0").
+		WithAddress(strings.TrimPrefix(newEchoBackend(t), "http://")).
+		WithBasePath("files").
+		AddRoute(route).
+		Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.SyncRoutes(); err != nil {
+		t.Fatal(err)
+	}
+	return ts.URL
+}
+
+func decodeEcho(t *testing.T, resp *http.Response, body string) echoRequest {
+	t.Helper()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", resp.StatusCode, body)
+	}
+	var echo echoRequest
+	if err := json.Unmarshal([]byte(body), &echo); err != nil {
+		t.Fatalf("Invalid echo response %q: %v", body, err)
+	}
+	return echo
+}
+
+func TestGatewayTransformFromMetadata(t *testing.T) {
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("files", "/{tenant}/docs/*"))
+	route.Transform = &pkgRegistry.TransformConfig{
+		RequestHeaders: &pkgRegistry.HeaderTransform{
+			Add:    map[string]string{"X-Tenant": "{tenant}", auth.HeaderUserID: "999"},
+			Remove: []string{"X-Debug"},
+			Rename: map[string]string{"X-Old": "X-New"},
+		},
+		ResponseHeaders: &pkgRegistry.HeaderTransform{
+			Add:    map[string]string{"X-Gateway": "goback"},
+			Remove: []string{"X-Internal"},
+			Rename: map[string]string{"X-Backend-Version": "X-Version"},
+		},
+		PathRewrite: &pkgRegistry.PathRewrite{Pattern: `^/([^/]+)/docs/(.*)$`, Replacement: "/v2/$1/documents/$2"},
+		Query:       map[string]string{"source": "gateway", "tenant": "{tenant}"},
+	}
+	url := newTransformGateway(t, route)
+
+	resp, body := doRequest(t, http.MethodGet, url+"/api/v1/files/acme/docs/a.txt?source=client&page=2", map[string]string{
+		"X-Debug": "1",
+		"X-Old":   "value",
+	})
+	echo := decodeEcho(t, resp, body)
+
+	if echo.Path != "/v2/acme/documents/a.txt" {
+		t.Errorf("Expected rewritten path, got %q", echo.Path)
+	}
+	if echo.Query != "page=2&source=gateway&tenant=acme" {
+		t.Errorf("Expected injected query, got %q", echo.Query)
+	}
+	if got := echo.Headers.Get("X-Tenant"); got != "acme" {
+		t.Errorf("Expected X-Tenant from path param, got %q", got)
+	}
+	if echo.Headers.Get("X-Debug") != "" || echo.Headers.Get("X-Old") != "" || echo.Headers.Get("X-New") != "value" {
+		t.Errorf("Expected request headers to be removed and renamed, got %v", echo.Headers)
+	}
+	// 转换规则不能伪造网关签名的身份头
+	if got := echo.Headers.Get(auth.HeaderUserID); got != "" {
+		t.Errorf("Expected identity header to be stripped, got %q", got)
+	}
+
+	if resp.Header.Get("X-Internal") != "" || resp.Header.Get("X-Gateway") != "goback" ||
+		resp.Header.Get("X-Version") != "1.2.3" || resp.Header.Get("X-Backend-Version") != "" {
+		t.Errorf("Expected response headers to be transformed, got %v", resp.Header)
+	}
+}
+
+func TestGatewayTransformPathRewriteNoMatch(t *testing.T) {
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("files", "/legacy/*"))
+	route.Transform = &pkgRegistry.TransformConfig{
+		PathRewrite: &pkgRegistry.PathRewrite{Pattern: `^/legacy/(\d+)$`, Replacement: "/items/$1"},
+	}
+	url := newTransformGateway(t, route)
+
+	resp, body := doRequest(t, http.MethodGet, url+"/api/v1/files/legacy/42", nil)
+	if echo := decodeEcho(t, resp, body); echo.Path != "/items/42" {
+		t.Errorf("Expected rewritten path, got %q", echo.Path)
+	}
+	resp, body = doRequest(t, http.MethodGet, url+"/api/v1/files/legacy/abc", nil)
+	if echo := decodeEcho(t, resp, body); echo.Path != "/legacy/abc" {
+		t.Errorf("Expected unmatched path to be kept, got %q", echo.Path)
+	}
+}
+
+func TestGatewayTransformMaxBodySize(t *testing.T) {
+	route := pkgRegistry.NewPublicRoute(pkgRegistry.GatewayPath("files", "/upload"))
+	route.Transform = &pkgRegistry.TransformConfig{MaxBodySize: 16}
+	url := newTransformGateway(t, route)
+
+	resp, body := doRequestWithBody(t, http.MethodPost, url+"/api/v1/files/upload", strings.NewReader("small"), nil)
+	if echo := decodeEcho(t, resp, body); echo.Body != "small" {
+		t.Errorf("Expected body to be forwarded, got %q", echo.Body)
+	}
+
+	// 已知长度的请求体直接拒绝
+	large := strings.Repeat("x", 64)
+	resp, body = doRequestWithBody(t, http.MethodPost, url+"/api/v1/files/upload", strings.NewReader(large), nil)
+	assertErrorResponse(t, resp, body, http.StatusRequestEntityTooLarge)
+
+	// 分块传输的请求体在转发中途中止
+	resp, body = doRequestWithBody(t, http.MethodPost, url+"/api/v1/files/upload", io.MultiReader(strings.NewReader(large)), nil)
+	assertErrorResponse(t, resp, body, http.StatusRequestEntityTooLarge)
+}
+
+func TestStaticTransform(t *testing.T) {
+	tc := &config.GatewayTransformConfig{
+		RequestHeaders: &config.GatewayHeaderTransformConfig{
+			Add:    []config.GatewayNameValue{{Name: "X-Source", Value: "gateway"}},
+			Remove: []string{"Cookie"},
+			Rename: []config.GatewayRenameConfig{{From: "X-Token", To: "Authorization"}},
+		},
+		PathRewrite: &config.GatewayPathRewriteConfig{Pattern: `^/(\d+)$`, Replacement: "/item.php"},
+		Query:       []config.GatewayNameValue{{Name: "appId", Value: "42"}},
+		MaxBodySize: 1024,
+	}
+
+	got := staticTransform(tc)
+	if got.RequestHeaders.Add["X-Source"] != "gateway" || got.RequestHeaders.Rename["X-Token"] != "Authorization" ||
+		got.Query["appId"] != "42" || got.PathRewrite.Replacement != "/item.php" || got.MaxBodySize != 1024 {
+		t.Fatalf("Unexpected transform %+v", got)
+	}
+	if _, err := compileTransform(got); err != nil {
+		t.Fatal(err)
+	}
+
+	tc.PathRewrite.Pattern = "("
+	if _, err := compileTransform(staticTransform(tc)); err == nil {
+		t.Fatal("Expected invalid pattern to be rejected")
+	}
+	tc.PathRewrite = nil
+	tc.MaxBodySize = -1
+	if _, err := compileTransform(staticTransform(tc)); err == nil {
+		t.Fatal("Expected negative max body size to be rejected")
+	}
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"github.com/goback/pkg/dal"
+	pkgRegistry "github.com/goback/pkg/registry"
+)
+
+// GatewayRoute 网关路由覆盖配置模型
+// 按 Host + PathPrefix 覆盖注册中心下发的同名路由；Disabled 为 true 时禁用该路由
+type GatewayRoute struct {
+	dal.Model
+	*dal.Collection[GatewayRoute] `gorm:"-" json:"-"`
+	Host                          string                       `gorm:"column:host;size:255;not null;default:'';uniqueIndex:idx_gateway_route_key" json:"host"`
+	PathPrefix                    string                       `gorm:"column:path_prefix;size:255;not null;uniqueIndex:idx_gateway_route_key" json:"pathPrefix"`
+	ServiceName                   string                       `gorm:"column:service_name;size:100" json:"serviceName"`
+	TargetPrefix                  string                       `gorm:"column:target_prefix;size:255" json:"targetPrefix"`
+	StripPrefix                   bool                         `gorm:"column:strip_prefix;default:false" json:"stripPrefix"`
+	Methods                       []string                     `gorm:"column:methods;type:text;serializer:json" json:"methods"`
+	AuthRequired                  bool                         `gorm:"column:auth_required;default:false" json:"authRequired"`
+	LoadBalance                   string                       `gorm:"column:load_balance;size:50" json:"loadBalance"`
+	HashKey                       string                       `gorm:"column:hash_key;size:100" json:"hashKey"`
+	Idempotent                    bool                         `gorm:"column:idempotent;default:false" json:"idempotent"`
+	RateLimit                     *pkgRegistry.RateLimitConfig `gorm:"column:rate_limit;type:text;serializer:json" json:"rateLimit,omitempty"`
+	Traffic                       *pkgRegistry.TrafficSplit    `gorm:"column:traffic;type:text;serializer:json" json:"traffic,omitempty"`
+	Transform                     *pkgRegistry.TransformConfig `gorm:"column:transform;type:text;serializer:json" json:"transform,omitempty"`
+	Disabled                      bool                         `gorm:"column:disabled;default:false" json:"disabled"`
+	CreateBy                      int64                        `gorm:"column:create_by;default:0" json:"createBy"`
+	Remark                        string                       `gorm:"column:remark;size:500" json:"remark"`
+}
+
+// TableName 返回表名
+func (GatewayRoute) TableName() string {
+	return "gateway_route"
+}
+
+// GatewayRoutes 网关路由覆盖配置集合（全局单例）
+var GatewayRoutes = &GatewayRoute{
+	Collection: &dal.Collection[GatewayRoute]{
+		DefaultSort: "id",
+		MaxPerPage:  500,
+		FieldAlias: map[string]string{
+			"createdAt":    "created_at",
+			"updatedAt":    "updated_at",
+			"pathPrefix":   "path_prefix",
+			"serviceName":  "service_name",
+			"targetPrefix": "target_prefix",
+			"stripPrefix":  "strip_prefix",
+			"authRequired": "auth_required",
+			"loadBalance":  "load_balance",
+			"hashKey":      "hash_key",
+			"createBy":     "create_by",
+		},
+	},
+}
+
+// ListAll 获取所有路由覆盖配置
+func (c *GatewayRoute) ListAll() ([]GatewayRoute, error) {
+	var routes []GatewayRoute
+	err := c.DB().Order("id").Find(&routes).Error
+	return routes, err
+}
+
+// GetByKey 根据主机与路径规则获取路由覆盖配置，不存在时返回 nil
+func (c *GatewayRoute) GetByKey(host, pathPrefix string) (*GatewayRoute, error) {
+	var routes []GatewayRoute
+	err := c.DB().Where("host = ? AND path_prefix = ?", host, pathPrefix).Limit(1).Find(&routes).Error
+	if err != nil || len(routes) == 0 {
+		return nil, err
+	}
+	return &routes[0], nil
+}
+
+// Save 保存路由覆盖配置
+func (c *GatewayRoute) Save(data *GatewayRoute) error {
+	return c.DB().Save(data).Error
+}
+
+// HardDeleteByID 物理删除路由覆盖配置（Host + PathPrefix 唯一，软删除会阻止重新创建）
+func (c *GatewayRoute) HardDeleteByID(id int64) (int64, error) {
+	result := c.DB().Unscoped().Where("id = ?", id).Delete(&GatewayRoute{})
+	return result.RowsAffected, result.Error
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/log/internal/loginlog"
 	"github.com/goback/services/log/internal/model"
 	"github.com/goback/services/log/internal/operationlog"
@@ -35,6 +38,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -49,6 +59,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
 	})
@@ -76,20 +87,35 @@ func main() {
 		// 操作日志路由
 		opLogGroup := e.Router.Group("/operation-logs")
 		opLogGroup.Bind(jwtMiddleware)
-		opLogGroup.GET("", operationlog.List)
-		opLogGroup.DELETE("/{ids}", operationlog.Delete)
-		opLogGroup.DELETE("/clear", operationlog.Clear)
+		opLogGroup.GET("", operationlog.List).Describe("操作日志列表").Request(operationlog.ListRequest{}).PagedResponse(model.OperationLog{})
+		opLogGroup.DELETE("/{ids}", operationlog.Delete).Describe("删除操作日志")
+		opLogGroup.DELETE("/clear", operationlog.Clear).Describe("清空操作日志")
 
 		// 登录日志路由
 		loginLogGroup := e.Router.Group("/login-logs")
 		loginLogGroup.Bind(jwtMiddleware)
-		loginLogGroup.GET("", loginlog.List)
-		loginLogGroup.DELETE("/{ids}", loginlog.Delete)
-		loginLogGroup.DELETE("/clear", loginlog.Clear)
+		loginLogGroup.GET("", loginlog.List).Describe("登录日志列表").Request(loginlog.ListRequest{}).PagedResponse(model.LoginLog{})
+		loginLogGroup.DELETE("/{ids}", loginlog.Delete).Describe("删除登录日志")
+		loginLogGroup.DELETE("/clear", loginlog.Clear).Describe("清空登录日志")
 
 		return e.Next()
 	})
 
+	// 以队列方式订阅网关投递的访问日志（每批只投递给一个日志服务节点写入）
+	app.SubscribeQueueTopic(core.TopicAccessLog, func(payload []byte) error {
+		var records []core.AccessLogRecord
+		if err := json.Unmarshal(payload, &records); err != nil {
+			logger.Warn("解析访问日志失败", zap.Error(err))
+			return nil
+		}
+		// 写入失败时返回错误，由 Redis 服务重试投递，重试耗尽后进入死信主题
+		if err := operationlog.CreateLogs(records); err != nil {
+			logger.Error("写入访问日志失败", zap.Int("records", len(records)), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+
 	// 服务就绪事件
 	app.OnServiceReady().BindFunc(func(e *core.LifecycleEvent) error {
 		logger.Info("日志服务就绪", zap.String("addr", addr))
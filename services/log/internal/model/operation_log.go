@@ -16,7 +16,8 @@ type OperationLog struct {
 	Body                          string `gorm:"type:text" json:"body"`
 	IP                            string `gorm:"size:50" json:"ip"`
 	UserAgent                     string `gorm:"size:500" json:"userAgent"`
-	Status                        int    `gorm:"default:1" json:"status"` // 1:成功 0:失败
+	Status                        int    `gorm:"default:1" json:"status"`     // 1:成功 0:失败
+	StatusCode                    int    `gorm:"default:0" json:"statusCode"` // HTTP 状态码
 	ErrorMessage                  string `gorm:"type:text" json:"errorMessage"`
 	Duration                      int64  `gorm:"default:0" json:"duration"` // 执行时长(ms)
 }
@@ -29,10 +30,11 @@ var OperationLogs = &OperationLog{
 		DefaultSort: "-id",
 		MaxPerPage:  100,
 		FieldAlias: map[string]string{
-			"createdAt": "created_at",
-			"updatedAt": "updated_at",
-			"userId":    "user_id",
-			"userAgent": "user_agent",
+			"createdAt":  "created_at",
+			"updatedAt":  "updated_at",
+			"userId":     "user_id",
+			"userAgent":  "user_agent",
+			"statusCode": "status_code",
 		},
 	},
 }
@@ -2,6 +2,7 @@ package operationlog
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/goback/pkg/app/apis"
@@ -49,6 +50,54 @@ func CreateLog(log *model.OperationLog) error {
 	return model.OperationLogs.Create(log)
 }
 
+// CreateLogs 批量写入网关投递的访问日志
+func CreateLogs(records []core.AccessLogRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	logs := make([]model.OperationLog, 0, len(records))
+	for _, r := range records {
+		log := model.OperationLog{
+			UserID:     r.UserID,
+			Username:   r.Username,
+			Module:     r.Module,
+			Action:     methodAction(r.Method),
+			Method:     r.Method,
+			Path:       r.Path,
+			Query:      r.Query,
+			IP:         r.IP,
+			UserAgent:  r.UserAgent,
+			Status:     1,
+			StatusCode: r.Status,
+			Duration:   r.Duration,
+		}
+		// 使用请求时间作为创建时间
+		log.CreatedAt = r.Time
+		if r.Status >= http.StatusBadRequest {
+			log.Status = 0
+			log.ErrorMessage = http.StatusText(r.Status)
+		}
+		logs = append(logs, log)
+	}
+	return model.OperationLogs.CreateBatch(logs)
+}
+
+// methodAction 按 HTTP 方法推断操作类型
+func methodAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return "查询"
+	case http.MethodPost:
+		return "新增"
+	case http.MethodPut, http.MethodPatch:
+		return "修改"
+	case http.MethodDelete:
+		return "删除"
+	default:
+		return method
+	}
+}
+
 // parseIDs 解析逗号分隔的ID字符串
 func parseIDs(idsStr string) ([]int64, error) {
 	parts := strings.Split(idsStr, ",")
@@ -8,10 +8,12 @@ import (
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/menu/internal/menu"
 	"github.com/goback/services/menu/internal/model"
 	"go.uber.org/zap"
@@ -35,6 +37,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -49,6 +58,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		IsDev:          cfg.App.Env == "dev",
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -94,17 +104,17 @@ func main() {
 		// 菜单路由组
 		menuGroup := e.Router.Group("/menus")
 		menuGroup.Bind(jwtMiddleware)
-		menuGroup.POST("", menu.Create)
-		menuGroup.PUT("/{id}", menu.Update)
-		menuGroup.DELETE("/{id}", menu.Delete)
-		menuGroup.GET("/{id}", menu.Get)
-		menuGroup.GET("", menu.List)
-		menuGroup.GET("/tree", menu.GetTree)
-		menuGroup.GET("/user/tree", menu.GetUserMenuTree)
+		menuGroup.POST("", menu.Create).Describe("创建菜单").Request(menu.CreateRequest{}).Response(model.Menu{})
+		menuGroup.PUT("/{id}", menu.Update).Describe("更新菜单").Request(menu.UpdateRequest{}).Response(model.Menu{})
+		menuGroup.DELETE("/{id}", menu.Delete).Describe("删除菜单")
+		menuGroup.GET("/{id}", menu.Get).Describe("获取菜单").Response(model.Menu{})
+		menuGroup.GET("", menu.List).Describe("菜单列表").Request(menu.ListRequest{}).Response([]model.Menu{})
+		menuGroup.GET("/tree", menu.GetTree).Describe("菜单树").Response([]model.Menu{})
+		menuGroup.GET("/user/tree", menu.GetUserMenuTree).Describe("当前用户的菜单树").Response([]model.Menu{})
 		// 角色菜单关联
-		menuGroup.GET("/role/{roleId}", menu.GetRoleMenus)
-		menuGroup.PUT("/role/{roleId}", menu.SetRoleMenus)
-		menuGroup.GET("/role/{roleId}/tree", menu.GetRoleMenuTree)
+		menuGroup.GET("/role/{roleId}", menu.GetRoleMenus).Describe("获取角色菜单").Response([]model.Menu{})
+		menuGroup.PUT("/role/{roleId}", menu.SetRoleMenus).Describe("设置角色菜单").Request(menu.SetRoleMenusRequest{})
+		menuGroup.GET("/role/{roleId}/tree", menu.GetRoleMenuTree).Describe("角色菜单树").Response([]model.Menu{})
 
 		return e.Next()
 	})
@@ -8,10 +8,12 @@ import (
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/rbac/internal/common"
 	"github.com/goback/services/rbac/internal/model"
 	"github.com/goback/services/rbac/internal/permission"
@@ -38,6 +40,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -52,6 +61,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		IsDev:          cfg.App.Env == "dev",
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -107,37 +117,38 @@ func main() {
 		// 角色路由组
 		roleGroup := e.Router.Group("/roles")
 		roleGroup.Bind(jwtMiddleware)
-		roleGroup.POST("", role.Create)
-		roleGroup.PUT("/{id}", role.Update)
-		roleGroup.DELETE("/{id}", role.Delete)
-		roleGroup.GET("/{id}", role.Get)
-		roleGroup.GET("", role.List)
-		roleGroup.GET("/all", role.GetAll)
-		roleGroup.GET("/tree", role.GetTree)
-		roleGroup.GET("/{id}/permissions", role.GetPermissions)
-		roleGroup.PUT("/{id}/permissions", role.SetPermissions)
-		roleGroup.GET("/{id}/all-permissions", role.GetAllPermissions)
-		roleGroup.POST("/cache/refresh", role.RefreshCache)
+		roleGroup.POST("", role.Create).Describe("创建角色").Request(role.CreateRequest{}).Response(model.Role{})
+		roleGroup.PUT("/{id}", role.Update).Describe("更新角色").Request(role.UpdateRequest{}).Response(model.Role{})
+		roleGroup.DELETE("/{id}", role.Delete).Describe("删除角色")
+		roleGroup.GET("/{id}", role.Get).Describe("获取角色").Response(model.Role{})
+		roleGroup.GET("", role.List).Describe("角色列表").Request(role.ListRequest{}).PagedResponse(model.Role{})
+		roleGroup.GET("/all", role.GetAll).Describe("全部角色").Response([]model.Role{})
+		roleGroup.GET("/tree", role.GetTree).Describe("角色树").Response([]model.Role{})
+		roleGroup.GET("/{id}/permissions", role.GetPermissions).Describe("获取角色权限").Response([]model.Permission{})
+		roleGroup.PUT("/{id}/permissions", role.SetPermissions).Describe("设置角色权限").Request(role.SetPermissionsRequest{})
+		roleGroup.GET("/{id}/all-permissions", role.GetAllPermissions).Describe("获取角色及子角色的全部权限").Response([]model.Permission{})
+		roleGroup.POST("/cache/refresh", role.RefreshCache).Describe("刷新角色缓存")
 
 		// 权限路由组
 		permGroup := e.Router.Group("/permissions")
 		permGroup.Bind(jwtMiddleware)
-		permGroup.POST("", permission.Create)
-		permGroup.PUT("/{id}", permission.Update)
-		permGroup.DELETE("/{id}", permission.Delete)
-		permGroup.GET("/{id}", permission.Get)
-		permGroup.GET("", permission.List)
-		permGroup.GET("/all", permission.GetAll)
+		permGroup.POST("", permission.Create).Describe("创建权限").Request(permission.CreateRequest{}).Response(model.Permission{})
+		permGroup.PUT("/{id}", permission.Update).Describe("更新权限").Request(permission.UpdateRequest{}).Response(model.Permission{})
+		permGroup.DELETE("/{id}", permission.Delete).Describe("删除权限")
+		permGroup.GET("/{id}", permission.Get).Describe("获取权限").Response(model.Permission{})
+		permGroup.GET("", permission.List).Describe("权限列表").Request(permission.ListRequest{}).PagedResponse(model.Permission{})
+		permGroup.GET("/all", permission.GetAll).Describe("全部权限").Response([]model.Permission{})
 
 		// 权限范围路由组
 		scopeGroup := e.Router.Group("/permission-scopes")
 		scopeGroup.Bind(jwtMiddleware)
-		scopeGroup.POST("", permissionscope.Create)
-		scopeGroup.PUT("/{id}", permissionscope.Update)
-		scopeGroup.DELETE("/{id}", permissionscope.Delete)
-		scopeGroup.GET("/{id}", permissionscope.Get)
-		scopeGroup.GET("", permissionscope.List)
-		scopeGroup.GET("/by-permission/{permissionId}", permissionscope.GetByPermission)
+		scopeGroup.POST("", permissionscope.Create).Describe("创建数据权限范围").Request(permissionscope.CreateRequest{}).Response(model.PermissionScope{})
+		scopeGroup.PUT("/{id}", permissionscope.Update).Describe("更新数据权限范围").Request(permissionscope.UpdateRequest{}).Response(model.PermissionScope{})
+		scopeGroup.DELETE("/{id}", permissionscope.Delete).Describe("删除数据权限范围")
+		scopeGroup.GET("/{id}", permissionscope.Get).Describe("获取数据权限范围").Response(model.PermissionScope{})
+		scopeGroup.GET("", permissionscope.List).Describe("数据权限范围列表").Request(permissionscope.ListRequest{}).PagedResponse(model.PermissionScope{})
+		scopeGroup.GET("/by-permission/{permissionId}", permissionscope.GetByPermission).
+			Describe("按权限获取数据权限范围").Response([]model.PermissionScope{})
 
 		return e.Next()
 	})
@@ -37,7 +37,7 @@ func Create(e *core.RequestEvent) error {
 		return apis.Error(e, 500, err.Error())
 	}
 
-	e.App.PublishTopicJSON(core.KeyRBACData, common.LoadRBACData())
+	e.App.PublishTopicJSONContext(e.Request.Context(), core.KeyRBACData, common.LoadRBACData())
 	return apis.Success(e, scope)
 }
 
@@ -76,7 +76,7 @@ func Update(e *core.RequestEvent) error {
 		return apis.Error(e, 500, err.Error())
 	}
 
-	e.App.PublishTopicJSON(core.KeyRBACData, common.LoadRBACData())
+	e.App.PublishTopicJSONContext(e.Request.Context(), core.KeyRBACData, common.LoadRBACData())
 	return apis.Success(e, scope)
 }
 
@@ -89,7 +89,7 @@ func Delete(e *core.RequestEvent) error {
 	if err := model.PermissionScopes.DeleteByID(id); err != nil {
 		return apis.Error(e, 500, err.Error())
 	}
-	e.App.PublishTopicJSON(core.KeyRBACData, common.LoadRBACData())
+	e.App.PublishTopicJSONContext(e.Request.Context(), core.KeyRBACData, common.LoadRBACData())
 	return apis.Success(e, nil)
 }
 
@@ -7,7 +7,9 @@ import (
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/logger"
+	"github.com/goback/pkg/metrics"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	"github.com/goback/services/redis/internal/redis"
 	"go.uber.org/zap"
 )
@@ -30,16 +32,24 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
 	logger.Info("启动 Redis 缓存服务", zap.String("service", serviceName))
 
 	// 服务地址
 	addr := fmt.Sprintf("%s:%d", cfg.Server.HTTP.Host, servicePort)
 
-	// 创建 Redis 服务
-	svc := redis.NewService(serviceName)
+	// 创建 Redis 服务（开启持久化时启动时从数据目录恢复，超出内存上限时按策略淘汰）
+	svc := redis.NewService(serviceName,
+		redis.WithPersistence(cfg.Redis.Persistence),
+		redis.WithMemoryLimit(cfg.Redis.Memory),
+	)
+	metrics.MustRegister(svc)
 
-	// 创建 PubSub 服务
-	pubsubSvc := redis.NewPubSubService()
+	// 创建 PubSub 服务（推送失败时按配置重试，多次失败后转入死信主题）
+	pubsubSvc := redis.NewPubSubService(redis.WithDelivery(cfg.Redis.PubSub))
+	metrics.MustRegister(pubsubSvc)
 
 	// 创建 BaseApp（Redis 服务使用内存注册中心，因为它是基础设施服务）
 	app := core.NewBaseApp(core.BaseAppConfig{
@@ -51,11 +61,21 @@ func main() {
 		DisablePubSub:  true,                            // Redis 服务是 PubSub 中心，不需要 PubSub 客户端
 	})
 
+	// RESP2 监听（与 HTTP 接口共用数据，可供 redis-cli 或 standalone 模式的客户端使用）
+	respSvc := redis.NewRESPServer(svc, pubsubSvc, redis.WithRESPPassword(cfg.Redis.Password))
+
 	// 启动时初始化缓存服务
 	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
 		if err := svc.Start(); err != nil {
 			return fmt.Errorf("缓存服务启动失败: %w", err)
 		}
+		if cfg.Redis.RESPPort > 0 {
+			respAddr := fmt.Sprintf("%s:%d", cfg.Server.HTTP.Host, cfg.Redis.RESPPort)
+			if err := respSvc.Listen(respAddr); err != nil {
+				return fmt.Errorf("RESP 监听失败: %w", err)
+			}
+			logger.Info("RESP 监听已启动", zap.String("addr", respAddr))
+		}
 		return e.Next()
 	})
 
@@ -81,6 +101,7 @@ func main() {
 	// 服务停止事件
 	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
 		logger.Info("正在关闭服务...")
+		_ = respSvc.Close()
 		pubsubSvc.Stop()
 		if err := svc.Stop(); err != nil {
 			logger.Error("停止服务失败", zap.Error(err))
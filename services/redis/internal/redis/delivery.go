@@ -0,0 +1,341 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DeadLetterTopic 死信主题
+// 投递失败达到最大次数、队列已满被挤出或订阅者过期时未送达的消息，以 DeadLetter（JSON）为内容发布到该主题；
+// 死信主题自身的消息未送达时只记录日志
+const DeadLetterTopic = "pubsub:deadletter"
+
+// 默认投递参数
+const (
+	DefaultDeliveryAttempts  = 8
+	DefaultRetryBackoff      = 200 * time.Millisecond
+	DefaultMaxRetryBackoff   = 30 * time.Second
+	DefaultDeliveryQueueSize = 1000
+)
+
+// deadLetterHistory 保留的最近死信数量
+const deadLetterHistory = 100
+
+var (
+	errQueueFull         = errors.New("delivery queue full")
+	errSubscriberExpired = errors.New("subscriber expired")
+)
+
+// DeadLetter 未送达的消息
+type DeadLetter struct {
+	Message    *PubSubMessage `json:"message"`
+	Subscriber string         `json:"subscriber"`
+	Node       string         `json:"node"`
+	Attempts   int            `json:"attempts"` // 已尝试的投递次数
+	Error      string         `json:"error"`
+	FailedAt   time.Time      `json:"failed_at"`
+}
+
+// deliveryConfig 投递参数
+type deliveryConfig struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	queueSize   int
+}
+
+// WithDelivery 设置投递重试参数（未设置的项使用默认值）
+func WithDelivery(cfg config.RedisPubSubConfig) PubSubOption {
+	return func(ps *PubSubService) {
+		if cfg.MaxAttempts > 0 {
+			ps.delivery.maxAttempts = cfg.MaxAttempts
+		}
+		if cfg.RetryBackoff > 0 {
+			ps.delivery.backoff = time.Duration(cfg.RetryBackoff) * time.Millisecond
+		}
+		if cfg.MaxBackoff > 0 {
+			ps.delivery.maxBackoff = time.Duration(cfg.MaxBackoff) * time.Second
+		}
+		if cfg.QueueSize > 0 {
+			ps.delivery.queueSize = cfg.QueueSize
+		}
+	}
+}
+
+// delivery 待投递的消息，data 为推送的请求体（同一条消息的所有订阅者共用）
+type delivery struct {
+	msg  *PubSubMessage
+	data []byte
+}
+
+// deliveryQueue 单个订阅节点的投递队列
+// 由一个协程按发布顺序逐条推送：订阅方返回 2xx 视为确认，失败时按指数退避重试同一条消息，
+// 达到最大次数后转入死信再继续下一条，因此同一订阅者收到的消息保持发布顺序
+type deliveryQueue struct {
+	ps      *PubSubService
+	service string
+	node    string
+
+	mu      sync.Mutex
+	pending []*delivery
+	closed  bool
+	wake    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	delivered   atomic.Uint64
+	deadLetters atomic.Uint64
+}
+
+func newDeliveryQueue(ps *PubSubService, service, node string) *deliveryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &deliveryQueue{
+		ps:      ps,
+		service: service,
+		node:    node,
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// enqueue 加入队列，队列已满时最早的待投递消息转入死信
+func (q *deliveryQueue) enqueue(d *delivery) {
+	q.mu.Lock()
+	if q.closed {
+		// 发布时订阅者恰好过期
+		q.mu.Unlock()
+		q.deadLetter(d, 0, errSubscriberExpired)
+		return
+	}
+	var dropped *delivery
+	if len(q.pending) >= q.ps.delivery.queueSize {
+		dropped = q.pending[0]
+		q.pending = q.pending[1:]
+	}
+	q.pending = append(q.pending, d)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	if dropped != nil {
+		q.deadLetter(dropped, 0, errQueueFull)
+	}
+}
+
+// stats 返回待投递、已确认与转入死信的消息数
+func (q *deliveryQueue) stats() (int, uint64, uint64) {
+	q.mu.Lock()
+	pending := len(q.pending)
+	q.mu.Unlock()
+	return pending, q.delivered.Load(), q.deadLetters.Load()
+}
+
+// close 停止投递并等待协程退出，返回未投递的消息（包括正在重试的消息）
+func (q *deliveryQueue) close() []*delivery {
+	q.cancel()
+	<-q.done
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
+
+func (q *deliveryQueue) run() {
+	defer close(q.done)
+
+	for {
+		d, ok := q.next()
+		if !ok {
+			return
+		}
+		if !q.deliver(d) {
+			// 重试中被停止，放回队首
+			q.mu.Lock()
+			q.pending = append([]*delivery{d}, q.pending...)
+			q.mu.Unlock()
+			return
+		}
+	}
+}
+
+// next 取出队首消息，队列为空时等待，停止时返回 false
+func (q *deliveryQueue) next() (*delivery, bool) {
+	for {
+		if q.ctx.Err() != nil {
+			return nil, false
+		}
+
+		q.mu.Lock()
+		if len(q.pending) > 0 {
+			d := q.pending[0]
+			q.pending[0] = nil
+			q.pending = q.pending[1:]
+			q.mu.Unlock()
+			return d, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.wake:
+		case <-q.ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// deliver 推送一条消息直到确认或达到最大次数（之后转入死信），队列停止时返回 false
+func (q *deliveryQueue) deliver(d *delivery) bool {
+	cfg := q.ps.delivery
+	backoff := cfg.backoff
+	for attempt := 1; ; attempt++ {
+		err := q.ps.push(q.ctx, q.node, d.data)
+		if err == nil {
+			q.delivered.Add(1)
+			q.ps.counters.delivered.Add(1)
+			return true
+		}
+		if q.ctx.Err() != nil {
+			return false
+		}
+		if attempt >= cfg.maxAttempts {
+			q.deadLetter(d, attempt, err)
+			return true
+		}
+
+		q.ps.counters.retries.Add(1)
+		logger.Debug("push to subscriber failed, retrying",
+			zap.String("service", q.service),
+			zap.String("node", q.node),
+			zap.String("id", d.msg.ID),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-q.ctx.Done():
+			t.Stop()
+			return false
+		}
+		backoff = min(backoff*2, cfg.maxBackoff)
+	}
+}
+
+func (q *deliveryQueue) deadLetter(d *delivery, attempts int, err error) {
+	q.deadLetters.Add(1)
+	q.ps.deadLetter(&DeadLetter{
+		Message:    d.msg,
+		Subscriber: q.service,
+		Node:       q.node,
+		Attempts:   attempts,
+		Error:      err.Error(),
+		FailedAt:   time.Now(),
+	})
+}
+
+// push 推送给订阅节点当前的回调地址（节点重新注册后重试发往新地址），2xx 视为确认
+func (ps *PubSubService) push(ctx context.Context, node string, data []byte) error {
+	ps.mu.RLock()
+	sub, ok := ps.subscribers[node]
+	var addr string
+	if ok {
+		addr = sub.CallbackAddr
+	}
+	ps.mu.RUnlock()
+	if !ok {
+		return errSubscriberExpired
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+addr+"/_pubsub", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ps.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deadLetter 记录死信并发布到死信主题
+func (ps *PubSubService) deadLetter(dl *DeadLetter) {
+	ps.counters.deadLetters.Add(1)
+	logger.Warn("message moved to dead letter",
+		zap.String("topic", dl.Message.Topic),
+		zap.String("id", dl.Message.ID),
+		zap.String("subscriber", dl.Subscriber),
+		zap.String("node", dl.Node),
+		zap.Int("attempts", dl.Attempts),
+		zap.String("error", dl.Error),
+	)
+
+	ps.mu.Lock()
+	if len(ps.deadLetters) >= deadLetterHistory {
+		ps.deadLetters = ps.deadLetters[1:]
+	}
+	ps.deadLetters = append(ps.deadLetters, dl)
+	ps.mu.Unlock()
+
+	if dl.Message.Topic == DeadLetterTopic {
+		return
+	}
+	payload, err := json.Marshal(dl)
+	if err != nil {
+		logger.Error("marshal dead letter failed", zap.Error(err))
+		return
+	}
+	_, _ = ps.Publish(&PubSubMessage{
+		Topic:       DeadLetterTopic,
+		Payload:     payload,
+		Timestamp:   time.Now(),
+		TraceParent: dl.Message.TraceParent,
+	})
+}
+
+// nextMessageID 生成消息ID，格式与 Redis Stream 相同：<毫秒时间戳>-<同一毫秒内的序号>，单调递增
+func (ps *PubSubService) nextMessageID() string {
+	ps.idMu.Lock()
+	defer ps.idMu.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms > ps.lastIDTime {
+		ps.lastIDTime = ms
+		ps.lastIDSeq = 0
+	} else {
+		ps.lastIDSeq++
+	}
+	return strconv.FormatInt(ps.lastIDTime, 10) + "-" + strconv.FormatUint(ps.lastIDSeq, 10)
+}
@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"maps"
+	"math"
+	"strconv"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// HSetRequest 哈希写入请求
+type HSetRequest struct {
+	Key    string            `json:"key"`
+	Fields map[string][]byte `json:"fields"`
+}
+
+// HFieldRequest 哈希字段请求
+type HFieldRequest struct {
+	Key   string `json:"key"`
+	Field string `json:"field"`
+}
+
+// HDelRequest 哈希字段删除请求
+type HDelRequest struct {
+	Key    string   `json:"key"`
+	Fields []string `json:"fields"`
+}
+
+// HIncrByRequest 哈希字段自增请求
+type HIncrByRequest struct {
+	Key   string `json:"key"`
+	Field string `json:"field"`
+	Delta int64  `json:"delta"`
+}
+
+func (s *Service) handleHSet(e *core.RequestEvent) error {
+	var req HSetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	added, err := s.HSet(req.Key, req.Fields)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"added": added})
+}
+
+func (s *Service) handleHGet(e *core.RequestEvent) error {
+	var req HFieldRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	value, ok, err := s.HGet(req.Key, req.Field)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, GetResponse{Value: value, Found: ok})
+}
+
+func (s *Service) handleHGetAll(e *core.RequestEvent) error {
+	var req GetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	fields, err := s.HGetAll(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"fields": fields})
+}
+
+func (s *Service) handleHDel(e *core.RequestEvent) error {
+	var req HDelRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	removed, err := s.HDel(req.Key, req.Fields...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"removed": removed})
+}
+
+func (s *Service) handleHIncrBy(e *core.RequestEvent) error {
+	var req HIncrByRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	value, err := s.HIncrBy(req.Key, req.Field, req.Delta)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"value": value})
+}
+
+// HSet 写入哈希字段，返回新增字段数
+func (s *Service) HSet(key string, fields map[string][]byte) (int, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindHash)
+	if err != nil {
+		return 0, err
+	}
+	added := len(fields)
+	if ok {
+		for f := range fields {
+			if _, exists := it.hash[f]; exists {
+				added--
+			}
+		}
+	}
+	if err := s.commitLocked(&record{Op: opHSet, Key: key, Fields: fields}); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// HGet 获取哈希字段
+func (s *Service) HGet(key, field string) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindHash)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	value, ok := it.hash[field]
+	return value, ok, nil
+}
+
+// HGetAll 获取哈希的全部字段，键不存在时返回空 map
+func (s *Service) HGetAll(key string) (map[string][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindHash)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string][]byte{}, nil
+	}
+	return maps.Clone(it.hash), nil
+}
+
+// HDel 删除哈希字段，返回实际删除的数量；字段全部删除后删除键
+func (s *Service) HDel(key string, fields ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindHash)
+	if err != nil || !ok {
+		return 0, err
+	}
+	var existing []string
+	for _, f := range fields {
+		if _, ok := it.hash[f]; ok {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) == 0 {
+		return 0, nil
+	}
+	if err := s.commitLocked(&record{Op: opHDel, Key: key, Members: existing}); err != nil {
+		return 0, err
+	}
+	return len(existing), nil
+}
+
+// HIncrBy 原子地将哈希字段的整数值增加 delta，字段不存在时从 0 开始
+func (s *Service) HIncrBy(key, field string, delta int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindHash)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	if ok {
+		if value, exists := it.hash[field]; exists {
+			if current, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+				return 0, ErrNotInteger
+			}
+		}
+	}
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, ErrNotInteger
+	}
+	current += delta
+
+	value := []byte(strconv.FormatInt(current, 10))
+	if err := s.commitLocked(&record{Op: opHSet, Key: key, Fields: map[string][]byte{field: value}}); err != nil {
+		return 0, err
+	}
+	return current, nil
+}
@@ -0,0 +1,463 @@
+package redis
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// ScanRequest 增量遍历请求
+type ScanRequest struct {
+	Cursor uint64 `json:"cursor"` // 0 表示开始
+	Match  string `json:"match"`  // glob 模式，为空表示全部
+	Count  int    `json:"count"`  // 每批约返回的键数，默认 10
+}
+
+// ScanResponse 增量遍历响应
+type ScanResponse struct {
+	Keys   []string `json:"keys"`
+	Cursor uint64   `json:"cursor"` // 0 表示遍历结束
+}
+
+// MGetRequest 批量获取请求
+type MGetRequest struct {
+	Keys []string `json:"keys"`
+}
+
+// MSetRequest 批量设置请求
+type MSetRequest struct {
+	Values map[string][]byte `json:"values"`
+	TTL    int64             `json:"ttl"` // 秒，0 表示永不过期
+}
+
+// PrefixRequest 按前缀操作请求
+type PrefixRequest struct {
+	Prefix string `json:"prefix"`
+}
+
+func (s *Service) handleScan(e *core.RequestEvent) error {
+	var req ScanRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	keys, cursor := s.Scan(req.Cursor, req.Match, req.Count)
+	return e.JSON(200, ScanResponse{Keys: keys, Cursor: cursor})
+}
+
+func (s *Service) handleMGet(e *core.RequestEvent) error {
+	var req MGetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"values": s.MGet(req.Keys...)})
+}
+
+func (s *Service) handleMSet(e *core.RequestEvent) error {
+	var req MSetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	if err := s.MSet(req.Values, time.Duration(req.TTL)*time.Second); err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"ok": true})
+}
+
+func (s *Service) handleGetPrefix(e *core.RequestEvent) error {
+	var req PrefixRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"values": s.GetByPrefix(req.Prefix)})
+}
+
+func (s *Service) handleDeletePrefix(e *core.RequestEvent) error {
+	var req PrefixRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	deleted, err := s.DeleteByPrefix(req.Prefix)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"deleted": deleted})
+}
+
+// SetOptions 条件写入参数
+type SetOptions struct {
+	TTL         time.Duration // 过期时间，0 表示永不过期
+	OnlyMissing bool          // 仅键不存在时写入（NX）
+	OnlyExists  bool          // 仅键存在时写入（XX）
+}
+
+// SetWithOptions 按条件写入键，返回是否写入
+func (s *Service) SetWithOptions(key string, value []byte, opts SetOptions) (bool, error) {
+	var exp int64
+	if opts.TTL > 0 {
+		exp = time.Now().Add(opts.TTL).UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	exists := ok && !it.expired()
+	if (opts.OnlyMissing && exists) || (opts.OnlyExists && !exists) {
+		return false, nil
+	}
+	if err := s.putLocked(key, &item{Value: value, Expiration: exp}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetNX 仅键不存在时写入，返回是否写入
+func (s *Service) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.SetWithOptions(key, value, SetOptions{TTL: ttl, OnlyMissing: true})
+}
+
+// CompareAndSwap 键的当前值等于 old 时替换为 value 并按 ttl 重设过期时间（0 表示永不过期）
+// 键不存在时返回 false
+func (s *Service) CompareAndSwap(key string, old, value []byte, ttl time.Duration) (bool, error) {
+	var exp int64
+	if ttl > 0 {
+		exp = time.Now().Add(ttl).UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindString)
+	if err != nil || !ok || !bytes.Equal(it.Value, old) {
+		return false, err
+	}
+	if err := s.putLocked(key, &item{Value: value, Expiration: exp}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompareAndDelete 键的当前值等于 old 时删除，返回是否删除
+func (s *Service) CompareAndDelete(key string, old []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindString)
+	if err != nil || !ok || !bytes.Equal(it.Value, old) {
+		return false, err
+	}
+	if err := s.commitLocked(&record{Op: opDel, Key: key}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MGet 批量获取字符串键，与 keys 一一对应，不存在或不是字符串的键为 nil
+func (s *Service) MGet(keys ...string) [][]byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if it, ok, err := s.lookup(key, KindString); err == nil && ok {
+			values[i] = append([]byte{}, it.Value...) // 空字符串也返回非 nil
+		}
+	}
+	return values
+}
+
+// MSet 在同一把锁内写入多个字符串键（覆盖原有类型），ttl 为 0 表示永不过期
+func (s *Service) MSet(values map[string][]byte, ttl time.Duration) error {
+	var exp int64
+	if ttl > 0 {
+		exp = time.Now().Add(ttl).UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, value := range values {
+		if err := s.putLocked(key, &item{Value: value, Expiration: exp}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByPrefix 返回以 prefix 开头的全部未过期字符串键及其值
+func (s *Service) GetByPrefix(prefix string) map[string][]byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values := make(map[string][]byte)
+	for k, v := range s.items {
+		if strings.HasPrefix(k, prefix) && !v.expired() && v.kind() == KindString {
+			values[k] = v.Value
+		}
+	}
+	return values
+}
+
+// DeleteByPrefix 删除以 prefix 开头的全部键，返回删除的未过期键数量
+func (s *Service) DeleteByPrefix(prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for k, v := range s.items {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if err := s.commitLocked(&record{Op: opDel, Key: k}); err != nil {
+			return removed, err
+		}
+		if !v.expired() {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Type 返回键的值类型，键不存在时返回 false
+func (s *Service) Type(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok := s.items[key]
+	if !ok || it.expired() {
+		return "", false
+	}
+	return it.kind(), true
+}
+
+// DeleteKeys 删除多个键，返回实际删除的数量
+func (s *Service) DeleteKeys(keys ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		it, ok := s.items[key]
+		if !ok {
+			continue
+		}
+		if err := s.commitLocked(&record{Op: opDel, Key: key}); err != nil {
+			return removed, err
+		}
+		if !it.expired() {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// Expire 设置键的过期时间，ttl <= 0 时删除键；键不存在时返回 false
+func (s *Service) Expire(key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.items[key]
+	if !ok || it.expired() {
+		return false, nil
+	}
+	if ttl <= 0 {
+		return true, s.commitLocked(&record{Op: opDel, Key: key})
+	}
+	return true, s.commitLocked(&record{Op: opExpire, Key: key, Expiration: time.Now().Add(ttl).UnixNano()})
+}
+
+// TTL 返回键的剩余过期时间（0 表示永不过期），键不存在时第二个返回值为 false
+func (s *Service) TTL(key string) (time.Duration, bool) {
+	s.mu.RLock()
+	it, ok := s.items[key]
+	s.mu.RUnlock()
+
+	if !ok || it.expired() {
+		return 0, false
+	}
+	if it.Expiration == 0 {
+		return 0, true
+	}
+	return time.Duration(it.Expiration - time.Now().UnixNano()), true
+}
+
+// Len 返回键的数量（包含尚未清理的过期键）
+func (s *Service) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.items)
+}
+
+// KeysMatching 返回匹配 glob 模式（*、?、[abc]、[^a]、[a-z]、\ 转义）的未过期键
+func (s *Service) KeysMatching(pattern string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0)
+	for k, v := range s.items {
+		if !v.expired() && matchGlob(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Scan 增量遍历键：从 cursor 开始返回约 count 个匹配 pattern 的键（pattern 为空表示全部）
+// 与下一次的游标，游标为 0 时遍历结束
+//
+// 键按哈希值排序，游标为下一个哈希值，因此遍历期间一直存在的键保证被返回，
+// 遍历期间新增或删除的键可能返回也可能不返回
+func (s *Service) Scan(cursor uint64, pattern string, count int) ([]string, uint64) {
+	if count <= 0 {
+		count = 10
+	}
+
+	type entry struct {
+		hash uint64
+		key  string
+	}
+
+	s.mu.RLock()
+	entries := make([]entry, 0, len(s.items))
+	for k, v := range s.items {
+		if v.expired() {
+			continue
+		}
+		if h := keyHash(k); h >= cursor {
+			entries = append(entries, entry{h, k})
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		if a.hash != b.hash {
+			if a.hash < b.hash {
+				return -1
+			}
+			return 1
+		}
+		if a.key < b.key {
+			return -1
+		}
+		if a.key > b.key {
+			return 1
+		}
+		return 0
+	})
+
+	// 哈希相同的键在同一批返回，保证游标不会跳过它们
+	end := min(count, len(entries))
+	for end < len(entries) && end > 0 && entries[end].hash == entries[end-1].hash {
+		end++
+	}
+
+	keys := make([]string, 0, end)
+	for _, e := range entries[:end] {
+		if pattern == "" || matchGlob(pattern, e.key) {
+			keys = append(keys, e.key)
+		}
+	}
+
+	if end == len(entries) || entries[end-1].hash == math.MaxUint64 {
+		return keys, 0
+	}
+	return keys, entries[end-1].hash + 1
+}
+
+// keyHash 键的遍历顺序（游标 0 表示开始，因此哈希值至少为 1）
+func keyHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return max(h.Sum64(), 1)
+}
+
+// matchGlob Redis 风格的 glob 匹配
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// 合并连续的 *
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			rest, ok := matchClass(pattern[1:], s[0])
+			if !ok {
+				return false
+			}
+			pattern, s = rest, s[1:]
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass 匹配字符类（pattern 从 '[' 之后开始），返回 ']' 之后的模式
+func matchClass(pattern string, c byte) (string, bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) >= 2:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) >= 3 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (c >= lo && c <= hi)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:] // 跳过 ']'
+	}
+	return pattern, matched != negate
+}
@@ -0,0 +1,183 @@
+package redis_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/goback/services/redis/internal/redis"
+)
+
+func TestServicePrefixOperations(t *testing.T) {
+	svc := redis.NewService("redis-test")
+	defer svc.Stop()
+
+	svc.MSet(map[string][]byte{"user:1": []byte("a"), "user:2": []byte("b"), "order:1": []byte("c")}, 0)
+	svc.HSet("user:hash", map[string][]byte{"f": []byte("v")})
+
+	values := svc.MGet("user:1", "missing", "order:1")
+	if string(values[0]) != "a" || values[1] != nil || string(values[2]) != "c" {
+		t.Fatalf("Expected [a <nil> c], got %q", values)
+	}
+
+	// 只返回字符串键
+	found := svc.GetByPrefix("user:")
+	if len(found) != 2 || string(found["user:2"]) != "b" {
+		t.Fatalf("Expected 2 user values, got %q", found)
+	}
+
+	n, err := svc.DeleteByPrefix("user:")
+	if err != nil || n != 3 {
+		t.Fatalf("Expected 3 deleted keys, got %d (%v)", n, err)
+	}
+	if keys := svc.KeysMatching("*"); !slices.Equal(keys, []string{"order:1"}) {
+		t.Fatalf("Expected [order:1] to remain, got %v", keys)
+	}
+}
+
+func TestCacheScan(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			values := make(map[string]any)
+			for i := range 25 {
+				values[fmt.Sprintf("scan:%s:%02d", name, i)] = i
+			}
+			values["other:"+name] = 0
+			if err := c.MSet(values, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			// 按游标分批遍历，合并结果与一次性匹配一致
+			var keys []string
+			var cursor uint64
+			for pages := 0; ; pages++ {
+				page, next, err := c.Scan(cursor, "scan:"+name+":*", 10)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, page...)
+				if next == 0 {
+					break
+				}
+				if pages > 25 {
+					t.Fatal("Expected the scan to terminate")
+				}
+				cursor = next
+			}
+			slices.Sort(keys)
+			keys = slices.Compact(keys)
+			if len(keys) != 25 || keys[0] != "scan:"+name+":00" {
+				t.Fatalf("Expected 25 scanned keys, got %v", keys)
+			}
+
+			matched, err := c.ScanAll("scan:" + name + ":1?")
+			if err != nil || len(matched) != 10 {
+				t.Fatalf("Expected 10 keys for ?, got %v (%v)", matched, err)
+			}
+			matched, _ = c.ScanAll("scan:" + name + ":0[1-3]")
+			slices.Sort(matched)
+			if len(matched) != 3 || matched[0] != "scan:"+name+":01" {
+				t.Fatalf("Expected 3 keys for class, got %v", matched)
+			}
+		})
+	}
+}
+
+func TestCacheBatch(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			prefix := "batch:" + name + ":"
+			if err := c.MSet(map[string]any{prefix + "a": "x", prefix + "b": 2}, time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.MSetRaw(map[string][]byte{prefix + "c": []byte(`"z"`)}, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := c.MGet(prefix+"a", prefix+"missing", prefix+"c")
+			if err != nil || len(values) != 2 || string(values[prefix+"a"]) != `"x"` {
+				t.Fatalf("Expected a and c, got %q (%v)", values, err)
+			}
+
+			found, err := c.GetByPrefix(prefix)
+			if err != nil || len(found) != 3 || string(found[prefix+"b"]) != "2" {
+				t.Fatalf("Expected 3 values by prefix, got %q (%v)", found, err)
+			}
+
+			// 带过期时间批量写入的键到期后不再返回（HTTP 接口的过期时间以秒为单位）
+			time.Sleep(1100 * time.Millisecond)
+			if found, _ := c.GetByPrefix(prefix); len(found) != 1 {
+				t.Fatalf("Expected only c after expiry, got %q", found)
+			}
+
+			n, err := c.DeleteByPrefix(prefix)
+			if err != nil || n != 1 {
+				t.Fatalf("Expected 1 deleted key, got %d (%v)", n, err)
+			}
+			if found, _ := c.GetByPrefix(prefix); len(found) != 0 {
+				t.Fatalf("Expected no values after delete, got %q", found)
+			}
+		})
+	}
+}
+
+func TestCacheNamespace(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			ns := c.Namespace("rbac-" + name)
+			other := c.Namespace("dept-" + name)
+			if ns.Prefix() != "rbac-"+name+":" {
+				t.Fatalf("Unexpected prefix %q", ns.Prefix())
+			}
+
+			ns.Set("role:1", "admin")
+			ns.Set("role:2", "user")
+			other.Set("role:1", "dept")
+
+			// 键自动带前缀，返回的键名不含前缀
+			var value string
+			if err := c.Get("rbac-"+name+":role:1", &value); err != nil || value != "admin" {
+				t.Fatalf("Expected the prefixed key to be stored, got %q (%v)", value, err)
+			}
+			keys, err := ns.ListKeys()
+			slices.Sort(keys)
+			if err != nil || !slices.Equal(keys, []string{"role:1", "role:2"}) {
+				t.Fatalf("Expected namespaced keys, got %v (%v)", keys, err)
+			}
+			found, _ := ns.GetByPrefix("role:")
+			if len(found) != 2 || string(found["role:2"]) != `"user"` {
+				t.Fatalf("Expected namespaced values, got %q", found)
+			}
+
+			// 嵌套命名空间与含 glob 字符的命名空间按字面匹配
+			nested := ns.Namespace("perm")
+			nested.Set("x", 1)
+			if keys, _ := nested.ListKeys(); !slices.Equal(keys, []string{"x"}) {
+				t.Fatalf("Expected nested key x, got %v", keys)
+			}
+			glob := c.Namespace("[a]*-" + name)
+			glob.Set("k", 1)
+			c.Set("a-"+name+":k", 1)
+			if keys, _ := glob.ListKeys(); !slices.Equal(keys, []string{"k"}) {
+				t.Fatalf("Expected the glob namespace to match literally, got %v", keys)
+			}
+
+			ns.RPush("queue", "job")
+			var job string
+			key, ok, err := ns.BLPop(time.Second, &job, "empty", "queue")
+			if err != nil || !ok || key != "queue" || job != "job" {
+				t.Fatalf("Expected BLPop from queue, got %q (%v)", key, err)
+			}
+
+			// Clear 只删除本命名空间的键
+			ns.Clear()
+			if keys, _ := ns.ListKeys(); len(keys) != 0 {
+				t.Fatalf("Expected an empty namespace, got %v", keys)
+			}
+			if err := other.Get("role:1", &value); err != nil || value != "dept" {
+				t.Fatalf("Expected the other namespace to be kept, got %q (%v)", value, err)
+			}
+		})
+	}
+}
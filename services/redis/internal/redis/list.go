@@ -0,0 +1,260 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// MaxBlockTimeout HTTP 阻塞弹出的最长等待时间，客户端需要更长时间时循环调用
+const MaxBlockTimeout = time.Minute
+
+// PushRequest 列表写入请求
+type PushRequest struct {
+	Key    string   `json:"key"`
+	Values [][]byte `json:"values"`
+	Left   bool     `json:"left"` // true 写入头部（LPUSH），否则写入尾部（RPUSH）
+}
+
+// PopRequest 列表弹出请求
+type PopRequest struct {
+	Keys    []string `json:"keys"`    // 依次检查，从第一个非空列表弹出
+	Left    bool     `json:"left"`    // true 从头部弹出（LPOP），否则从尾部弹出（RPOP）
+	Timeout int64    `json:"timeout"` // 毫秒，0 表示不阻塞；最长 MaxBlockTimeout
+}
+
+// PopResponse 列表弹出响应
+type PopResponse struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// LRangeRequest 列表区间请求，下标为闭区间，负数从末尾计数
+type LRangeRequest struct {
+	Key   string `json:"key"`
+	Start int64  `json:"start"`
+	Stop  int64  `json:"stop"`
+}
+
+func (s *Service) handlePush(e *core.RequestEvent) error {
+	var req PushRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	length, err := s.Push(req.Key, req.Left, req.Values...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"length": length})
+}
+
+func (s *Service) handlePop(e *core.RequestEvent) error {
+	var req PopRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+	if len(req.Keys) == 0 {
+		return apis.Error(e, 400, "keys is required")
+	}
+
+	var (
+		key   string
+		value []byte
+		ok    bool
+		err   error
+	)
+	if req.Timeout <= 0 {
+		key, value, ok, err = s.popFirst(req.Keys, req.Left)
+	} else {
+		timeout := min(time.Duration(req.Timeout)*time.Millisecond, MaxBlockTimeout)
+		key, value, ok, err = s.BlockingPop(e.Request.Context(), req.Keys, req.Left, timeout)
+	}
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, PopResponse{Key: key, Value: value, Found: ok})
+}
+
+func (s *Service) handleLRange(e *core.RequestEvent) error {
+	var req LRangeRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	values, err := s.LRange(req.Key, req.Start, req.Stop)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"values": values})
+}
+
+func (s *Service) handleLLen(e *core.RequestEvent) error {
+	var req GetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	length, err := s.LLen(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"length": length})
+}
+
+// Push 依次写入列表头部（left）或尾部，返回写入后的长度，并唤醒等待该键的阻塞弹出
+func (s *Service) Push(key string, left bool, values ...[]byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindList)
+	if err != nil {
+		return 0, err
+	}
+	length := len(values)
+	if ok {
+		length += it.list.Len()
+	}
+	if len(values) == 0 {
+		return length, nil
+	}
+	if err := s.commitLocked(&record{Op: opPush, Key: key, Values: values, Left: left}); err != nil {
+		return 0, err
+	}
+
+	for w := range s.waiters[key] {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+	return length, nil
+}
+
+// Pop 从列表头部（left）或尾部弹出一个值，列表为空时返回 false
+func (s *Service) Pop(key string, left bool) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.popLocked(key, left)
+}
+
+// popLocked 弹出一个值（调用方持有写锁）
+func (s *Service) popLocked(key string, left bool) ([]byte, bool, error) {
+	it, ok, err := s.typedLocked(key, KindList)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	e := it.list.Back()
+	if left {
+		e = it.list.Front()
+	}
+	value := e.Value.([]byte)
+	if err := s.commitLocked(&record{Op: opPop, Key: key, Left: left, Count: 1}); err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// popFirst 从 keys 中第一个非空列表弹出
+func (s *Service) popFirst(keys []string, left bool) (string, []byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		value, ok, err := s.popLocked(key, left)
+		if err != nil || ok {
+			return key, value, ok, err
+		}
+	}
+	return "", nil, false, nil
+}
+
+// BlockingPop 从 keys 中第一个非空列表弹出；均为空时等待写入，直到超时（timeout <= 0 表示一直等待）或 ctx 结束
+// 超时返回 false，ctx 结束返回 ctx.Err()
+func (s *Service) BlockingPop(ctx context.Context, keys []string, left bool, timeout time.Duration) (string, []byte, bool, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		deadline = t.C
+	}
+
+	wake := make(chan struct{}, 1)
+	defer s.unwatch(keys, wake)
+
+	for {
+		s.mu.Lock()
+		for _, key := range keys {
+			value, ok, err := s.popLocked(key, left)
+			if err != nil || ok {
+				s.mu.Unlock()
+				return key, value, ok, err
+			}
+		}
+		// 在同一把锁内登记，不会错过检查之后的写入
+		for _, key := range keys {
+			if s.waiters[key] == nil {
+				s.waiters[key] = make(map[chan struct{}]struct{})
+			}
+			s.waiters[key][wake] = struct{}{}
+		}
+		s.mu.Unlock()
+
+		// 被唤醒后重新检查：同时等待的其他客户端可能已取走该值
+		select {
+		case <-wake:
+		case <-deadline:
+			return "", nil, false, nil
+		case <-ctx.Done():
+			return "", nil, false, ctx.Err()
+		}
+	}
+}
+
+// unwatch 取消阻塞弹出的登记
+func (s *Service) unwatch(keys []string, wake chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.waiters[key], wake)
+		if len(s.waiters[key]) == 0 {
+			delete(s.waiters, key)
+		}
+	}
+}
+
+// LRange 返回列表闭区间 [start, stop] 的值，负数下标从末尾计数
+func (s *Service) LRange(key string, start, stop int64) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindList)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return [][]byte{}, nil
+	}
+	from, to := normalizeRange(start, stop, it.list.Len())
+	return listValues(it.list, from, to), nil
+}
+
+// LLen 返回列表长度
+func (s *Service) LLen(key string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindList)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return it.list.Len(), nil
+}
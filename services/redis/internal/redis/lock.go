@@ -0,0 +1,208 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// 租约锁的存储键
+// 锁状态保存为带过期时间的字符串键；令牌计数保存在不过期的哈希中，锁过期或释放后重新获取仍单调递增
+const (
+	LockKeyPrefix = "lock:"      // 锁状态键前缀，值为 LockState JSON
+	LockFenceKey  = "lock-fence" // 哈希，字段为锁名，值为最近发放的令牌
+)
+
+// ErrInvalidLease 租约时间无效错误
+var ErrInvalidLease = errors.New("lock ttl must be positive")
+
+// LockState 锁状态
+type LockState struct {
+	Owner string `json:"owner"`
+	Token int64  `json:"token"` // 防护令牌（fencing token），同名锁每次被获取时递增
+}
+
+// LockRequest 获取或续约锁请求
+type LockRequest struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	Token int64  `json:"token"` // 续约、释放时校验
+	TTL   int64  `json:"ttl"`   // 毫秒，必须大于 0
+}
+
+// LockInfoResponse 锁状态响应
+type LockInfoResponse struct {
+	Owner string `json:"owner"`
+	Token int64  `json:"token"`
+	TTL   int64  `json:"ttl"` // 剩余租约（毫秒）
+	Found bool   `json:"found"`
+}
+
+func (s *Service) handleLockAcquire(e *core.RequestEvent) error {
+	var req LockRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	token, ok, err := s.AcquireLock(req.Name, req.Owner, time.Duration(req.TTL)*time.Millisecond)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"token": token, "acquired": ok})
+}
+
+func (s *Service) handleLockRenew(e *core.RequestEvent) error {
+	var req LockRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.RenewLock(req.Name, req.Owner, req.Token, time.Duration(req.TTL)*time.Millisecond)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"renewed": ok})
+}
+
+func (s *Service) handleLockRelease(e *core.RequestEvent) error {
+	var req LockRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.ReleaseLock(req.Name, req.Owner, req.Token)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"released": ok})
+}
+
+func (s *Service) handleLockInfo(e *core.RequestEvent) error {
+	var req LockRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	state, ttl, ok := s.LockInfo(req.Name)
+	return e.JSON(200, LockInfoResponse{
+		Owner: state.Owner,
+		Token: state.Token,
+		TTL:   ttl.Milliseconds(),
+		Found: ok,
+	})
+}
+
+// AcquireLock 获取租约为 ttl 的锁，返回防护令牌
+// 锁被其他 owner 持有时返回 false；已由同一 owner 持有时延长租约并返回原令牌
+func (s *Service) AcquireLock(name, owner string, ttl time.Duration) (int64, bool, error) {
+	if ttl <= 0 {
+		return 0, false, ErrInvalidLease
+	}
+	exp := time.Now().Add(ttl).UnixNano()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok, err := s.lockLocked(name)
+	if err != nil {
+		return 0, false, err
+	}
+	if ok {
+		if state.Owner != owner {
+			return 0, false, nil
+		}
+		if err := s.commitLocked(&record{Op: opExpire, Key: LockKeyPrefix + name, Expiration: exp}); err != nil {
+			return 0, false, err
+		}
+		return state.Token, true, nil
+	}
+
+	// 先记录令牌计数，崩溃时最多跳过一个令牌，不会重复发放
+	var token int64
+	if fence, ok, err := s.typedLocked(LockFenceKey, KindHash); err != nil {
+		return 0, false, err
+	} else if ok {
+		token, _ = strconv.ParseInt(string(fence.hash[name]), 10, 64)
+	}
+	token++
+	fence := map[string][]byte{name: []byte(strconv.FormatInt(token, 10))}
+	if err := s.commitLocked(&record{Op: opHSet, Key: LockFenceKey, Fields: fence}); err != nil {
+		return 0, false, err
+	}
+
+	value, _ := json.Marshal(LockState{Owner: owner, Token: token})
+	if err := s.putLocked(LockKeyPrefix+name, &item{Value: value, Expiration: exp}); err != nil {
+		return 0, false, err
+	}
+	return token, true, nil
+}
+
+// RenewLock 锁仍由 owner 以 token 持有时将租约延长为 ttl，返回是否续约
+func (s *Service) RenewLock(name, owner string, token int64, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, ErrInvalidLease
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok, err := s.lockLocked(name)
+	if err != nil || !ok || state.Owner != owner || state.Token != token {
+		return false, err
+	}
+	if err := s.commitLocked(&record{Op: opExpire, Key: LockKeyPrefix + name, Expiration: time.Now().Add(ttl).UnixNano()}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseLock 锁仍由 owner 以 token 持有时释放，返回是否释放
+func (s *Service) ReleaseLock(name, owner string, token int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok, err := s.lockLocked(name)
+	if err != nil || !ok || state.Owner != owner || state.Token != token {
+		return false, err
+	}
+	if err := s.commitLocked(&record{Op: opDel, Key: LockKeyPrefix + name}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockInfo 返回锁的持有者、令牌与剩余租约，锁未被持有时返回 false
+func (s *Service) LockInfo(name string) (LockState, time.Duration, bool) {
+	s.mu.RLock()
+	it, ok, err := s.lookup(LockKeyPrefix+name, KindString)
+	s.mu.RUnlock()
+
+	var state LockState
+	if err != nil || !ok || json.Unmarshal(it.Value, &state) != nil {
+		return LockState{}, 0, false
+	}
+	if it.Expiration == 0 {
+		return state, 0, true
+	}
+	return state, time.Duration(it.Expiration - time.Now().UnixNano()), true
+}
+
+// lockLocked 返回锁的当前状态（调用方持有写锁）
+func (s *Service) lockLocked(name string) (LockState, bool, error) {
+	it, ok, err := s.typedLocked(LockKeyPrefix+name, KindString)
+	if err != nil || !ok {
+		return LockState{}, false, err
+	}
+	var state LockState
+	if err := json.Unmarshal(it.Value, &state); err != nil {
+		return LockState{}, false, ErrWrongType
+	}
+	return state, true, nil
+}
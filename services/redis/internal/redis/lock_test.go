@@ -0,0 +1,221 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/config"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+func TestServiceLock(t *testing.T) {
+	svc := redis.NewService("redis-test")
+	defer svc.Stop()
+
+	token, ok, err := svc.AcquireLock("job", "a", 50*time.Millisecond)
+	if err != nil || !ok || token != 1 {
+		t.Fatalf("Expected token 1, got %d (%v, %v)", token, ok, err)
+	}
+	if _, ok, _ := svc.AcquireLock("job", "b", time.Minute); ok {
+		t.Fatal("Expected the lock to be held by a")
+	}
+	// 同一持有者再次获取得到原令牌
+	if again, ok, _ := svc.AcquireLock("job", "a", 50*time.Millisecond); !ok || again != token {
+		t.Fatalf("Expected a to reacquire token %d, got %d (%v)", token, again, ok)
+	}
+	if ok, _ := svc.RenewLock("job", "a", token+1, time.Minute); ok {
+		t.Fatal("Expected renew with a wrong token to fail")
+	}
+	if _, _, err := svc.AcquireLock("job", "a", 0); !errors.Is(err, redis.ErrInvalidLease) {
+		t.Fatalf("Expected ErrInvalidLease, got %v", err)
+	}
+
+	// 租约到期后由他人获取，令牌递增，旧持有者无法续约或释放
+	time.Sleep(80 * time.Millisecond)
+	next, ok, _ := svc.AcquireLock("job", "b", time.Minute)
+	if !ok || next != token+1 {
+		t.Fatalf("Expected b to acquire token %d, got %d (%v)", token+1, next, ok)
+	}
+	if ok, _ := svc.RenewLock("job", "a", token, time.Minute); ok {
+		t.Fatal("Expected the expired holder not to renew")
+	}
+	if ok, _ := svc.ReleaseLock("job", "a", token); ok {
+		t.Fatal("Expected the expired holder not to release")
+	}
+
+	state, ttl, ok := svc.LockInfo("job")
+	if !ok || state.Owner != "b" || state.Token != next || ttl <= 0 {
+		t.Fatalf("Expected b to hold token %d, got %+v %s (%v)", next, state, ttl, ok)
+	}
+	if ok, _ := svc.ReleaseLock("job", "b", next); !ok {
+		t.Fatal("Expected b to release the lock")
+	}
+	if _, _, ok := svc.LockInfo("job"); ok {
+		t.Fatal("Expected the lock to be released")
+	}
+}
+
+func TestLockTokensSurviveRestart(t *testing.T) {
+	cfg := config.RedisPersistenceConfig{Dir: t.TempDir(), Fsync: redis.FsyncAlways}
+
+	svc := newPersistentService(t, cfg)
+	token, _, _ := svc.AcquireLock("job", "a", time.Minute)
+	svc.ReleaseLock("job", "a", token)
+	svc.Stop()
+
+	restored := newPersistentService(t, cfg)
+	defer restored.Stop()
+
+	if next, ok, _ := restored.AcquireLock("job", "b", time.Minute); !ok || next != token+1 {
+		t.Fatalf("Expected token %d after restart, got %d (%v)", token+1, next, ok)
+	}
+}
+
+func TestCacheLock(t *testing.T) {
+	_, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	lock, ok, err := c.TryLock("report", 300*time.Millisecond)
+	if err != nil || !ok {
+		t.Fatalf("Expected to acquire the lock, got %v (%v)", ok, err)
+	}
+
+	// 自动续约使锁在多个租约周期后仍然有效
+	time.Sleep(700 * time.Millisecond)
+	if lock.Context().Err() != nil {
+		t.Fatalf("Expected the lock to be renewed, got %v", context.Cause(lock.Context()))
+	}
+	state, ok, _ := c.LockInfo("report")
+	if !ok || state.Owner != lock.Owner() || state.Token != lock.Token() {
+		t.Fatalf("Expected the lock to be held by %s, got %+v (%v)", lock.Owner(), state, ok)
+	}
+
+	if _, ok, _ := c.TryLock("report", time.Second); ok {
+		t.Fatal("Expected a second holder to fail")
+	}
+
+	// 等待中的获取在释放后成功，且令牌递增
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.Release()
+	}()
+	next, ok, err := c.TryLock("report", time.Second, cache.WithLockWait(2*time.Second), cache.WithLockRetry(20*time.Millisecond))
+	if err != nil || !ok {
+		t.Fatalf("Expected to acquire the lock after release, got %v (%v)", ok, err)
+	}
+	defer next.Release()
+	if next.Token() != lock.Token()+1 {
+		t.Fatalf("Expected token %d, got %d", lock.Token()+1, next.Token())
+	}
+	if err := lock.Release(); !errors.Is(err, cache.ErrLockNotHeld) {
+		t.Fatalf("Expected ErrLockNotHeld for a second release, got %v", err)
+	}
+}
+
+func TestCacheLockLost(t *testing.T) {
+	_, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	lock, _, _ := c.TryLock("report", 150*time.Millisecond)
+	c.Delete(redis.LockKeyPrefix + "report")
+
+	select {
+	case <-lock.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the lock context to be cancelled")
+	}
+	if cause := context.Cause(lock.Context()); !errors.Is(cause, cache.ErrLockNotHeld) {
+		t.Fatalf("Expected ErrLockNotHeld, got %v", cause)
+	}
+}
+
+func TestLeaderElection(t *testing.T) {
+	_, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	first := core.NewLeaderElector(c, "leader:test", "node-1", 300*time.Millisecond, nil)
+	second := core.NewLeaderElector(c, "leader:test", "node-2", 300*time.Millisecond, nil)
+	first.Start()
+	waitFor(t, first.IsLeader)
+	second.Start()
+	defer second.Stop()
+
+	time.Sleep(200 * time.Millisecond)
+	if second.IsLeader() {
+		t.Fatal("Expected only one leader")
+	}
+	token, _ := first.Token()
+
+	// leader 停止后由另一个节点接替
+	first.Stop()
+	if first.IsLeader() {
+		t.Fatal("Expected the stopped node not to be leader")
+	}
+	waitFor(t, second.IsLeader)
+	if next, _ := second.Token(); next <= token {
+		t.Fatalf("Expected the new leader token to exceed %d, got %d", token, next)
+	}
+}
+
+func TestLeaderLostWhenLeaseExpires(t *testing.T) {
+	_, ts := newTestServer(t)
+	target, _ := url.Parse(ts.URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	// 代理拒绝所有续约请求，并延迟获取请求的响应，使续约周期落后于租约
+	var (
+		mu       sync.Mutex
+		acquired time.Time
+	)
+	front := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cache/lock/renew":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case "/cache/lock/acquire":
+			mu.Lock()
+			acquired = time.Now()
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer front.Close()
+
+	const ttl = 300 * time.Millisecond
+	elector := core.NewLeaderElector(cache.NewWithURL(front.URL), "leader:expiry", "node-1", ttl, nil)
+	elector.Start()
+	defer elector.Stop()
+	waitFor(t, elector.IsLeader)
+
+	// 租约从发出获取请求时起算，到期时应立即卸任，而不是等到下一次续约
+	mu.Lock()
+	expires := acquired.Add(ttl)
+	mu.Unlock()
+	time.Sleep(time.Until(expires) + 20*time.Millisecond)
+	if elector.IsLeader() {
+		t.Fatal("Expected leadership to be lost once the lease expired")
+	}
+}
+
+// waitFor 等待 cond 成立，最多 2 秒
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
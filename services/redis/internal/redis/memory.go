@@ -0,0 +1,393 @@
+package redis
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	"github.com/goback/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// 淘汰策略
+const (
+	PolicyAllKeysLRU  = "allkeys-lru"  // 淘汰最久未访问的键
+	PolicyAllKeysLFU  = "allkeys-lfu"  // 淘汰访问频率最低的键
+	PolicyVolatileTTL = "volatile-ttl" // 只淘汰设置了过期时间的键，剩余时间最短的先淘汰
+	PolicyNoEviction  = "noeviction"   // 不淘汰，超出上限时拒绝写入
+)
+
+// ErrOOM 超出内存或键数量上限，且没有可淘汰的键
+var ErrOOM = errors.New("OOM command not allowed when used memory > 'maxmemory'")
+
+// ErrValueTooLarge 写入后键的大小超过单键上限
+var ErrValueTooLarge = errors.New("value exceeds the max value size")
+
+// DefaultProtectedPrefixes 默认不参与淘汰的键前缀：租约锁、防护令牌计数、服务注册信息与网关运行时配置
+// 淘汰这些键会破坏互斥、使令牌回退、使服务暂时无法被发现或使网关的分流配置静默丢失
+var DefaultProtectedPrefixes = []string{LockKeyPrefix, LockFenceKey, "registry:", "gateway:"}
+
+// 内存估算参数（字节）
+const (
+	keyOverhead   = 64 // 每个键的固定开销（map 项与 item 结构）
+	entryOverhead = 32 // 集合类型每个元素的固定开销
+)
+
+// 淘汰与主动过期参数
+const (
+	evictionSamples      = 16                     // 每次淘汰比较的候选键数量（近似 LRU/LFU，与 Redis 的 maxmemory-samples 相同思路）
+	evictionScanLimit    = 1024                   // 每次淘汰最多检查的键数量，仍未找到候选时视为没有可淘汰的键
+	lfuDecayPeriod       = time.Minute            // 访问次数每空闲一个周期减半
+	activeExpireInterval = 100 * time.Millisecond // 主动过期的间隔
+	activeExpireSamples  = 200                    // 每轮主动过期检查的键数量
+	activeExpireBudget   = 25 * time.Millisecond  // 单次主动过期的最长耗时
+)
+
+// limits 内存上限与淘汰策略
+type limits struct {
+	maxMemory    int64
+	maxKeys      int
+	maxValueSize int64
+	policy       string
+	protected    []string
+}
+
+// WithMemoryLimit 设置内存上限、键数量上限、单键大小上限与淘汰策略（上限均为 0 时不生效）
+func WithMemoryLimit(cfg config.RedisMemoryConfig) Option {
+	return func(s *Service) {
+		if cfg.MaxMemory <= 0 && cfg.MaxKeys <= 0 && cfg.MaxValueSize <= 0 {
+			return
+		}
+
+		l := &limits{
+			maxMemory:    int64(max(cfg.MaxMemory, 0)) << 20,
+			maxKeys:      max(cfg.MaxKeys, 0),
+			maxValueSize: int64(max(cfg.MaxValueSize, 0)) << 10,
+			policy:       cfg.Policy,
+			protected:    cfg.Protected,
+		}
+		switch l.policy {
+		case PolicyAllKeysLFU, PolicyVolatileTTL, PolicyNoEviction:
+		default:
+			l.policy = PolicyAllKeysLRU
+		}
+		if l.protected == nil {
+			l.protected = DefaultProtectedPrefixes
+		}
+		s.limits = l
+	}
+}
+
+// exceeded 写入后是否超出上限
+func (l *limits) exceeded(used int64, keys int, newKey bool) bool {
+	return (l.maxMemory > 0 && used > l.maxMemory) || (l.maxKeys > 0 && newKey && keys >= l.maxKeys)
+}
+
+// evictable 键是否可按策略淘汰
+func (l *limits) evictable(key string, it *item) bool {
+	switch {
+	case l.policy == PolicyNoEviction:
+		return false
+	case l.policy == PolicyVolatileTTL && it.Expiration == 0:
+		return false
+	}
+	for _, p := range l.protected {
+		if strings.HasPrefix(key, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// before a 是否应先于 b 被淘汰
+func (l *limits) before(a, b *item, now int64) bool {
+	switch l.policy {
+	case PolicyAllKeysLFU:
+		if fa, fb := a.frequency(now), b.frequency(now); fa != fb {
+			return fa < fb
+		}
+	case PolicyVolatileTTL:
+		return a.Expiration < b.Expiration
+	}
+	return a.accessed < b.accessed
+}
+
+// Stats 缓存统计
+type Stats struct {
+	Keys       int    `json:"keys"`
+	UsedMemory int64  `json:"usedMemory"` // 估算的键与值占用（字节）
+	MaxMemory  int64  `json:"maxMemory"`  // 0 表示不限制
+	MaxKeys    int    `json:"maxKeys"`    // 0 表示不限制
+	Policy     string `json:"policy"`     // 未设置上限时为空
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Evictions  uint64 `json:"evictions"`
+	Expired    uint64 `json:"expired"`  // 主动清理与淘汰时删除的过期键
+	Rejected   uint64 `json:"rejected"` // 超出上限被拒绝的写入
+}
+
+// counters 统计计数
+type counters struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+func (s *Service) handleStats(e *core.RequestEvent) error {
+	return e.JSON(200, s.Stats())
+}
+
+// Stats 返回缓存统计
+func (s *Service) Stats() Stats {
+	s.mu.RLock()
+	st := Stats{Keys: len(s.items), UsedMemory: s.used}
+	s.mu.RUnlock()
+
+	if l := s.limits; l != nil {
+		st.MaxMemory = l.maxMemory
+		st.MaxKeys = l.maxKeys
+		st.Policy = l.policy
+	}
+	st.Hits = s.counters.hits.Load()
+	st.Misses = s.counters.misses.Load()
+	st.Evictions = s.counters.evictions.Load()
+	st.Expired = s.counters.expired.Load()
+	st.Rejected = s.counters.rejected.Load()
+	return st
+}
+
+// Collect 以 Prometheus 指标输出缓存统计（实现 metrics.Collector）
+func (s *Service) Collect(e *metrics.Encoder) {
+	st := s.Stats()
+	for _, m := range []struct {
+		name, typ, help string
+		value           float64
+	}{
+		{"cache_service_keys", metrics.TypeGauge, "Number of keys in the cache service.", float64(st.Keys)},
+		{"cache_service_used_memory_bytes", metrics.TypeGauge, "Estimated memory used by keys and values.", float64(st.UsedMemory)},
+		{"cache_service_max_memory_bytes", metrics.TypeGauge, "Configured memory limit, 0 for unlimited.", float64(st.MaxMemory)},
+		{"cache_service_keyspace_hits_total", metrics.TypeCounter, "Total successful key lookups.", float64(st.Hits)},
+		{"cache_service_keyspace_misses_total", metrics.TypeCounter, "Total failed key lookups.", float64(st.Misses)},
+		{"cache_service_evicted_keys_total", metrics.TypeCounter, "Total keys evicted by the memory policy.", float64(st.Evictions)},
+		{"cache_service_expired_keys_total", metrics.TypeCounter, "Total expired keys removed.", float64(st.Expired)},
+		{"cache_service_rejected_writes_total", metrics.TypeCounter, "Total writes rejected by memory limits.", float64(st.Rejected)},
+	} {
+		e.Header(m.name, m.typ, m.help)
+		e.Sample(m.name, m.value)
+	}
+}
+
+// reserveLocked 写入前检查上限（调用方持有写锁）
+// 超出内存或键数量上限时先淘汰其他键，没有可淘汰的键时返回 ErrOOM；不增加占用的写入总是允许
+func (s *Service) reserveLocked(rec *record) error {
+	l := s.limits
+	if l == nil {
+		return nil
+	}
+	size, delta, ok := s.growthLocked(rec)
+	if !ok {
+		return nil
+	}
+	if l.maxValueSize > 0 && size > l.maxValueSize {
+		s.counters.rejected.Add(1)
+		return ErrValueTooLarge
+	}
+
+	_, exists := s.items[rec.Key]
+	for l.exceeded(s.used+delta, len(s.items), !exists) {
+		evicted, err := s.evictLocked(rec.Key)
+		if err != nil {
+			return err
+		}
+		if !evicted {
+			if delta <= 0 && exists {
+				return nil
+			}
+			s.counters.rejected.Add(1)
+			return ErrOOM
+		}
+	}
+	return nil
+}
+
+// growthLocked 估算应用 rec 后键的大小与内存增量，不会增加占用的记录返回 false（调用方持有写锁）
+func (s *Service) growthLocked(rec *record) (int64, int64, bool) {
+	it, exists := s.items[rec.Key]
+	var current int64
+	var hash map[string][]byte
+	var set map[string]struct{}
+	var scores map[string]float64
+	if exists {
+		current = it.size
+		hash, set = it.hash, it.set
+		if it.zset != nil {
+			scores = it.zset.scores
+		}
+	}
+
+	size := max(current, keySize(rec.Key))
+	switch rec.Op {
+	case opSet:
+		size = keySize(rec.Key) + int64(len(rec.Value))
+	case opHSet:
+		for f, v := range rec.Fields {
+			if old, ok := hash[f]; ok {
+				size += int64(len(v) - len(old))
+			} else {
+				size += entrySize(len(f) + len(v))
+			}
+		}
+	case opPush:
+		for _, v := range rec.Values {
+			size += entrySize(len(v))
+		}
+	case opSAdd:
+		for _, m := range rec.Members {
+			if _, ok := set[m]; !ok {
+				size += entrySize(len(m))
+			}
+		}
+	case opZAdd:
+		for _, m := range rec.Members {
+			if _, ok := scores[m]; !ok {
+				size += zsetEntrySize(len(m))
+			}
+		}
+	default:
+		return 0, 0, false
+	}
+	return size, size - current, true
+}
+
+// evictLocked 采样淘汰一个键，已过期的键优先（调用方持有写锁）
+// 不淘汰 exclude（正在写入的键），没有可淘汰的键时返回 false
+func (s *Service) evictLocked(exclude string) (bool, error) {
+	now := time.Now().UnixNano()
+	var victim string
+	var best *item
+	expired := false
+	sampled, checked := 0, 0
+	for k, it := range s.items {
+		if sampled >= evictionSamples || checked >= evictionScanLimit {
+			break
+		}
+		checked++
+		if k == exclude {
+			continue
+		}
+		if it.Expiration > 0 && now > it.Expiration {
+			victim, best, expired = k, it, true
+			break
+		}
+		if !s.limits.evictable(k, it) {
+			continue
+		}
+		sampled++
+		if best == nil || s.limits.before(it, best, now) {
+			victim, best = k, it
+		}
+	}
+	if best == nil {
+		return false, nil
+	}
+
+	if err := s.commitLocked(&record{Op: opDel, Key: victim}); err != nil {
+		return false, err
+	}
+	if expired {
+		s.counters.expired.Add(1)
+	} else {
+		s.counters.evictions.Add(1)
+		logger.Debug("缓存键已淘汰", zap.String("key", victim), zap.String("policy", s.limits.policy))
+	}
+	return true, nil
+}
+
+// deleteExpired 主动清理过期键：每轮检查 activeExpireSamples 个键，
+// 过期键超过其中带过期时间键的 1/4 时继续下一轮，最多耗时 activeExpireBudget（与 Redis 的主动过期相同思路）
+func (s *Service) deleteExpired() {
+	deadline := time.Now().Add(activeExpireBudget)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		volatile, expired, err := s.expireSampleLocked()
+		s.mu.Unlock()
+
+		if err != nil {
+			logger.Error("清理过期键失败", zap.Error(err))
+			return
+		}
+		if expired*4 <= volatile {
+			return
+		}
+	}
+}
+
+// expireSampleLocked 检查一批键并删除其中已过期的键，返回带过期时间的键数与删除数（调用方持有写锁）
+// 删除写入追加日志，之后写入同名键的记录在重放时不会落到已过期的旧值上
+func (s *Service) expireSampleLocked() (int, int, error) {
+	now := time.Now().UnixNano()
+	volatile, expired, checked := 0, 0, 0
+	for k, it := range s.items {
+		if checked >= activeExpireSamples {
+			break
+		}
+		checked++
+		if it.Expiration == 0 {
+			continue
+		}
+		volatile++
+		if now > it.Expiration {
+			if err := s.commitLocked(&record{Op: opDel, Key: k}); err != nil {
+				return volatile, expired, err
+			}
+			expired++
+		}
+	}
+	s.counters.expired.Add(uint64(expired))
+	return volatile, expired, nil
+}
+
+// keySize 键的固定占用
+func keySize(key string) int64 {
+	return keyOverhead + int64(len(key))
+}
+
+// entrySize 集合元素的占用，n 为元素内容的字节数
+func entrySize(n int) int64 {
+	return entryOverhead + int64(n)
+}
+
+// zsetEntrySize 有序集合成员的占用（分数索引与有序切片各一份）
+func zsetEntrySize(n int) int64 {
+	return 2*entryOverhead + int64(n)
+}
+
+// usedMemory 统计 items 的总占用
+func usedMemory(items map[string]*item) int64 {
+	var used int64
+	for _, it := range items {
+		used += it.size
+	}
+	return used
+}
+
+// touch 记录访问时间与次数（可在读锁内并发调用）
+func (i *item) touch(now int64) {
+	atomic.StoreInt64(&i.accessed, now)
+	if atomic.LoadUint32(&i.hits) < math.MaxUint32 {
+		atomic.AddUint32(&i.hits, 1)
+	}
+}
+
+// frequency 访问次数，每空闲 lfuDecayPeriod 减半（调用方持有写锁）
+func (i *item) frequency(now int64) uint32 {
+	idle := time.Duration(now-i.accessed) / lfuDecayPeriod
+	return i.hits >> min(idle, 31)
+}
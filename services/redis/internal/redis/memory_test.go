@@ -0,0 +1,272 @@
+package redis_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/config"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// newLimitedService 创建带内存上限的缓存服务
+func newLimitedService(t *testing.T, cfg config.RedisMemoryConfig) *redis.Service {
+	t.Helper()
+
+	svc := redis.NewService("redis-test", redis.WithMemoryLimit(cfg))
+	t.Cleanup(func() { svc.Stop() })
+	return svc
+}
+
+// setKeys 依次写入 keys，每次写入间隔 1ms 以区分访问时间
+func setKeys(t *testing.T, svc *redis.Service, keys ...string) {
+	t.Helper()
+
+	for _, k := range keys {
+		if _, err := svc.SetWithOptions(k, []byte(k), redis.SetOptions{}); err != nil {
+			t.Fatalf("Expected %s to be written, got %v", k, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryAccounting(t *testing.T) {
+	svc := redis.NewService("redis-test")
+	defer svc.Stop()
+
+	svc.SetRaw("s", []byte("value"), 0)
+	svc.HSet("h", map[string][]byte{"a": []byte("1"), "b": []byte("22")})
+	svc.HSet("h", map[string][]byte{"a": []byte("333")})
+	svc.Push("l", false, []byte("x"), []byte("yy"))
+	svc.SAdd("set", "m1", "m2", "m1")
+	svc.ZAdd("z", redis.ZMember{Member: "a", Score: 1}, redis.ZMember{Member: "b", Score: 2})
+	svc.ZIncrBy("z", "a", 5)
+	svc.Expire("s", time.Hour)
+
+	used := svc.Stats().UsedMemory
+	if used <= 0 {
+		t.Fatalf("Expected positive memory usage, got %d", used)
+	}
+
+	// 删除全部元素后占用回到 0
+	svc.HDel("h", "a", "b")
+	svc.Pop("l", true)
+	svc.Pop("l", false)
+	svc.SRem("set", "m1", "m2")
+	svc.ZRem("z", "a", "b")
+	svc.Delete("s")
+	if st := svc.Stats(); st.UsedMemory != 0 || st.Keys != 0 {
+		t.Fatalf("Expected no usage after deleting everything, got %+v", st)
+	}
+}
+
+func TestEvictionPolicies(t *testing.T) {
+	t.Run("lru", func(t *testing.T) {
+		svc := newLimitedService(t, config.RedisMemoryConfig{MaxKeys: 3})
+		setKeys(t, svc, "a", "b", "c")
+		svc.GetRaw("a")
+		setKeys(t, svc, "d")
+
+		if svc.Exists("b") || !svc.Exists("a") || !svc.Exists("d") {
+			t.Fatalf("Expected the least recently used key b to be evicted, got %v", svc.Keys())
+		}
+		if st := svc.Stats(); st.Evictions != 1 || st.Keys != 3 || st.Policy != redis.PolicyAllKeysLRU {
+			t.Fatalf("Unexpected stats %+v", st)
+		}
+	})
+
+	t.Run("lfu", func(t *testing.T) {
+		svc := newLimitedService(t, config.RedisMemoryConfig{MaxKeys: 3, Policy: redis.PolicyAllKeysLFU})
+		setKeys(t, svc, "a", "b", "c")
+		for range 3 {
+			svc.GetRaw("a")
+			svc.GetRaw("c")
+		}
+		svc.GetRaw("b")
+		setKeys(t, svc, "d")
+
+		if svc.Exists("b") {
+			t.Fatalf("Expected the least frequently used key b to be evicted, got %v", svc.Keys())
+		}
+	})
+
+	t.Run("volatile-ttl", func(t *testing.T) {
+		svc := newLimitedService(t, config.RedisMemoryConfig{MaxKeys: 3, Policy: redis.PolicyVolatileTTL})
+		setKeys(t, svc, "a")
+		svc.SetWithOptions("b", []byte("b"), redis.SetOptions{TTL: time.Hour})
+		svc.SetWithOptions("c", []byte("c"), redis.SetOptions{TTL: time.Minute})
+
+		setKeys(t, svc, "d")
+		if svc.Exists("c") || !svc.Exists("b") {
+			t.Fatalf("Expected the key closest to expiry to be evicted, got %v", svc.Keys())
+		}
+		setKeys(t, svc, "e")
+		if svc.Exists("b") {
+			t.Fatalf("Expected b to be evicted, got %v", svc.Keys())
+		}
+
+		// 只剩没有过期时间的键，拒绝写入新键
+		if _, err := svc.SetWithOptions("f", []byte("f"), redis.SetOptions{}); !errors.Is(err, redis.ErrOOM) {
+			t.Fatalf("Expected ErrOOM, got %v", err)
+		}
+	})
+
+	t.Run("noeviction", func(t *testing.T) {
+		svc := newLimitedService(t, config.RedisMemoryConfig{MaxKeys: 2, Policy: redis.PolicyNoEviction})
+		setKeys(t, svc, "a", "b")
+
+		if _, err := svc.SetWithOptions("c", []byte("c"), redis.SetOptions{}); !errors.Is(err, redis.ErrOOM) {
+			t.Fatalf("Expected ErrOOM, got %v", err)
+		}
+		// 覆盖已有的键与删除不受影响
+		setKeys(t, svc, "a")
+		svc.Delete("b")
+		setKeys(t, svc, "c")
+		if st := svc.Stats(); st.Rejected != 1 || st.Evictions != 0 {
+			t.Fatalf("Unexpected stats %+v", st)
+		}
+	})
+}
+
+func TestEvictionSkipsProtectedKeys(t *testing.T) {
+	svc := newLimitedService(t, config.RedisMemoryConfig{MaxKeys: 5})
+
+	// 锁占用 lock:job 与 lock-fence 两个键
+	svc.AcquireLock("job", "a", time.Minute)
+	svc.SetRaw("registry:service:user:1", []byte("{}"), 30)
+	svc.SetRaw("gateway:traffic:order-service", []byte("{}"), 0)
+	setKeys(t, svc, "a", "b")
+
+	if _, ok := svc.GetRaw("a"); ok {
+		t.Fatal("Expected the unprotected key a to be evicted")
+	}
+	if _, _, ok := svc.LockInfo("job"); !ok {
+		t.Fatal("Expected the lock to survive eviction")
+	}
+	if !svc.Exists("registry:service:user:1") {
+		t.Fatal("Expected the registry key to survive eviction")
+	}
+	if !svc.Exists("gateway:traffic:order-service") {
+		t.Fatal("Expected the gateway traffic key to survive eviction")
+	}
+}
+
+func TestMaxMemory(t *testing.T) {
+	svc := newLimitedService(t, config.RedisMemoryConfig{MaxMemory: 1})
+
+	value := make([]byte, 100<<10)
+	for i := range 20 {
+		svc.SetRaw(fmt.Sprintf("blob:%d", i), value, 0)
+	}
+
+	st := svc.Stats()
+	if st.UsedMemory > st.MaxMemory || st.Evictions == 0 {
+		t.Fatalf("Expected usage within %d bytes with evictions, got %+v", st.MaxMemory, st)
+	}
+	if _, ok := svc.GetRaw("blob:19"); !ok {
+		t.Fatal("Expected the latest key to be kept")
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	svc := newLimitedService(t, config.RedisMemoryConfig{MaxValueSize: 1})
+
+	if _, err := svc.SetWithOptions("big", make([]byte, 2<<10), redis.SetOptions{}); !errors.Is(err, redis.ErrValueTooLarge) {
+		t.Fatalf("Expected ErrValueTooLarge, got %v", err)
+	}
+
+	// 集合类型按写入后的总大小计算
+	chunk := make([]byte, 300)
+	var err error
+	for range 5 {
+		if _, err = svc.Push("queue", false, chunk); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, redis.ErrValueTooLarge) {
+		t.Fatalf("Expected the list to hit the size limit, got %v", err)
+	}
+	if n, _ := svc.LLen("queue"); n != 2 {
+		t.Fatalf("Expected 2 elements within the limit, got %d", n)
+	}
+}
+
+func TestActiveExpiry(t *testing.T) {
+	svc := redis.NewService("redis-test")
+	defer svc.Stop()
+
+	for i := range 10 {
+		svc.SetWithOptions(fmt.Sprintf("tmp:%d", i), []byte("x"), redis.SetOptions{TTL: 30 * time.Millisecond})
+	}
+	svc.SetRaw("keep", []byte("x"), 0)
+
+	// 不访问过期键，由后台主动清理
+	waitFor(t, func() bool { return svc.Stats().Keys == 1 })
+	if st := svc.Stats(); st.Expired != 10 {
+		t.Fatalf("Expected 10 expired keys, got %+v", st)
+	}
+}
+
+func TestExpiredKeyRewrittenAfterRestart(t *testing.T) {
+	cfg := config.RedisPersistenceConfig{Dir: t.TempDir(), Fsync: redis.FsyncAlways}
+
+	svc := newPersistentService(t, cfg)
+	svc.Push("jobs", false, []byte("old"))
+	svc.Expire("jobs", 50*time.Millisecond)
+	svc.Stop()
+	time.Sleep(80 * time.Millisecond)
+
+	// 恢复后向已过期的键写入，未停止（未写快照）时再次恢复
+	restarted := newPersistentService(t, cfg)
+	restarted.Push("jobs", false, []byte("new"))
+
+	restored := newPersistentService(t, cfg)
+	defer restored.Stop()
+	values, err := restored.LRange("jobs", 0, -1)
+	if err != nil || len(values) != 1 || string(values[0]) != "new" {
+		t.Fatalf("Expected [new], got %q (%v)", values, err)
+	}
+	restarted.Stop()
+}
+
+func TestStatsEndpoint(t *testing.T) {
+	_, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	c.Set("user", "alice")
+	var name string
+	c.Get("user", &name)
+	c.Get("missing", &name)
+
+	resp, err := http.Get(ts.URL + "/cache/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var st redis.Stats
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Keys != 1 || st.Hits != 1 || st.Misses != 1 || st.UsedMemory <= 0 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+}
+
+func TestRESPInfo(t *testing.T) {
+	svc, _, addr := newRESPServer(t)
+	svc.SetRaw("user", []byte("alice"), 0)
+	svc.GetRaw("user")
+
+	info := string(dialRESP(t, addr).do(t, "INFO").Bulk)
+	for _, line := range []string{"keyspace_hits:1", "maxmemory_policy:noeviction", "db0:keys=1"} {
+		if !strings.Contains(info, line) {
+			t.Fatalf("Expected INFO to contain %q, got %q", line, info)
+		}
+	}
+}
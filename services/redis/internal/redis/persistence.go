@@ -0,0 +1,473 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 追加日志刷盘策略
+const (
+	FsyncAlways   = "always"   // 每次写入后刷盘，最多丢失正在写入的一条
+	FsyncEverySec = "everysec" // 每秒刷盘，最多丢失一秒的写入
+	FsyncNo       = "no"       // 由操作系统决定刷盘时机
+)
+
+// 数据目录中的文件
+const (
+	SnapshotFile = "snapshot.jsonl" // 快照：首行为 meta 记录，之后每个键一至两条记录
+	AOFFile      = "appendonly.jsonl"
+)
+
+// 默认持久化参数
+const (
+	DefaultPersistenceDir   = "data/redis"
+	DefaultSnapshotInterval = 5 * time.Minute
+	DefaultCompactSize      = 64 << 20
+)
+
+// 追加日志记录的操作
+const (
+	opSet    = "set"
+	opDel    = "del"
+	opClear  = "clear"
+	opExpire = "expire"
+	opHSet   = "hset"
+	opHDel   = "hdel"
+	opPush   = "push"
+	opPop    = "pop"
+	opSAdd   = "sadd"
+	opSRem   = "srem"
+	opZAdd   = "zadd"
+	opZRem   = "zrem"
+	opMeta   = "meta" // 快照首行，记录快照包含的最后一条日志序号
+)
+
+// record 追加日志与快照中的一条记录
+// 过期时间均为绝对时间；日志记录带递增序号，快照记下其包含的最后序号，
+// 快照写入后、日志截断前崩溃时，重放日志会跳过快照已包含的记录（pop 等记录不能重复应用）
+type record struct {
+	Seq        uint64            `json:"seq,omitempty"`
+	Op         string            `json:"op"`
+	Key        string            `json:"key,omitempty"`
+	Value      []byte            `json:"value,omitempty"`
+	Expiration int64             `json:"exp,omitempty"`
+	Fields     map[string][]byte `json:"fields,omitempty"`  // hset
+	Values     [][]byte          `json:"values,omitempty"`  // push
+	Members    []string          `json:"members,omitempty"` // hdel 的字段，sadd/srem/zadd/zrem 的成员
+	Scores     []float64         `json:"scores,omitempty"`  // zadd，与 Members 一一对应
+	Left       bool              `json:"left,omitempty"`    // push/pop 的方向
+	Count      int               `json:"count,omitempty"`   // pop 的数量
+}
+
+// persistence 快照与追加日志
+type persistence struct {
+	dir              string
+	fsync            string
+	snapshotInterval time.Duration
+	compactSize      int64
+
+	mu      sync.Mutex // 保护 aof、aofSize 与 seq（写入在 Service.mu 内，刷盘与压缩在后台）
+	aof     *os.File
+	aofSize int64
+	seq     uint64 // 最后一条日志记录的序号
+
+	compactMu sync.Mutex // 同一时间只进行一次压缩
+	compact   chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// WithPersistence 开启持久化（cfg.Enabled 为 false 时不生效）
+func WithPersistence(cfg config.RedisPersistenceConfig) Option {
+	return func(s *Service) {
+		if !cfg.Enabled {
+			return
+		}
+
+		p := &persistence{
+			dir:              cfg.Dir,
+			fsync:            cfg.Fsync,
+			snapshotInterval: time.Duration(cfg.SnapshotInterval) * time.Second,
+			compactSize:      int64(cfg.CompactSize) << 20,
+			compact:          make(chan struct{}, 1),
+			done:             make(chan struct{}),
+		}
+		if p.dir == "" {
+			p.dir = DefaultPersistenceDir
+		}
+		if p.fsync != FsyncAlways && p.fsync != FsyncNo {
+			p.fsync = FsyncEverySec
+		}
+		if p.snapshotInterval <= 0 {
+			p.snapshotInterval = DefaultSnapshotInterval
+		}
+		if p.compactSize <= 0 {
+			p.compactSize = DefaultCompactSize
+		}
+		s.persist = p
+	}
+}
+
+// appendRecord 写入追加日志（调用方持有 Service.mu 写锁，保证日志顺序与内存一致）
+// 未开启持久化或尚未加载时忽略
+func (p *persistence) appendRecord(rec *record) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.aof == nil {
+		return nil
+	}
+
+	rec.Seq = p.seq + 1
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := p.aof.Write(line); err != nil {
+		// 去掉写了一半的记录，避免重放时截断之后的日志
+		_ = p.aof.Truncate(p.aofSize)
+		return fmt.Errorf("write append-only log: %w", err)
+	}
+	p.aofSize += int64(len(line))
+	p.seq = rec.Seq
+	if p.fsync == FsyncAlways {
+		if err := p.aof.Sync(); err != nil {
+			return fmt.Errorf("sync append-only log: %w", err)
+		}
+	}
+
+	if p.aofSize >= p.compactSize {
+		select {
+		case p.compact <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// sync 将追加日志刷盘
+func (p *persistence) sync() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.aof == nil {
+		return nil
+	}
+	return p.aof.Sync()
+}
+
+// load 加载快照并重放追加日志，然后打开追加日志继续写入
+func (s *Service) load() error {
+	p := s.persist
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+
+	items := make(map[string]*item)
+
+	// 快照通过重命名原子替换，内容损坏说明文件被破坏，拒绝启动
+	_, seq, err := replayFile(filepath.Join(p.dir, SnapshotFile), items, 0)
+	if err != nil {
+		return fmt.Errorf("load snapshot: %w", err)
+	}
+
+	// 追加日志末尾可能有崩溃时写了一半的记录，截断到最后一条完整记录
+	aofPath := filepath.Join(p.dir, AOFFile)
+	valid, seq, err := replayFile(aofPath, items, seq)
+	if err != nil {
+		logger.Warn("追加日志末尾记录不完整，已截断",
+			zap.String("file", aofPath),
+			zap.Int64("offset", valid),
+			zap.Error(err),
+		)
+		if err := os.Truncate(aofPath, valid); err != nil {
+			return fmt.Errorf("truncate append-only log: %w", err)
+		}
+	}
+
+	// 已过期的键不在此直接删除：由主动过期或下一次写入记录删除，
+	// 否则之后写入同名键的记录在下次重放时会落到快照中的旧值上
+	aof, err := os.OpenFile(aofPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open append-only log: %w", err)
+	}
+	info, err := aof.Stat()
+	if err != nil {
+		aof.Close()
+		return fmt.Errorf("stat append-only log: %w", err)
+	}
+
+	s.mu.Lock()
+	s.items = items
+	s.used = usedMemory(items)
+	s.mu.Unlock()
+
+	p.mu.Lock()
+	p.aof = aof
+	p.aofSize = info.Size()
+	p.seq = seq
+	p.mu.Unlock()
+
+	logger.Info("缓存数据已恢复",
+		zap.String("dir", p.dir),
+		zap.Int("keys", len(items)),
+		zap.Int64("aof_bytes", info.Size()),
+	)
+	return nil
+}
+
+// replayFile 将文件中序号大于 seq 的记录依次应用到 items，文件不存在时忽略
+// 返回最后一条完整记录之后的偏移量与已应用的最大序号；遇到不完整或无法解析的记录时停止并返回错误
+func replayFile(path string, items map[string]*item, seq uint64) (int64, uint64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, seq, nil
+	}
+	if err != nil {
+		return 0, seq, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				return offset, seq, errors.New("incomplete record")
+			}
+			return offset, seq, nil
+		}
+		if err != nil {
+			return offset, seq, err
+		}
+
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return offset, seq, fmt.Errorf("invalid record at offset %d: %w", offset, err)
+		}
+		switch {
+		case rec.Op == opMeta:
+			seq = rec.Seq
+		case rec.Seq != 0 && rec.Seq <= seq:
+			// 已包含在快照中
+		default:
+			if err := applyRecord(items, &rec); err != nil {
+				return offset, seq, fmt.Errorf("invalid record at offset %d: %w", offset, err)
+			}
+			seq = max(seq, rec.Seq)
+		}
+		offset += int64(len(line))
+	}
+}
+
+// persistLoop 后台刷盘、定期快照与按大小压缩
+func (s *Service) persistLoop() {
+	p := s.persist
+	defer p.wg.Done()
+
+	snapshot := time.NewTicker(p.snapshotInterval)
+	defer snapshot.Stop()
+
+	// 仅 everysec 需要定时刷盘
+	var fsync <-chan time.Time
+	if p.fsync == FsyncEverySec {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		fsync = t.C
+	}
+
+	for {
+		select {
+		case <-fsync:
+			if err := p.sync(); err != nil {
+				logger.Error("追加日志刷盘失败", zap.Error(err))
+			}
+		case <-snapshot.C:
+			s.compactAndLog()
+		case <-p.compact:
+			s.compactAndLog()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (s *Service) compactAndLog() {
+	if err := s.Compact(); err != nil {
+		logger.Error("缓存快照失败", zap.Error(err))
+	}
+}
+
+// Compact 写入快照并压缩追加日志，未开启持久化时忽略
+//
+// 快照写入期间不阻塞写操作：先在锁内复制数据并记下日志位置，
+// 快照落盘后只保留该位置之后的日志
+func (s *Service) Compact() error {
+	p := s.persist
+	if p == nil {
+		return nil
+	}
+	p.compactMu.Lock()
+	defer p.compactMu.Unlock()
+
+	// 字符串写入后不再修改，集合类型在锁内原地修改，需要深拷贝
+	s.mu.RLock()
+	items := make(map[string]*item, len(s.items))
+	for k, v := range s.items {
+		items[k] = v.clone()
+	}
+	p.mu.Lock()
+	offset := p.aofSize
+	seq := p.seq
+	opened := p.aof != nil
+	p.mu.Unlock()
+	s.mu.RUnlock()
+
+	if !opened {
+		return nil
+	}
+
+	if err := writeSnapshot(p.dir, items, seq); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return p.truncateAOF(offset)
+}
+
+// writeSnapshot 将数据与其包含的最后日志序号写入临时文件后原子替换快照
+func writeSnapshot(dir string, items map[string]*item, seq uint64) error {
+	tmp, err := os.CreateTemp(dir, SnapshotFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(&record{Op: opMeta, Seq: seq}); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	now := time.Now().UnixNano()
+	for k, v := range items {
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		for _, rec := range v.records(k) {
+			if err := enc.Encode(rec); err != nil {
+				return fmt.Errorf("write snapshot: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, SnapshotFile)); err != nil {
+		return fmt.Errorf("replace snapshot: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// truncateAOF 丢弃 offset 之前已包含在快照中的日志（调用方持有 Service.mu 写锁）
+func (p *persistence) truncateAOF(offset int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := filepath.Join(p.dir, AOFFile)
+
+	// 快照期间写入的日志
+	tail := make([]byte, p.aofSize-offset)
+	if len(tail) > 0 {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("read append-only log: %w", err)
+		}
+		_, err = f.ReadAt(tail, offset)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read append-only log: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, tail, 0o644); err != nil {
+		return fmt.Errorf("rewrite append-only log: %w", err)
+	}
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("rewrite append-only log: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync append-only log: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		tmp.Close()
+		return fmt.Errorf("replace append-only log: %w", err)
+	}
+
+	p.aof.Close()
+	p.aof = tmp
+	p.aofSize = int64(len(tail))
+	return syncDir(p.dir)
+}
+
+// closePersistence 停止后台任务，写入最终快照并关闭追加日志
+func (s *Service) closePersistence() error {
+	p := s.persist
+	close(p.done)
+	p.wg.Wait()
+
+	err := s.Compact()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.aof != nil {
+		if syncErr := p.aof.Sync(); err == nil {
+			err = syncErr
+		}
+		p.aof.Close()
+		p.aof = nil
+	}
+	return err
+}
+
+// syncDir 刷盘目录项，确保重命名持久化
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+	return nil
+}
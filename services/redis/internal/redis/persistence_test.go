@@ -0,0 +1,163 @@
+package redis_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/config"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// newPersistentService 启动数据目录为 dir 的缓存服务
+func newPersistentService(t *testing.T, cfg config.RedisPersistenceConfig) *redis.Service {
+	t.Helper()
+
+	cfg.Enabled = true
+	svc := redis.NewService("redis-test", redis.WithPersistence(cfg))
+	if err := svc.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestPersistenceRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	svc := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir, Fsync: redis.FsyncAlways})
+	svc.SetRaw("user:1", []byte("alice"), 0)
+	svc.SetRaw("user:2", []byte("bob"), 0)
+	svc.SetRaw("session", []byte("expiring"), 1)
+	svc.Delete("user:2")
+	if _, _, err := svc.IncrBy("hits", 3, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未停止（未写快照）时从追加日志恢复
+	restored := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir, Fsync: redis.FsyncAlways})
+	if value, ok := restored.GetRaw("user:1"); !ok || string(value) != "alice" {
+		t.Fatalf("Expected user:1 to be restored from the log, got %q (%v)", value, ok)
+	}
+	if restored.Exists("user:2") {
+		t.Fatal("Expected deleted key to stay deleted")
+	}
+	if value, _, _ := restored.IncrBy("hits", 0, 0); value != 3 {
+		t.Fatalf("Expected hits 3, got %d", value)
+	}
+	if err := restored.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停止时写入快照并清空追加日志
+	if err := svc.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, redis.AOFFile)); err != nil || info.Size() != 0 {
+		t.Fatalf("Expected empty append-only log after the final snapshot, got %v (%v)", info, err)
+	}
+
+	// 过期的键不会恢复
+	time.Sleep(1100 * time.Millisecond)
+	svc = newPersistentService(t, config.RedisPersistenceConfig{Dir: dir})
+	defer svc.Stop()
+	if value, ok := svc.GetRaw("user:1"); !ok || string(value) != "alice" {
+		t.Fatalf("Expected user:1 to be restored from the snapshot, got %q (%v)", value, ok)
+	}
+	if svc.Exists("session") {
+		t.Fatal("Expected expired key not to be restored")
+	}
+}
+
+func TestPersistenceTruncatedLog(t *testing.T) {
+	dir := t.TempDir()
+
+	// 崩溃时写了一半的最后一条记录
+	log := `{"op":"set","key":"a","value":"MQ=="}` + "\n" +
+		`{"op":"set","key":"b","value":"Mg=="}` + "\n" +
+		`{"op":"clear"}` + "\n" +
+		`{"op":"set","key":"c","value":"Mw=="}` + "\n" +
+		`{"op":"set","key":"d","val`
+	if err := os.WriteFile(filepath.Join(dir, redis.AOFFile), []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir, Fsync: redis.FsyncAlways})
+	if svc.Exists("a") || svc.Exists("d") {
+		t.Fatal("Expected cleared and incomplete keys to be absent")
+	}
+	if value, ok := svc.GetRaw("c"); !ok || string(value) != "3" {
+		t.Fatalf("Expected c=3, got %q (%v)", value, ok)
+	}
+
+	// 不完整的记录被截断，之后的写入可以正常重放
+	svc.SetRaw("e", []byte("5"), 0)
+	data, err := os.ReadFile(filepath.Join(dir, redis.AOFFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"key":"d"`) || !strings.HasSuffix(string(data), "\n") {
+		t.Fatalf("Expected truncated log, got %q", data)
+	}
+
+	restored := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir})
+	if value, ok := restored.GetRaw("e"); !ok || string(value) != "5" {
+		t.Fatalf("Expected e=5 after restart, got %q (%v)", value, ok)
+	}
+	_ = restored.Stop()
+	_ = svc.Stop()
+}
+
+func TestPersistenceCompactBySize(t *testing.T) {
+	dir := t.TempDir()
+
+	svc := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir, CompactSize: 1})
+	defer svc.Stop()
+
+	// 反复覆盖同一个键，日志超过 1MB 后压缩为快照
+	value := []byte(strings.Repeat("x", 64<<10))
+	for range 20 {
+		svc.SetRaw("big", value, 0)
+	}
+
+	aofPath := filepath.Join(dir, redis.AOFFile)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if info, err := os.Stat(aofPath); err == nil && info.Size() < 1<<20 {
+			if _, err := os.Stat(filepath.Join(dir, redis.SnapshotFile)); err == nil {
+				return
+			}
+		}
+	}
+	t.Fatal("Expected the append-only log to be compacted into a snapshot")
+}
+
+func TestCompactKeepsConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+
+	svc := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir})
+	for i := range 100 {
+		svc.SetRaw("key:"+string(rune('a'+i%26)), []byte{byte(i)}, 0)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 50 {
+			svc.SetRaw("late", []byte("value"), 0)
+		}
+	}()
+	if err := svc.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if err := svc.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newPersistentService(t, config.RedisPersistenceConfig{Dir: dir})
+	defer restored.Stop()
+	if len(restored.Keys()) != 27 {
+		t.Fatalf("Expected 27 keys after restart, got %d", len(restored.Keys()))
+	}
+}
@@ -1,40 +1,67 @@
 package redis
 
 import (
-	"bytes"
 	"encoding/json"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/app/tools/router"
 	"github.com/goback/pkg/logger"
+	"github.com/goback/pkg/metrics"
+	"github.com/goback/pkg/tracing"
 	"go.uber.org/zap"
 )
 
 // PubSubMessage 发布/订阅消息
 type PubSubMessage struct {
+	// ID 消息ID，发布时分配；重试投递使用相同的 ID，订阅方据此去重
+	ID        string    `json:"id"`
 	Topic     string    `json:"topic"`
 	Sender    string    `json:"sender"`
 	Payload   []byte    `json:"payload"`
 	Timestamp time.Time `json:"timestamp"`
+	// TraceParent 发布请求所在链路，订阅方据此延续链路
+	TraceParent string `json:"traceparent,omitempty"`
 }
 
-// Subscriber 订阅者信息
+// Subscriber 订阅者信息（每个服务节点一个订阅者，同一服务的多个副本各自收到全部消息）
 type Subscriber struct {
+	NodeID       string    `json:"node_id"`
 	Service      string    `json:"service"`
 	CallbackAddr string    `json:"callback_addr"`
 	Topics       []string  `json:"topics"`
+	QueueTopics  []string  `json:"queue_topics,omitempty"` // 以队列方式订阅的主题
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	queue *deliveryQueue
+}
+
+// queued 是否以队列方式订阅 topic（同一服务的节点中只投递给一个）
+func (s *Subscriber) queued(topic string) bool {
+	return containsString(s.QueueTopics, topic)
+}
+
+// SubscriberStatus 订阅者及其投递状态
+type SubscriberStatus struct {
+	*Subscriber
+	Pending     int    `json:"pending"`      // 待投递（含正在重试）的消息数
+	Delivered   uint64 `json:"delivered"`    // 已确认的消息数
+	DeadLetters uint64 `json:"dead_letters"` // 转入死信的消息数
 }
 
 // SubscribeRequest 订阅请求
 type SubscribeRequest struct {
+	// NodeID 订阅节点ID，为空时以回调地址区分节点
+	NodeID       string   `json:"node_id,omitempty"`
 	Service      string   `json:"service"`
 	CallbackAddr string   `json:"callback_addr"`
 	Topics       []string `json:"topics"`
+	// QueueTopics Topics 中以队列方式订阅的主题：每条消息只投递给该服务的一个节点
+	QueueTopics []string `json:"queue_topics,omitempty"`
 }
 
 // PublishRequest 发布请求
@@ -45,23 +72,56 @@ type PublishRequest struct {
 }
 
 // PubSubService 发布/订阅服务
+// 每个订阅者有独立的投递队列，推送失败时按指数退避重试（至少一次投递），多次失败后转入死信主题
 type PubSubService struct {
-	subscribers map[string]*Subscriber // service -> Subscriber
-	topicIndex  map[string][]string    // topic -> []service names
+	subscribers map[string]*Subscriber                     // node ID -> Subscriber
+	topicIndex  map[string][]string                        // topic -> []node IDs
+	local       map[string]map[uint64]func(*PubSubMessage) // topic -> 进程内订阅（RESP 连接）
+	nextLocalID uint64
+	groupSeq    atomic.Uint64 // 队列订阅的轮询序号
+	deadLetters []*DeadLetter // 最近的死信
 	mu          sync.RWMutex
 	client      *http.Client
 	stopCleanup chan struct{}
+
+	delivery deliveryConfig
+	counters pubsubCounters
+
+	idMu       sync.Mutex
+	lastIDTime int64
+	lastIDSeq  uint64
+}
+
+// PubSubOption PubSub 服务配置选项
+type PubSubOption func(*PubSubService)
+
+// pubsubCounters 投递统计
+type pubsubCounters struct {
+	published   atomic.Uint64
+	delivered   atomic.Uint64
+	retries     atomic.Uint64
+	deadLetters atomic.Uint64
 }
 
 // NewPubSubService 创建 PubSub 服务
-func NewPubSubService() *PubSubService {
+func NewPubSubService(opts ...PubSubOption) *PubSubService {
 	ps := &PubSubService{
 		subscribers: make(map[string]*Subscriber),
 		topicIndex:  make(map[string][]string),
+		local:       make(map[string]map[uint64]func(*PubSubMessage)),
 		client: &http.Client{
 			Timeout: 3 * time.Second,
 		},
 		stopCleanup: make(chan struct{}),
+		delivery: deliveryConfig{
+			maxAttempts: DefaultDeliveryAttempts,
+			backoff:     DefaultRetryBackoff,
+			maxBackoff:  DefaultMaxRetryBackoff,
+			queueSize:   DefaultDeliveryQueueSize,
+		},
+	}
+	for _, opt := range opts {
+		opt(ps)
 	}
 	go ps.cleanupLoop()
 	return ps
@@ -82,19 +142,37 @@ func (ps *PubSubService) cleanupLoop() {
 }
 
 // cleanupExpired 清理过期订阅（超过 2 分钟未更新）
+// 未投递的队列订阅消息转交同一服务的其他节点，其余转入死信
 func (ps *PubSubService) cleanupExpired() {
 	ps.mu.Lock()
-	defer ps.mu.Unlock()
-
+	var removed []*Subscriber
 	expireTime := time.Now().Add(-2 * time.Minute)
-	for service, sub := range ps.subscribers {
+	for node, sub := range ps.subscribers {
 		if sub.UpdatedAt.Before(expireTime) {
-			logger.Debug("removing expired subscriber", zap.String("service", service))
-			delete(ps.subscribers, service)
+			logger.Debug("removing expired subscriber", zap.String("service", sub.Service), zap.String("node", node))
+			delete(ps.subscribers, node)
+			removed = append(removed, sub)
 			// 从 topicIndex 中移除
 			for topic := range ps.topicIndex {
-				ps.topicIndex[topic] = removeFromSlice(ps.topicIndex[topic], service)
+				ps.topicIndex[topic] = removeFromSlice(ps.topicIndex[topic], node)
+				if len(ps.topicIndex[topic]) == 0 {
+					delete(ps.topicIndex, topic)
+				}
+			}
+		}
+	}
+	ps.mu.Unlock()
+
+	// 死信会重新发布，需在释放锁后处理
+	for _, sub := range removed {
+		for _, d := range sub.queue.close() {
+			if sub.queued(d.msg.Topic) {
+				if q := ps.groupQueue(sub.Service, d.msg.Topic); q != nil {
+					q.enqueue(d)
+					continue
+				}
 			}
+			sub.queue.deadLetter(d, 0, errSubscriberExpired)
 		}
 	}
 }
@@ -104,6 +182,7 @@ func (ps *PubSubService) RegisterRoutes(r *router.Router[*core.RequestEvent]) {
 	r.POST("/pubsub/subscribe", ps.handleSubscribe)
 	r.POST("/pubsub/publish", ps.handlePublish)
 	r.GET("/pubsub/subscribers", ps.handleListSubscribers)
+	r.GET("/pubsub/deadletters", ps.handleListDeadLetters)
 }
 
 // handleSubscribe 处理订阅请求
@@ -117,47 +196,47 @@ func (ps *PubSubService) handleSubscribe(e *core.RequestEvent) error {
 		return apis.Error(e, 400, "service and callback_addr are required")
 	}
 
+	node := req.NodeID
+	if node == "" {
+		node = req.CallbackAddr
+	}
+
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
 	// 更新或创建订阅者
-	existing, exists := ps.subscribers[req.Service]
+	existing, exists := ps.subscribers[node]
 	if exists {
 		// 更新现有订阅者
 		existing.CallbackAddr = req.CallbackAddr
 		existing.UpdatedAt = time.Now()
 
 		// 合并主题列表
-		topicSet := make(map[string]bool)
-		for _, t := range existing.Topics {
-			topicSet[t] = true
-		}
-		for _, t := range req.Topics {
-			topicSet[t] = true
-		}
-		existing.Topics = make([]string, 0, len(topicSet))
-		for t := range topicSet {
-			existing.Topics = append(existing.Topics, t)
-		}
+		existing.Topics = mergeTopics(existing.Topics, req.Topics)
+		existing.QueueTopics = mergeTopics(existing.QueueTopics, req.QueueTopics)
 	} else {
 		// 创建新订阅者
-		ps.subscribers[req.Service] = &Subscriber{
+		ps.subscribers[node] = &Subscriber{
+			NodeID:       node,
 			Service:      req.Service,
 			CallbackAddr: req.CallbackAddr,
 			Topics:       req.Topics,
+			QueueTopics:  req.QueueTopics,
 			UpdatedAt:    time.Now(),
+			queue:        newDeliveryQueue(ps, req.Service, node),
 		}
 	}
 
 	// 更新主题索引
 	for _, topic := range req.Topics {
-		if !containsString(ps.topicIndex[topic], req.Service) {
-			ps.topicIndex[topic] = append(ps.topicIndex[topic], req.Service)
+		if !containsString(ps.topicIndex[topic], node) {
+			ps.topicIndex[topic] = append(ps.topicIndex[topic], node)
 		}
 	}
 
 	logger.Debug("subscriber registered",
 		zap.String("service", req.Service),
+		zap.String("node", node),
 		zap.String("callback", req.CallbackAddr),
 		zap.Strings("topics", req.Topics),
 	)
@@ -181,42 +260,131 @@ func (ps *PubSubService) handlePublish(e *core.RequestEvent) error {
 		Sender:    req.Sender,
 		Payload:   req.Payload,
 		Timestamp: time.Now(),
+		// 订阅方的处理 Span 作为本次发布请求的子 Span
+		TraceParent: tracing.TraceParent(e.Request.Context()),
 	}
 
-	// 获取订阅该主题的服务列表
+	receivers, err := ps.Publish(msg)
+	if err != nil {
+		return apis.Error(e, 500, "marshal message failed")
+	}
+
+	return e.JSON(200, map[string]any{
+		"ok":          true,
+		"subscribers": receivers,
+	})
+}
+
+// Publish 将消息加入订阅该主题的各节点（不含发送者所属服务）的投递队列并推送给进程内订阅，返回接收方数量
+// 以队列方式订阅的服务只由其中一个节点接收
+// 未设置 ID 时分配消息ID
+func (ps *PubSubService) Publish(msg *PubSubMessage) (int, error) {
+	if msg.ID == "" {
+		msg.ID = ps.nextMessageID()
+	}
+
+	// 获取订阅该主题的节点的投递队列
 	ps.mu.RLock()
-	services := ps.topicIndex[req.Topic]
-	subscribers := make([]*Subscriber, 0, len(services))
-	for _, svc := range services {
-		if sub, ok := ps.subscribers[svc]; ok {
-			// 不发送给发送者自己
-			if sub.Service != req.Sender {
-				subscribers = append(subscribers, sub)
-			}
+	nodes := ps.topicIndex[msg.Topic]
+	queues := make([]*deliveryQueue, 0, len(nodes))
+	groups := make(map[string][]*Subscriber) // service -> 以队列方式订阅的节点
+	for _, node := range nodes {
+		sub, ok := ps.subscribers[node]
+		// 不发送给发送者自己
+		if !ok || sub.Service == msg.Sender {
+			continue
+		}
+		if sub.queued(msg.Topic) {
+			groups[sub.Service] = append(groups[sub.Service], sub)
+			continue
 		}
+		queues = append(queues, sub.queue)
+	}
+	for _, members := range groups {
+		queues = append(queues, ps.pickLocked(members))
+	}
+	local := make([]func(*PubSubMessage), 0, len(ps.local[msg.Topic]))
+	for _, fn := range ps.local[msg.Topic] {
+		local = append(local, fn)
 	}
 	ps.mu.RUnlock()
 
-	// 异步推送给所有订阅者
+	// 由各订阅者的投递队列异步推送
 	data, err := json.Marshal(msg)
 	if err != nil {
-		return apis.Error(e, 500, "marshal message failed")
+		return 0, err
 	}
+	ps.counters.published.Add(1)
 
-	for _, sub := range subscribers {
-		go ps.pushToSubscriber(sub, data)
+	for _, q := range queues {
+		q.enqueue(&delivery{msg: msg, data: data})
+	}
+	for _, fn := range local {
+		fn(msg)
 	}
 
 	logger.Debug("message published",
-		zap.String("topic", req.Topic),
-		zap.String("sender", req.Sender),
-		zap.Int("subscribers", len(subscribers)),
+		zap.String("topic", msg.Topic),
+		zap.String("id", msg.ID),
+		zap.String("sender", msg.Sender),
+		zap.Int("subscribers", len(queues)+len(local)),
 	)
 
-	return e.JSON(200, map[string]any{
-		"ok":          true,
-		"subscribers": len(subscribers),
-	})
+	return len(queues) + len(local), nil
+}
+
+// pickLocked 从同一服务以队列方式订阅的节点中选择待投递消息最少的节点，相同时轮询（调用方持有锁）
+func (ps *PubSubService) pickLocked(members []*Subscriber) *deliveryQueue {
+	start := int(ps.groupSeq.Add(1) % uint64(len(members)))
+	var picked *deliveryQueue
+	least := -1
+	for i := range members {
+		q := members[(start+i)%len(members)].queue
+		if pending, _, _ := q.stats(); least < 0 || pending < least {
+			picked, least = q, pending
+		}
+	}
+	return picked
+}
+
+// groupQueue 选择 service 中以队列方式订阅 topic 的节点，没有时返回 nil
+func (ps *PubSubService) groupQueue(service, topic string) *deliveryQueue {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var members []*Subscriber
+	for _, node := range ps.topicIndex[topic] {
+		if sub, ok := ps.subscribers[node]; ok && sub.Service == service && sub.queued(topic) {
+			members = append(members, sub)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return ps.pickLocked(members)
+}
+
+// SubscribeLocal 进程内订阅主题（fn 不应阻塞），返回取消订阅函数
+func (ps *PubSubService) SubscribeLocal(topic string, fn func(*PubSubMessage)) (unsubscribe func()) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.nextLocalID++
+	id := ps.nextLocalID
+	if ps.local[topic] == nil {
+		ps.local[topic] = make(map[uint64]func(*PubSubMessage))
+	}
+	ps.local[topic][id] = fn
+
+	return func() {
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+
+		delete(ps.local[topic], id)
+		if len(ps.local[topic]) == 0 {
+			delete(ps.local, topic)
+		}
+	}
 }
 
 // handleListSubscribers 列出所有订阅者
@@ -224,9 +392,11 @@ func (ps *PubSubService) handleListSubscribers(e *core.RequestEvent) error {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
 
-	result := make([]*Subscriber, 0, len(ps.subscribers))
+	result := make([]*SubscriberStatus, 0, len(ps.subscribers))
 	for _, sub := range ps.subscribers {
-		result = append(result, sub)
+		status := &SubscriberStatus{Subscriber: sub}
+		status.Pending, status.Delivered, status.DeadLetters = sub.queue.stats()
+		result = append(result, status)
 	}
 
 	return e.JSON(200, map[string]any{
@@ -235,24 +405,61 @@ func (ps *PubSubService) handleListSubscribers(e *core.RequestEvent) error {
 	})
 }
 
-// pushToSubscriber 推送消息给订阅者
-func (ps *PubSubService) pushToSubscriber(sub *Subscriber, data []byte) {
-	url := "http://" + sub.CallbackAddr + "/_pubsub"
-	resp, err := ps.client.Post(url, "application/json", bytes.NewReader(data))
-	if err != nil {
-		logger.Debug("push to subscriber failed",
-			zap.String("service", sub.Service),
-			zap.String("callback", sub.CallbackAddr),
-			zap.Error(err),
-		)
-		return
-	}
-	resp.Body.Close()
+// handleListDeadLetters 列出最近的死信
+func (ps *PubSubService) handleListDeadLetters(e *core.RequestEvent) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return e.JSON(200, map[string]any{
+		"deadletters": append([]*DeadLetter{}, ps.deadLetters...),
+	})
+}
+
+// Collect 以 Prometheus 指标输出投递统计（实现 metrics.Collector）
+func (ps *PubSubService) Collect(e *metrics.Encoder) {
+	ps.mu.RLock()
+	var pending int
+	for _, sub := range ps.subscribers {
+		n, _, _ := sub.queue.stats()
+		pending += n
+	}
+	ps.mu.RUnlock()
+
+	for _, m := range []struct {
+		name, typ, help string
+		value           float64
+	}{
+		{"pubsub_service_published_total", metrics.TypeCounter, "Total messages published.", float64(ps.counters.published.Load())},
+		{"pubsub_service_delivered_total", metrics.TypeCounter, "Total messages acknowledged by subscribers.", float64(ps.counters.delivered.Load())},
+		{"pubsub_service_retries_total", metrics.TypeCounter, "Total delivery retries.", float64(ps.counters.retries.Load())},
+		{"pubsub_service_dead_letters_total", metrics.TypeCounter, "Total messages moved to the dead letter topic.", float64(ps.counters.deadLetters.Load())},
+		{"pubsub_service_pending_messages", metrics.TypeGauge, "Messages waiting for delivery.", float64(pending)},
+	} {
+		e.Header(m.name, m.typ, m.help)
+		e.Sample(m.name, m.value)
+	}
 }
 
-// Stop 停止服务
+// Stop 停止服务，未投递的消息随之丢弃
 func (ps *PubSubService) Stop() {
 	close(ps.stopCleanup)
+
+	ps.mu.Lock()
+	queues := make([]*deliveryQueue, 0, len(ps.subscribers))
+	for _, sub := range ps.subscribers {
+		queues = append(queues, sub.queue)
+	}
+	ps.mu.Unlock()
+
+	for _, q := range queues {
+		if pending := q.close(); len(pending) > 0 {
+			logger.Warn("dropping undelivered messages",
+				zap.String("service", q.service),
+				zap.String("node", q.node),
+				zap.Int("count", len(pending)),
+			)
+		}
+	}
 }
 
 // 辅助函数
@@ -265,6 +472,16 @@ func containsString(slice []string, s string) bool {
 	return false
 }
 
+// mergeTopics 合并主题列表（去重）
+func mergeTopics(topics, added []string) []string {
+	for _, t := range added {
+		if !containsString(topics, t) {
+			topics = append(topics, t)
+		}
+	}
+	return topics
+}
+
 func removeFromSlice(slice []string, s string) []string {
 	result := make([]string, 0, len(slice))
 	for _, item := range slice {
@@ -0,0 +1,401 @@
+package redis_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/config"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// newPubSubServer 启动 PubSub 服务
+func newPubSubServer(t *testing.T, cfg config.RedisPubSubConfig) (*redis.PubSubService, *httptest.Server) {
+	t.Helper()
+
+	ps := redis.NewPubSubService(redis.WithDelivery(cfg))
+	r := router.NewRouter(func(w http.ResponseWriter, req *http.Request) (*core.RequestEvent, router.EventCleanupFunc) {
+		event := new(core.RequestEvent)
+		event.Response = w
+		event.Request = req
+		return event, nil
+	})
+	ps.RegisterRoutes(r)
+
+	mux, err := r.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		ts.Close()
+		ps.Stop()
+	})
+	return ps, ts
+}
+
+// newSubscriber 启动订阅方，前 failures 次推送返回 500
+func newSubscriber(t *testing.T, ts *httptest.Server, service string, failures int, topics ...string) (chan *core.PubSubMessage, *atomic.Int32) {
+	t.Helper()
+
+	received := make(chan *core.PubSubMessage, 16)
+	var requests atomic.Int32
+	var sub *core.PubSub
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		sub.Handler()(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	sub = core.NewPubSub(service, strings.TrimPrefix(server.URL, "http://"), core.WithRedisAddr(strings.TrimPrefix(ts.URL, "http://")))
+	for _, topic := range topics {
+		sub.Subscribe(topic, func(msg *core.PubSubMessage) error {
+			received <- msg
+			return nil
+		})
+	}
+	if err := sub.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sub.Stop() })
+	return received, &requests
+}
+
+// receive 等待一条消息
+func receive(t *testing.T, ch chan *core.PubSubMessage) *core.PubSubMessage {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected message to be delivered")
+		return nil
+	}
+}
+
+// getJSON 请求 PubSub 服务并解析响应
+func getJSON(t *testing.T, url string, dest any) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPubSubRetry(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{RetryBackoff: 10})
+	received, requests := newSubscriber(t, ts, "log-service", 2, "log:access")
+
+	if n, err := ps.Publish(&redis.PubSubMessage{Topic: "log:access", Sender: "gateway-service", Payload: []byte(`1`)}); err != nil || n != 1 {
+		t.Fatalf("Expected 1 receiver, got %d (%v)", n, err)
+	}
+
+	msg := receive(t, received)
+	if msg.ID == "" || string(msg.Payload) != "1" {
+		t.Fatalf("Unexpected message %+v", msg)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("Expected 2 failed pushes and 1 delivery, got %d requests", n)
+	}
+
+	// 订阅方响应后确认
+	var list struct {
+		Subscribers []redis.SubscriberStatus `json:"subscribers"`
+	}
+	waitFor(t, func() bool {
+		getJSON(t, ts.URL+"/pubsub/subscribers", &list)
+		return len(list.Subscribers) == 1 && list.Subscribers[0].Delivered == 1
+	})
+	if list.Subscribers[0].Pending != 0 || list.Subscribers[0].DeadLetters != 0 {
+		t.Fatalf("Unexpected subscriber status %+v", list.Subscribers[0])
+	}
+}
+
+func TestPubSubReplicas(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{RetryBackoff: 10})
+
+	// 同一服务的两个副本以各自的节点ID订阅，均收到每条消息
+	var replicas []chan *core.PubSubMessage
+	for _, node := range []string{"rbac-service-a", "rbac-service-b"} {
+		received := make(chan *core.PubSubMessage, 16)
+		var sub *core.PubSub
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sub.Handler()(w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		sub = core.NewPubSub("rbac-service", strings.TrimPrefix(server.URL, "http://"),
+			core.WithRedisAddr(strings.TrimPrefix(ts.URL, "http://")),
+			core.WithPubSubNodeID(node),
+		)
+		sub.Subscribe("rbac:policy", func(msg *core.PubSubMessage) error {
+			received <- msg
+			return nil
+		})
+		if err := sub.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sub.Stop() })
+		replicas = append(replicas, received)
+	}
+
+	var ids []string
+	for range 3 {
+		msg := &redis.PubSubMessage{Topic: "rbac:policy", Sender: "gateway-service"}
+		if n, _ := ps.Publish(msg); n != 2 {
+			t.Fatalf("Expected 2 receivers, got %d", n)
+		}
+		ids = append(ids, msg.ID)
+	}
+	for i, received := range replicas {
+		for _, id := range ids {
+			if msg := receive(t, received); msg.ID != id {
+				t.Fatalf("Expected replica %d to receive %s, got %s", i, id, msg.ID)
+			}
+		}
+	}
+
+	var list struct {
+		Subscribers []redis.SubscriberStatus `json:"subscribers"`
+	}
+	getJSON(t, ts.URL+"/pubsub/subscribers", &list)
+	if len(list.Subscribers) != 2 {
+		t.Fatalf("Expected one subscriber per node, got %+v", list.Subscribers)
+	}
+}
+
+func TestPubSubQueueSubscription(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{RetryBackoff: 10})
+	broadcast, _ := newSubscriber(t, ts, "audit-service", 0, "log:access")
+
+	// 日志服务的两个节点以队列方式订阅，每条消息只由其中一个节点处理
+	received := make(chan *core.PubSubMessage, 16)
+	var perNode [2]atomic.Int32
+	for i, node := range []string{"log-service-a", "log-service-b"} {
+		var sub *core.PubSub
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sub.Handler()(w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		sub = core.NewPubSub("log-service", strings.TrimPrefix(server.URL, "http://"),
+			core.WithRedisAddr(strings.TrimPrefix(ts.URL, "http://")),
+			core.WithPubSubNodeID(node),
+		)
+		sub.SubscribeQueue("log:access", func(msg *core.PubSubMessage) error {
+			perNode[i].Add(1)
+			received <- msg
+			return nil
+		})
+		if err := sub.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { sub.Stop() })
+	}
+
+	ids := make(map[string]bool)
+	for range 4 {
+		msg := &redis.PubSubMessage{Topic: "log:access", Sender: "gateway-service"}
+		if n, _ := ps.Publish(msg); n != 2 {
+			t.Fatalf("Expected the audit service and one log node, got %d receivers", n)
+		}
+		ids[msg.ID] = true
+	}
+	for range 4 {
+		if msg := receive(t, received); !ids[msg.ID] {
+			t.Fatalf("Unexpected or duplicate message %s", msg.ID)
+		} else {
+			delete(ids, msg.ID)
+		}
+		receive(t, broadcast)
+	}
+	select {
+	case msg := <-received:
+		t.Fatalf("Expected each message once, got an extra %s", msg.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if perNode[0].Load() == 0 || perNode[1].Load() == 0 {
+		t.Fatalf("Expected messages to be spread over both nodes, got %d and %d", perNode[0].Load(), perNode[1].Load())
+	}
+}
+
+func TestPubSubDeliveryOrder(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{RetryBackoff: 10})
+	received, _ := newSubscriber(t, ts, "log-service", 3, "log:access")
+
+	// 队首消息重试期间后续消息等待，收到的顺序与发布顺序一致
+	var ids []string
+	for _, payload := range []string{"1", "2", "3"} {
+		msg := &redis.PubSubMessage{Topic: "log:access", Payload: []byte(payload)}
+		ps.Publish(msg)
+		ids = append(ids, msg.ID)
+	}
+	for i, id := range ids {
+		if msg := receive(t, received); msg.ID != id {
+			t.Fatalf("Expected message %d to be %s, got %s", i, id, msg.ID)
+		}
+	}
+}
+
+func TestPubSubDeadLetter(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{MaxAttempts: 3, RetryBackoff: 10})
+	_, requests := newSubscriber(t, ts, "log-service", 100, "log:access")
+	deadLetters, _ := newSubscriber(t, ts, "ops-service", 0, redis.DeadLetterTopic)
+
+	msg := &redis.PubSubMessage{Topic: "log:access", Payload: []byte(`1`)}
+	ps.Publish(msg)
+
+	var dl redis.DeadLetter
+	if err := json.Unmarshal(receive(t, deadLetters).Payload, &dl); err != nil {
+		t.Fatal(err)
+	}
+	if dl.Message.ID != msg.ID || dl.Subscriber != "log-service" || dl.Attempts != 3 || !strings.Contains(dl.Error, "500") {
+		t.Fatalf("Unexpected dead letter %+v", dl)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", n)
+	}
+
+	var list struct {
+		DeadLetters []redis.DeadLetter `json:"deadletters"`
+	}
+	getJSON(t, ts.URL+"/pubsub/deadletters", &list)
+	if len(list.DeadLetters) != 1 || list.DeadLetters[0].Message.Topic != "log:access" {
+		t.Fatalf("Expected the dead letter to be listed, got %+v", list.DeadLetters)
+	}
+}
+
+func TestPubSubQueueFull(t *testing.T) {
+	ps, ts := newPubSubServer(t, config.RedisPubSubConfig{QueueSize: 2})
+
+	// 订阅方阻塞第一条推送，使后续消息留在队列中
+	release := make(chan struct{})
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	var requests atomic.Int32
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+	}))
+	t.Cleanup(subscriber.Close)
+	t.Cleanup(unblock)
+
+	body, _ := json.Marshal(redis.SubscribeRequest{
+		Service:      "log-service",
+		CallbackAddr: strings.TrimPrefix(subscriber.URL, "http://"),
+		Topics:       []string{"log:access"},
+	})
+	resp, err := http.Post(ts.URL+"/pubsub/subscribe", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var msgs []*redis.PubSubMessage
+	publish := func() {
+		msg := &redis.PubSubMessage{Topic: "log:access"}
+		ps.Publish(msg)
+		msgs = append(msgs, msg)
+	}
+	publish()
+	waitFor(t, func() bool { return requests.Load() == 1 })
+	for range 3 {
+		publish()
+	}
+
+	// 队列中最早的待投递消息被挤出
+	var list struct {
+		DeadLetters []redis.DeadLetter `json:"deadletters"`
+	}
+	getJSON(t, ts.URL+"/pubsub/deadletters", &list)
+	if len(list.DeadLetters) != 1 || list.DeadLetters[0].Message.ID != msgs[1].ID || list.DeadLetters[0].Error != "delivery queue full" {
+		t.Fatalf("Expected the second message to be dead-lettered, got %+v", list.DeadLetters)
+	}
+
+	unblock()
+	waitFor(t, func() bool { return requests.Load() == 3 })
+}
+
+func TestPubSubHandlerDeduplicates(t *testing.T) {
+	var handled atomic.Int32
+	sub := core.NewPubSub("log-service", "127.0.0.1:0")
+	sub.Subscribe("log:access", func(msg *core.PubSubMessage) error {
+		handled.Add(1)
+		return nil
+	})
+
+	deliver := func(msg core.PubSubMessage) {
+		body, _ := json.Marshal(msg)
+		rec := httptest.NewRecorder()
+		sub.Handler()(rec, httptest.NewRequest(http.MethodPost, "/_pubsub", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+	}
+
+	// 处理函数返回后才确认
+	deliver(core.PubSubMessage{ID: "1-0", Topic: "log:access"})
+	if n := handled.Load(); n != 1 {
+		t.Fatalf("Expected the message to be handled before the ack, got %d", n)
+	}
+
+	deliver(core.PubSubMessage{ID: "1-0", Topic: "log:access"})
+	deliver(core.PubSubMessage{ID: "1-1", Topic: "log:access"})
+	// 没有 ID 的消息不去重
+	deliver(core.PubSubMessage{Topic: "log:access"})
+	deliver(core.PubSubMessage{Topic: "log:access"})
+	if n := handled.Load(); n != 4 {
+		t.Fatalf("Expected the redelivered message to be skipped, got %d handled", n)
+	}
+}
+
+func TestPubSubHandlerFailureNotAcked(t *testing.T) {
+	var attempts atomic.Int32
+	sub := core.NewPubSub("log-service", "127.0.0.1:0")
+	sub.Subscribe("log:access", func(msg *core.PubSubMessage) error {
+		switch attempts.Add(1) {
+		case 1:
+			return errors.New("database unavailable")
+		case 2:
+			panic("boom")
+		}
+		return nil
+	})
+
+	deliver := func() int {
+		body, _ := json.Marshal(core.PubSubMessage{ID: "1-0", Topic: "log:access"})
+		rec := httptest.NewRecorder()
+		sub.Handler()(rec, httptest.NewRequest(http.MethodPost, "/_pubsub", bytes.NewReader(body)))
+		return rec.Code
+	}
+
+	// 处理失败或 panic 时不确认，重试投递的消息仍会处理
+	for _, expected := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		if code := deliver(); code != expected {
+			t.Fatalf("Expected %d, got %d", expected, code)
+		}
+	}
+	// 成功处理后的重复投递被跳过
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("Expected 3 handler calls, got %d", n)
+	}
+}
@@ -1,8 +1,11 @@
 package redis
 
 import (
+	"container/list"
 	"encoding/json"
 	"errors"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,10 +19,25 @@ import (
 // ErrNotFound 未找到错误
 var ErrNotFound = errors.New("key not found")
 
+// ErrNotInteger 值不是整数错误
+var ErrNotInteger = errors.New("value is not an integer or out of range")
+
 // item 缓存项
+// Value 与 Expiration 写入后不再修改（可在锁外读取）；集合类型的值在写锁内原地修改，只能在锁内访问
+// accessed 与 hits 在读锁内以原子操作更新，供淘汰策略使用
 type item struct {
 	Value      []byte
 	Expiration int64
+
+	size     int64  // 估算的占用（字节），随写入增量维护
+	accessed int64  // 最近访问时间（UnixNano）
+	hits     uint32 // 访问次数
+
+	// 集合类型的值，最多一个非空，均为空时为字符串
+	hash map[string][]byte
+	list *list.List
+	set  map[string]struct{}
+	zset *zset
 }
 
 func (i *item) expired() bool {
@@ -32,22 +50,34 @@ type Service struct {
 	items       map[string]*item
 	mu          sync.RWMutex
 	stopCleanup chan struct{}
+	persist     *persistence
+	waiters     map[string]map[chan struct{}]struct{} // 阻塞弹出的等待者，按键索引
+	limits      *limits                               // 内存上限，nil 表示不限制
+	used        int64                                 // 估算的总占用（字节），在写锁内维护
+	counters    counters
 }
 
+// Option 服务选项
+type Option func(*Service)
+
 // NewService 创建 Redis 服务
-func NewService(name string) *Service {
+func NewService(name string, opts ...Option) *Service {
 	s := &Service{
 		name:        name,
 		items:       make(map[string]*item),
 		stopCleanup: make(chan struct{}),
+		waiters:     make(map[string]map[chan struct{}]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 	go s.cleanupLoop()
 	return s
 }
 
-// cleanupLoop 定期清理过期项
+// cleanupLoop 定期主动清理过期项
 func (s *Service) cleanupLoop() {
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(activeExpireInterval)
 	defer ticker.Stop()
 	for {
 		select {
@@ -59,34 +89,145 @@ func (s *Service) cleanupLoop() {
 	}
 }
 
-func (s *Service) deleteExpired() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	now := time.Now().UnixNano()
-	for k, v := range s.items {
-		if v.Expiration > 0 && now > v.Expiration {
-			delete(s.items, k)
+// Start 启动服务，开启持久化时先恢复数据
+func (s *Service) Start() error {
+	if s.persist != nil {
+		if err := s.load(); err != nil {
+			return err
 		}
+		s.persist.wg.Add(1)
+		go s.persistLoop()
 	}
-}
 
-// Start 启动服务
-func (s *Service) Start() error {
 	logger.Info("Redis 缓存服务初始化完成",
 		zap.String("service", s.name),
 		zap.String("mode", "memory"),
+		zap.Bool("persistence", s.persist != nil),
+		zap.Bool("memory_limit", s.limits != nil),
 	)
 	return nil
 }
 
-// Stop 停止服务
+// Stop 停止服务，开启持久化时写入最终快照
 func (s *Service) Stop() error {
 	logger.Info("正在停止 Redis 缓存服务")
 	close(s.stopCleanup)
+	if s.persist != nil {
+		if err := s.closePersistence(); err != nil {
+			return err
+		}
+	}
 	logger.Info("Redis 缓存服务已停止")
 	return nil
 }
 
+// commitLocked 检查内存上限，写入追加日志后应用到内存并更新占用（调用方持有写锁）
+func (s *Service) commitLocked(rec *record) error {
+	if err := s.reserveLocked(rec); err != nil {
+		return err
+	}
+	if err := s.persist.appendRecord(rec); err != nil {
+		return err
+	}
+
+	// 集合类型原地修改，先记下原占用
+	var before int64
+	if it, ok := s.items[rec.Key]; ok {
+		before = it.size
+	}
+	if err := applyRecord(s.items, rec); err != nil {
+		return err
+	}
+	if rec.Op == opClear {
+		s.used = 0
+		return nil
+	}
+
+	var after int64
+	if it, ok := s.items[rec.Key]; ok {
+		after = it.size
+		it.touch(time.Now().UnixNano())
+	}
+	s.used += after - before
+	return nil
+}
+
+// putLocked 写入字符串键（调用方持有写锁）
+func (s *Service) putLocked(key string, it *item) error {
+	return s.commitLocked(&record{Op: opSet, Key: key, Value: it.Value, Expiration: it.Expiration})
+}
+
+// liveLocked 返回未过期的键，已过期的键先记录删除（调用方持有写锁）
+// 使后续记录不依赖重放时的当前时间
+func (s *Service) liveLocked(key string) (*item, bool, error) {
+	it, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if it.expired() {
+		return nil, false, s.commitLocked(&record{Op: opDel, Key: key})
+	}
+	return it, true, nil
+}
+
+// typedLocked 返回 kind 类型的未过期键，类型不符时返回 ErrWrongType（调用方持有写锁）
+func (s *Service) typedLocked(key, kind string) (*item, bool, error) {
+	it, ok, err := s.liveLocked(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	if it.kind() != kind {
+		return nil, false, ErrWrongType
+	}
+	return it, true, nil
+}
+
+// lookup 返回 kind 类型的未过期键并记录访问（调用方持有读锁）
+func (s *Service) lookup(key, kind string) (*item, bool, error) {
+	it, ok := s.items[key]
+	if !ok || it.expired() {
+		s.counters.misses.Add(1)
+		return nil, false, nil
+	}
+	if it.kind() != kind {
+		return nil, false, ErrWrongType
+	}
+	s.counters.hits.Add(1)
+	it.touch(time.Now().UnixNano())
+	return it, true, nil
+}
+
+// put 写入键
+func (s *Service) put(key string, value []byte, ttl int64) error {
+	var exp int64
+	if ttl > 0 {
+		exp = time.Now().Add(time.Duration(ttl) * time.Second).UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.putLocked(key, &item{Value: value, Expiration: exp})
+}
+
+// remove 删除键
+func (s *Service) remove(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.items[key]; !ok {
+		return nil
+	}
+	return s.commitLocked(&record{Op: opDel, Key: key})
+}
+
+// flush 清空所有键
+func (s *Service) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commitLocked(&record{Op: opClear})
+}
+
 // RegisterRoutes 注册 HTTP 路由
 func (s *Service) RegisterRoutes(r *router.Router[*core.RequestEvent]) {
 	r.POST("/cache/set", s.handleSet)
@@ -95,6 +236,60 @@ func (s *Service) RegisterRoutes(r *router.Router[*core.RequestEvent]) {
 	r.POST("/cache/exists", s.handleExists)
 	r.GET("/cache/keys", s.handleKeys)
 	r.POST("/cache/clear", s.handleClear)
+	r.POST("/cache/incr", s.handleIncr)
+	r.POST("/cache/setnx", s.handleSetNX)
+	r.POST("/cache/cas", s.handleCompareAndSwap)
+	r.POST("/cache/cad", s.handleCompareAndDelete)
+	r.POST("/cache/scan", s.handleScan)
+	r.POST("/cache/mget", s.handleMGet)
+	r.POST("/cache/mset", s.handleMSet)
+	r.POST("/cache/prefix/get", s.handleGetPrefix)
+	r.POST("/cache/prefix/delete", s.handleDeletePrefix)
+	r.GET("/cache/stats", s.handleStats)
+
+	r.POST("/cache/hset", s.handleHSet)
+	r.POST("/cache/hget", s.handleHGet)
+	r.POST("/cache/hgetall", s.handleHGetAll)
+	r.POST("/cache/hdel", s.handleHDel)
+	r.POST("/cache/hincrby", s.handleHIncrBy)
+
+	r.POST("/cache/push", s.handlePush)
+	r.POST("/cache/pop", s.handlePop)
+	r.POST("/cache/lrange", s.handleLRange)
+	r.POST("/cache/llen", s.handleLLen)
+
+	r.POST("/cache/sadd", s.handleSAdd)
+	r.POST("/cache/srem", s.handleSRem)
+	r.POST("/cache/sismember", s.handleSIsMember)
+	r.POST("/cache/smembers", s.handleSMembers)
+	r.POST("/cache/scard", s.handleSCard)
+
+	r.POST("/cache/zadd", s.handleZAdd)
+	r.POST("/cache/zincrby", s.handleZIncrBy)
+	r.POST("/cache/zscore", s.handleZScore)
+	r.POST("/cache/zrem", s.handleZRem)
+	r.POST("/cache/zrank", s.handleZRank)
+	r.POST("/cache/zrange", s.handleZRange)
+	r.POST("/cache/zrangebyscore", s.handleZRangeByScore)
+	r.POST("/cache/zcard", s.handleZCard)
+
+	r.POST("/cache/lock/acquire", s.handleLockAcquire)
+	r.POST("/cache/lock/renew", s.handleLockRenew)
+	r.POST("/cache/lock/release", s.handleLockRelease)
+	r.POST("/cache/lock/info", s.handleLockInfo)
+}
+
+// errorStatus 类型或取值错误返回 400，值过大返回 413，超出内存上限返回 507，持久化等内部错误返回 500
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrWrongType) || errors.Is(err, ErrNotInteger) || errors.Is(err, ErrNotFloat) || errors.Is(err, ErrInvalidLease):
+		return 400
+	case errors.Is(err, ErrValueTooLarge):
+		return 413
+	case errors.Is(err, ErrOOM):
+		return 507
+	}
+	return 500
 }
 
 // SetRequest 设置请求
@@ -115,21 +310,29 @@ type GetResponse struct {
 	Found bool   `json:"found"`
 }
 
+// IncrRequest 自增请求
+type IncrRequest struct {
+	Key   string `json:"key"`
+	Delta int64  `json:"delta"`
+	TTL   int64  `json:"ttl"` // 秒，仅在键新建时设置过期时间
+}
+
+// IncrResponse 自增响应
+type IncrResponse struct {
+	Value int64 `json:"value"`
+	TTL   int64 `json:"ttl"` // 剩余过期时间（秒，向上取整），-1 表示永不过期
+}
+
 func (s *Service) handleSet(e *core.RequestEvent) error {
 	var req SetRequest
 	if err := e.BindBody(&req); err != nil {
 		return apis.Error(e, 400, err.Error())
 	}
 
-	var exp int64
-	if req.TTL > 0 {
-		exp = time.Now().Add(time.Duration(req.TTL) * time.Second).UnixNano()
+	if err := s.put(req.Key, req.Value, req.TTL); err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
 	}
 
-	s.mu.Lock()
-	s.items[req.Key] = &item{Value: req.Value, Expiration: exp}
-	s.mu.Unlock()
-
 	return e.JSON(200, map[string]any{"ok": true})
 }
 
@@ -139,15 +342,12 @@ func (s *Service) handleGet(e *core.RequestEvent) error {
 		return apis.Error(e, 400, err.Error())
 	}
 
-	s.mu.RLock()
-	it, ok := s.items[req.Key]
-	s.mu.RUnlock()
-
-	if !ok || it.expired() {
-		return e.JSON(200, GetResponse{Found: false})
+	value, ok, err := s.getString(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
 	}
 
-	return e.JSON(200, GetResponse{Value: it.Value, Found: true})
+	return e.JSON(200, GetResponse{Value: value, Found: ok})
 }
 
 func (s *Service) handleDelete(e *core.RequestEvent) error {
@@ -156,9 +356,9 @@ func (s *Service) handleDelete(e *core.RequestEvent) error {
 		return apis.Error(e, 400, err.Error())
 	}
 
-	s.mu.Lock()
-	delete(s.items, req.Key)
-	s.mu.Unlock()
+	if err := s.remove(req.Key); err != nil {
+		return apis.Error(e, 500, err.Error())
+	}
 
 	return e.JSON(200, map[string]any{"ok": true})
 }
@@ -193,64 +393,174 @@ func (s *Service) handleKeys(e *core.RequestEvent) error {
 }
 
 func (s *Service) handleClear(e *core.RequestEvent) error {
-	s.mu.Lock()
-	s.items = make(map[string]*item)
-	s.mu.Unlock()
+	if err := s.flush(); err != nil {
+		return apis.Error(e, 500, err.Error())
+	}
 
 	return e.JSON(200, map[string]any{"ok": true})
 }
 
+func (s *Service) handleIncr(e *core.RequestEvent) error {
+	var req IncrRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	value, ttl, err := s.IncrBy(req.Key, req.Delta, req.TTL)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	resp := IncrResponse{Value: value, TTL: -1}
+	if ttl > 0 {
+		resp.TTL = int64(math.Ceil(ttl.Seconds()))
+	}
+	return e.JSON(200, resp)
+}
+
+// CompareAndSwapRequest 比较并替换请求
+type CompareAndSwapRequest struct {
+	Key   string `json:"key"`
+	Old   []byte `json:"old"`
+	Value []byte `json:"value"`
+	TTL   int64  `json:"ttl"` // 秒，0 表示永不过期
+}
+
+// CompareAndDeleteRequest 比较并删除请求
+type CompareAndDeleteRequest struct {
+	Key string `json:"key"`
+	Old []byte `json:"old"`
+}
+
+func (s *Service) handleSetNX(e *core.RequestEvent) error {
+	var req SetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.SetNX(req.Key, req.Value, time.Duration(req.TTL)*time.Second)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"set": ok})
+}
+
+func (s *Service) handleCompareAndSwap(e *core.RequestEvent) error {
+	var req CompareAndSwapRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.CompareAndSwap(req.Key, req.Old, req.Value, time.Duration(req.TTL)*time.Second)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"swapped": ok})
+}
+
+func (s *Service) handleCompareAndDelete(e *core.RequestEvent) error {
+	var req CompareAndDeleteRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.CompareAndDelete(req.Key, req.Old)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"deleted": ok})
+}
+
 // --- 直接访问方法（供本地调用） ---
 
+// IncrBy 原子地将键的整数值增加 delta，键不存在或已过期时从 0 开始并按 ttl（秒）设置过期时间
+// 返回增加后的值与剩余过期时间（0 表示永不过期）
+func (s *Service) IncrBy(key string, delta int64, ttl int64) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	it, ok := s.items[key]
+	if !ok || it.expired() {
+		it = &item{Value: []byte("0")}
+		if ttl > 0 {
+			it.Expiration = now.Add(time.Duration(ttl) * time.Second).UnixNano()
+		}
+	} else if it.kind() != KindString {
+		return 0, 0, ErrWrongType
+	}
+
+	current, err := strconv.ParseInt(string(it.Value), 10, 64)
+	if err != nil {
+		return 0, 0, ErrNotInteger
+	}
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, 0, ErrNotInteger
+	}
+	current += delta
+
+	// 保持原有过期时间
+	if err := s.putLocked(key, &item{Value: []byte(strconv.FormatInt(current, 10)), Expiration: it.Expiration}); err != nil {
+		return 0, 0, err
+	}
+
+	var remaining time.Duration
+	if it.Expiration > 0 {
+		remaining = time.Duration(it.Expiration - now.UnixNano())
+	}
+	return current, remaining, nil
+}
+
 func (s *Service) Set(key string, value any, ttl int64) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	var exp int64
-	if ttl > 0 {
-		exp = time.Now().Add(time.Duration(ttl) * time.Second).UnixNano()
-	}
-	s.mu.Lock()
-	s.items[key] = &item{Value: data, Expiration: exp}
-	s.mu.Unlock()
-	return nil
+	return s.put(key, data, ttl)
 }
 
 func (s *Service) SetRaw(key string, value []byte, ttl int64) {
-	var exp int64
-	if ttl > 0 {
-		exp = time.Now().Add(time.Duration(ttl) * time.Second).UnixNano()
+	if err := s.put(key, value, ttl); err != nil {
+		logger.Error("写入缓存失败", zap.String("key", key), zap.Error(err))
 	}
-	s.mu.Lock()
-	s.items[key] = &item{Value: value, Expiration: exp}
-	s.mu.Unlock()
 }
 
 func (s *Service) Get(key string, dest any) error {
-	s.mu.RLock()
-	it, ok := s.items[key]
-	s.mu.RUnlock()
-	if !ok || it.expired() {
+	value, ok, err := s.getString(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		return ErrNotFound
 	}
-	return json.Unmarshal(it.Value, dest)
+	return json.Unmarshal(value, dest)
 }
 
+// GetRaw 获取字符串键的原始值，键不存在或不是字符串时返回 false
 func (s *Service) GetRaw(key string) ([]byte, bool) {
+	value, ok, _ := s.getString(key)
+	return value, ok
+}
+
+// getString 获取字符串键的值，键的值为集合类型时返回 ErrWrongType
+func (s *Service) getString(key string) ([]byte, bool, error) {
 	s.mu.RLock()
-	it, ok := s.items[key]
-	s.mu.RUnlock()
-	if !ok || it.expired() {
-		return nil, false
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindString)
+	if err != nil || !ok {
+		return nil, false, err
 	}
-	return it.Value, true
+	return it.Value, true, nil
 }
 
 func (s *Service) Delete(key string) {
-	s.mu.Lock()
-	delete(s.items, key)
-	s.mu.Unlock()
+	if err := s.remove(key); err != nil {
+		logger.Error("删除缓存失败", zap.String("key", key), zap.Error(err))
+	}
 }
 
 func (s *Service) Exists(key string) bool {
@@ -0,0 +1,92 @@
+package redis_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/cache"
+)
+
+func TestCacheIncrBy(t *testing.T) {
+	_, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	value, ttl, err := c.IncrBy("counter", 5, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 5 {
+		t.Fatalf("Expected 5, got %d", value)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("Expected TTL within one minute, got %s", ttl)
+	}
+
+	// 已存在的键不会重置过期时间
+	value, ttl, err = c.IncrBy("counter", -2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 3 || ttl > time.Minute {
+		t.Fatalf("Expected 3 with the original TTL, got %d (%s)", value, ttl)
+	}
+
+	// 自增后的值可以按 JSON 读取
+	var n int64
+	if err := c.Get("counter", &n); err != nil || n != 3 {
+		t.Fatalf("Expected Get to return 3, got %d (%v)", n, err)
+	}
+
+	// 未设置过期时间
+	if _, ttl, err = c.IncrBy("persistent", 1, 0); err != nil || ttl != 0 {
+		t.Fatalf("Expected no TTL, got %s (%v)", ttl, err)
+	}
+
+	// 非整数值
+	if err := c.Set("text", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.IncrBy("text", 1, 0); err == nil {
+		t.Fatal("Expected an error for a non-integer value")
+	}
+}
+
+func TestCacheIncrByConcurrent(t *testing.T) {
+	svc, ts := newTestServer(t)
+	c := cache.NewWithURL(ts.URL)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			if _, _, err := c.IncrBy("hits", 1, time.Minute); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	value, _, err := svc.IncrBy("hits", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 50 {
+		t.Fatalf("Expected 50, got %d", value)
+	}
+}
+
+func TestServiceIncrByOverflow(t *testing.T) {
+	svc, _ := newTestServer(t)
+
+	svc.SetRaw("window", []byte("10"), 0)
+	if value, _, _ := svc.IncrBy("window", 1, 0); value != 11 {
+		t.Fatalf("Expected 11, got %d", value)
+	}
+
+	if _, _, err := svc.IncrBy("window", 1<<62, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.IncrBy("window", 1<<62, 0); err == nil {
+		t.Fatal("Expected an overflow error")
+	}
+}
@@ -0,0 +1,359 @@
+package redis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/cache"
+	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/services/redis/internal/redis"
+	"go-micro.dev/v5/registry"
+)
+
+// newTestServer 启动进程内缓存服务，返回服务实例与 HTTP 测试服务器
+func newTestServer(t *testing.T) (*redis.Service, *httptest.Server) {
+	t.Helper()
+
+	svc := redis.NewService("redis-test")
+	r := router.NewRouter(func(w http.ResponseWriter, req *http.Request) (*core.RequestEvent, router.EventCleanupFunc) {
+		event := new(core.RequestEvent)
+		event.Response = w
+		event.Request = req
+		return event, nil
+	})
+	svc.RegisterRoutes(r)
+
+	mux, err := r.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		ts.Close()
+		_ = svc.Stop()
+	})
+
+	return svc, ts
+}
+
+func newTestService(name, nodeID, addr string) *registry.Service {
+	return pkgRegistry.NewServiceBuilder(name, "v1.0.0").
+		WithNodeID(nodeID).
+		WithAddress(addr).
+		WithBasePath("test").
+		Build()
+}
+
+func nextResult(t *testing.T, w registry.Watcher) *registry.Result {
+	t.Helper()
+
+	ch := make(chan *registry.Result, 1)
+	go func() {
+		res, err := w.Next()
+		if err != nil {
+			close(ch)
+			return
+		}
+		ch <- res
+	}()
+
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			t.Fatal("Expected watch result, got watcher error")
+		}
+		return res
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for watch result")
+	}
+	return nil
+}
+
+func TestRedisRegistryWatch(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(
+		pkgRegistry.WithCache(cache.NewWithURL(ts.URL)),
+		pkgRegistry.WithWatchInterval(20*time.Millisecond),
+	)
+
+	// 已存在的服务作为基线，不应产生事件
+	existing := newTestService("existing-service", "existing-1", "127.0.0.1:9000")
+	if err := reg.Register(existing); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(existing)
+
+	w, err := reg.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	svc := newTestService("watch-service", "watch-1", "127.0.0.1:9001")
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+
+	res := nextResult(t, w)
+	if res.Action != "create" || res.Service.Name != "watch-service" {
+		t.Fatalf("Expected create watch-service, got %s %s", res.Action, res.Service.Name)
+	}
+
+	svc.Nodes[0].Address = "127.0.0.1:9002"
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+
+	res = nextResult(t, w)
+	if res.Action != "update" || res.Service.Name != "watch-service" {
+		t.Fatalf("Expected update watch-service, got %s %s", res.Action, res.Service.Name)
+	}
+	if addr := res.Service.Nodes[0].Address; addr != "127.0.0.1:9002" {
+		t.Fatalf("Expected updated address 127.0.0.1:9002, got %s", addr)
+	}
+
+	if err := reg.Deregister(svc); err != nil {
+		t.Fatal(err)
+	}
+
+	res = nextResult(t, w)
+	if res.Action != "delete" || res.Service.Name != "watch-service" {
+		t.Fatalf("Expected delete watch-service, got %s %s", res.Action, res.Service.Name)
+	}
+}
+
+func TestRedisRegistryWatchService(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(
+		pkgRegistry.WithCache(cache.NewWithURL(ts.URL)),
+		pkgRegistry.WithWatchInterval(20*time.Millisecond),
+	)
+
+	w, err := reg.Watch(registry.WatchService("wanted-service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	other := newTestService("other-service", "other-1", "127.0.0.1:9010")
+	wanted := newTestService("wanted-service", "wanted-1", "127.0.0.1:9011")
+	if err := reg.Register(other); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(other)
+	if err := reg.Register(wanted); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(wanted)
+
+	res := nextResult(t, w)
+	if res.Service.Name != "wanted-service" {
+		t.Fatalf("Expected only wanted-service events, got %s", res.Service.Name)
+	}
+}
+
+func TestRedisRegistryWatchStop(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(pkgRegistry.WithCache(cache.NewWithURL(ts.URL)))
+
+	w, err := reg.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Stop()
+
+	if _, err := w.Next(); err != registry.ErrWatcherStopped {
+		t.Fatalf("Expected ErrWatcherStopped, got %v", err)
+	}
+}
+
+func TestRedisRegistryWatchCacheUnavailable(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(
+		pkgRegistry.WithCache(cache.NewWithURL(ts.URL)),
+		pkgRegistry.WithWatchInterval(20*time.Millisecond),
+	)
+
+	svc := newTestService("stable-service", "stable-1", "127.0.0.1:9020")
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(svc)
+
+	w, err := reg.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	// 缓存服务不可达时不应误报 delete
+	ts.Close()
+
+	ch := make(chan *registry.Result, 1)
+	go func() {
+		if res, err := w.Next(); err == nil {
+			ch <- res
+		}
+	}()
+
+	select {
+	case res := <-ch:
+		t.Fatalf("Expected no events while cache is unavailable, got %s %s", res.Action, res.Service.Name)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestRedisRegistryMultiNode(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	c := cache.NewWithURL(ts.URL)
+
+	// 模拟两个独立进程中的副本
+	regA := pkgRegistry.NewRedisRegistry(pkgRegistry.WithCache(c))
+	regB := pkgRegistry.NewRedisRegistry(pkgRegistry.WithCache(c))
+
+	nodeA := newTestService("user-service", "user-a", "127.0.0.1:9101")
+	nodeB := newTestService("user-service", "user-b", "127.0.0.1:9102")
+	if err := regA.Register(nodeA); err != nil {
+		t.Fatal(err)
+	}
+	if err := regB.Register(nodeB); err != nil {
+		t.Fatal(err)
+	}
+	defer regB.Deregister(nodeB)
+
+	services, err := regA.GetService("user-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service version, got %d", len(services))
+	}
+	if n := len(services[0].Nodes); n != 2 {
+		t.Fatalf("Expected 2 merged nodes, got %d", n)
+	}
+	if services[0].Nodes[0].Id != "user-a" || services[0].Nodes[1].Id != "user-b" {
+		t.Fatalf("Expected nodes [user-a user-b], got [%s %s]", services[0].Nodes[0].Id, services[0].Nodes[1].Id)
+	}
+
+	list, err := regA.ListServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || len(list[0].Nodes) != 2 {
+		t.Fatalf("Expected 1 listed service with 2 nodes, got %d services", len(list))
+	}
+
+	// 注销一个副本不影响另一个
+	if err := regA.Deregister(nodeA); err != nil {
+		t.Fatal(err)
+	}
+
+	services, err = regB.GetService("user-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services[0].Nodes) != 1 || services[0].Nodes[0].Id != "user-b" {
+		t.Fatalf("Expected only user-b to remain, got %v", services[0].Nodes)
+	}
+}
+
+func TestRedisRegistryMultiVersion(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(pkgRegistry.WithCache(cache.NewWithURL(ts.URL)))
+
+	v1 := newTestService("order-service", "order-v1", "127.0.0.1:9201")
+	v2 := newTestService("order-service", "order-v2", "127.0.0.1:9202")
+	v2.Version = "v2.0.0"
+	for _, s := range []*registry.Service{v1, v2} {
+		if err := reg.Register(s); err != nil {
+			t.Fatal(err)
+		}
+		defer reg.Deregister(s)
+	}
+
+	services, err := reg.GetService("order-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 service versions, got %d", len(services))
+	}
+	if services[0].Version != "v1.0.0" || services[1].Version != "v2.0.0" {
+		t.Fatalf("Expected versions [v1.0.0 v2.0.0], got [%s %s]", services[0].Version, services[1].Version)
+	}
+}
+
+func TestRedisRegistryGetServiceNotFound(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(pkgRegistry.WithCache(cache.NewWithURL(ts.URL)))
+
+	// "user" 不应匹配 "user-service" 的节点
+	svc := newTestService("user-service", "user-1", "127.0.0.1:9301")
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Deregister(svc)
+
+	if _, err := reg.GetService("user"); err != registry.ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRedisRegistryWatchReplicas(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	reg := pkgRegistry.NewRedisRegistry(
+		pkgRegistry.WithCache(cache.NewWithURL(ts.URL)),
+		pkgRegistry.WithWatchInterval(20*time.Millisecond),
+	)
+
+	nodeA := newTestService("menu-service", "menu-a", "127.0.0.1:9401")
+	if err := reg.Register(nodeA); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := reg.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	nodeB := newTestService("menu-service", "menu-b", "127.0.0.1:9402")
+	if err := reg.Register(nodeB); err != nil {
+		t.Fatal(err)
+	}
+
+	res := nextResult(t, w)
+	if res.Action != "update" || len(res.Service.Nodes) != 2 {
+		t.Fatalf("Expected update with 2 nodes, got %s with %d nodes", res.Action, len(res.Service.Nodes))
+	}
+
+	// 移除一个副本仍然是 update，而不是 delete
+	if err := reg.Deregister(nodeA); err != nil {
+		t.Fatal(err)
+	}
+	res = nextResult(t, w)
+	if res.Action != "update" || len(res.Service.Nodes) != 1 {
+		t.Fatalf("Expected update with 1 node, got %s with %d nodes", res.Action, len(res.Service.Nodes))
+	}
+
+	if err := reg.Deregister(nodeB); err != nil {
+		t.Fatal(err)
+	}
+	res = nextResult(t, w)
+	if res.Action != "delete" {
+		t.Fatalf("Expected delete, got %s", res.Action)
+	}
+}
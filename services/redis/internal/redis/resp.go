@@ -0,0 +1,730 @@
+package redis
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goback/pkg/logger"
+	"github.com/goback/pkg/resp"
+	"go.uber.org/zap"
+)
+
+// respSubscriberBuffer 订阅连接待发送的消息数，写满时断开连接（避免慢客户端拖慢发布方）
+const respSubscriberBuffer = 1024
+
+// RESPServer RESP2 协议监听，与 HTTP 接口共用缓存数据与 PubSub
+// 支持 redis-cli 与常见客户端的最小命令集：
+// PING ECHO AUTH SELECT QUIT TYPE GET SET SETNX MGET MSET DEL EXISTS EXPIRE PEXPIRE TTL PTTL INCR INCRBY DECR DECRBY
+// KEYS SCAN DBSIZE INFO FLUSHDB FLUSHALL PUBLISH SUBSCRIBE UNSUBSCRIBE
+// HSET HGET HGETALL HDEL HINCRBY LPUSH RPUSH LPOP RPOP BLPOP BRPOP LRANGE LLEN
+// SADD SREM SISMEMBER SMEMBERS SCARD ZADD ZINCRBY ZSCORE ZREM ZRANK ZREVRANK ZRANGE ZREVRANGE ZRANGEBYSCORE ZCARD
+// 不支持 EVAL 等脚本命令
+type RESPServer struct {
+	svc      *Service
+	pubsub   *PubSubService
+	password string
+	ctx      context.Context // 关闭时取消，结束阻塞中的命令
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// RESPOption RESP 监听选项
+type RESPOption func(*RESPServer)
+
+// WithRESPPassword 要求客户端先执行 AUTH
+func WithRESPPassword(password string) RESPOption {
+	return func(s *RESPServer) {
+		s.password = password
+	}
+}
+
+// NewRESPServer 创建 RESP 监听，pubsub 为空时不支持 PUBLISH/SUBSCRIBE
+func NewRESPServer(svc *Service, pubsub *PubSubService, opts ...RESPOption) *RESPServer {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &RESPServer{
+		svc:    svc,
+		pubsub: pubsub,
+		ctx:    ctx,
+		cancel: cancel,
+		conns:  make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Listen 监听 addr 并在后台处理连接
+func (s *RESPServer) Listen(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.serve(l)
+	}()
+	return nil
+}
+
+// Addr 返回监听地址
+func (s *RESPServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close 停止监听并断开所有连接
+func (s *RESPServer) Close() error {
+	s.cancel()
+
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *RESPServer) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("RESP 监听失败", zap.Error(err))
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+			}()
+			newRESPConn(s, conn).serve()
+		}()
+	}
+}
+
+// respConn 单个客户端连接
+type respConn struct {
+	server *RESPServer
+	conn   net.Conn
+	r      *resp.Reader
+
+	wmu sync.Mutex // 命令回复与订阅消息共用写入器
+	w   *resp.Writer
+
+	authed        bool
+	subscriptions map[string]func() // channel -> 取消订阅
+	messages      chan *PubSubMessage
+	done          chan struct{}
+}
+
+func newRESPConn(server *RESPServer, conn net.Conn) *respConn {
+	c := &respConn{
+		server:        server,
+		conn:          conn,
+		r:             resp.NewReader(conn),
+		w:             resp.NewWriter(conn),
+		authed:        server.password == "",
+		subscriptions: make(map[string]func()),
+		done:          make(chan struct{}),
+	}
+	if !c.authed {
+		// 认证前只接受很小的请求，防止未认证的客户端声明超长请求占用内存
+		c.r.SetLimits(resp.UnauthArrayLength, resp.UnauthBulkLength)
+	}
+	return c
+}
+
+func (c *respConn) serve() {
+	defer func() {
+		close(c.done)
+		for _, unsubscribe := range c.subscriptions {
+			unsubscribe()
+		}
+		c.conn.Close()
+	}()
+
+	for {
+		args, err := c.r.ReadCommand()
+		if err != nil {
+			if errors.Is(err, resp.ErrProtocol) {
+				c.reply(func(w *resp.Writer) { w.WriteError("ERR Protocol error: " + err.Error()) })
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				logger.Debug("RESP 连接读取失败", zap.Error(err))
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(string(args[0]))
+		if name == "QUIT" {
+			c.reply(func(w *resp.Writer) { w.WriteSimple("OK") })
+			return
+		}
+		if !c.reply(func(w *resp.Writer) { c.dispatch(w, name, args[1:]) }) {
+			return
+		}
+	}
+}
+
+// reply 在写锁内写入回复并刷新，写入失败时返回 false
+func (c *respConn) reply(fn func(w *resp.Writer)) bool {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	fn(c.w)
+	return c.w.Flush() == nil
+}
+
+// dispatch 执行命令并写入回复
+func (c *respConn) dispatch(w *resp.Writer, name string, args [][]byte) {
+	if name == "AUTH" {
+		c.auth(w, args)
+		return
+	}
+	if !c.authed {
+		w.WriteError("NOAUTH Authentication required.")
+		return
+	}
+
+	// 订阅状态下只允许订阅相关命令
+	if len(c.subscriptions) > 0 {
+		switch name {
+		case "SUBSCRIBE", "UNSUBSCRIBE", "PING":
+		default:
+			w.WriteError("ERR Can't execute '" + strings.ToLower(name) + "': only (UN)SUBSCRIBE / PING / QUIT are allowed in this context")
+			return
+		}
+	}
+
+	cmd, ok := respCommands[name]
+	if !ok {
+		w.WriteError("ERR unknown command '" + strings.ToLower(name) + "'")
+		return
+	}
+	if len(args) < cmd.minArgs || (cmd.maxArgs >= 0 && len(args) > cmd.maxArgs) {
+		w.WriteError("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+		return
+	}
+	cmd.fn(c, w, args)
+}
+
+func (c *respConn) auth(w *resp.Writer, args [][]byte) {
+	if len(args) < 1 || len(args) > 2 {
+		w.WriteError("ERR wrong number of arguments for 'auth' command")
+		return
+	}
+	if c.server.password == "" {
+		w.WriteError("ERR AUTH <password> called without any password configured for the default user.")
+		return
+	}
+	// AUTH [username] password，仅支持默认用户
+	password := args[len(args)-1]
+	if subtle.ConstantTimeCompare(password, []byte(c.server.password)) != 1 {
+		w.WriteError("WRONGPASS invalid username-password pair or user is disabled.")
+		return
+	}
+	c.authed = true
+	c.r.SetLimits(resp.MaxArrayLength, resp.MaxBulkLength)
+	w.WriteSimple("OK")
+}
+
+// respCommand 命令定义，maxArgs 为 -1 表示不限
+type respCommand struct {
+	minArgs int
+	maxArgs int
+	fn      func(c *respConn, w *resp.Writer, args [][]byte)
+}
+
+var respCommands = map[string]respCommand{
+	"PING":        {0, 1, cmdPing},
+	"ECHO":        {1, 1, func(c *respConn, w *resp.Writer, args [][]byte) { w.WriteBulk(args[0]) }},
+	"SELECT":      {1, 1, cmdSelect},
+	"TYPE":        {1, 1, cmdType},
+	"GET":         {1, 1, cmdGet},
+	"SET":         {2, -1, cmdSet},
+	"SETNX":       {2, 2, cmdSetNX},
+	"MGET":        {1, -1, cmdMGet},
+	"MSET":        {2, -1, cmdMSet},
+	"DEL":         {1, -1, cmdDel},
+	"EXISTS":      {1, -1, cmdExists},
+	"EXPIRE":      {2, 2, cmdExpire(time.Second)},
+	"PEXPIRE":     {2, 2, cmdExpire(time.Millisecond)},
+	"TTL":         {1, 1, cmdTTL(time.Second)},
+	"PTTL":        {1, 1, cmdTTL(time.Millisecond)},
+	"INCR":        {1, 1, cmdIncr(1, false)},
+	"DECR":        {1, 1, cmdIncr(-1, false)},
+	"INCRBY":      {2, 2, cmdIncr(1, true)},
+	"DECRBY":      {2, 2, cmdIncr(-1, true)},
+	"KEYS":        {1, 1, cmdKeys},
+	"SCAN":        {1, -1, cmdScan},
+	"DBSIZE":      {0, 0, func(c *respConn, w *resp.Writer, args [][]byte) { w.WriteInt(int64(c.server.svc.Len())) }},
+	"INFO":        {0, 1, cmdInfo},
+	"FLUSHDB":     {0, 1, cmdFlush},
+	"FLUSHALL":    {0, 1, cmdFlush},
+	"PUBLISH":     {2, 2, cmdPublish},
+	"SUBSCRIBE":   {1, -1, cmdSubscribe},
+	"UNSUBSCRIBE": {0, -1, cmdUnsubscribe},
+
+	"HSET":    {3, -1, cmdHSet},
+	"HGET":    {2, 2, cmdHGet},
+	"HGETALL": {1, 1, cmdHGetAll},
+	"HDEL":    {2, -1, cmdHDel},
+	"HINCRBY": {3, 3, cmdHIncrBy},
+
+	"LPUSH":  {2, -1, cmdPush(true)},
+	"RPUSH":  {2, -1, cmdPush(false)},
+	"LPOP":   {1, 1, cmdPop(true)},
+	"RPOP":   {1, 1, cmdPop(false)},
+	"BLPOP":  {2, -1, cmdBlockingPop(true)},
+	"BRPOP":  {2, -1, cmdBlockingPop(false)},
+	"LRANGE": {3, 3, cmdLRange},
+	"LLEN":   {1, 1, cmdLLen},
+
+	"SADD":      {2, -1, cmdSAdd},
+	"SREM":      {2, -1, cmdSRem},
+	"SISMEMBER": {2, 2, cmdSIsMember},
+	"SMEMBERS":  {1, 1, cmdSMembers},
+	"SCARD":     {1, 1, cmdSCard},
+
+	"ZADD":          {3, -1, cmdZAdd},
+	"ZINCRBY":       {3, 3, cmdZIncrBy},
+	"ZSCORE":        {2, 2, cmdZScore},
+	"ZREM":          {2, -1, cmdZRem},
+	"ZRANK":         {2, 2, cmdZRank(false)},
+	"ZREVRANK":      {2, 2, cmdZRank(true)},
+	"ZRANGE":        {3, 4, cmdZRange(false)},
+	"ZREVRANGE":     {3, 4, cmdZRange(true)},
+	"ZRANGEBYSCORE": {3, -1, cmdZRangeByScore},
+	"ZCARD":         {1, 1, cmdZCard},
+}
+
+// writeErr 写入错误回复，WRONGTYPE、OOM 等自带错误类型的错误不加 ERR 前缀
+func writeErr(w *resp.Writer, err error) {
+	if errors.Is(err, ErrWrongType) || errors.Is(err, ErrOOM) {
+		w.WriteError(err.Error())
+		return
+	}
+	w.WriteError("ERR " + err.Error())
+}
+
+// cmdInfo 回复 Memory、Stats 与 Keyspace 段（忽略段名参数）
+func cmdInfo(c *respConn, w *resp.Writer, args [][]byte) {
+	st := c.server.svc.Stats()
+	policy := st.Policy
+	if policy == "" {
+		policy = PolicyNoEviction
+	}
+	w.WriteBulkString("# Memory\r\n" +
+		"used_memory:" + strconv.FormatInt(st.UsedMemory, 10) + "\r\n" +
+		"maxmemory:" + strconv.FormatInt(st.MaxMemory, 10) + "\r\n" +
+		"maxmemory_policy:" + policy + "\r\n" +
+		"\r\n# Stats\r\n" +
+		"keyspace_hits:" + strconv.FormatUint(st.Hits, 10) + "\r\n" +
+		"keyspace_misses:" + strconv.FormatUint(st.Misses, 10) + "\r\n" +
+		"evicted_keys:" + strconv.FormatUint(st.Evictions, 10) + "\r\n" +
+		"expired_keys:" + strconv.FormatUint(st.Expired, 10) + "\r\n" +
+		"\r\n# Keyspace\r\n" +
+		"db0:keys=" + strconv.Itoa(st.Keys) + "\r\n")
+}
+
+func cmdPing(c *respConn, w *resp.Writer, args [][]byte) {
+	// 订阅状态下以数组回复
+	if len(c.subscriptions) > 0 {
+		w.WriteArray(2)
+		w.WriteBulkString("pong")
+		if len(args) > 0 {
+			w.WriteBulk(args[0])
+		} else {
+			w.WriteBulkString("")
+		}
+		return
+	}
+	if len(args) > 0 {
+		w.WriteBulk(args[0])
+		return
+	}
+	w.WriteSimple("PONG")
+}
+
+func cmdSelect(c *respConn, w *resp.Writer, args [][]byte) {
+	if string(args[0]) != "0" {
+		w.WriteError("ERR DB index is out of range")
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+func cmdGet(c *respConn, w *resp.Writer, args [][]byte) {
+	value, ok, err := c.server.svc.getString(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if !ok {
+		w.WriteNull()
+		return
+	}
+	w.WriteBulk(value)
+}
+
+func cmdMGet(c *respConn, w *resp.Writer, args [][]byte) {
+	values := c.server.svc.MGet(stringArgs(args)...)
+	w.WriteArray(len(values))
+	for _, v := range values {
+		if v == nil {
+			w.WriteNull()
+		} else {
+			w.WriteBulk(v)
+		}
+	}
+}
+
+// cmdMSet MSET key value [key value ...]
+func cmdMSet(c *respConn, w *resp.Writer, args [][]byte) {
+	if len(args)%2 != 0 {
+		w.WriteError("ERR wrong number of arguments for 'mset' command")
+		return
+	}
+	values := make(map[string][]byte, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		values[string(args[i])] = args[i+1]
+	}
+	if err := c.server.svc.MSet(values, 0); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+// cmdSet SET key value [EX seconds | PX milliseconds] [NX | XX]
+func cmdSet(c *respConn, w *resp.Writer, args [][]byte) {
+	var opts SetOptions
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "NX":
+			opts.OnlyMissing = true
+		case "XX":
+			opts.OnlyExists = true
+		case "EX", "PX":
+			if i+1 >= len(args) || opts.TTL != 0 {
+				w.WriteError("ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				w.WriteError("ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if strings.EqualFold(string(args[i]), "PX") {
+				unit = time.Millisecond
+			}
+			opts.TTL = time.Duration(n) * unit
+			i++
+		default:
+			w.WriteError("ERR syntax error")
+			return
+		}
+	}
+	if opts.OnlyMissing && opts.OnlyExists {
+		w.WriteError("ERR syntax error")
+		return
+	}
+
+	ok, err := c.server.svc.SetWithOptions(string(args[0]), args[1], opts)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if !ok {
+		w.WriteNull()
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+func cmdDel(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.DeleteKeys(stringArgs(args)...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdExists(c *respConn, w *resp.Writer, args [][]byte) {
+	var n int64
+	for _, key := range args {
+		if c.server.svc.Exists(string(key)) {
+			n++
+		}
+	}
+	w.WriteInt(n)
+}
+
+func cmdExpire(unit time.Duration) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		n, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			w.WriteError("ERR value is not an integer or out of range")
+			return
+		}
+		ok, err := c.server.svc.Expire(string(args[0]), time.Duration(n)*unit)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		if ok {
+			w.WriteInt(1)
+		} else {
+			w.WriteInt(0)
+		}
+	}
+}
+
+// cmdTTL 键不存在返回 -2，永不过期返回 -1
+func cmdTTL(unit time.Duration) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		ttl, ok := c.server.svc.TTL(string(args[0]))
+		switch {
+		case !ok:
+			w.WriteInt(-2)
+		case ttl == 0:
+			w.WriteInt(-1)
+		default:
+			// 向上取整，避免还未过期的键返回 0
+			w.WriteInt(int64((ttl + unit - 1) / unit))
+		}
+	}
+}
+
+// cmdIncr INCR/DECR/INCRBY/DECRBY，sign 为 -1 时取反
+func cmdIncr(sign int64, withDelta bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		delta := int64(1)
+		if withDelta {
+			n, err := strconv.ParseInt(string(args[1]), 10, 64)
+			if err != nil || (sign < 0 && n == math.MinInt64) {
+				w.WriteError("ERR value is not an integer or out of range")
+				return
+			}
+			delta = n
+		}
+		value, _, err := c.server.svc.IncrBy(string(args[0]), sign*delta, 0)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		w.WriteInt(value)
+	}
+}
+
+func cmdKeys(c *respConn, w *resp.Writer, args [][]byte) {
+	keys := c.server.svc.KeysMatching(string(args[0]))
+	w.WriteArray(len(keys))
+	for _, k := range keys {
+		w.WriteBulkString(k)
+	}
+}
+
+// cmdScan SCAN cursor [MATCH pattern] [COUNT count]
+func cmdScan(c *respConn, w *resp.Writer, args [][]byte) {
+	cursor, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		w.WriteError("ERR invalid cursor")
+		return
+	}
+
+	var pattern string
+	count := 10
+	for i := 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			w.WriteError("ERR syntax error")
+			return
+		}
+		switch strings.ToUpper(string(args[i])) {
+		case "MATCH":
+			pattern = string(args[i+1])
+		case "COUNT":
+			n, err := strconv.Atoi(string(args[i+1]))
+			if err != nil || n <= 0 {
+				w.WriteError("ERR value is not an integer or out of range")
+				return
+			}
+			count = n
+		default:
+			w.WriteError("ERR syntax error")
+			return
+		}
+	}
+
+	keys, next := c.server.svc.Scan(cursor, pattern, count)
+	w.WriteArray(2)
+	w.WriteBulkString(strconv.FormatUint(next, 10))
+	w.WriteArray(len(keys))
+	for _, k := range keys {
+		w.WriteBulkString(k)
+	}
+}
+
+func cmdFlush(c *respConn, w *resp.Writer, args [][]byte) {
+	if err := c.server.svc.flush(); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+func cmdPublish(c *respConn, w *resp.Writer, args [][]byte) {
+	if c.server.pubsub == nil {
+		w.WriteError("ERR pubsub is not enabled")
+		return
+	}
+	n, err := c.server.pubsub.Publish(&PubSubMessage{
+		Topic:     string(args[0]),
+		Payload:   args[1],
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdSubscribe(c *respConn, w *resp.Writer, args [][]byte) {
+	if c.server.pubsub == nil {
+		w.WriteError("ERR pubsub is not enabled")
+		return
+	}
+	if c.messages == nil {
+		c.messages = make(chan *PubSubMessage, respSubscriberBuffer)
+		go c.deliver()
+	}
+
+	for _, arg := range args {
+		channel := string(arg)
+		if _, ok := c.subscriptions[channel]; !ok {
+			c.subscriptions[channel] = c.server.pubsub.SubscribeLocal(channel, c.enqueue)
+		}
+		w.WriteArray(3)
+		w.WriteBulkString("subscribe")
+		w.WriteBulkString(channel)
+		w.WriteInt(int64(len(c.subscriptions)))
+	}
+}
+
+func cmdUnsubscribe(c *respConn, w *resp.Writer, args [][]byte) {
+	channels := stringArgs(args)
+	if len(channels) == 0 {
+		for channel := range c.subscriptions {
+			channels = append(channels, channel)
+		}
+	}
+	if len(channels) == 0 {
+		w.WriteArray(3)
+		w.WriteBulkString("unsubscribe")
+		w.WriteNull()
+		w.WriteInt(0)
+		return
+	}
+
+	for _, channel := range channels {
+		if unsubscribe, ok := c.subscriptions[channel]; ok {
+			unsubscribe()
+			delete(c.subscriptions, channel)
+		}
+		w.WriteArray(3)
+		w.WriteBulkString("unsubscribe")
+		w.WriteBulkString(channel)
+		w.WriteInt(int64(len(c.subscriptions)))
+	}
+}
+
+// enqueue 由发布方调用，不阻塞；缓冲写满说明客户端消费过慢，断开连接
+func (c *respConn) enqueue(msg *PubSubMessage) {
+	select {
+	case c.messages <- msg:
+	case <-c.done:
+	default:
+		logger.Warn("RESP 订阅客户端消费过慢，断开连接", zap.String("remote", c.conn.RemoteAddr().String()))
+		c.conn.Close()
+	}
+}
+
+// deliver 将订阅消息写给客户端
+func (c *respConn) deliver() {
+	for {
+		select {
+		case msg := <-c.messages:
+			ok := c.reply(func(w *resp.Writer) {
+				w.WriteArray(3)
+				w.WriteBulkString("message")
+				w.WriteBulkString(msg.Topic)
+				w.WriteBulk(msg.Payload)
+			})
+			if !ok {
+				c.conn.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func stringArgs(args [][]byte) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = string(arg)
+	}
+	return out
+}
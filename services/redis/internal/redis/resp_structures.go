@@ -0,0 +1,449 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goback/pkg/resp"
+)
+
+// RESP 集合类型命令
+
+func cmdType(c *respConn, w *resp.Writer, args [][]byte) {
+	kind, ok := c.server.svc.Type(string(args[0]))
+	if !ok {
+		kind = "none"
+	}
+	w.WriteSimple(kind)
+}
+
+func cmdSetNX(c *respConn, w *resp.Writer, args [][]byte) {
+	ok, err := c.server.svc.SetNX(string(args[0]), args[1], 0)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeBool(w, ok)
+}
+
+// cmdHSet HSET key field value [field value ...]
+func cmdHSet(c *respConn, w *resp.Writer, args [][]byte) {
+	if len(args)%2 != 1 {
+		w.WriteError("ERR wrong number of arguments for 'hset' command")
+		return
+	}
+	fields := make(map[string][]byte, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		fields[string(args[i])] = args[i+1]
+	}
+	n, err := c.server.svc.HSet(string(args[0]), fields)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdHGet(c *respConn, w *resp.Writer, args [][]byte) {
+	value, ok, err := c.server.svc.HGet(string(args[0]), string(args[1]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if !ok {
+		w.WriteNull()
+		return
+	}
+	w.WriteBulk(value)
+}
+
+// cmdHGetAll 以 field value 交替的数组回复
+func cmdHGetAll(c *respConn, w *resp.Writer, args [][]byte) {
+	fields, err := c.server.svc.HGetAll(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteArray(len(fields) * 2)
+	for f, v := range fields {
+		w.WriteBulkString(f)
+		w.WriteBulk(v)
+	}
+}
+
+func cmdHDel(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.HDel(string(args[0]), stringArgs(args[1:])...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdHIncrBy(c *respConn, w *resp.Writer, args [][]byte) {
+	delta, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		w.WriteError("ERR value is not an integer or out of range")
+		return
+	}
+	value, err := c.server.svc.HIncrBy(string(args[0]), string(args[1]), delta)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(value)
+}
+
+func cmdPush(left bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		n, err := c.server.svc.Push(string(args[0]), left, args[1:]...)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		w.WriteInt(int64(n))
+	}
+}
+
+func cmdPop(left bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		value, ok, err := c.server.svc.Pop(string(args[0]), left)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		if !ok {
+			w.WriteNull()
+			return
+		}
+		w.WriteBulk(value)
+	}
+}
+
+// cmdBlockingPop BLPOP/BRPOP key [key ...] timeout（秒，可为小数，0 表示一直等待）
+// 回复 [key, value]，超时回复空数组
+func cmdBlockingPop(left bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		seconds, err := strconv.ParseFloat(string(args[len(args)-1]), 64)
+		if err != nil || seconds < 0 || math.IsInf(seconds, 0) {
+			w.WriteError("ERR timeout is not a float or out of range")
+			return
+		}
+
+		ctx, stop := c.blockingContext()
+		key, value, ok, err := c.server.svc.BlockingPop(ctx, stringArgs(args[:len(args)-1]), left, time.Duration(seconds*float64(time.Second)))
+		stop()
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		if !ok {
+			w.WriteNullArray()
+			return
+		}
+		w.WriteArray(2)
+		w.WriteBulkString(key)
+		w.WriteBulk(value)
+	}
+}
+
+// blockingContext 阻塞命令期间在后台等待连接可读：客户端断开时取消，避免弹出的值写给已断开的连接
+// 返回的 stop 结束等待并恢复连接供后续命令读取
+func (c *respConn) blockingContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(c.server.ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// 读到数据说明客户端发送了下一条命令（仍在线），只有读取出错才取消
+		if err := c.r.Peek(); err != nil {
+			cancel()
+		}
+	}()
+
+	return ctx, func() {
+		c.conn.SetReadDeadline(time.Now())
+		<-done
+		c.conn.SetReadDeadline(time.Time{})
+		cancel()
+	}
+}
+
+// cmdLRange LRANGE key start stop
+func cmdLRange(c *respConn, w *resp.Writer, args [][]byte) {
+	start, err1 := strconv.ParseInt(string(args[1]), 10, 64)
+	stop, err2 := strconv.ParseInt(string(args[2]), 10, 64)
+	if err1 != nil || err2 != nil {
+		w.WriteError("ERR value is not an integer or out of range")
+		return
+	}
+	values, err := c.server.svc.LRange(string(args[0]), start, stop)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteArray(len(values))
+	for _, v := range values {
+		w.WriteBulk(v)
+	}
+}
+
+func cmdLLen(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.LLen(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdSAdd(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.SAdd(string(args[0]), stringArgs(args[1:])...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdSRem(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.SRem(string(args[0]), stringArgs(args[1:])...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdSIsMember(c *respConn, w *resp.Writer, args [][]byte) {
+	ok, err := c.server.svc.SIsMember(string(args[0]), string(args[1]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeBool(w, ok)
+}
+
+func cmdSMembers(c *respConn, w *resp.Writer, args [][]byte) {
+	members, err := c.server.svc.SMembers(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteArray(len(members))
+	for _, m := range members {
+		w.WriteBulkString(m)
+	}
+}
+
+func cmdSCard(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.SCard(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+// cmdZAdd ZADD key score member [score member ...]
+func cmdZAdd(c *respConn, w *resp.Writer, args [][]byte) {
+	if len(args)%2 != 1 {
+		w.WriteError("ERR syntax error")
+		return
+	}
+	members := make([]ZMember, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(string(args[i]), 64)
+		if err != nil {
+			w.WriteError("ERR value is not a valid float")
+			return
+		}
+		members = append(members, ZMember{Member: string(args[i+1]), Score: score})
+	}
+	n, err := c.server.svc.ZAdd(string(args[0]), members...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+// cmdZIncrBy ZINCRBY key increment member
+func cmdZIncrBy(c *respConn, w *resp.Writer, args [][]byte) {
+	delta, err := strconv.ParseFloat(string(args[1]), 64)
+	if err != nil {
+		w.WriteError("ERR value is not a valid float")
+		return
+	}
+	score, err := c.server.svc.ZIncrBy(string(args[0]), string(args[2]), delta)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteBulkString(formatScore(score))
+}
+
+func cmdZScore(c *respConn, w *resp.Writer, args [][]byte) {
+	score, ok, err := c.server.svc.ZScore(string(args[0]), string(args[1]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if !ok {
+		w.WriteNull()
+		return
+	}
+	w.WriteBulkString(formatScore(score))
+}
+
+func cmdZRem(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.ZRem(string(args[0]), stringArgs(args[1:])...)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+func cmdZRank(reverse bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		rank, ok, err := c.server.svc.ZRank(string(args[0]), string(args[1]), reverse)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		if !ok {
+			w.WriteNull()
+			return
+		}
+		w.WriteInt(int64(rank))
+	}
+}
+
+// cmdZRange ZRANGE/ZREVRANGE key start stop [WITHSCORES]
+func cmdZRange(reverse bool) func(c *respConn, w *resp.Writer, args [][]byte) {
+	return func(c *respConn, w *resp.Writer, args [][]byte) {
+		start, err1 := strconv.ParseInt(string(args[1]), 10, 64)
+		stop, err2 := strconv.ParseInt(string(args[2]), 10, 64)
+		if err1 != nil || err2 != nil {
+			w.WriteError("ERR value is not an integer or out of range")
+			return
+		}
+		withScores := len(args) == 4
+		if withScores && !strings.EqualFold(string(args[3]), "WITHSCORES") {
+			w.WriteError("ERR syntax error")
+			return
+		}
+		members, err := c.server.svc.ZRange(string(args[0]), start, stop, reverse)
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		writeZMembers(w, members, withScores)
+	}
+}
+
+// cmdZRangeByScore ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]
+// min/max 支持 -inf、+inf 与表示开区间的 ( 前缀
+func cmdZRangeByScore(c *respConn, w *resp.Writer, args [][]byte) {
+	lo, err1 := parseScoreBound(string(args[1]), math.Inf(1))
+	hi, err2 := parseScoreBound(string(args[2]), math.Inf(-1))
+	if err1 != nil || err2 != nil {
+		w.WriteError("ERR min or max is not a float")
+		return
+	}
+
+	withScores := false
+	offset, count := 0, -1
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "WITHSCORES":
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(args) {
+				w.WriteError("ERR syntax error")
+				return
+			}
+			var err error
+			if offset, err = strconv.Atoi(string(args[i+1])); err != nil {
+				w.WriteError("ERR value is not an integer or out of range")
+				return
+			}
+			if count, err = strconv.Atoi(string(args[i+2])); err != nil {
+				w.WriteError("ERR value is not an integer or out of range")
+				return
+			}
+			i += 2
+		default:
+			w.WriteError("ERR syntax error")
+			return
+		}
+	}
+
+	// 负的 offset 返回空结果，负的 count 表示不限（与 Redis 一致）
+	var members []ZMember
+	if offset >= 0 && count != 0 {
+		limit := 0
+		if count > 0 {
+			limit = offset + count
+		}
+		var err error
+		if members, err = c.server.svc.ZRangeByScore(string(args[0]), lo, hi, limit); err != nil {
+			writeErr(w, err)
+			return
+		}
+		members = members[min(offset, len(members)):]
+	}
+	writeZMembers(w, members, withScores)
+}
+
+// parseScoreBound 解析分数边界，( 前缀表示开区间，转换为朝 toward 方向相邻的浮点数
+func parseScoreBound(s string, toward float64) (float64, error) {
+	exclusive := strings.HasPrefix(s, "(")
+	score, err := strconv.ParseFloat(strings.TrimPrefix(s, "("), 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, ErrNotFloat
+	}
+	if exclusive {
+		score = math.Nextafter(score, toward)
+	}
+	return score, nil
+}
+
+func cmdZCard(c *respConn, w *resp.Writer, args [][]byte) {
+	n, err := c.server.svc.ZCard(string(args[0]))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteInt(int64(n))
+}
+
+// writeZMembers 写入成员列表，withScores 时成员与分数交替
+func writeZMembers(w *resp.Writer, members []ZMember, withScores bool) {
+	if withScores {
+		w.WriteArray(len(members) * 2)
+	} else {
+		w.WriteArray(len(members))
+	}
+	for _, m := range members {
+		w.WriteBulkString(m.Member)
+		if withScores {
+			w.WriteBulkString(formatScore(m.Score))
+		}
+	}
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+func writeBool(w *resp.Writer, ok bool) {
+	if ok {
+		w.WriteInt(1)
+	} else {
+		w.WriteInt(0)
+	}
+}
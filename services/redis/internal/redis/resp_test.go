@@ -0,0 +1,333 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/resp"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// newRESPServer 启动监听随机端口的 RESP 服务
+func newRESPServer(t *testing.T, opts ...redis.RESPOption) (*redis.Service, *redis.PubSubService, string) {
+	t.Helper()
+
+	svc := redis.NewService("redis-test")
+	pubsub := redis.NewPubSubService()
+	server := redis.NewRESPServer(svc, pubsub, opts...)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		pubsub.Stop()
+		svc.Stop()
+	})
+	return svc, pubsub, server.Addr().String()
+}
+
+// respClient 直接收发 RESP 的测试连接
+type respClient struct {
+	conn net.Conn
+	r    *resp.Reader
+	w    *resp.Writer
+}
+
+func dialRESP(t *testing.T, addr string) *respClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	t.Cleanup(func() { conn.Close() })
+	return &respClient{conn: conn, r: resp.NewReader(conn), w: resp.NewWriter(conn)}
+}
+
+func (c *respClient) send(t *testing.T, args ...any) {
+	t.Helper()
+	if err := c.w.WriteCommand(args...); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *respClient) read(t *testing.T) resp.Value {
+	t.Helper()
+	v, err := c.r.ReadValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func (c *respClient) do(t *testing.T, args ...any) resp.Value {
+	t.Helper()
+	c.send(t, args...)
+	return c.read(t)
+}
+
+func TestRESPCacheBackend(t *testing.T) {
+	svc, _, addr := newRESPServer(t)
+	backend := cache.NewRedisBackend(cache.RedisOptions{Addr: addr})
+	defer backend.Close()
+	c := cache.NewWithBackend(backend)
+
+	if err := c.SetWithExpiration("user:1", map[string]string{"name": "alice"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var user map[string]string
+	if err := c.Get("user:1", &user); err != nil || user["name"] != "alice" {
+		t.Fatalf("Expected alice, got %v (%v)", user, err)
+	}
+
+	// 与 HTTP 接口共用同一份数据
+	if value, ok := svc.GetRaw("user:1"); !ok || string(value) != `{"name":"alice"}` {
+		t.Fatalf("Expected the value to be visible to the service, got %q (%v)", value, ok)
+	}
+	if ttl, ok := svc.TTL("user:1"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("Expected TTL within one minute, got %s (%v)", ttl, ok)
+	}
+
+	if !c.Exists("user:1") {
+		t.Fatal("Expected user:1 to exist")
+	}
+	c.Delete("user:1")
+	if c.Exists("user:1") {
+		t.Fatal("Expected user:1 to be deleted")
+	}
+	if _, ok := c.GetRaw("user:1"); ok {
+		t.Fatal("Expected user:1 to be missing")
+	}
+
+	// 自增：首次建立过期时间，之后保留
+	value, ttl, err := c.IncrBy("counter", 5, time.Minute)
+	if err != nil || value != 5 || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("Expected 5 with a TTL, got %d (%s, %v)", value, ttl, err)
+	}
+	value, ttl, err = c.IncrBy("counter", -2, time.Hour)
+	if err != nil || value != 3 || ttl > time.Minute {
+		t.Fatalf("Expected 3 with the original TTL, got %d (%s, %v)", value, ttl, err)
+	}
+	if _, ttl, err = c.IncrBy("persistent", 1, 0); err != nil || ttl != 0 {
+		t.Fatalf("Expected no TTL, got %s (%v)", ttl, err)
+	}
+	c.Set("text", "abc")
+	var respErr resp.Error
+	if _, _, err := c.IncrBy("text", 1, 0); !errors.As(err, &respErr) {
+		t.Fatalf("Expected a server error for a non-integer value, got %v", err)
+	}
+
+	// 键列表通过 SCAN 分批获取
+	for i := range 2500 {
+		svc.SetRaw("bulk:"+strconv.Itoa(i), []byte("x"), 0)
+	}
+	keys, err := c.ListKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2503 {
+		t.Fatalf("Expected 2503 keys, got %d", len(keys))
+	}
+
+	c.Clear()
+	if svc.Len() != 0 {
+		t.Fatalf("Expected empty cache after Clear, got %d keys", svc.Len())
+	}
+}
+
+func TestRESPCommands(t *testing.T) {
+	_, _, addr := newRESPServer(t)
+	c := dialRESP(t, addr)
+
+	if v := c.do(t, "PING"); v.Text() != "PONG" {
+		t.Fatalf("Expected PONG, got %+v", v)
+	}
+	if v := c.do(t, "SET", "a", "1", "NX"); v.Text() != "OK" {
+		t.Fatalf("Expected OK, got %+v", v)
+	}
+	if v := c.do(t, "SET", "a", "2", "NX"); !v.Null {
+		t.Fatalf("Expected null reply for SET NX on an existing key, got %+v", v)
+	}
+	if v := c.do(t, "SET", "missing", "2", "XX"); !v.Null {
+		t.Fatalf("Expected null reply for SET XX on a missing key, got %+v", v)
+	}
+	if v := c.do(t, "TTL", "a"); v.Int != -1 {
+		t.Fatalf("Expected TTL -1 for a key without expiration, got %+v", v)
+	}
+	if v := c.do(t, "EXPIRE", "a", 100); v.Int != 1 {
+		t.Fatalf("Expected EXPIRE to return 1, got %+v", v)
+	}
+	if v := c.do(t, "TTL", "a"); v.Int <= 0 || v.Int > 100 {
+		t.Fatalf("Expected TTL within 100 seconds, got %+v", v)
+	}
+	if v := c.do(t, "TTL", "missing"); v.Int != -2 {
+		t.Fatalf("Expected TTL -2 for a missing key, got %+v", v)
+	}
+	if v := c.do(t, "GET", "a"); v.Text() != "1" {
+		t.Fatalf("Expected 1, got %+v", v)
+	}
+	if v := c.do(t, "SET", "a", "1", "EX", "abc"); v.Err() == nil {
+		t.Fatalf("Expected an error for an invalid expiration, got %+v", v)
+	}
+	if v := c.do(t, "NOSUCH"); v.Err() == nil {
+		t.Fatalf("Expected an error for an unknown command, got %+v", v)
+	}
+
+	// SCAN 按 MATCH 过滤并以游标分批返回
+	for _, key := range []string{"user:1", "user:2", "user:10", "role:1"} {
+		c.do(t, "SET", key, "x")
+	}
+	var matched []string
+	cursor := "0"
+	for {
+		v := c.do(t, "SCAN", cursor, "MATCH", "user:?", "COUNT", 1)
+		for _, k := range v.Array[1].Array {
+			matched = append(matched, k.Text())
+		}
+		if cursor = v.Array[0].Text(); cursor == "0" {
+			break
+		}
+	}
+	slices.Sort(matched)
+	if !slices.Equal(matched, []string{"user:1", "user:2"}) {
+		t.Fatalf("Expected [user:1 user:2], got %v", matched)
+	}
+
+	if v := c.do(t, "KEYS", "[ur]*:1"); len(v.Array) != 2 {
+		t.Fatalf("Expected 2 keys, got %+v", v)
+	}
+	if v := c.do(t, "DEL", "user:1", "user:2", "missing"); v.Int != 2 {
+		t.Fatalf("Expected 2 deleted keys, got %+v", v)
+	}
+	if v := c.do(t, "DBSIZE"); v.Int != 3 {
+		t.Fatalf("Expected 3 keys, got %+v", v)
+	}
+}
+
+func TestRESPAuth(t *testing.T) {
+	_, _, addr := newRESPServer(t, redis.WithRESPPassword("secret"))
+
+	c := dialRESP(t, addr)
+	if v := c.do(t, "GET", "a"); v.Err() == nil {
+		t.Fatalf("Expected NOAUTH error, got %+v", v)
+	}
+	if v := c.do(t, "AUTH", "wrong"); v.Err() == nil {
+		t.Fatalf("Expected an error for a wrong password, got %+v", v)
+	}
+	if v := c.do(t, "AUTH", "secret"); v.Text() != "OK" {
+		t.Fatalf("Expected OK, got %+v", v)
+	}
+	if v := c.do(t, "GET", "a"); !v.Null {
+		t.Fatalf("Expected null reply, got %+v", v)
+	}
+
+	// 客户端在建立连接时认证
+	backend := cache.NewRedisBackend(cache.RedisOptions{Addr: addr, Password: "secret"})
+	defer backend.Close()
+	if err := backend.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wrong := cache.NewRedisBackend(cache.RedisOptions{Addr: addr, Password: "wrong"})
+	defer wrong.Close()
+	if err := wrong.Ping(context.Background()); err == nil {
+		t.Fatal("Expected an error for a wrong password")
+	}
+}
+
+func TestRESPUnauthenticatedLimits(t *testing.T) {
+	_, _, addr := newRESPServer(t, redis.WithRESPPassword("secret"))
+
+	// 认证前超长的请求直接断开连接
+	c := dialRESP(t, addr)
+	if _, err := c.conn.Write([]byte("*1048576\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if v := c.read(t); v.Err() == nil {
+		t.Fatalf("Expected protocol error, got %+v", v)
+	}
+	if _, err := c.r.ReadValue(); err == nil {
+		t.Fatal("Expected the connection to be closed")
+	}
+
+	c = dialRESP(t, addr)
+	c.send(t, "AUTH", strings.Repeat("x", resp.UnauthBulkLength+1))
+	if v := c.read(t); v.Err() == nil {
+		t.Fatalf("Expected protocol error, got %+v", v)
+	}
+
+	// 认证后恢复正常限制
+	c = dialRESP(t, addr)
+	if v := c.do(t, "AUTH", "secret"); v.Text() != "OK" {
+		t.Fatalf("Expected OK, got %+v", v)
+	}
+	value := strings.Repeat("v", resp.UnauthBulkLength+1)
+	if v := c.do(t, "SET", "large", value); v.Text() != "OK" {
+		t.Fatalf("Expected OK, got %+v", v)
+	}
+	if v := c.do(t, "GET", "large"); string(v.Bulk) != value {
+		t.Fatalf("Expected %d bytes, got %d", len(value), len(v.Bulk))
+	}
+}
+
+func TestRESPPubSub(t *testing.T) {
+	_, pubsub, addr := newRESPServer(t)
+
+	sub := dialRESP(t, addr)
+	sub.send(t, "SUBSCRIBE", "orders", "users")
+	for _, topic := range []string{"orders", "users"} {
+		v := sub.read(t)
+		if len(v.Array) != 3 || v.Array[0].Text() != "subscribe" || v.Array[1].Text() != topic {
+			t.Fatalf("Expected subscribe confirmation for %s, got %+v", topic, v)
+		}
+	}
+
+	// 订阅状态下只允许订阅相关命令
+	if v := sub.do(t, "GET", "a"); v.Err() == nil {
+		t.Fatalf("Expected an error in subscribe mode, got %+v", v)
+	}
+
+	// RESP 发布
+	pub := dialRESP(t, addr)
+	if v := pub.do(t, "PUBLISH", "orders", "created"); v.Int != 1 {
+		t.Fatalf("Expected 1 receiver, got %+v", v)
+	}
+	v := sub.read(t)
+	if len(v.Array) != 3 || v.Array[0].Text() != "message" || v.Array[1].Text() != "orders" || v.Array[2].Text() != "created" {
+		t.Fatalf("Expected message on orders, got %+v", v)
+	}
+
+	// HTTP 发布的消息同样投递给 RESP 订阅者
+	if _, err := pubsub.Publish(&redis.PubSubMessage{Topic: "users", Sender: "user", Payload: []byte("updated")}); err != nil {
+		t.Fatal(err)
+	}
+	v = sub.read(t)
+	if v.Array[1].Text() != "users" || v.Array[2].Text() != "updated" {
+		t.Fatalf("Expected message on users, got %+v", v)
+	}
+
+	sub.send(t, "UNSUBSCRIBE")
+	for range 2 {
+		if v := sub.read(t); v.Array[0].Text() != "unsubscribe" {
+			t.Fatalf("Expected unsubscribe confirmation, got %+v", v)
+		}
+	}
+	if v := pub.do(t, "PUBLISH", "orders", "ignored"); v.Int != 0 {
+		t.Fatalf("Expected no receivers after unsubscribe, got %+v", v)
+	}
+
+	// 退出订阅状态后恢复普通命令
+	if v := sub.do(t, "GET", "a"); !v.Null {
+		t.Fatalf("Expected null reply, got %+v", v)
+	}
+}
@@ -0,0 +1,184 @@
+package redis
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// MembersRequest 集合成员请求
+type MembersRequest struct {
+	Key     string   `json:"key"`
+	Members []string `json:"members"`
+}
+
+// MemberRequest 单个成员请求
+type MemberRequest struct {
+	Key    string `json:"key"`
+	Member string `json:"member"`
+}
+
+func (s *Service) handleSAdd(e *core.RequestEvent) error {
+	var req MembersRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	added, err := s.SAdd(req.Key, req.Members...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"added": added})
+}
+
+func (s *Service) handleSRem(e *core.RequestEvent) error {
+	var req MembersRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	removed, err := s.SRem(req.Key, req.Members...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"removed": removed})
+}
+
+func (s *Service) handleSIsMember(e *core.RequestEvent) error {
+	var req MemberRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	ok, err := s.SIsMember(req.Key, req.Member)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"member": ok})
+}
+
+func (s *Service) handleSMembers(e *core.RequestEvent) error {
+	var req GetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	members, err := s.SMembers(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"members": members})
+}
+
+func (s *Service) handleSCard(e *core.RequestEvent) error {
+	var req GetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	count, err := s.SCard(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"count": count})
+}
+
+// SAdd 添加集合成员，返回新增数量
+func (s *Service) SAdd(key string, members ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindSet)
+	if err != nil {
+		return 0, err
+	}
+	added := make([]string, 0, len(members))
+	for _, m := range members {
+		if ok {
+			if _, exists := it.set[m]; exists {
+				continue
+			}
+		}
+		if !slices.Contains(added, m) {
+			added = append(added, m)
+		}
+	}
+	if len(added) == 0 {
+		return 0, nil
+	}
+	if err := s.commitLocked(&record{Op: opSAdd, Key: key, Members: added}); err != nil {
+		return 0, err
+	}
+	return len(added), nil
+}
+
+// SRem 删除集合成员，返回实际删除的数量；成员全部删除后删除键
+func (s *Service) SRem(key string, members ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindSet)
+	if err != nil || !ok {
+		return 0, err
+	}
+	removed := make([]string, 0, len(members))
+	for _, m := range members {
+		if _, exists := it.set[m]; exists && !slices.Contains(removed, m) {
+			removed = append(removed, m)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	if err := s.commitLocked(&record{Op: opSRem, Key: key, Members: removed}); err != nil {
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// SIsMember 检查成员是否在集合中
+func (s *Service) SIsMember(key, member string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindSet)
+	if err != nil || !ok {
+		return false, err
+	}
+	_, ok = it.set[member]
+	return ok, nil
+}
+
+// SMembers 返回集合的全部成员（无序）
+func (s *Service) SMembers(key string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindSet)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []string{}, nil
+	}
+	return slices.Collect(maps.Keys(it.set)), nil
+}
+
+// SCard 返回集合成员数量
+func (s *Service) SCard(key string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindSet)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return len(it.set), nil
+}
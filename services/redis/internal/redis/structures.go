@@ -0,0 +1,357 @@
+package redis
+
+import (
+	"cmp"
+	"container/list"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// 值类型（TYPE 命令的回复）
+const (
+	KindString = "string"
+	KindHash   = "hash"
+	KindList   = "list"
+	KindSet    = "set"
+	KindZSet   = "zset"
+)
+
+// ErrWrongType 键的值类型与操作不符
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// ErrNotFloat 分数不是有效的有限浮点数
+var ErrNotFloat = errors.New("value is not a valid float")
+
+// ZMember 有序集合成员
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// kind 返回数据项的值类型
+func (i *item) kind() string {
+	switch {
+	case i.hash != nil:
+		return KindHash
+	case i.list != nil:
+		return KindList
+	case i.set != nil:
+		return KindSet
+	case i.zset != nil:
+		return KindZSet
+	}
+	return KindString
+}
+
+// empty 集合类型的数据项没有元素时删除键（与 Redis 一致）
+func (i *item) empty() bool {
+	switch i.kind() {
+	case KindHash:
+		return len(i.hash) == 0
+	case KindList:
+		return i.list.Len() == 0
+	case KindSet:
+		return len(i.set) == 0
+	case KindZSet:
+		return len(i.zset.scores) == 0
+	}
+	return false
+}
+
+// clone 复制数据项，集合类型深拷贝（字符串写入后不再修改，共用即可）
+func (i *item) clone() *item {
+	c := &item{Value: i.Value, Expiration: i.Expiration, size: i.size}
+	switch i.kind() {
+	case KindHash:
+		c.hash = maps.Clone(i.hash)
+	case KindList:
+		c.list = list.New()
+		c.list.PushBackList(i.list)
+	case KindSet:
+		c.set = maps.Clone(i.set)
+	case KindZSet:
+		c.zset = &zset{scores: maps.Clone(i.zset.scores), sorted: slices.Clone(i.zset.sorted)}
+	}
+	return c
+}
+
+// records 以记录表示数据项（用于快照），重放后得到相同的数据项
+func (i *item) records(key string) []*record {
+	var rec *record
+	switch i.kind() {
+	case KindString:
+		return []*record{{Op: opSet, Key: key, Value: i.Value, Expiration: i.Expiration}}
+	case KindHash:
+		rec = &record{Op: opHSet, Key: key, Fields: i.hash}
+	case KindList:
+		rec = &record{Op: opPush, Key: key, Values: listValues(i.list, 0, i.list.Len())}
+	case KindSet:
+		rec = &record{Op: opSAdd, Key: key, Members: slices.Collect(maps.Keys(i.set))}
+	case KindZSet:
+		rec = &record{Op: opZAdd, Key: key}
+		for _, m := range i.zset.sorted {
+			rec.Members = append(rec.Members, m.Member)
+			rec.Scores = append(rec.Scores, m.Score)
+		}
+	}
+	if i.Expiration == 0 {
+		return []*record{rec}
+	}
+	return []*record{rec, {Op: opExpire, Key: key, Expiration: i.Expiration}}
+}
+
+// newItem 创建 kind 类型的空数据项
+func newItem(kind string) *item {
+	switch kind {
+	case KindHash:
+		return &item{hash: make(map[string][]byte)}
+	case KindList:
+		return &item{list: list.New()}
+	case KindSet:
+		return &item{set: make(map[string]struct{})}
+	case KindZSet:
+		return &item{zset: &zset{scores: make(map[string]float64)}}
+	}
+	return &item{}
+}
+
+// listValues 返回链表 [start, stop) 区间的值
+func listValues(l *list.List, start, stop int) [][]byte {
+	values := make([][]byte, 0, max(stop-start, 0))
+	i := 0
+	for e := l.Front(); e != nil && i < stop; e = e.Next() {
+		if i >= start {
+			values = append(values, e.Value.([]byte))
+		}
+		i++
+	}
+	return values
+}
+
+// normalizeRange 将 Redis 风格的闭区间下标（负数从末尾计数）转换为 [start, stop)，区间为空时返回 0, 0
+func normalizeRange(start, stop int64, n int) (int, int) {
+	if start < 0 {
+		start += int64(n)
+	}
+	if stop < 0 {
+		stop += int64(n)
+	}
+	start = max(start, 0)
+	stop = min(stop, int64(n)-1)
+	if start > stop {
+		return 0, 0
+	}
+	return int(start), int(stop) + 1
+}
+
+// validScore 分数必须是有限数（追加日志以 JSON 保存，无法表示 Inf 与 NaN）
+func validScore(score float64) error {
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return ErrNotFloat
+	}
+	return nil
+}
+
+// zset 有序集合：成员分数索引与按 (分数, 成员) 升序排列的切片
+type zset struct {
+	scores map[string]float64
+	sorted []ZMember
+}
+
+func compareZMember(a, b ZMember) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Member, b.Member)
+}
+
+// add 写入成员分数，新成员返回 true
+func (z *zset) add(member string, score float64) bool {
+	old, exists := z.scores[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		z.removeSorted(ZMember{member, old})
+	}
+	z.scores[member] = score
+	m := ZMember{member, score}
+	i, _ := slices.BinarySearchFunc(z.sorted, m, compareZMember)
+	z.sorted = slices.Insert(z.sorted, i, m)
+	return !exists
+}
+
+// remove 删除成员，成员存在时返回 true
+func (z *zset) remove(member string) bool {
+	score, ok := z.scores[member]
+	if !ok {
+		return false
+	}
+	delete(z.scores, member)
+	z.removeSorted(ZMember{member, score})
+	return true
+}
+
+func (z *zset) removeSorted(m ZMember) {
+	if i, ok := slices.BinarySearchFunc(z.sorted, m, compareZMember); ok {
+		z.sorted = slices.Delete(z.sorted, i, i+1)
+	}
+}
+
+// rank 返回成员按分数升序的排名（从 0 开始）
+func (z *zset) rank(member string) (int, bool) {
+	score, ok := z.scores[member]
+	if !ok {
+		return 0, false
+	}
+	i, _ := slices.BinarySearchFunc(z.sorted, ZMember{member, score}, compareZMember)
+	return i, true
+}
+
+// rangeByScore 返回分数在 [min, max] 内的成员（升序），limit <= 0 表示不限
+func (z *zset) rangeByScore(min, max float64, limit int) []ZMember {
+	start, _ := slices.BinarySearchFunc(z.sorted, min, func(m ZMember, score float64) int {
+		if m.Score < score {
+			return -1
+		}
+		return 1
+	})
+
+	members := make([]ZMember, 0)
+	for _, m := range z.sorted[start:] {
+		if m.Score > max || (limit > 0 && len(members) >= limit) {
+			break
+		}
+		members = append(members, m)
+	}
+	return members
+}
+
+// applyRecord 将一条记录应用到 items，写操作与日志重放共用，保证重放结果与写入时一致
+// 记录不检查过期时间：写入时已过期的键会先单独记录删除；数据项的占用随记录增量更新
+func applyRecord(items map[string]*item, rec *record) error {
+	// get 返回 kind 类型的数据项，不存在时新建
+	get := func(kind string) (*item, error) {
+		it, ok := items[rec.Key]
+		if !ok {
+			it = newItem(kind)
+			it.size = keySize(rec.Key)
+			items[rec.Key] = it
+		} else if it.kind() != kind {
+			return nil, ErrWrongType
+		}
+		return it, nil
+	}
+
+	var it *item
+	var err error
+	switch rec.Op {
+	case opSet:
+		items[rec.Key] = &item{Value: rec.Value, Expiration: rec.Expiration, size: keySize(rec.Key) + int64(len(rec.Value))}
+		return nil
+	case opDel:
+		delete(items, rec.Key)
+		return nil
+	case opClear:
+		clear(items)
+		return nil
+	case opExpire:
+		// 数据项的 Value 与 Expiration 写入后不再修改，复制后替换
+		if old, ok := items[rec.Key]; ok {
+			c := *old
+			c.Expiration = rec.Expiration
+			items[rec.Key] = &c
+		}
+		return nil
+	case opHSet:
+		if it, err = get(KindHash); err == nil {
+			for f, v := range rec.Fields {
+				if old, ok := it.hash[f]; ok {
+					it.size += int64(len(v) - len(old))
+				} else {
+					it.size += entrySize(len(f) + len(v))
+				}
+				it.hash[f] = v
+			}
+		}
+	case opHDel:
+		if it, err = get(KindHash); err == nil {
+			for _, f := range rec.Members {
+				if old, ok := it.hash[f]; ok {
+					it.size -= entrySize(len(f) + len(old))
+					delete(it.hash, f)
+				}
+			}
+		}
+	case opPush:
+		if it, err = get(KindList); err == nil {
+			for _, v := range rec.Values {
+				it.size += entrySize(len(v))
+				if rec.Left {
+					it.list.PushFront(v)
+				} else {
+					it.list.PushBack(v)
+				}
+			}
+		}
+	case opPop:
+		if it, err = get(KindList); err == nil {
+			for n := 0; n < rec.Count && it.list.Len() > 0; n++ {
+				e := it.list.Back()
+				if rec.Left {
+					e = it.list.Front()
+				}
+				it.size -= entrySize(len(it.list.Remove(e).([]byte)))
+			}
+		}
+	case opSAdd:
+		if it, err = get(KindSet); err == nil {
+			for _, m := range rec.Members {
+				if _, ok := it.set[m]; !ok {
+					it.size += entrySize(len(m))
+					it.set[m] = struct{}{}
+				}
+			}
+		}
+	case opSRem:
+		if it, err = get(KindSet); err == nil {
+			for _, m := range rec.Members {
+				if _, ok := it.set[m]; ok {
+					it.size -= entrySize(len(m))
+					delete(it.set, m)
+				}
+			}
+		}
+	case opZAdd:
+		if len(rec.Scores) != len(rec.Members) {
+			return errors.New("zadd record: members and scores mismatch")
+		}
+		if it, err = get(KindZSet); err == nil {
+			for i, m := range rec.Members {
+				if it.zset.add(m, rec.Scores[i]) {
+					it.size += zsetEntrySize(len(m))
+				}
+			}
+		}
+	case opZRem:
+		if it, err = get(KindZSet); err == nil {
+			for _, m := range rec.Members {
+				if it.zset.remove(m) {
+					it.size -= zsetEntrySize(len(m))
+				}
+			}
+		}
+	default:
+		return errors.New("unknown op " + rec.Op)
+	}
+	if err != nil {
+		return err
+	}
+	if it.empty() {
+		delete(items, rec.Key)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/config"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// backendClients 分别通过 HTTP 接口与 RESP 监听访问同一个缓存服务
+func backendClients(t *testing.T) map[string]*cache.Cache {
+	t.Helper()
+
+	svc, ts := newTestServer(t)
+	server := redis.NewRESPServer(svc, nil)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	backend := cache.NewRedisBackend(cache.RedisOptions{Addr: server.Addr().String()})
+	t.Cleanup(func() {
+		backend.Close()
+		server.Close()
+	})
+
+	return map[string]*cache.Cache{
+		"http": cache.NewWithURL(ts.URL),
+		"resp": cache.NewWithBackend(backend),
+	}
+}
+
+func TestCacheHash(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "hash:" + name
+			added, err := c.HSet(key, map[string]any{"name": "alice", "age": 30})
+			if err != nil || added != 2 {
+				t.Fatalf("Expected 2 added fields, got %d (%v)", added, err)
+			}
+			if added, _ := c.HSet(key, map[string]any{"name": "bob", "city": "x"}); added != 1 {
+				t.Fatalf("Expected 1 added field, got %d", added)
+			}
+
+			var value string
+			if ok, err := c.HGet(key, "name", &value); err != nil || !ok || value != "bob" {
+				t.Fatalf("Expected bob, got %q (%v, %v)", value, ok, err)
+			}
+			if ok, err := c.HGet(key, "missing", &value); err != nil || ok {
+				t.Fatalf("Expected missing field, got %v (%v)", ok, err)
+			}
+
+			if n, err := c.HIncrBy(key, "age", 5); err != nil || n != 35 {
+				t.Fatalf("Expected 35, got %d (%v)", n, err)
+			}
+			if _, err := c.HIncrBy(key, "name", 1); err == nil {
+				t.Fatal("Expected an error for a non-integer field")
+			}
+
+			fields, err := c.HGetAll(key)
+			if err != nil || len(fields) != 3 || string(fields["age"]) != "35" {
+				t.Fatalf("Expected 3 fields with age 35, got %q (%v)", fields, err)
+			}
+
+			if n, err := c.HDel(key, "name", "age", "missing"); err != nil || n != 2 {
+				t.Fatalf("Expected 2 removed fields, got %d (%v)", n, err)
+			}
+			c.HDel(key, "city")
+			if c.Exists(key) {
+				t.Fatal("Expected the hash to be deleted with its last field")
+			}
+
+			// 类型不符
+			c.Set("string:"+name, "x")
+			if _, err := c.HSet("string:"+name, map[string]any{"f": 1}); err == nil {
+				t.Fatal("Expected a wrong type error")
+			}
+		})
+	}
+}
+
+func TestCacheList(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "queue:" + name
+			if n, err := c.RPush(key, "a", "b"); err != nil || n != 2 {
+				t.Fatalf("Expected length 2, got %d (%v)", n, err)
+			}
+			if n, _ := c.LPush(key, "z"); n != 3 {
+				t.Fatalf("Expected length 3, got %d", n)
+			}
+
+			values, err := c.LRange(key, 0, -1)
+			if err != nil || len(values) != 3 || string(values[0]) != `"z"` || string(values[2]) != `"b"` {
+				t.Fatalf("Expected [z a b], got %q (%v)", values, err)
+			}
+			if values, _ := c.LRange(key, -2, 10); len(values) != 2 {
+				t.Fatalf("Expected the last 2 values, got %q", values)
+			}
+
+			var value string
+			if ok, err := c.LPop(key, &value); err != nil || !ok || value != "z" {
+				t.Fatalf("Expected z, got %q (%v, %v)", value, ok, err)
+			}
+			if ok, _ := c.RPop(key, &value); !ok || value != "b" {
+				t.Fatalf("Expected b, got %q", value)
+			}
+			if n, _ := c.LLen(key); n != 1 {
+				t.Fatalf("Expected length 1, got %d", n)
+			}
+			c.LPop(key, &value)
+			if ok, err := c.LPop(key, &value); err != nil || ok {
+				t.Fatalf("Expected an empty list, got %v (%v)", ok, err)
+			}
+
+			// 超时
+			start := time.Now()
+			if _, ok, err := c.BLPop(100*time.Millisecond, &value, key); err != nil || ok {
+				t.Fatalf("Expected a timeout, got %v (%v)", ok, err)
+			}
+			if time.Since(start) < 100*time.Millisecond {
+				t.Fatal("Expected BLPop to wait for the timeout")
+			}
+
+			// 等待期间写入的值被取出
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				c.RPush("other:"+name, "job")
+			}()
+			popped, ok, err := c.BLPop(5*time.Second, &value, key, "other:"+name)
+			if err != nil || !ok || popped != "other:"+name || value != "job" {
+				t.Fatalf("Expected job from other, got %q %q (%v, %v)", popped, value, ok, err)
+			}
+		})
+	}
+}
+
+func TestCacheSet(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "set:" + name
+			if n, err := c.SAdd(key, "a", "b", "a"); err != nil || n != 2 {
+				t.Fatalf("Expected 2 added members, got %d (%v)", n, err)
+			}
+			if n, _ := c.SAdd(key, "b", "c"); n != 1 {
+				t.Fatalf("Expected 1 added member, got %d", n)
+			}
+			if ok, err := c.SIsMember(key, "c"); err != nil || !ok {
+				t.Fatalf("Expected c to be a member, got %v (%v)", ok, err)
+			}
+			members, err := c.SMembers(key)
+			slices.Sort(members)
+			if err != nil || !slices.Equal(members, []string{"a", "b", "c"}) {
+				t.Fatalf("Expected [a b c], got %v (%v)", members, err)
+			}
+			if n, _ := c.SRem(key, "a", "x"); n != 1 {
+				t.Fatalf("Expected 1 removed member, got %d", n)
+			}
+			if n, _ := c.SCard(key); n != 2 {
+				t.Fatalf("Expected 2 members, got %d", n)
+			}
+		})
+	}
+}
+
+func TestCacheSortedSet(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "leaderboard:" + name
+			n, err := c.ZAdd(key,
+				cache.ZMember{Member: "alice", Score: 30},
+				cache.ZMember{Member: "bob", Score: 10},
+				cache.ZMember{Member: "carol", Score: 20},
+			)
+			if err != nil || n != 3 {
+				t.Fatalf("Expected 3 added members, got %d (%v)", n, err)
+			}
+			if score, err := c.ZIncrBy(key, "bob", 25.5); err != nil || score != 35.5 {
+				t.Fatalf("Expected 35.5, got %v (%v)", score, err)
+			}
+
+			top, err := c.ZRevRange(key, 0, 1)
+			if err != nil || len(top) != 2 || top[0] != (cache.ZMember{Member: "bob", Score: 35.5}) || top[1].Member != "alice" {
+				t.Fatalf("Expected [bob alice], got %v (%v)", top, err)
+			}
+			if all, _ := c.ZRange(key, 0, -1); len(all) != 3 || all[0].Member != "carol" {
+				t.Fatalf("Expected carol first, got %v", all)
+			}
+
+			if rank, ok, err := c.ZRevRank(key, "carol"); err != nil || !ok || rank != 2 {
+				t.Fatalf("Expected rank 2, got %d (%v, %v)", rank, ok, err)
+			}
+			if rank, ok, _ := c.ZRank(key, "carol"); !ok || rank != 0 {
+				t.Fatalf("Expected rank 0, got %d", rank)
+			}
+			if _, ok, _ := c.ZRank(key, "dave"); ok {
+				t.Fatal("Expected dave to be missing")
+			}
+			if score, ok, _ := c.ZScore(key, "alice"); !ok || score != 30 {
+				t.Fatalf("Expected 30, got %v", score)
+			}
+
+			members, err := c.ZRangeByScore(key, 20, math.Inf(1), 0)
+			if err != nil || len(members) != 3 {
+				t.Fatalf("Expected 3 members with score >= 20, got %v (%v)", members, err)
+			}
+			if members, _ := c.ZRangeByScore(key, math.Inf(-1), 31, 1); len(members) != 1 || members[0].Member != "carol" {
+				t.Fatalf("Expected [carol], got %v", members)
+			}
+
+			if n, _ := c.ZRem(key, "carol", "dave"); n != 1 {
+				t.Fatalf("Expected 1 removed member, got %d", n)
+			}
+			if n, _ := c.ZCard(key); n != 2 {
+				t.Fatalf("Expected 2 members, got %d", n)
+			}
+		})
+	}
+}
+
+func TestCacheConditionalWrites(t *testing.T) {
+	for name, c := range backendClients(t) {
+		t.Run(name, func(t *testing.T) {
+			key := "lock:" + name
+			if ok, err := c.SetNX(key, "owner-1", time.Minute); err != nil || !ok {
+				t.Fatalf("Expected SetNX to succeed, got %v (%v)", ok, err)
+			}
+			if ok, _ := c.SetNX(key, "owner-2", time.Minute); ok {
+				t.Fatal("Expected SetNX to fail on an existing key")
+			}
+		})
+	}
+
+	// 比较并写入使用 EVAL，仅通过 HTTP 接口验证
+	c := backendClients(t)["http"]
+	c.Set("version", 1)
+	if ok, err := c.CompareAndSwap("version", 2, 3, 0); err != nil || ok {
+		t.Fatalf("Expected CompareAndSwap to fail on a stale value, got %v (%v)", ok, err)
+	}
+	if ok, err := c.CompareAndSwap("version", 1, 2, time.Minute); err != nil || !ok {
+		t.Fatalf("Expected CompareAndSwap to succeed, got %v (%v)", ok, err)
+	}
+	if ok, _ := c.CompareAndSwap("missing", nil, 1, 0); ok {
+		t.Fatal("Expected CompareAndSwap to fail on a missing key")
+	}
+	if ok, _ := c.CompareAndDelete("version", 1); ok {
+		t.Fatal("Expected CompareAndDelete to fail on a stale value")
+	}
+	if ok, err := c.CompareAndDelete("version", 2); err != nil || !ok {
+		t.Fatalf("Expected CompareAndDelete to succeed, got %v (%v)", ok, err)
+	}
+	if c.Exists("version") {
+		t.Fatal("Expected version to be deleted")
+	}
+}
+
+func TestBlockingPopWakesOneWaiter(t *testing.T) {
+	svc := redis.NewService("redis-test")
+	defer svc.Stop()
+
+	results := make(chan string, 2)
+	for range 2 {
+		go func() {
+			_, value, ok, _ := svc.BlockingPop(context.Background(), []string{"jobs"}, true, 500*time.Millisecond)
+			if ok {
+				results <- string(value)
+			} else {
+				results <- ""
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	svc.Push("jobs", false, []byte("job-1"))
+
+	got := []string{<-results, <-results}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"", "job-1"}) {
+		t.Fatalf("Expected exactly one waiter to receive the job, got %q", got)
+	}
+
+	// ctx 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := svc.BlockingPop(ctx, []string{"jobs"}, true, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPersistenceStructures(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.RedisPersistenceConfig{Dir: dir, Fsync: redis.FsyncAlways}
+
+	svc := newPersistentService(t, cfg)
+	svc.HSet("hash", map[string][]byte{"a": []byte("1"), "b": []byte("2")})
+	svc.HDel("hash", "a")
+	svc.Push("queue", false, []byte("1"), []byte("2"), []byte("3"))
+	svc.Pop("queue", true)
+	svc.SAdd("set", "x", "y")
+	svc.SRem("set", "x")
+	svc.ZAdd("zset", redis.ZMember{Member: "m", Score: 1}, redis.ZMember{Member: "n", Score: 2})
+	svc.ZIncrBy("zset", "m", 5)
+	svc.Expire("hash", time.Hour)
+
+	// 模拟快照写入后、日志截断前崩溃：快照之后恢复压缩前的完整日志
+	aofPath := filepath.Join(dir, redis.AOFFile)
+	before, err := os.ReadFile(aofPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	svc.Pop("queue", true)
+	after, err := os.ReadFile(aofPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(aofPath, append(before, after...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newPersistentService(t, cfg)
+	defer restored.Stop()
+
+	if value, ok, _ := restored.HGet("hash", "b"); !ok || string(value) != "2" {
+		t.Fatalf("Expected hash.b=2, got %q (%v)", value, ok)
+	}
+	if _, ok, _ := restored.HGet("hash", "a"); ok {
+		t.Fatal("Expected hash.a to stay deleted")
+	}
+	if ttl, ok := restored.TTL("hash"); !ok || ttl <= 0 {
+		t.Fatalf("Expected hash to keep its TTL, got %s (%v)", ttl, ok)
+	}
+	// 快照已包含的 pop 不会重复应用
+	values, _ := restored.LRange("queue", 0, -1)
+	if len(values) != 1 || string(values[0]) != "3" {
+		t.Fatalf("Expected queue [3], got %q", values)
+	}
+	if members, _ := restored.SMembers("set"); !slices.Equal(members, []string{"y"}) {
+		t.Fatalf("Expected set [y], got %v", members)
+	}
+	if members, _ := restored.ZRange("zset", 0, -1, false); len(members) != 2 || members[1] != (redis.ZMember{Member: "m", Score: 6}) {
+		t.Fatalf("Expected m with score 6 last, got %v", members)
+	}
+}
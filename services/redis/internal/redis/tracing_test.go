@@ -0,0 +1,156 @@
+package redis_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goback/pkg/app/core"
+	"github.com/goback/pkg/app/tools/router"
+	"github.com/goback/pkg/cache"
+	"github.com/goback/pkg/tracing"
+	"github.com/goback/services/redis/internal/redis"
+)
+
+// newTracedServer 启动带链路追踪中间件的缓存与 PubSub 服务
+func newTracedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	svc := redis.NewService("redis-test")
+	pubsub := redis.NewPubSubService()
+	r := router.NewRouter(func(w http.ResponseWriter, req *http.Request) (*core.RequestEvent, router.EventCleanupFunc) {
+		event := new(core.RequestEvent)
+		event.Response = w
+		event.Request = req
+		return event, nil
+	})
+	r.Bind(core.TracingMiddleware())
+	svc.RegisterRoutes(r)
+	pubsub.RegisterRoutes(r)
+
+	mux, err := r.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(func() {
+		ts.Close()
+		pubsub.Stop()
+		_ = svc.Stop()
+	})
+	return ts
+}
+
+// useTestTracer 使用内存导出器替换全局 Tracer
+func useTestTracer(t *testing.T) *tracing.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracing.NewInMemoryExporter()
+	prev := tracing.SetTracer(tracing.NewTracer("test", exporter))
+	t.Cleanup(func() { tracing.SetTracer(prev) })
+	return exporter
+}
+
+// findSpan 等待名称为 name 的 Span 导出
+func findSpan(t *testing.T, exporter *tracing.InMemoryExporter, name string) *tracing.Span {
+	t.Helper()
+
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		for _, span := range exporter.Spans() {
+			if span.Name == name {
+				return span
+			}
+		}
+	}
+	t.Fatalf("Expected span %q to be exported", name)
+	return nil
+}
+
+func TestCacheTracing(t *testing.T) {
+	exporter := useTestTracer(t)
+	ts := newTracedServer(t)
+
+	ctx, root := tracing.Start(context.Background(), "GET /users", tracing.SpanKindServer)
+	c := cache.NewWithURL(ts.URL).WithContext(ctx)
+	if err := c.Set("user:1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	client := findSpan(t, exporter, "cache set")
+	if client.Kind != tracing.SpanKindClient || client.ParentID != root.Context.SpanID {
+		t.Fatalf("Expected cache span to be a child of the request span, got %+v", client)
+	}
+	server := findSpan(t, exporter, "POST /cache/set")
+	if server.Context.TraceID != root.Context.TraceID || server.ParentID != client.Context.SpanID {
+		t.Fatalf("Expected redis service span to continue the cache call, got %+v", server)
+	}
+
+	// 不在链路中的调用不产生客户端 Span
+	exporter.Reset()
+	if err := cache.NewWithURL(ts.URL).Set("user:2", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	findSpan(t, exporter, "POST /cache/set")
+	for _, span := range exporter.Spans() {
+		if strings.HasPrefix(span.Name, "cache ") {
+			t.Fatalf("Unexpected client span %q outside a trace", span.Name)
+		}
+	}
+}
+
+func TestPubSubTracing(t *testing.T) {
+	exporter := useTestTracer(t)
+	ts := newTracedServer(t)
+	redisAddr := strings.TrimPrefix(ts.URL, "http://")
+
+	// 订阅方
+	received := make(chan *core.PubSubMessage, 1)
+	var sub *core.PubSub
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.Handler()(w, r)
+	}))
+	t.Cleanup(subscriber.Close)
+	sub = core.NewPubSub("log-service", strings.TrimPrefix(subscriber.URL, "http://"), core.WithRedisAddr(redisAddr))
+	sub.Subscribe("log:access", func(msg *core.PubSubMessage) error {
+		received <- msg
+		return nil
+	})
+	if err := sub.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sub.Stop() })
+
+	// 发布方在请求链路中发布
+	pub := core.NewPubSub("gateway-service", "127.0.0.1:0", core.WithRedisAddr(redisAddr))
+	ctx, root := tracing.Start(context.Background(), "GET /api/v1/users", tracing.SpanKindServer)
+	if err := pub.PublishContext(ctx, "log:access", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+
+	var msg *core.PubSubMessage
+	select {
+	case msg = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected message to be delivered")
+	}
+
+	consumer := tracing.SpanFromContext(msg.Context())
+	if consumer == nil || consumer.Context.TraceID != root.Context.TraceID {
+		t.Fatalf("Expected handler context to continue the publisher trace, got %+v", consumer)
+	}
+
+	producer := findSpan(t, exporter, "pubsub publish log:access")
+	server := findSpan(t, exporter, "POST /pubsub/publish")
+	if producer.ParentID != root.Context.SpanID || server.ParentID != producer.Context.SpanID {
+		t.Fatal("Expected publish span chain request -> producer -> redis service")
+	}
+	if consumer.ParentID != server.Context.SpanID || consumer.Kind != tracing.SpanKindConsumer {
+		t.Fatalf("Expected consumer span to be a child of the redis service span, got %+v", consumer)
+	}
+}
@@ -0,0 +1,334 @@
+package redis
+
+import (
+	"math"
+	"slices"
+
+	"github.com/goback/pkg/app/apis"
+	"github.com/goback/pkg/app/core"
+)
+
+// ZAddRequest 有序集合写入请求
+type ZAddRequest struct {
+	Key     string    `json:"key"`
+	Members []ZMember `json:"members"`
+}
+
+// ZIncrByRequest 有序集合分数自增请求
+type ZIncrByRequest struct {
+	Key    string  `json:"key"`
+	Member string  `json:"member"`
+	Delta  float64 `json:"delta"`
+}
+
+// ZRankRequest 成员排名请求
+type ZRankRequest struct {
+	Key     string `json:"key"`
+	Member  string `json:"member"`
+	Reverse bool   `json:"reverse"` // true 按分数降序排名
+}
+
+// ZRangeRequest 按排名区间查询，下标为闭区间，负数从末尾计数
+type ZRangeRequest struct {
+	Key     string `json:"key"`
+	Start   int64  `json:"start"`
+	Stop    int64  `json:"stop"`
+	Reverse bool   `json:"reverse"` // true 按分数降序
+}
+
+// ZRangeByScoreRequest 按分数区间查询（升序），Min/Max 为空表示不限
+type ZRangeByScoreRequest struct {
+	Key   string   `json:"key"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Limit int      `json:"limit"` // 0 表示不限
+}
+
+func (s *Service) handleZAdd(e *core.RequestEvent) error {
+	var req ZAddRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	added, err := s.ZAdd(req.Key, req.Members...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"added": added})
+}
+
+func (s *Service) handleZIncrBy(e *core.RequestEvent) error {
+	var req ZIncrByRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	score, err := s.ZIncrBy(req.Key, req.Member, req.Delta)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"score": score})
+}
+
+func (s *Service) handleZScore(e *core.RequestEvent) error {
+	var req MemberRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	score, ok, err := s.ZScore(req.Key, req.Member)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"score": score, "found": ok})
+}
+
+func (s *Service) handleZRem(e *core.RequestEvent) error {
+	var req MembersRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	removed, err := s.ZRem(req.Key, req.Members...)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"removed": removed})
+}
+
+func (s *Service) handleZRank(e *core.RequestEvent) error {
+	var req ZRankRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	rank, ok, err := s.ZRank(req.Key, req.Member, req.Reverse)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"rank": rank, "found": ok})
+}
+
+func (s *Service) handleZRange(e *core.RequestEvent) error {
+	var req ZRangeRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	members, err := s.ZRange(req.Key, req.Start, req.Stop, req.Reverse)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"members": members})
+}
+
+func (s *Service) handleZRangeByScore(e *core.RequestEvent) error {
+	var req ZRangeByScoreRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	min, max := math.Inf(-1), math.Inf(1)
+	if req.Min != nil {
+		min = *req.Min
+	}
+	if req.Max != nil {
+		max = *req.Max
+	}
+	members, err := s.ZRangeByScore(req.Key, min, max, req.Limit)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"members": members})
+}
+
+func (s *Service) handleZCard(e *core.RequestEvent) error {
+	var req GetRequest
+	if err := e.BindBody(&req); err != nil {
+		return apis.Error(e, 400, err.Error())
+	}
+
+	count, err := s.ZCard(req.Key)
+	if err != nil {
+		return apis.Error(e, errorStatus(err), err.Error())
+	}
+
+	return e.JSON(200, map[string]any{"count": count})
+}
+
+// ZAdd 写入成员分数（已存在的成员更新分数），返回新增成员数量
+func (s *Service) ZAdd(key string, members ...ZMember) (int, error) {
+	for _, m := range members {
+		if err := validScore(m.Score); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindZSet)
+	if err != nil {
+		return 0, err
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	rec := &record{Op: opZAdd, Key: key}
+	seen := make(map[string]bool, len(members))
+	added := 0
+	for _, m := range members {
+		rec.Members = append(rec.Members, m.Member)
+		rec.Scores = append(rec.Scores, m.Score)
+		if seen[m.Member] {
+			continue
+		}
+		seen[m.Member] = true
+		if ok {
+			if _, exists := it.zset.scores[m.Member]; exists {
+				continue
+			}
+		}
+		added++
+	}
+	if err := s.commitLocked(rec); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// ZIncrBy 将成员分数增加 delta（成员不存在时从 0 开始），返回新分数
+func (s *Service) ZIncrBy(key, member string, delta float64) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindZSet)
+	if err != nil {
+		return 0, err
+	}
+	var score float64
+	if ok {
+		score = it.zset.scores[member]
+	}
+	score += delta
+	if err := validScore(score); err != nil {
+		return 0, err
+	}
+	if err := s.commitLocked(&record{Op: opZAdd, Key: key, Members: []string{member}, Scores: []float64{score}}); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// ZScore 返回成员分数
+func (s *Service) ZScore(key, member string) (float64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindZSet)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	score, ok := it.zset.scores[member]
+	return score, ok, nil
+}
+
+// ZRem 删除成员，返回实际删除的数量；成员全部删除后删除键
+func (s *Service) ZRem(key string, members ...string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok, err := s.typedLocked(key, KindZSet)
+	if err != nil || !ok {
+		return 0, err
+	}
+	removed := make([]string, 0, len(members))
+	for _, m := range members {
+		if _, exists := it.zset.scores[m]; exists && !slices.Contains(removed, m) {
+			removed = append(removed, m)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	if err := s.commitLocked(&record{Op: opZRem, Key: key, Members: removed}); err != nil {
+		return 0, err
+	}
+	return len(removed), nil
+}
+
+// ZRank 返回成员排名（从 0 开始），reverse 为 true 时按分数降序
+func (s *Service) ZRank(key, member string, reverse bool) (int, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindZSet)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	rank, ok := it.zset.rank(member)
+	if ok && reverse {
+		rank = len(it.zset.sorted) - 1 - rank
+	}
+	return rank, ok, nil
+}
+
+// ZRange 返回排名闭区间 [start, stop] 的成员，负数下标从末尾计数；reverse 为 true 时按分数降序
+func (s *Service) ZRange(key string, start, stop int64, reverse bool) ([]ZMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindZSet)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []ZMember{}, nil
+	}
+
+	n := len(it.zset.sorted)
+	from, to := normalizeRange(start, stop, n)
+	if !reverse {
+		return slices.Clone(it.zset.sorted[from:to]), nil
+	}
+	members := make([]ZMember, 0, to-from)
+	for i := from; i < to; i++ {
+		members = append(members, it.zset.sorted[n-1-i])
+	}
+	return members, nil
+}
+
+// ZRangeByScore 返回分数在闭区间 [min, max] 内的成员（升序），limit <= 0 表示不限
+func (s *Service) ZRangeByScore(key string, min, max float64, limit int) ([]ZMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindZSet)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []ZMember{}, nil
+	}
+	return it.zset.rangeByScore(min, max, limit), nil
+}
+
+// ZCard 返回成员数量
+func (s *Service) ZCard(key string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	it, ok, err := s.lookup(key, KindZSet)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return len(it.zset.scores), nil
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/goback/pkg/app/apis"
 	"github.com/goback/pkg/app/core"
 	"github.com/goback/pkg/auth"
+	"github.com/goback/pkg/cache"
 	"github.com/goback/pkg/config"
 	"github.com/goback/pkg/database"
 	"github.com/goback/pkg/logger"
 	pkgRegistry "github.com/goback/pkg/registry"
+	"github.com/goback/pkg/tracing"
 	authpkg "github.com/goback/services/user/internal/auth"
 	"github.com/goback/services/user/internal/dept"
 	"github.com/goback/services/user/internal/model"
@@ -37,6 +39,13 @@ func main() {
 	logger.Init(&cfg.Log)
 	defer logger.Sync()
 
+	// 初始化链路追踪（未开启导出时只传递 traceparent）
+	tracing.Init(serviceName, &cfg.Tracing)
+
+	// 初始化缓存客户端（按 redis.mode 选择缓存服务或外部 Redis）
+	cache.InitWithConfig(&cfg.Redis)
+	defer cache.Close()
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -51,6 +60,7 @@ func main() {
 		ServiceVersion: "v1.0.0",
 		ServiceAddress: addr,
 		BasePath:       basePath,
+		PublicPaths:    []string{"/health", "/auth/login", "/auth/register", "/auth/refresh"},
 		Registry:       pkgRegistry.NewRedisRegistry(),
 		IsDev:          cfg.App.Env == "dev",
 		RedisAddr:      fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -95,34 +105,34 @@ func main() {
 
 		// 认证路由组（部分需要认证）
 		authGroup := e.Router.Group("/auth")
-		authGroup.POST("/login", authpkg.Login(jwtManager))
-		authGroup.POST("/register", authpkg.Register)
-		authGroup.POST("/logout", authpkg.Logout).Bind(jwtMiddleware)
-		authGroup.POST("/refresh", authpkg.RefreshToken(jwtManager))
+		authGroup.POST("/login", authpkg.Login(jwtManager)).Describe("登录").Request(authpkg.LoginRequest{}).Response(authpkg.LoginResponse{})
+		authGroup.POST("/register", authpkg.Register).Describe("注册").Request(user.CreateRequest{})
+		authGroup.POST("/logout", authpkg.Logout).Bind(jwtMiddleware).Describe("登出")
+		authGroup.POST("/refresh", authpkg.RefreshToken(jwtManager)).Describe("刷新令牌").Response(auth.TokenInfo{})
 
 		// 用户管理路由组
 		userGroup := e.Router.Group("/users")
 		userGroup.Bind(jwtMiddleware)
-		userGroup.POST("", user.Create)
-		userGroup.PUT("/{id}", user.Update)
-		userGroup.DELETE("/{id}", user.Delete)
-		userGroup.GET("/{id}", user.Get)
-		userGroup.GET("", user.List)
-		userGroup.PUT("/{id}/password/reset", user.ResetPassword)
+		userGroup.POST("", user.Create).Describe("创建用户").Request(user.CreateRequest{}).Response(model.User{})
+		userGroup.PUT("/{id}", user.Update).Describe("更新用户").Request(user.UpdateRequest{}).Response(model.User{})
+		userGroup.DELETE("/{id}", user.Delete).Describe("删除用户")
+		userGroup.GET("/{id}", user.Get).Describe("获取用户").Response(model.User{})
+		userGroup.GET("", user.List).Describe("用户列表").Request(user.ListRequest{}).PagedResponse(model.User{})
+		userGroup.PUT("/{id}/password/reset", user.ResetPassword).Describe("重置用户密码")
 		// 个人信息
-		userGroup.GET("/profile", user.GetProfile)
-		userGroup.PUT("/profile", user.UpdateProfile)
-		userGroup.PUT("/profile/password", user.ChangePassword)
+		userGroup.GET("/profile", user.GetProfile).Describe("获取个人信息").Response(model.User{})
+		userGroup.PUT("/profile", user.UpdateProfile).Describe("更新个人信息").Request(user.UpdateRequest{}).Response(model.User{})
+		userGroup.PUT("/profile/password", user.ChangePassword).Describe("修改密码").Request(user.ChangePasswordRequest{})
 
 		// 部门路由组
 		deptGroup := e.Router.Group("/depts")
 		deptGroup.Bind(jwtMiddleware)
-		deptGroup.POST("", dept.Create)
-		deptGroup.PUT("/{id}", dept.Update)
-		deptGroup.DELETE("/{id}", dept.Delete)
-		deptGroup.GET("/{id}", dept.Get)
-		deptGroup.GET("", dept.List)
-		deptGroup.GET("/tree", dept.GetTree)
+		deptGroup.POST("", dept.Create).Describe("创建部门").Request(dept.CreateRequest{}).Response(model.Dept{})
+		deptGroup.PUT("/{id}", dept.Update).Describe("更新部门").Request(dept.UpdateRequest{}).Response(model.Dept{})
+		deptGroup.DELETE("/{id}", dept.Delete).Describe("删除部门")
+		deptGroup.GET("/{id}", dept.Get).Describe("获取部门").Response(model.Dept{})
+		deptGroup.GET("", dept.List).Describe("部门列表").Request(dept.ListRequest{}).Response([]model.Dept{})
+		deptGroup.GET("/tree", dept.GetTree).Describe("部门树").Response([]model.Dept{})
 
 		return e.Next()
 	})
@@ -1,5 +1,7 @@
 package dept
 
+import "github.com/goback/pkg/dal"
+
 // CreateRequest 创建部门请求
 type CreateRequest struct {
 	ParentID int64  `json:"parentId"`
@@ -21,3 +23,6 @@ type UpdateRequest struct {
 	Email    string `json:"email" binding:"omitempty,email"`
 	Status   int8   `json:"status"`
 }
+
+// ListRequest 部门列表请求（使用 PocketBase 风格参数）
+type ListRequest = dal.ListParams